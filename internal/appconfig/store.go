@@ -10,14 +10,16 @@ import (
 )
 
 type Config struct {
-	DefaultRoom string `json:"defaultRoom,omitempty"`
-	Format      string `json:"format,omitempty"`
+	DefaultRoom  string        `json:"defaultRoom,omitempty"`
+	Format       string        `json:"format,omitempty"`
+	VolumeLimits []VolumeLimit `json:"volumeLimits,omitempty"`
 }
 
 func (c Config) Normalize() Config {
 	out := Config{
-		DefaultRoom: strings.TrimSpace(c.DefaultRoom),
-		Format:      strings.ToLower(strings.TrimSpace(c.Format)),
+		DefaultRoom:  strings.TrimSpace(c.DefaultRoom),
+		Format:       strings.ToLower(strings.TrimSpace(c.Format)),
+		VolumeLimits: normalizeVolumeLimits(c.VolumeLimits),
 	}
 	if out.Format == "" {
 		out.Format = "plain"
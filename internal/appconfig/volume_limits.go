@@ -0,0 +1,110 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VolumeLimit caps a room's volume, either always (empty Window) or during a
+// daily local-time window such as "22:00-07:00", which may wrap midnight.
+type VolumeLimit struct {
+	Room   string `json:"room"`
+	Max    int    `json:"max"`
+	Window string `json:"window,omitempty"`
+}
+
+// Validate reports a missing room, an out-of-range max or a malformed window.
+func (l VolumeLimit) Validate() error {
+	if strings.TrimSpace(l.Room) == "" {
+		return errors.New("room is required")
+	}
+	if l.Max < 0 || l.Max > 100 {
+		return errors.New("max volume must be between 0 and 100")
+	}
+	if strings.TrimSpace(l.Window) != "" {
+		if _, _, err := ParseWindow(l.Window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActiveAt reports whether the limit applies at t. A window that does not
+// parse is treated as always active, so a typo never lifts a limit.
+func (l VolumeLimit) ActiveAt(t time.Time) bool {
+	if strings.TrimSpace(l.Window) == "" {
+		return true
+	}
+	start, end, err := ParseWindow(l.Window)
+	if err != nil {
+		return true
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start == end {
+		return true
+	}
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// ParseWindow parses "HH:MM-HH:MM" into minutes after midnight.
+func ParseWindow(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid window %q (expected HH:MM-HH:MM, e.g. 22:00-07:00)", s)
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// MaxVolume returns the tightest limit in effect for room at t (rooms match
+// case-insensitively); ok is false when none applies.
+func (c Config) MaxVolume(room string, t time.Time) (limit int, ok bool) {
+	room = strings.TrimSpace(room)
+	for _, l := range c.VolumeLimits {
+		if !strings.EqualFold(l.Room, room) || !l.ActiveAt(t) {
+			continue
+		}
+		if !ok || l.Max < limit {
+			limit, ok = l.Max, true
+		}
+	}
+	return limit, ok
+}
+
+func normalizeVolumeLimits(in []VolumeLimit) []VolumeLimit {
+	var out []VolumeLimit
+	for _, l := range in {
+		l.Room = strings.TrimSpace(l.Room)
+		l.Window = strings.TrimSpace(l.Window)
+		if l.Room == "" {
+			continue
+		}
+		l.Max = min(max(l.Max, 0), 100)
+		out = append(out, l)
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package appconfig
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVolumeLimitWindows(t *testing.T) {
+	t.Parallel()
+
+	at := func(hhmm string) time.Time {
+		ts, err := time.Parse("15:04", hhmm)
+		if err != nil {
+			t.Fatalf("parse %s: %v", hhmm, err)
+		}
+		return ts
+	}
+	cfg := Config{VolumeLimits: []VolumeLimit{
+		{Room: "Kids", Max: 40},
+		{Room: "kids", Max: 20, Window: "22:00-07:00"},
+		{Room: "Office", Max: 30, Window: "12:00-13:30"},
+	}}
+
+	for _, tc := range []struct {
+		room, at string
+		want     int
+		ok       bool
+	}{
+		{"Kids", "21:59", 40, true},
+		{"Kids", "22:00", 20, true},
+		{"KIDS", "03:15", 20, true},
+		{"Kids", "07:00", 40, true},
+		{"Office", "12:30", 30, true},
+		{"Office", "13:30", 0, false},
+		{"Kitchen", "23:00", 0, false},
+	} {
+		got, ok := cfg.MaxVolume(tc.room, at(tc.at))
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("MaxVolume(%s, %s) = %d, %v; want %d, %v", tc.room, tc.at, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	if (VolumeLimit{Room: "Kids", Max: 20, Window: "late"}).ActiveAt(at("12:00")) != true {
+		t.Fatalf("malformed window should fail safe to always active")
+	}
+	for _, bad := range []VolumeLimit{
+		{Max: 10},
+		{Room: "Kids", Max: 101},
+		{Room: "Kids", Max: 10, Window: "22:00"},
+		{Room: "Kids", Max: 10, Window: "25:00-07:00"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected Validate error for %+v", bad)
+		}
+	}
+}
+
+func TestVolumeLimitsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	cfg := Config{VolumeLimits: []VolumeLimit{{Room: " Kids ", Max: 120, Window: " 22:00-07:00 "}, {Room: " ", Max: 5}}}
+	if err := s.Save(cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got.VolumeLimits) != 1 || got.VolumeLimits[0] != (VolumeLimit{Room: "Kids", Max: 100, Window: "22:00-07:00"}) {
+		t.Fatalf("unexpected limits: %+v", got.VolumeLimits)
+	}
+}
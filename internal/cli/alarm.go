@@ -0,0 +1,451 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type alarmClient interface {
+	ListAlarms(ctx context.Context) (sonos.AlarmList, error)
+	CreateAlarm(ctx context.Context, alarm sonos.Alarm) (int, error)
+	UpdateAlarm(ctx context.Context, alarm sonos.Alarm) error
+	DestroyAlarm(ctx context.Context, id int) error
+	GetTopology(ctx context.Context) (sonos.Topology, error)
+	ListFavorites(ctx context.Context, start, count int) (sonos.FavoritesPage, error)
+}
+
+// Alarms are stored household-wide, so any reachable speaker can manage them.
+var newAlarmClient = func(ctx context.Context, flags *rootFlags) (alarmClient, error) {
+	if strings.TrimSpace(flags.IP) != "" {
+		return newSonosClient(flags.IP, flags.Timeout), nil
+	}
+	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	if len(devs) == 0 {
+		return nil, errors.New("no speakers found")
+	}
+	return newSonosClient(devs[0].IP, flags.Timeout), nil
+}
+
+type alarmView struct {
+	sonos.Alarm
+	Room   string `json:"room"`
+	Source string `json:"source"`
+}
+
+type alarmOptions struct {
+	time           string
+	duration       time.Duration
+	recurrence     string
+	room           string
+	volume         int
+	mode           string
+	source         string
+	includeGrouped bool
+	disabled       bool
+}
+
+func newAlarmCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alarm",
+		Short: "Manage Sonos alarms",
+		Long:  "Lists, creates, updates and deletes household alarms (AlarmClock service). Alarms are shared by every speaker in the household.",
+	}
+	cmd.AddCommand(newAlarmListCmd(flags))
+	cmd.AddCommand(newAlarmAddCmd(flags))
+	cmd.AddCommand(newAlarmUpdateCmd(flags))
+	cmd.AddCommand(newAlarmDeleteCmd(flags))
+	cmd.AddCommand(newAlarmEnableCmd(flags, true))
+	cmd.AddCommand(newAlarmEnableCmd(flags, false))
+	return cmd
+}
+
+func newAlarmListCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List alarms",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			list, err := c.ListAlarms(ctx)
+			if err != nil {
+				return err
+			}
+			top, err := c.GetTopology(ctx)
+			if err != nil {
+				return err
+			}
+			views := make([]alarmView, 0, len(list.Alarms))
+			for _, a := range list.Alarms {
+				views = append(views, newAlarmView(top, a))
+			}
+
+			if isJSON(flags) {
+				return writeJSON(cmd, map[string]any{"version": list.Version, "alarms": views})
+			}
+			if isTSV(flags) {
+				for _, v := range views {
+					writeAlarmRow(cmd.OutOrStdout(), v)
+				}
+				return nil
+			}
+			if len(views) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No alarms.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "ID\tTIME\tRECURRENCE\tROOM\tENABLED\tVOLUME\tMODE\tSOURCE\n")
+			for _, v := range views {
+				writeAlarmRow(w, v)
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
+
+func writeAlarmRow(w io.Writer, v alarmView) {
+	enabled := "no"
+	if v.Enabled {
+		enabled = "yes"
+	}
+	_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n", v.ID, v.StartTime, v.Recurrence, v.Room, enabled, v.Volume, v.PlayMode, v.Source)
+}
+
+func newAlarmView(top sonos.Topology, a sonos.Alarm) alarmView {
+	room := a.RoomUUID
+	if mem, ok := top.FindByUUID(a.RoomUUID); ok && mem.Name != "" {
+		room = mem.Name
+	}
+	return alarmView{Alarm: a, Room: room, Source: alarmSourceLabel(a)}
+}
+
+func alarmSourceLabel(a sonos.Alarm) string {
+	if a.ProgramURI == "" || a.ProgramURI == sonos.AlarmBuzzerURI {
+		return "Chime"
+	}
+	if items, err := sonos.ParseDIDLItems(a.ProgramMetaData); err == nil && len(items) > 0 && items[0].Title != "" {
+		return items[0].Title
+	}
+	return a.ProgramURI
+}
+
+func newAlarmAddCmd(flags *rootFlags) *cobra.Command {
+	opts := alarmOptions{duration: time.Hour, recurrence: "once", volume: 20, mode: "normal"}
+	cmd := &cobra.Command{
+		Use:   "add --time <HH:MM>",
+		Short: "Create an alarm",
+		Long: `Creates an alarm in a room (--room, or the --name/--ip target).
+
+Recurrence: once, daily, weekdays, weekends, or a day list such as "mon,wed,fri".
+Source: a Sonos Favorite title or a URI; defaults to the Sonos chime.`,
+		Example:      "  sonos alarm add --name Bedroom --time 07:00 --recurrence weekdays --source \"Morning Jazz\" --volume 15",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(opts.time) == "" {
+				return errors.New("--time is required")
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarm := sonos.Alarm{Enabled: true}
+			if err := applyAlarmOptions(ctx, cmd, flags, c, &alarm, opts, true); err != nil {
+				return err
+			}
+			id, err := c.CreateAlarm(ctx, alarm)
+			if err != nil {
+				return err
+			}
+			alarm.ID = id
+			if isJSON(flags) {
+				return writeOK(cmd, flags, "alarm.add", map[string]any{"id": id, "alarm": alarm})
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), id)
+			return nil
+		},
+	}
+	addAlarmFlags(cmd, &opts)
+	cmd.Flags().BoolVar(&opts.disabled, "disabled", false, "Create the alarm disabled")
+	return cmd
+}
+
+func newAlarmUpdateCmd(flags *rootFlags) *cobra.Command {
+	var opts alarmOptions
+	cmd := &cobra.Command{
+		Use:          "update <id>",
+		Short:        "Change an existing alarm",
+		Long:         "Changes only the fields given as flags; everything else is kept.",
+		Example:      "  sonos alarm update 3 --time 06:30 --recurrence mon,tue,wed",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseAlarmID(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarm, err := findAlarm(ctx, c, id)
+			if err != nil {
+				return err
+			}
+			if err := applyAlarmOptions(ctx, cmd, flags, c, &alarm, opts, false); err != nil {
+				return err
+			}
+			if err := c.UpdateAlarm(ctx, alarm); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "alarm.update", map[string]any{"id": id, "alarm": alarm})
+		},
+	}
+	addAlarmFlags(cmd, &opts)
+	return cmd
+}
+
+func newAlarmDeleteCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "delete <id>",
+		Short:        "Delete an alarm",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseAlarmID(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			if err := c.DestroyAlarm(ctx, id); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "alarm.delete", map[string]any{"id": id})
+		},
+	}
+	return cmd
+}
+
+func newAlarmEnableCmd(flags *rootFlags, enabled bool) *cobra.Command {
+	use, short, action := "enable <id>", "Enable an alarm", "alarm.enable"
+	if !enabled {
+		use, short, action = "disable <id>", "Disable an alarm", "alarm.disable"
+	}
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseAlarmID(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarm, err := findAlarm(ctx, c, id)
+			if err != nil {
+				return err
+			}
+			alarm.Enabled = enabled
+			if err := c.UpdateAlarm(ctx, alarm); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, action, map[string]any{"id": id})
+		},
+	}
+	return cmd
+}
+
+func addAlarmFlags(cmd *cobra.Command, opts *alarmOptions) {
+	cmd.Flags().StringVar(&opts.time, "time", opts.time, "Start time (HH:MM or HH:MM:SS, speaker local time)")
+	cmd.Flags().DurationVar(&opts.duration, "duration", opts.duration, "How long the alarm plays (e.g. 30m, 1h)")
+	cmd.Flags().StringVar(&opts.recurrence, "recurrence", opts.recurrence, "once|daily|weekdays|weekends|mon,tue,...")
+	cmd.Flags().StringVar(&opts.room, "room", "", "Room for the alarm (defaults to the --name/--ip target)")
+	cmd.Flags().IntVar(&opts.volume, "volume", opts.volume, "Alarm volume (0-100)")
+	cmd.Flags().StringVar(&opts.mode, "mode", opts.mode, "Play mode: normal|shuffle|shuffle-norepeat|repeat|repeat-one")
+	cmd.Flags().StringVar(&opts.source, "source", "", "Sonos Favorite title or URI (default: chime)")
+	cmd.Flags().BoolVar(&opts.includeGrouped, "include-grouped", false, "Also play on rooms grouped with the alarm room")
+}
+
+// applyAlarmOptions copies flag values onto alarm. For updates (all=false)
+// only flags set on the command line are applied.
+func applyAlarmOptions(ctx context.Context, cmd *cobra.Command, flags *rootFlags, c alarmClient, alarm *sonos.Alarm, opts alarmOptions, all bool) error {
+	set := func(name string) bool { return all || cmd.Flags().Changed(name) }
+
+	if set("time") {
+		t, err := sonos.NormalizeAlarmTime(opts.time)
+		if err != nil {
+			return err
+		}
+		alarm.StartTime = t
+	}
+	if set("duration") {
+		if opts.duration <= 0 || opts.duration >= 24*time.Hour {
+			return errors.New("--duration must be between 1s and 24h")
+		}
+		alarm.Duration = formatAlarmDuration(opts.duration)
+	}
+	if set("recurrence") {
+		r, err := sonos.NormalizeRecurrence(opts.recurrence)
+		if err != nil {
+			return err
+		}
+		alarm.Recurrence = r
+	}
+	if set("volume") {
+		if opts.volume < 0 || opts.volume > 100 {
+			return errors.New("--volume must be 0-100")
+		}
+		alarm.Volume = opts.volume
+	}
+	if set("mode") {
+		mode, err := parsePlayModeName(opts.mode)
+		if err != nil {
+			return err
+		}
+		alarm.PlayMode = mode
+	}
+	if set("include-grouped") {
+		alarm.IncludeLinkedZones = opts.includeGrouped
+	}
+	if all {
+		alarm.Enabled = !opts.disabled
+	}
+	if set("source") {
+		uri, meta, err := resolveAlarmSource(ctx, c, opts.source)
+		if err != nil {
+			return err
+		}
+		alarm.ProgramURI = uri
+		alarm.ProgramMetaData = meta
+	}
+	if all || cmd.Flags().Changed("room") {
+		room, ip := strings.TrimSpace(opts.room), ""
+		if room == "" {
+			room, ip = flags.Name, flags.IP
+		}
+		if strings.TrimSpace(room) == "" && strings.TrimSpace(ip) == "" {
+			return errors.New("provide --room (or --name/--ip)")
+		}
+		top, err := c.GetTopology(ctx)
+		if err != nil {
+			return err
+		}
+		mem, err := resolveMember(top, room, ip)
+		if err != nil {
+			return err
+		}
+		alarm.RoomUUID = mem.UUID
+	}
+	return nil
+}
+
+func findAlarm(ctx context.Context, c alarmClient, id int) (sonos.Alarm, error) {
+	list, err := c.ListAlarms(ctx)
+	if err != nil {
+		return sonos.Alarm{}, err
+	}
+	alarm, ok := list.Find(id)
+	if !ok {
+		return sonos.Alarm{}, fmt.Errorf("alarm not found: %d", id)
+	}
+	return alarm, nil
+}
+
+func parseAlarmID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || id <= 0 {
+		return 0, errors.New("alarm id must be a positive integer (see `sonos alarm list`)")
+	}
+	return id, nil
+}
+
+func formatAlarmDuration(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+// parsePlayModeName accepts the `sonos mode` names as well as raw Sonos values.
+func parsePlayModeName(s string) (sonos.PlayMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "normal":
+		return sonos.PlayModeNormal, nil
+	case "shuffle":
+		return sonos.PlayModeShuffle, nil
+	case "shuffle-norepeat", "shuffle_norepeat":
+		return sonos.PlayModeShuffleNoRepeat, nil
+	case "repeat", "repeat-all", "repeat_all":
+		return sonos.PlayModeRepeatAll, nil
+	case "repeat-one", "repeat_one":
+		return sonos.PlayModeRepeatOne, nil
+	default:
+		return "", fmt.Errorf("invalid play mode: %q (expected normal|shuffle|shuffle-norepeat|repeat|repeat-one)", s)
+	}
+}
+
+var uriSchemeRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:\S+$`)
+
+// resolveAlarmSource turns --source into a ProgramURI and metadata. Favorite
+// titles win over URIs so a favorite named "x:y" still resolves as a favorite.
+func resolveAlarmSource(ctx context.Context, c alarmClient, source string) (string, string, error) {
+	source = strings.TrimSpace(source)
+	switch strings.ToLower(source) {
+	case "", "chime", "buzzer":
+		return sonos.AlarmBuzzerURI, "", nil
+	}
+
+	const pageSize = 100
+	start := 0
+	for {
+		page, err := c.ListFavorites(ctx, start, pageSize)
+		if err != nil {
+			return "", "", err
+		}
+		for _, it := range page.Items {
+			if !strings.EqualFold(it.Item.Title, source) {
+				continue
+			}
+			uri := sonos.FavoriteURI(it.Item)
+			if uri == "" {
+				return "", "", errors.New("favorite has no URI: " + it.Item.Title)
+			}
+			return uri, it.Item.ResMD, nil
+		}
+		start += page.NumberReturned
+		if page.NumberReturned == 0 || start >= page.TotalMatches {
+			break
+		}
+	}
+
+	if uriSchemeRE.MatchString(source) {
+		return source, "", nil
+	}
+	return "", "", errors.New("favorite not found: " + source)
+}
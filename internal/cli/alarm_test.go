@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type fakeAlarmClient struct {
+	list      sonos.AlarmList
+	top       sonos.Topology
+	favorites sonos.FavoritesPage
+
+	created   []sonos.Alarm
+	updated   []sonos.Alarm
+	destroyed []int
+}
+
+func (f *fakeAlarmClient) ListAlarms(ctx context.Context) (sonos.AlarmList, error) {
+	return f.list, nil
+}
+
+func (f *fakeAlarmClient) CreateAlarm(ctx context.Context, alarm sonos.Alarm) (int, error) {
+	f.created = append(f.created, alarm)
+	return 42, nil
+}
+
+func (f *fakeAlarmClient) UpdateAlarm(ctx context.Context, alarm sonos.Alarm) error {
+	f.updated = append(f.updated, alarm)
+	return nil
+}
+
+func (f *fakeAlarmClient) DestroyAlarm(ctx context.Context, id int) error {
+	f.destroyed = append(f.destroyed, id)
+	return nil
+}
+
+func (f *fakeAlarmClient) GetTopology(ctx context.Context) (sonos.Topology, error) {
+	return f.top, nil
+}
+
+func (f *fakeAlarmClient) ListFavorites(ctx context.Context, start, count int) (sonos.FavoritesPage, error) {
+	return f.favorites, nil
+}
+
+func newFakeAlarmClient(t *testing.T) *fakeAlarmClient {
+	t.Helper()
+	top := sonos.Topology{
+		Groups: []sonos.Group{{
+			ID:          "RINCON_A:1",
+			Coordinator: sonos.Member{Name: "Bedroom", IP: "192.0.2.1", UUID: "RINCON_A", IsVisible: true, IsCoordinator: true},
+			Members:     []sonos.Member{{Name: "Bedroom", IP: "192.0.2.1", UUID: "RINCON_A", IsVisible: true, IsCoordinator: true}},
+		}},
+		ByName: map[string]sonos.Member{"Bedroom": {Name: "Bedroom", IP: "192.0.2.1", UUID: "RINCON_A", IsVisible: true, IsCoordinator: true}},
+		ByIP:   map[string]sonos.Member{"192.0.2.1": {Name: "Bedroom", IP: "192.0.2.1", UUID: "RINCON_A", IsVisible: true, IsCoordinator: true}},
+	}
+	fake := &fakeAlarmClient{
+		top: top,
+		list: sonos.AlarmList{Version: "RINCON_A:3", Alarms: []sonos.Alarm{{
+			ID: 3, StartTime: "07:00:00", Duration: "01:00:00", Recurrence: "WEEKDAYS", Enabled: true,
+			RoomUUID: "RINCON_A", ProgramURI: sonos.AlarmBuzzerURI, PlayMode: sonos.PlayModeNormal, Volume: 20,
+		}}},
+		favorites: sonos.FavoritesPage{
+			Items: []sonos.FavoriteItem{{Position: 1, Item: sonos.DIDLItem{
+				Title: "Morning Jazz",
+				ResMD: `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/"><item id="x"><dc:title>Morning Jazz</dc:title><res>x-sonosapi-stream:jazz</res></item></DIDL-Lite>`,
+			}}},
+			NumberReturned: 1,
+			TotalMatches:   1,
+		},
+	}
+	orig := newAlarmClient
+	t.Cleanup(func() { newAlarmClient = orig })
+	newAlarmClient = func(ctx context.Context, flags *rootFlags) (alarmClient, error) {
+		return fake, nil
+	}
+	return fake
+}
+
+func runAlarmCmd(t *testing.T, flags *rootFlags, args ...string) (string, error) {
+	t.Helper()
+	cmd := newAlarmCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestAlarmListFormats(t *testing.T) {
+	newFakeAlarmClient(t)
+
+	out, err := runAlarmCmd(t, &rootFlags{Timeout: time.Second, Format: formatPlain}, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out, "RECURRENCE") || !strings.Contains(out, "Bedroom") || !strings.Contains(out, "Chime") {
+		t.Fatalf("unexpected plain output: %s", out)
+	}
+
+	out, err = runAlarmCmd(t, &rootFlags{Timeout: time.Second, Format: formatTSV}, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if out != "3\t07:00:00\tWEEKDAYS\tBedroom\tyes\t20\tNORMAL\tChime\n" {
+		t.Fatalf("unexpected tsv output: %q", out)
+	}
+
+	out, err = runAlarmCmd(t, &rootFlags{Timeout: time.Second, Format: formatJSON}, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var got struct {
+		Version string `json:"version"`
+		Alarms  []struct {
+			ID   int    `json:"id"`
+			Room string `json:"room"`
+		} `json:"alarms"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	if got.Version != "RINCON_A:3" || len(got.Alarms) != 1 || got.Alarms[0].Room != "Bedroom" {
+		t.Fatalf("unexpected json: %+v", got)
+	}
+}
+
+func TestAlarmAddResolvesRoomAndFavorite(t *testing.T) {
+	fake := newFakeAlarmClient(t)
+
+	out, err := runAlarmCmd(t, &rootFlags{Name: "bedroom", Timeout: time.Second, Format: formatPlain},
+		"add", "--time", "6:30", "--recurrence", "mon,wed", "--source", "morning jazz", "--volume", "12", "--mode", "shuffle-norepeat", "--duration", "45m")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if strings.TrimSpace(out) != "42" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if len(fake.created) != 1 {
+		t.Fatalf("created: %d", len(fake.created))
+	}
+	a := fake.created[0]
+	if a.StartTime != "06:30:00" || a.Duration != "00:45:00" || a.Recurrence != "ON_13" || a.RoomUUID != "RINCON_A" ||
+		a.ProgramURI != "x-sonosapi-stream:jazz" || !strings.Contains(a.ProgramMetaData, "Morning Jazz") ||
+		a.PlayMode != sonos.PlayModeShuffleNoRepeat || a.Volume != 12 || !a.Enabled {
+		t.Fatalf("unexpected alarm: %+v", a)
+	}
+}
+
+func TestAlarmAddErrors(t *testing.T) {
+	newFakeAlarmClient(t)
+	flags := &rootFlags{Timeout: time.Second}
+
+	cases := [][]string{
+		{"add", "--room", "Bedroom"},
+		{"add", "--time", "07:00"},
+		{"add", "--time", "07:00", "--room", "Bedroom", "--source", "Unknown Station"},
+		{"add", "--time", "07:00", "--room", "Bedroom", "--mode", "loud"},
+		{"add", "--time", "07:00", "--room", "Bedroom", "--volume", "101"},
+		{"add", "--time", "07:00", "--room", "Bedroom", "--recurrence", "someday"},
+		{"add", "--time", "07:00", "--room", "Garage"},
+	}
+	for _, args := range cases {
+		if _, err := runAlarmCmd(t, flags, args...); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestAlarmAddURISource(t *testing.T) {
+	fake := newFakeAlarmClient(t)
+	if _, err := runAlarmCmd(t, &rootFlags{Timeout: time.Second}, "add", "--time", "07:00", "--room", "Bedroom", "--source", "x-rincon-mp3radio://example.com/live", "--disabled"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if a := fake.created[0]; a.ProgramURI != "x-rincon-mp3radio://example.com/live" || a.ProgramMetaData != "" || a.Enabled {
+		t.Fatalf("unexpected alarm: %+v", a)
+	}
+}
+
+func TestAlarmUpdateOnlyChangesGivenFlags(t *testing.T) {
+	fake := newFakeAlarmClient(t)
+
+	out, err := runAlarmCmd(t, &rootFlags{Timeout: time.Second, Format: formatJSON}, "update", "3", "--volume", "35")
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !strings.Contains(out, `"action": "alarm.update"`) {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	a := fake.updated[0]
+	if a.ID != 3 || a.Volume != 35 || a.StartTime != "07:00:00" || a.Recurrence != "WEEKDAYS" || a.ProgramURI != sonos.AlarmBuzzerURI || !a.Enabled {
+		t.Fatalf("unexpected alarm: %+v", a)
+	}
+
+	if _, err := runAlarmCmd(t, &rootFlags{Timeout: time.Second}, "update", "9", "--volume", "35"); err == nil || !strings.Contains(err.Error(), "alarm not found") {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := runAlarmCmd(t, &rootFlags{Timeout: time.Second}, "update", "abc"); err == nil {
+		t.Fatalf("expected invalid id error")
+	}
+}
+
+func TestAlarmEnableDisableDelete(t *testing.T) {
+	fake := newFakeAlarmClient(t)
+	flags := &rootFlags{Timeout: time.Second, Format: formatJSON}
+
+	if _, err := runAlarmCmd(t, flags, "disable", "3"); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if fake.updated[0].Enabled {
+		t.Fatalf("expected disabled alarm")
+	}
+	if _, err := runAlarmCmd(t, flags, "enable", "3"); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if !fake.updated[1].Enabled {
+		t.Fatalf("expected enabled alarm")
+	}
+	out, err := runAlarmCmd(t, flags, "delete", "3")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(fake.destroyed) != 1 || fake.destroyed[0] != 3 || !strings.Contains(out, `"alarm.delete"`) {
+		t.Fatalf("unexpected delete: %v %s", fake.destroyed, out)
+	}
+}
+
+func TestE2EAlarmLifecycle(t *testing.T) {
+	h := newFakeHousehold(t, "Bedroom", "Kitchen")
+	h.Speaker("Kitchen").SetFavorites(sonostest.Favorite{Title: "Radio", URI: "x-rincon-mp3radio://example.com/live"})
+
+	out, err := runFake(t, "alarm", "add", "--name", "Bedroom", "--time", "07:15", "--recurrence", "weekdays", "--source", "Radio")
+	if err != nil {
+		t.Fatalf("alarm add: %v (%s)", err, out)
+	}
+	alarms := h.Alarms()
+	if len(alarms) != 1 || alarms[0].RoomUUID != h.Speaker("Bedroom").UUID || alarms[0].ProgramURI != "x-rincon-mp3radio://example.com/live" {
+		t.Fatalf("unexpected alarms: %+v", alarms)
+	}
+	id := strings.TrimSpace(out)
+
+	if _, err := runFake(t, "alarm", "disable", id); err != nil {
+		t.Fatalf("alarm disable: %v", err)
+	}
+	if _, err := runFake(t, "alarm", "update", id, "--room", "Kitchen", "--time", "08:00"); err != nil {
+		t.Fatalf("alarm update: %v", err)
+	}
+	out, err = runFake(t, "alarm", "list", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("alarm list: %v", err)
+	}
+	if want := id + "\t08:00:00\tWEEKDAYS\tKitchen\tno\t20\tNORMAL\tx-rincon-mp3radio://example.com/live\n"; out != want {
+		t.Fatalf("unexpected list:\n%q\nwant\n%q", out, want)
+	}
+
+	if _, err := runFake(t, "alarm", "delete", id); err != nil {
+		t.Fatalf("alarm delete: %v", err)
+	}
+	if n := len(h.Alarms()); n != 0 {
+		t.Fatalf("alarms left: %d", n)
+	}
+}
@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// announcePollInterval is the fallback transport poll used while waiting for
+// the clip to end (in case GENA callbacks cannot reach this machine).
+var announcePollInterval = 2 * time.Second
+
+// announceStartGrace is how long a stopped transport is taken to mean "not
+// started yet" before it is accepted as the end of a clip too short to catch
+// playing.
+var announceStartGrace = 5 * time.Second
+
+func newAnnounceCmd(flags *rootFlags) *cobra.Command {
+	var (
+		names       []string
+		volume      int
+		maxDuration time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "announce <file|url>",
+		Short: "Play a clip on rooms, then resume what was playing",
+		Long: `Plays a short clip (doorbell, build chime, ...) on one or more rooms and then puts
+everything back: each affected group's source, queue position, volume and grouping is
+snapshotted first and restored after the clip stops.
+
+The rooms are temporarily grouped under the first --name. Local files are served from a
+short-lived HTTP server on this machine, so the speakers must be able to reach it.`,
+		Example:      "  sonos announce --name Kitchen ./doorbell.mp3\n  sonos announce --name Kitchen --name Office --volume 40 https://example.com/build-failed.mp3",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targets := names
+			if len(targets) == 0 && strings.TrimSpace(flags.IP) != "" {
+				targets = []string{strings.TrimSpace(flags.IP)}
+			}
+			if len(targets) == 0 && strings.TrimSpace(flags.Name) != "" {
+				targets = []string{strings.TrimSpace(flags.Name)}
+			}
+			if len(targets) == 0 {
+				return errors.New("provide --name (repeatable) or --ip")
+			}
+			if cmd.Flags().Changed("volume") && (volume < 0 || volume > 100) {
+				return errors.New("--volume must be 0..100")
+			}
+			if maxDuration <= 0 {
+				return errors.New("--max-duration must be positive")
+			}
+			clip := strings.TrimSpace(args[0])
+			if !isAnnounceURI(clip) {
+				if _, err := os.Stat(clip); err != nil {
+					return fmt.Errorf("clip must be a readable file or a URL: %w", err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			a := &announcement{flags: flags, clip: clip}
+			if cmd.Flags().Changed("volume") {
+				a.volume = &volume
+			}
+			if err := a.resolve(ctx, targets); err != nil {
+				return err
+			}
+			if err := a.snapshot(ctx); err != nil {
+				return err
+			}
+
+			uri, playErr := a.play(ctx, maxDuration)
+			restoreErr := a.restore(ctx)
+			if playErr != nil {
+				return playErr
+			}
+			if restoreErr != nil {
+				return fmt.Errorf("restore after announcement: %w", restoreErr)
+			}
+
+			rooms := make([]string, 0, len(a.rooms))
+			for _, m := range a.rooms {
+				rooms = append(rooms, m.Name)
+			}
+			out := map[string]any{"rooms": rooms, "uri": uri}
+			if a.volume != nil {
+				out["volume"] = *a.volume
+			}
+			return writeOK(cmd, flags, "announce", out)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&names, "name", nil, "Room to announce on (repeatable; the first one leads the temporary group)")
+	cmd.Flags().IntVar(&volume, "volume", 0, "Announcement volume for every room (0-100; default keeps current volumes)")
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 2*time.Minute, "Stop the clip and restore after this long")
+	_ = cmd.RegisterFlagCompletionFunc("name", nameFlagCompletion(flags))
+	return cmd
+}
+
+// isAnnounceURI reports whether clip is a URI the speaker can fetch itself
+// rather than a local file path.
+func isAnnounceURI(clip string) bool {
+	return strings.Contains(clip, "://") || strings.HasPrefix(clip, "x-")
+}
+
+type announcement struct {
+	flags  *rootFlags
+	clip   string
+	volume *int
+
+	top       sonos.Topology
+	rooms     []sonos.Member
+	snapshots []*sonos.Snapshot
+}
+
+func (a *announcement) resolve(ctx context.Context, targets []string) error {
+	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: a.flags.Timeout})
+	if err != nil {
+		return err
+	}
+	if len(devs) == 0 {
+		return errors.New("no speakers found")
+	}
+	a.top, err = newSonosClient(devs[0].IP, a.flags.Timeout).GetTopology(ctx)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, t := range targets {
+		m, err := resolveMember(a.top, t, "")
+		if err != nil {
+			return err
+		}
+		if seen[m.UUID] {
+			continue
+		}
+		seen[m.UUID] = true
+		a.rooms = append(a.rooms, m)
+	}
+	return nil
+}
+
+// snapshot records every group that contains one of the rooms.
+func (a *announcement) snapshot(ctx context.Context) error {
+	seen := map[string]bool{}
+	for _, m := range a.rooms {
+		group, ok := a.top.GroupForIP(m.IP)
+		if !ok || seen[group.Coordinator.UUID] {
+			continue
+		}
+		seen[group.Coordinator.UUID] = true
+		snap, err := sonos.TakeSnapshot(ctx, withVolumeLimit(newSonosClient(group.Coordinator.IP, a.flags.Timeout), a.flags))
+		if err != nil {
+			return err
+		}
+		a.snapshots = append(a.snapshots, snap)
+	}
+	return nil
+}
+
+// play groups the rooms, plays the clip on the lead room and waits for it to
+// finish. It returns the URI that was played.
+func (a *announcement) play(ctx context.Context, maxDuration time.Duration) (string, error) {
+	lead := a.rooms[0]
+	leadClient := newSonosClient(lead.IP, a.flags.Timeout)
+
+	wanted := map[string]bool{}
+	for _, m := range a.rooms {
+		wanted[m.UUID] = true
+	}
+	group, _ := a.top.GroupForIP(lead.IP)
+	isolate := group.Coordinator.UUID != lead.UUID
+	for _, m := range group.Members {
+		if m.IsVisible && !wanted[m.UUID] {
+			isolate = true
+		}
+	}
+	if isolate {
+		if err := leadClient.LeaveGroup(ctx); err != nil {
+			return "", err
+		}
+	}
+	for _, m := range a.rooms[1:] {
+		if !isolate && groupHasMember(group, m.UUID) {
+			continue
+		}
+		if err := newSonosClient(m.IP, a.flags.Timeout).JoinGroup(ctx, lead.UUID); err != nil {
+			return "", err
+		}
+	}
+	if a.volume != nil {
+		for _, m := range a.rooms {
+			if err := withVolumeLimit(newSonosClient(m.IP, a.flags.Timeout), a.flags).SetVolume(ctx, *a.volume); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	uri := a.clip
+	if !isAnnounceURI(a.clip) {
+		served, closeServer, err := serveAnnouncementFile(a.clip, lead.IP)
+		if err != nil {
+			return "", err
+		}
+		defer closeServer()
+		uri = served
+	}
+
+	// Events tell us promptly when the clip ends; polling covers networks where
+	// the speaker cannot reach the callback server.
+	var events <-chan sonos.Event
+	if em, err := sonos.NewEventManager(lead.IP, sonos.EventManagerOptions{}); err == nil {
+		defer closeEventManager(ctx, em, a.flags.Timeout)
+		if _, err := em.Subscribe(ctx, leadClient, sonos.ServiceAVTransport); err == nil {
+			events = em.Events()
+		}
+	}
+
+	if err := leadClient.PlayURI(ctx, uri, ""); err != nil {
+		return "", err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, maxDuration)
+	defer cancel()
+	if err := waitForTransportStop(waitCtx, leadClient, events); err != nil {
+		// Ran too long or interrupted: cut the clip before restoring.
+		stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), a.flags.Timeout)
+		defer stopCancel()
+		_ = leadClient.StopOrNoop(stopCtx)
+	}
+	return uri, nil
+}
+
+func groupHasMember(group sonos.Group, uuid string) bool {
+	for _, m := range group.Members {
+		if m.UUID == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+// restore reinstates every snapshot, even after Ctrl+C. Groups led by other
+// rooms go first so the lead room's group is rebuilt last.
+func (a *announcement) restore(ctx context.Context) error {
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 4*a.flags.Timeout)
+	defer cancel()
+
+	lead := a.rooms[0].UUID
+	ordered := make([]*sonos.Snapshot, 0, len(a.snapshots))
+	var leadSnaps []*sonos.Snapshot
+	for _, s := range a.snapshots {
+		if s.CoordinatorUUID == lead {
+			leadSnaps = append(leadSnaps, s)
+			continue
+		}
+		ordered = append(ordered, s)
+	}
+	ordered = append(ordered, leadSnaps...)
+
+	var errs []error
+	for _, s := range ordered {
+		if err := s.Restore(rctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type transportInfoGetter interface {
+	GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error)
+}
+
+// waitForTransportStop returns once the transport leaves playback after
+// having started. The initial GENA event (SEQ 0) reflects the state before
+// the clip and is ignored.
+func waitForTransportStop(ctx context.Context, c transportInfoGetter, events <-chan sonos.Event) error {
+	ticker := time.NewTicker(announcePollInterval)
+	defer ticker.Stop()
+
+	started := false
+	deadline := time.Now().Add(announceStartGrace)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-events:
+			state := ev.Vars["transport_state"]
+			if ev.Seq == 0 || state == "" {
+				continue
+			}
+			switch state {
+			case "PLAYING", "TRANSITIONING":
+				started = true
+			case "STOPPED", "PAUSED_PLAYBACK":
+				if started {
+					return nil
+				}
+			}
+		case <-ticker.C:
+			info, err := c.GetTransportInfo(ctx)
+			if err != nil {
+				continue
+			}
+			switch info.State {
+			case "PLAYING", "TRANSITIONING":
+				started = true
+			case "STOPPED", "PAUSED_PLAYBACK":
+				if started || time.Now().After(deadline) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// serveAnnouncementFile serves path over HTTP on the local address that routes
+// to remoteIP and returns its URL.
+func serveAnnouncementFile(path, remoteIP string) (string, func(), error) {
+	listenIP, err := sonos.LocalIPFor(remoteIP)
+	if err != nil {
+		return "", nil, err
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
+	if err != nil {
+		return "", nil, err
+	}
+	name := filepath.Base(path)
+	route := "/announce/" + url.PathEscape(name)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/announce/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != route {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, path)
+	})
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() { _ = srv.Serve(ln) }()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	uri := fmt.Sprintf("http://%s:%d%s", listenIP, port, route)
+	return uri, func() { _ = srv.Shutdown(context.Background()) }, nil
+}
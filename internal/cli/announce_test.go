@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type fakeTransportInfo struct {
+	mu    sync.Mutex
+	state string
+	calls int
+}
+
+func (f *fakeTransportInfo) GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return sonos.TransportInfo{State: f.state}, nil
+}
+
+func TestIsAnnounceURI(t *testing.T) {
+	cases := map[string]bool{
+		"https://example.com/chime.mp3":        true,
+		"x-rincon-mp3radio://example.com/live": true,
+		"./doorbell.mp3":                       false,
+		"/tmp/doorbell.mp3":                    false,
+		"doorbell.mp3":                         false,
+	}
+	for in, want := range cases {
+		if got := isAnnounceURI(in); got != want {
+			t.Fatalf("isAnnounceURI(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestWaitForTransportStopUsesEvents(t *testing.T) {
+	orig := announcePollInterval
+	t.Cleanup(func() { announcePollInterval = orig })
+	announcePollInterval = time.Hour
+
+	events := make(chan sonos.Event, 8)
+	// The initial event and a STOPPED before playback started must not end the wait.
+	events <- sonos.Event{Seq: 0, Vars: map[string]string{"transport_state": "STOPPED"}}
+	events <- sonos.Event{Seq: 1, Vars: map[string]string{"transport_state": "STOPPED"}}
+	events <- sonos.Event{Seq: 2, Vars: map[string]string{"transport_state": "TRANSITIONING"}}
+	events <- sonos.Event{Seq: 3, Vars: map[string]string{"volume_master": "30"}}
+	events <- sonos.Event{Seq: 4, Vars: map[string]string{"transport_state": "STOPPED"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := waitForTransportStop(ctx, &fakeTransportInfo{state: "PLAYING"}, events); err != nil {
+		t.Fatalf("waitForTransportStop: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected all events consumed, %d left", len(events))
+	}
+}
+
+type scriptedTransportInfo struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (f *scriptedTransportInfo) GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state := f.states[0]
+	if len(f.states) > 1 {
+		f.states = f.states[1:]
+	}
+	return sonos.TransportInfo{State: state}, nil
+}
+
+func TestWaitForTransportStopPollsWithoutEvents(t *testing.T) {
+	origPoll, origGrace := announcePollInterval, announceStartGrace
+	t.Cleanup(func() { announcePollInterval, announceStartGrace = origPoll, origGrace })
+	announcePollInterval = 10 * time.Millisecond
+	announceStartGrace = time.Hour
+
+	// A STOPPED poll before the clip started does not end the wait.
+	c := &scriptedTransportInfo{states: []string{"STOPPED", "STOPPED", "TRANSITIONING", "PLAYING", "STOPPED"}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := waitForTransportStop(ctx, c, nil); err != nil {
+		t.Fatalf("waitForTransportStop: %v", err)
+	}
+	if len(c.states) != 1 {
+		t.Fatalf("returned before playback started, %d states left", len(c.states))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := waitForTransportStop(ctx, &fakeTransportInfo{state: "STOPPED"}, nil); err == nil {
+		t.Fatalf("expected timeout while waiting for playback to start")
+	}
+
+	// A clip too short to be seen playing ends the wait after the grace period.
+	announceStartGrace = 30 * time.Millisecond
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := waitForTransportStop(ctx, &fakeTransportInfo{state: "STOPPED"}, nil); err != nil {
+		t.Fatalf("waitForTransportStop after grace: %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := waitForTransportStop(ctx, &fakeTransportInfo{state: "PLAYING"}, nil); err == nil {
+		t.Fatalf("expected timeout while still playing")
+	}
+}
+
+func TestAnnounceValidation(t *testing.T) {
+	cases := [][]string{
+		{"announce", "https://example.com/a.mp3"},
+		{"announce", "--name", "Kitchen", filepath.Join(t.TempDir(), "missing.mp3")},
+		{"announce", "--name", "Kitchen", "--volume", "101", "https://example.com/a.mp3"},
+		{"announce", "--name", "Kitchen", "--max-duration", "0s", "https://example.com/a.mp3"},
+	}
+	for _, args := range cases {
+		cmd := newAnnounceCmd(&rootFlags{Timeout: time.Second})
+		cmd.SetOut(&captureWriter{})
+		cmd.SetErr(&captureWriter{})
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args[1:])
+		if err := cmd.ExecuteContext(context.Background()); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestE2EAnnounceRestoresRooms(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office", "Den")
+	orig := announcePollInterval
+	t.Cleanup(func() { announcePollInterval = orig })
+	announcePollInterval = time.Hour // rely on GENA events from the fake
+
+	kitchen, office, den := h.Speaker("Kitchen"), h.Speaker("Office"), h.Speaker("Den")
+	if err := h.Join("Den", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "First", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Second", Duration: "0:03:00"},
+	)
+	kitchen.SetVolume(20)
+	office.SetVolume(35)
+	den.SetVolume(15)
+	if _, err := runFake(t, "queue", "play", "--name", "Kitchen", "2"); err != nil {
+		t.Fatalf("queue play: %v", err)
+	}
+	if _, err := runFake(t, "play-uri", "--name", "Office", "x-rincon-mp3radio://example.com/live"); err != nil {
+		t.Fatalf("play-uri: %v", err)
+	}
+
+	clip := filepath.Join(t.TempDir(), "doorbell.mp3")
+	if err := os.WriteFile(clip, []byte("ding-dong"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := runFake(t, "announce", "--name", "Kitchen", "--name", "Office", "--volume", "50", "--format", "json", clip)
+		done <- result{out, err}
+	}()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !strings.HasPrefix(kitchen.State().AVTransportURI, "http://") || kitchen.State().TransportState != "PLAYING" {
+		if time.Now().After(deadline) {
+			t.Fatalf("announcement did not start: %+v", kitchen.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	st := kitchen.State()
+	if office.State().Coordinator != kitchen.UUID || den.State().Coordinator == kitchen.UUID {
+		t.Fatalf("unexpected grouping during announcement: office=%q den=%q", office.State().Coordinator, den.State().Coordinator)
+	}
+	if st.Volume != 50 || office.State().Volume != 50 {
+		t.Fatalf("announcement volume not applied: %d %d", st.Volume, office.State().Volume)
+	}
+	resp, err := http.Get(st.AVTransportURI)
+	if err != nil {
+		t.Fatalf("fetch clip: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "ding-dong" {
+		t.Fatalf("unexpected clip body: %q", body)
+	}
+
+	kitchen.SetTransportState("STOPPED") // the clip ends
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("announce did not finish")
+	}
+	if res.err != nil {
+		t.Fatalf("announce: %v", res.err)
+	}
+	if !strings.Contains(res.out, `"action": "announce"`) {
+		t.Fatalf("unexpected output: %q", res.out)
+	}
+
+	st = kitchen.State()
+	if !strings.HasPrefix(st.AVTransportURI, "x-rincon-queue:") || st.Track != 2 || st.TransportState != "PLAYING" || st.Volume != 20 {
+		t.Fatalf("Kitchen not restored: %+v", st)
+	}
+	if got := den.State(); got.Coordinator != kitchen.UUID || got.Volume != 15 {
+		t.Fatalf("Den not restored: %+v", got)
+	}
+	if got := office.State(); got.Coordinator != office.UUID || got.AVTransportURI != "x-rincon-mp3radio://example.com/live" || got.TransportState != "PLAYING" || got.Volume != 35 {
+		t.Fatalf("Office not restored: %+v", got)
+	}
+}
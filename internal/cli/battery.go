@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type batteryClient interface {
+	GetBatteryStatus(ctx context.Context) (sonos.BatteryStatus, error)
+}
+
+var newBatteryClient = func(ip string, flags *rootFlags) batteryClient {
+	return newSonosClient(ip, flags.Timeout)
+}
+
+type batteryEntry struct {
+	Name    string               `json:"name"`
+	IP      string               `json:"ip,omitempty"`
+	UUID    string               `json:"uuid"`
+	Battery *sonos.BatteryStatus `json:"battery,omitempty"`
+	// Offline portables were seen by an earlier run but are missing from
+	// the topology now (asleep, out of range, battery empty).
+	Offline       bool       `json:"offline,omitempty"`
+	OfflineReason string     `json:"offlineReason,omitempty"`
+	LastSeen      *time.Time `json:"lastSeen,omitempty"`
+	LastLevel     int        `json:"lastLevel,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+func newBatteryCmd(flags *rootFlags) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "battery",
+		Short: "Show battery level of portable speakers (Roam, Move)",
+		Long: `Reads the battery status page of a portable speaker: charge level, whether it is on external
+power (charging ring or USB), temperature and health. --all checks every device in the
+household and lists the portables.
+
+Portables that answered before are remembered in the user cache dir. When one is missing from
+the topology (asleep, out of range, empty), it is listed as offline with the last level seen
+and the reason the household reports under VanishedDevices.
+
+TSV columns: name, IP, UUID, level, charging, power source, temperature, health, status
+(ok|offline|error), detail (offline reason or error).`,
+		Example:      "  sonos battery --name \"Roam\"\n  sonos battery --all\n  sonos battery --all --format json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !all {
+				if err := validateTarget(flags); err != nil {
+					return err
+				}
+			}
+			ctx := cmd.Context()
+			top, err := householdTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			known := readPortableCache()
+
+			var members []sonos.Member
+			var offline []batteryEntry
+			if all {
+				members = topologyMembers(top, true)
+				offline = offlinePortables(top, known, nil)
+			} else {
+				m, err := resolveMember(top, flags.Name, flags.IP)
+				if err != nil {
+					offline = offlinePortables(top, known, func(p portableRecord) bool {
+						return strings.EqualFold(p.Name, strings.TrimSpace(flags.Name)) || (flags.IP != "" && p.IP == strings.TrimSpace(flags.IP))
+					})
+					if len(offline) == 0 {
+						return err
+					}
+				} else {
+					members = []sonos.Member{m}
+				}
+			}
+
+			entries := collectBatteries(ctx, flags, members)
+			if !all && len(members) == 1 && len(entries) == 0 {
+				return fmt.Errorf("%s has no battery (not a portable speaker)", members[0].Name)
+			}
+			storePortableCache(known, entries, time.Now())
+			entries = append(entries, offline...)
+
+			if err := writeBatteries(cmd, flags, entries); err != nil {
+				return err
+			}
+			failed := 0
+			for _, e := range entries {
+				if e.Error != "" {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d portables could not be queried", failed, len(entries))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Check every speaker in the household and list the portables")
+	return cmd
+}
+
+// collectBatteries queries members concurrently and keeps the portables;
+// speakers reporting sonos.ErrNoBattery are dropped.
+func collectBatteries(ctx context.Context, flags *rootFlags, members []sonos.Member) []batteryEntry {
+	entries := make([]batteryEntry, len(members))
+	portable := make([]bool, len(members))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(inventoryWorkers, len(members)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				m := members[i]
+				entries[i] = batteryEntry{Name: m.Name, IP: m.IP, UUID: m.UUID}
+				st, err := newBatteryClient(m.IP, flags).GetBatteryStatus(ctx)
+				switch {
+				case errors.Is(err, sonos.ErrNoBattery):
+					continue
+				case err != nil:
+					entries[i].Error = err.Error()
+				default:
+					entries[i].Battery = &st
+				}
+				portable[i] = true
+			}
+		}()
+	}
+	for i := range members {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	var out []batteryEntry
+	for i, e := range entries {
+		if portable[i] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// offlinePortables lists remembered portables (optionally only those match
+// accepts) that are no longer in the topology.
+func offlinePortables(top sonos.Topology, known []portableRecord, match func(portableRecord) bool) []batteryEntry {
+	reasons := map[string]string{}
+	for _, v := range top.Vanished {
+		reasons[v.UUID] = v.Reason
+	}
+	var out []batteryEntry
+	for _, p := range known {
+		if match != nil && !match(p) {
+			continue
+		}
+		if _, ok := top.FindByUUID(p.UUID); ok {
+			continue
+		}
+		reason, ok := reasons[p.UUID]
+		if !ok {
+			reason = "not in topology"
+		}
+		lastSeen := p.LastSeen
+		out = append(out, batteryEntry{
+			Name:          p.Name,
+			IP:            p.IP,
+			UUID:          p.UUID,
+			Offline:       true,
+			OfflineReason: reason,
+			LastSeen:      &lastSeen,
+			LastLevel:     p.LastLevel,
+		})
+	}
+	return out
+}
+
+func writeBatteries(cmd *cobra.Command, flags *rootFlags, entries []batteryEntry) error {
+	if isJSON(flags) {
+		if entries == nil {
+			entries = []batteryEntry{}
+		}
+		return writeJSON(cmd, entries)
+	}
+	if isTSV(flags) {
+		for _, e := range entries {
+			var b sonos.BatteryStatus
+			status, detail := "ok", ""
+			switch {
+			case e.Offline:
+				b.Level = e.LastLevel
+				status, detail = "offline", e.OfflineReason
+			case e.Error != "":
+				status, detail = "error", e.Error
+			default:
+				b = *e.Battery
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%d\t%v\t%s\t%s\t%s\t%s\t%s\n",
+				e.Name, e.IP, e.UUID, b.Level, b.Charging, b.PowerSource, b.Temperature, b.Health, status, detail)
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No portable speakers found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tIP\tLEVEL\tPOWER\tTEMPERATURE\tHEALTH")
+	for _, e := range entries {
+		switch {
+		case e.Offline:
+			_, _ = fmt.Fprintf(w, "%s\t%s\toffline\t%s (last seen %s at %d%%)\t\t\n",
+				e.Name, e.IP, e.OfflineReason, e.LastSeen.Local().Format("2006-01-02 15:04"), e.LastLevel)
+		case e.Error != "":
+			_, _ = fmt.Fprintf(w, "%s\t%s\terror: %s\t\t\t\n", e.Name, e.IP, e.Error)
+		default:
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d%%\t%s\t%s\t%s\n",
+				e.Name, e.IP, e.Battery.Level, batteryPower(*e.Battery), e.Battery.Temperature, e.Battery.Health)
+		}
+	}
+	return w.Flush()
+}
+
+func batteryPower(b sonos.BatteryStatus) string {
+	if !b.Charging {
+		return "battery"
+	}
+	if b.PowerSource == "" {
+		return "charging"
+	}
+	return "charging (" + strings.ToLower(b.PowerSource) + ")"
+}
+
+func batteryLine(b sonos.BatteryStatus) string {
+	return strconv.Itoa(b.Level) + "%, " + batteryPower(b)
+}
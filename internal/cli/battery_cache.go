@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// portableRecord is a portable speaker that answered `sonos battery`, kept so
+// later runs can tell when it drops out of the topology.
+type portableRecord struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	IP        string    `json:"ip"`
+	LastSeen  time.Time `json:"lastSeen"`
+	LastLevel int       `json:"lastLevel"`
+}
+
+type portableCacheFile struct {
+	Portables []portableRecord `json:"portables"`
+}
+
+var portableCachePath = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sonoscli", "portables.json"), nil
+}
+
+func readPortableCache() []portableRecord {
+	path, err := portableCachePath()
+	if err != nil {
+		return nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache portableCacheFile
+	if err := json.Unmarshal(raw, &cache); err != nil {
+		return nil
+	}
+	return cache.Portables
+}
+
+// storePortableCache merges the portables that answered now into known and
+// writes the result. Failures are ignored: the cache only feeds the offline
+// hint.
+func storePortableCache(known []portableRecord, entries []batteryEntry, now time.Time) {
+	byUUID := map[string]portableRecord{}
+	for _, p := range known {
+		byUUID[p.UUID] = p
+	}
+	changed := false
+	for _, e := range entries {
+		if e.Battery == nil || e.UUID == "" {
+			continue
+		}
+		byUUID[e.UUID] = portableRecord{UUID: e.UUID, Name: e.Name, IP: e.IP, LastSeen: now, LastLevel: e.Battery.Level}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	cache := portableCacheFile{Portables: make([]portableRecord, 0, len(byUUID))}
+	for _, p := range byUUID {
+		cache.Portables = append(cache.Portables, p)
+	}
+	sort.Slice(cache.Portables, func(i, j int) bool { return cache.Portables[i].Name < cache.Portables[j].Name })
+
+	path, err := portableCachePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	raw, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "portables-*.json")
+	if err != nil {
+		return
+	}
+	tmp := f.Name()
+	defer func() { _ = os.Remove(tmp) }()
+	if _, err := f.Write(raw); err != nil {
+		_ = f.Close()
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func withPortableCache(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "portables.json")
+	orig := portableCachePath
+	t.Cleanup(func() { portableCachePath = orig })
+	portableCachePath = func() (string, error) { return path, nil }
+}
+
+func TestE2EBatteryListsPortablesAndFlagsOffline(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Roam", "Move")
+	withPortableCache(t)
+	h.Speaker("Roam").SetBattery(sonostest.Battery{Level: 80, PowerSource: "SONOS_CHARGING_RING", Temperature: "NORMAL", Health: "GREEN"})
+	h.Speaker("Move").SetBattery(sonostest.Battery{Level: 35, PowerSource: "BATTERY", Temperature: "NORMAL", Health: "GREEN"})
+	// Kitchen refuses the battery page outright instead of serving it empty.
+	h.Speaker("Kitchen").SetStatusPages(false)
+
+	out, err := runFake(t, "battery", "--all", "--format", "json")
+	if err != nil {
+		t.Fatalf("battery --all: %v", err)
+	}
+	var entries []batteryEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if len(entries) != 2 || entries[0].Name != "Move" || entries[0].Battery.Level != 35 || !entries[1].Battery.Charging {
+		t.Fatalf("unexpected entries: %s", out)
+	}
+
+	out, err = runFake(t, "battery", "--name", "Roam")
+	if err != nil || !strings.Contains(out, "80%") || !strings.Contains(out, "charging (sonos_charging_ring)") {
+		t.Fatalf("battery --name Roam: %v\n%s", err, out)
+	}
+	if _, err := runFake(t, "battery", "--name", "Kitchen"); err == nil || !strings.Contains(err.Error(), "no battery") {
+		t.Fatalf("battery --name Kitchen: %v", err)
+	}
+
+	if err := h.Vanish("Move", "powered off"); err != nil {
+		t.Fatalf("Vanish: %v", err)
+	}
+	out, err = runFake(t, "battery", "--all", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("battery --all after vanish: %v", err)
+	}
+	if !strings.Contains(out, "Move\t") || !strings.Contains(out, "\t35\tfalse\t\t\t\toffline\tpowered off\n") {
+		t.Fatalf("Move not flagged offline:\n%s", out)
+	}
+
+	out, err = runFake(t, "battery", "--name", "Move")
+	if err != nil || !strings.Contains(out, "offline") || !strings.Contains(out, "last seen") {
+		t.Fatalf("battery --name Move: %v\n%s", err, out)
+	}
+}
+
+func TestE2EStatusIncludesBatteryForPortables(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Roam")
+	h.Speaker("Roam").SetBattery(sonostest.Battery{Level: 55, PowerSource: "BATTERY", Health: "GREEN"})
+	h.Speaker("Roam").SetModel("Sonos Roam")
+	// Only portable models are asked for their battery.
+	h.Speaker("Kitchen").SetBattery(sonostest.Battery{Level: 10})
+
+	out, err := runFake(t, "status", "--name", "Roam", "--format", "json")
+	if err != nil {
+		t.Fatalf("status Roam: %v", err)
+	}
+	if !strings.Contains(out, `"battery": {`) || !strings.Contains(out, `"level": 55`) {
+		t.Fatalf("missing battery: %s", out)
+	}
+	out, err = runFake(t, "status", "--name", "Kitchen", "--format", "json")
+	if err != nil {
+		t.Fatalf("status Kitchen: %v", err)
+	}
+	if strings.Contains(out, `"battery"`) {
+		t.Fatalf("battery shown for a mains speaker: %s", out)
+	}
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type bondClient interface {
+	CreateStereoPair(ctx context.Context, leftUUID, rightUUID string) error
+	SeparateStereoPair(ctx context.Context, channelMapSet string) error
+	AddHTSatellites(ctx context.Context, soundbarUUID string, satellites []sonos.BondChannel) error
+	RemoveHTSatellite(ctx context.Context, satelliteUUID string) error
+}
+
+var newBondClient = func(ip string, timeout time.Duration) bondClient {
+	return sonos.NewClient(ip, timeout)
+}
+
+func newBondCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bond",
+		Short: "Create or break stereo pairs and home-theater sets",
+		Long: `Bonds physical speakers into one room (DeviceProperties): stereo pairs, and a sub and/or
+surrounds attached to a home-theater speaker. Bonded speakers disappear as rooms of their own;
+"sonos group status" lists them under the room they belong to.`,
+	}
+	cmd.AddCommand(newBondStereoCmd(flags))
+	cmd.AddCommand(newBondSeparateCmd(flags))
+	cmd.AddCommand(newBondAttachCmd(flags))
+	cmd.AddCommand(newBondDetachCmd(flags))
+	return cmd
+}
+
+func bondTopology(ctx context.Context, flags *rootFlags) (sonos.Topology, error) {
+	tg, err := newTopologyGetter(ctx, flags.Timeout)
+	if err != nil {
+		return sonos.Topology{}, err
+	}
+	return tg.GetTopology(ctx)
+}
+
+// resolveStandalone resolves a speaker that is about to be bonded: a visible
+// room that is not part of a stereo pair or home-theater set yet.
+func resolveStandalone(top sonos.Topology, name string) (sonos.Member, error) {
+	m, err := resolveMember(top, name, "")
+	if err != nil {
+		return sonos.Member{}, err
+	}
+	if !m.IsVisible || m.Role != "" {
+		return sonos.Member{}, fmt.Errorf("%s is already bonded (%s); separate it first", m.Name, m.Role)
+	}
+	return m, nil
+}
+
+// resolveBondedRoom resolves --name/--ip to the primary device of its room.
+func resolveBondedRoom(top sonos.Topology, flags *rootFlags) (sonos.Member, error) {
+	m, err := resolveMember(top, flags.Name, flags.IP)
+	if err != nil {
+		return sonos.Member{}, err
+	}
+	if m.BondedTo != "" {
+		primary, ok := top.FindByUUID(m.BondedTo)
+		if !ok {
+			return sonos.Member{}, fmt.Errorf("primary device %s of %s not found in topology", m.BondedTo, m.Name)
+		}
+		return primary, nil
+	}
+	return m, nil
+}
+
+func newBondStereoCmd(flags *rootFlags) *cobra.Command {
+	var left, right string
+	cmd := &cobra.Command{
+		Use:          "stereo --left <name> --right <name>",
+		Short:        "Bond two speakers into a stereo pair",
+		Long:         "Creates a stereo pair (CreateStereoPair). The left speaker keeps its room name; the right one becomes part of that room.",
+		Example:      "  sonos bond stereo --left Office --right \"Office 2\"",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(left) == "" || strings.TrimSpace(right) == "" {
+				return errors.New("--left and --right are required")
+			}
+			ctx := cmd.Context()
+			top, err := bondTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			l, err := resolveStandalone(top, left)
+			if err != nil {
+				return err
+			}
+			r, err := resolveStandalone(top, right)
+			if err != nil {
+				return err
+			}
+			if l.UUID == r.UUID {
+				return errors.New("--left and --right must be different speakers")
+			}
+			if err := newBondClient(l.IP, flags.Timeout).CreateStereoPair(ctx, l.UUID, r.UUID); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "bond.stereo", map[string]any{"room": l.Name, "left": l.IP, "right": r.IP})
+		},
+	}
+	cmd.Flags().StringVar(&left, "left", "", "Speaker for the left channel (keeps its room name)")
+	cmd.Flags().StringVar(&right, "right", "", "Speaker for the right channel")
+	return cmd
+}
+
+func newBondSeparateCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "separate",
+		Short:        "Split a stereo pair back into two rooms",
+		Example:      "  sonos bond separate --name Office",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			top, err := bondTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			primary, err := resolveBondedRoom(top, flags)
+			if err != nil {
+				return err
+			}
+			set := []sonos.BondChannel{{UUID: primary.UUID, Channels: primary.Channels}}
+			for _, m := range top.BondedDevices(primary.UUID) {
+				if m.Role == "left" || m.Role == "right" {
+					set = append(set, sonos.BondChannel{UUID: m.UUID, Channels: m.Channels})
+				}
+			}
+			if primary.Role != "left" && primary.Role != "right" || len(set) < 2 {
+				return fmt.Errorf("%s is not a stereo pair", primary.Name)
+			}
+			if err := newBondClient(primary.IP, flags.Timeout).SeparateStereoPair(ctx, sonos.FormatChannelMapSet(set)); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "bond.separate", map[string]any{"room": primary.Name})
+		},
+	}
+}
+
+func newBondAttachCmd(flags *rootFlags) *cobra.Command {
+	var sub, surroundLeft, surroundRight string
+	cmd := &cobra.Command{
+		Use:          "attach --sub <name> | --surround-left <name> --surround-right <name>",
+		Short:        "Attach a sub and/or surrounds to a home-theater speaker",
+		Long:         "Bonds standalone speakers to the home-theater speaker given by --name/--ip (AddHTSatellite).",
+		Example:      "  sonos bond attach --name \"Living Room\" --sub Sub\n  sonos bond attach --name \"Living Room\" --surround-left \"Rear L\" --surround-right \"Rear R\"",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			if (surroundLeft == "") != (surroundRight == "") {
+				return errors.New("--surround-left and --surround-right must be given together")
+			}
+			if sub == "" && surroundLeft == "" {
+				return errors.New("provide --sub and/or --surround-left/--surround-right")
+			}
+			ctx := cmd.Context()
+			top, err := bondTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			room, err := resolveBondedRoom(top, flags)
+			if err != nil {
+				return err
+			}
+			if room.Role == "left" || room.Role == "right" {
+				return fmt.Errorf("%s is a stereo pair, not a home-theater speaker", room.Name)
+			}
+			var sats []sonos.BondChannel
+			for _, s := range []struct{ name, channels string }{
+				{sub, sonos.ChannelsSub},
+				{surroundLeft, sonos.ChannelsSurroundLeft},
+				{surroundRight, sonos.ChannelsSurroundRight},
+			} {
+				if s.name == "" {
+					continue
+				}
+				m, err := resolveStandalone(top, s.name)
+				if err != nil {
+					return err
+				}
+				if m.UUID == room.UUID {
+					return fmt.Errorf("%s cannot be attached to itself", m.Name)
+				}
+				sats = append(sats, sonos.BondChannel{UUID: m.UUID, Channels: s.channels})
+			}
+			if err := newBondClient(room.IP, flags.Timeout).AddHTSatellites(ctx, room.UUID, sats); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "bond.attach", map[string]any{"room": room.Name, "satellites": len(sats)})
+		},
+	}
+	cmd.Flags().StringVar(&sub, "sub", "", "Speaker to attach as the subwoofer")
+	cmd.Flags().StringVar(&surroundLeft, "surround-left", "", "Speaker to attach as the left surround")
+	cmd.Flags().StringVar(&surroundRight, "surround-right", "", "Speaker to attach as the right surround")
+	return cmd
+}
+
+func newBondDetachCmd(flags *rootFlags) *cobra.Command {
+	var sub, surrounds bool
+	cmd := &cobra.Command{
+		Use:          "detach [--sub] [--surrounds]",
+		Short:        "Detach the sub and/or surrounds from a home-theater speaker",
+		Long:         "Removes satellites from the home-theater room given by --name/--ip (RemoveHTSatellite); each becomes a standalone room again. Without --sub/--surrounds all satellites are detached.",
+		Example:      "  sonos bond detach --name \"Living Room\" --sub\n  sonos bond detach --name \"Living Room\"",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			all := !sub && !surrounds
+			ctx := cmd.Context()
+			top, err := bondTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			room, err := resolveBondedRoom(top, flags)
+			if err != nil {
+				return err
+			}
+			c := newBondClient(room.IP, flags.Timeout)
+			detached := 0
+			for _, m := range top.BondedDevices(room.UUID) {
+				isSub := m.Role == "sub"
+				isSurround := strings.HasPrefix(m.Role, "surround-")
+				if !(isSub && (all || sub)) && !(isSurround && (all || surrounds)) {
+					continue
+				}
+				if err := c.RemoveHTSatellite(ctx, m.UUID); err != nil {
+					return err
+				}
+				detached++
+			}
+			if detached == 0 {
+				return fmt.Errorf("%s has no matching satellites to detach", room.Name)
+			}
+			return writeOK(cmd, flags, "bond.detach", map[string]any{"room": room.Name, "detached": detached})
+		},
+	}
+	cmd.Flags().BoolVar(&sub, "sub", false, "Detach the subwoofer")
+	cmd.Flags().BoolVar(&surrounds, "surrounds", false, "Detach the surrounds")
+	return cmd
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestE2EBondStereoPair(t *testing.T) {
+	h := newFakeHousehold(t, "Office", "Office 2", "Kitchen")
+	right := h.Speaker("Office 2")
+
+	if _, err := runFake(t, "bond", "stereo", "--left", "Office", "--right", "Office 2"); err != nil {
+		t.Fatalf("bond stereo: %v", err)
+	}
+	out, err := runFake(t, "group", "status")
+	if err != nil {
+		t.Fatalf("group status: %v", err)
+	}
+	if strings.Contains(out, "Office 2") || !strings.Contains(out, "left") || !strings.Contains(out, "+ right") || !strings.Contains(out, right.IP) {
+		t.Fatalf("unexpected group status:\n%s", out)
+	}
+	out, err = runFake(t, "group", "status", "--format", "json")
+	if err != nil || !strings.Contains(out, `"role": "left"`) || strings.Contains(out, `"role": "right"`) {
+		t.Fatalf("group status json: %s, %v", out, err)
+	}
+
+	if _, err := runFake(t, "bond", "stereo", "--left", "Kitchen", "--right", "Office"); err == nil || !strings.Contains(err.Error(), "already bonded") {
+		t.Fatalf("expected already-bonded error, got %v", err)
+	}
+	if _, err := runFake(t, "bond", "separate", "--name", "Kitchen"); err == nil {
+		t.Fatalf("expected not-a-stereo-pair error")
+	}
+
+	if _, err := runFake(t, "bond", "separate", "--name", "Office"); err != nil {
+		t.Fatalf("bond separate: %v", err)
+	}
+	out, _ = runFake(t, "group", "status")
+	if !strings.Contains(out, "Office 2") || strings.Contains(out, "right") {
+		t.Fatalf("pair not separated:\n%s", out)
+	}
+}
+
+func TestE2EBondHomeTheaterSatellites(t *testing.T) {
+	h := newFakeHousehold(t, "Living Room", "Sub", "Rear L", "Rear R")
+	h.Speaker("Living Room").SetModel("Sonos Arc")
+
+	if _, err := runFake(t, "bond", "attach", "--name", "Living Room", "--surround-left", "Rear L"); err == nil {
+		t.Fatalf("expected paired-surrounds error")
+	}
+	if _, err := runFake(t, "bond", "attach", "--name", "Living Room", "--sub", "Sub", "--surround-left", "Rear L", "--surround-right", "Rear R"); err != nil {
+		t.Fatalf("bond attach: %v", err)
+	}
+	out, err := runFake(t, "group", "status")
+	if err != nil {
+		t.Fatalf("group status: %v", err)
+	}
+	for _, want := range []string{"home-theater", "+ sub", "+ surround-left", "+ surround-right"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Rear L") {
+		t.Fatalf("satellite still listed as a room:\n%s", out)
+	}
+
+	out, err = runFake(t, "group", "status", "--all", "--format", "tsv")
+	if err != nil || strings.Count(out, "\n") != 4 || strings.Contains(out, "\tsub") {
+		t.Fatalf("group status --all tsv: %q, %v", out, err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if cols := strings.Split(line, "\t"); len(cols) != 6 {
+			t.Fatalf("expected 6 tsv columns without --details: %q", line)
+		}
+	}
+	out, err = runFake(t, "group", "status", "--all", "--details", "--format", "tsv")
+	if err != nil || !strings.Contains(out, "\tsub\t") {
+		t.Fatalf("group status --all --details tsv: %q, %v", out, err)
+	}
+
+	if _, err := runFake(t, "bond", "detach", "--name", "Living Room", "--sub"); err != nil {
+		t.Fatalf("bond detach --sub: %v", err)
+	}
+	out, _ = runFake(t, "group", "status")
+	if !strings.Contains(out, "Sub") || strings.Contains(out, "+ sub") || !strings.Contains(out, "+ surround-left") {
+		t.Fatalf("sub not detached:\n%s", out)
+	}
+	if _, err := runFake(t, "bond", "detach", "--name", "Living Room"); err != nil {
+		t.Fatalf("bond detach: %v", err)
+	}
+	if _, err := runFake(t, "bond", "detach", "--name", "Living Room"); err == nil {
+		t.Fatalf("expected nothing-to-detach error")
+	}
+	out, _ = runFake(t, "group", "status")
+	if !strings.Contains(out, "Rear L") || !strings.Contains(out, "Rear R") || strings.Contains(out, "home-theater") {
+		t.Fatalf("surrounds not detached:\n%s", out)
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type deviceInfoClient interface {
+	GetDeviceInfo(ctx context.Context) (sonos.DeviceInfo, error)
+}
+
+// Device details belong to the named speaker itself, not its group
+// coordinator.
+var newDeviceInfoClient = func(ctx context.Context, flags *rootFlags) (deviceInfoClient, error) {
+	return anySpeakerClient(ctx, flags)
+}
+
+func newDeviceCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "device",
+		Short: "Inspect and administer a speaker (hardware details, room name, LED, buttons)",
+	}
+	cmd.AddCommand(newDeviceInfoCmd(flags))
+	cmd.AddCommand(newDeviceSetCmd(flags))
+	return cmd
+}
+
+func newDeviceInfoCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show model, serial, MAC, versions, uptime and network details",
+		Long: `Shows a speaker's identity: model name/number, serial number, MAC address, software and
+hardware versions and series ID (device description plus DeviceProperties GetZoneInfo and
+GetZoneAttributes). Uptime and network interfaces come from the /status diagnostic pages,
+which newer firmware no longer serves; they are omitted when unavailable.`,
+		Example:      "  sonos device info --name Kitchen\n  sonos device info --ip 192.168.1.20 --format json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newDeviceInfoClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			info, err := c.GetDeviceInfo(ctx)
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, info)
+			}
+			fields := deviceInfoFields(info)
+			if isTSV(flags) {
+				for _, f := range fields {
+					if f.value != "" {
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", f.key, f.value)
+					}
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			for _, f := range fields {
+				if f.value != "" {
+					_, _ = fmt.Fprintf(w, "%s:\t%s\n", f.label, f.value)
+				}
+			}
+			return w.Flush()
+		},
+	}
+}
+
+type deviceInfoField struct {
+	label, key, value string
+}
+
+// deviceInfoFields lists the fields in display order; key is the TSV name.
+func deviceInfoFields(info sonos.DeviceInfo) []deviceInfoField {
+	uptime := ""
+	if info.UptimeSeconds > 0 {
+		uptime = formatUptime(info.UptimeSeconds)
+	}
+	var network []string
+	for _, n := range info.Network {
+		s := n.Name
+		if n.Address != "" {
+			s += " " + n.Address
+			if n.Netmask != "" {
+				s += "/" + n.Netmask
+			}
+		}
+		if n.MACAddress != "" {
+			s += " (" + n.MACAddress + ")"
+		}
+		network = append(network, s)
+	}
+	return []deviceInfoField{
+		{"Name", "name", info.Name},
+		{"IP", "ip", info.IP},
+		{"UDN", "udn", info.UDN},
+		{"Model", "model", info.ModelName},
+		{"Model number", "model_number", info.ModelNumber},
+		{"Serial", "serial", info.SerialNumber},
+		{"MAC", "mac", info.MACAddress},
+		{"Software", "software_version", info.SoftwareVersion},
+		{"Display version", "display_version", info.DisplaySoftwareVersion},
+		{"Hardware", "hardware_version", info.HardwareVersion},
+		{"Series ID", "series_id", info.SeriesID},
+		{"Uptime", "uptime", uptime},
+		{"Network", "network", strings.Join(network, ", ")},
+	}
+}
+
+// formatUptime renders seconds as e.g. "3d 4h 12m".
+func formatUptime(secs int64) string {
+	d, h, m := secs/86400, (secs/3600)%24, (secs/60)%60
+	switch {
+	case d > 0:
+		return fmt.Sprintf("%dd %dh %dm", d, h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	default:
+		return fmt.Sprintf("%dm", m)
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type deviceSettingsClient interface {
+	GetDeviceDescription(ctx context.Context) (sonos.Device, error)
+	GetZoneAttributes(ctx context.Context) (sonos.ZoneAttributes, error)
+	SetRoomName(ctx context.Context, name string) error
+	GetLEDState(ctx context.Context) (bool, error)
+	SetLEDState(ctx context.Context, on bool) error
+	GetButtonLockState(ctx context.Context) (bool, error)
+	SetButtonLockState(ctx context.Context, locked bool) error
+	GetAutoplaySettings(ctx context.Context, source string) (sonos.AutoplaySettings, error)
+	SetAutoplayRoomUUID(ctx context.Context, source, roomUUID string) error
+	SetAutoplayLinkedZones(ctx context.Context, source string, include bool) error
+	SetAutoplayVolume(ctx context.Context, source string, volume int) error
+}
+
+var newDeviceSettingsClient = func(ctx context.Context, flags *rootFlags) (deviceSettingsClient, error) {
+	return anySpeakerClient(ctx, flags)
+}
+
+var deviceSettingNames = []string{"name", "led", "buttons", "tv-autoplay", "tv-ungroup", "tv-autoplay-volume"}
+
+func newDeviceSetCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <setting> <value>",
+		Short: "Rename a room or change LED, button lock and TV autoplay settings",
+		Long: `Changes a speaker setting (DeviceProperties) and prints the value read back from the speaker.
+
+Settings:
+  name <new name>               Rename the room (SetZoneAttributes)
+  led on|off                    Status light
+  buttons lock|unlock           Physical controls
+  tv-autoplay on|off            Home theater: start playing when the TV turns on
+  tv-ungroup on|off             Home theater: leave the group when TV audio starts
+  tv-autoplay-volume <0-100|off> Home theater: fixed volume for TV autoplay`,
+		Example:      "  sonos device set --name Kitchen name \"Kitchen Island\"\n  sonos device set --name Kitchen led off\n  sonos device set --name \"Living Room\" tv-autoplay on",
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return deviceSettingNames, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			setting := strings.ToLower(args[0])
+			value := strings.Join(args[1:], " ")
+			if setting != "name" && len(args) > 2 {
+				return fmt.Errorf("%s takes a single value", setting)
+			}
+			ctx := cmd.Context()
+			c, err := newDeviceSettingsClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			got, err := applyDeviceSetting(ctx, c, setting, value)
+			if err != nil {
+				return err
+			}
+			if setting == "name" {
+				if err := invalidateNameCompletions(); err != nil {
+					slog.Debug("device set: could not clear name completion cache", "err", err)
+				}
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("%s: %v", setting, got))
+			return writeOK(cmd, flags, "device.set", map[string]any{"setting": setting, "value": got})
+		},
+	}
+}
+
+// applyDeviceSetting changes one setting and returns its value as read back
+// from the speaker.
+func applyDeviceSetting(ctx context.Context, c deviceSettingsClient, setting, value string) (any, error) {
+	switch setting {
+	case "name":
+		if err := c.SetRoomName(ctx, value); err != nil {
+			return nil, err
+		}
+		za, err := c.GetZoneAttributes(ctx)
+		return za.Name, err
+
+	case "led":
+		on, err := parseOnOff(setting, value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetLEDState(ctx, on); err != nil {
+			return nil, err
+		}
+		on, err = c.GetLEDState(ctx)
+		return onOffString(on), err
+
+	case "buttons":
+		var locked bool
+		switch strings.ToLower(value) {
+		case "lock", "locked":
+			locked = true
+		case "unlock", "unlocked":
+		default:
+			return nil, errors.New("buttons must be lock or unlock")
+		}
+		if err := c.SetButtonLockState(ctx, locked); err != nil {
+			return nil, err
+		}
+		locked, err := c.GetButtonLockState(ctx)
+		if locked {
+			return "locked", err
+		}
+		return "unlocked", err
+
+	case "tv-autoplay":
+		on, err := parseOnOff(setting, value)
+		if err != nil {
+			return nil, err
+		}
+		room := ""
+		if on {
+			dev, err := c.GetDeviceDescription(ctx)
+			if err != nil {
+				return nil, err
+			}
+			room = dev.UDN
+		}
+		if err := c.SetAutoplayRoomUUID(ctx, sonos.AutoplaySourceTV, room); err != nil {
+			return nil, err
+		}
+		ap, err := c.GetAutoplaySettings(ctx, sonos.AutoplaySourceTV)
+		return onOffString(ap.RoomUUID != ""), err
+
+	case "tv-ungroup":
+		on, err := parseOnOff(setting, value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetAutoplayLinkedZones(ctx, sonos.AutoplaySourceTV, !on); err != nil {
+			return nil, err
+		}
+		ap, err := c.GetAutoplaySettings(ctx, sonos.AutoplaySourceTV)
+		return onOffString(!ap.IncludeLinkedZones), err
+
+	case "tv-autoplay-volume":
+		volume := -1
+		if !strings.EqualFold(value, "off") {
+			v, err := strconv.Atoi(value)
+			if err != nil || v < 0 || v > 100 {
+				return nil, errors.New("tv-autoplay-volume must be 0-100 or off")
+			}
+			volume = v
+		}
+		if err := c.SetAutoplayVolume(ctx, sonos.AutoplaySourceTV, volume); err != nil {
+			return nil, err
+		}
+		ap, err := c.GetAutoplaySettings(ctx, sonos.AutoplaySourceTV)
+		if !ap.UseVolume {
+			return "off", err
+		}
+		return ap.Volume, err
+
+	default:
+		return nil, fmt.Errorf("unknown setting %q (expected %s)", setting, strings.Join(deviceSettingNames, ", "))
+	}
+}
+
+func parseOnOff(setting, value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "1", "yes":
+		return true, nil
+	case "off", "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s must be on or off", setting)
+}
+
+func onOffString(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestE2EDeviceSet(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Living Room")
+	t.Setenv("SONOSCLI_COMPLETION_CACHE_DIR", t.TempDir())
+	if err := storeNameCompletions(time.Now(), []string{"Kitchen", "Living Room"}); err != nil {
+		t.Fatalf("store cache: %v", err)
+	}
+
+	out, err := runFake(t, "device", "set", "--name", "Kitchen", "name", "Kitchen", "Island")
+	if err != nil || strings.TrimSpace(out) != "name: Kitchen Island" {
+		t.Fatalf("set name: %q, %v", out, err)
+	}
+	if h.Speaker("Kitchen Island") == nil {
+		t.Fatalf("room not renamed")
+	}
+	if _, ok := cachedNameCompletions(time.Now()); ok {
+		t.Fatalf("name completion cache not invalidated")
+	}
+
+	if out, err := runFake(t, "device", "set", "--name", "Kitchen Island", "led", "off"); err != nil || strings.TrimSpace(out) != "led: off" {
+		t.Fatalf("set led: %q, %v", out, err)
+	}
+	if h.Speaker("Kitchen Island").State().LED {
+		t.Fatalf("LED still on")
+	}
+	if out, err := runFake(t, "device", "set", "--name", "Kitchen Island", "buttons", "lock", "--format", "json"); err != nil || !strings.Contains(out, `"value": "locked"`) {
+		t.Fatalf("set buttons: %q, %v", out, err)
+	}
+	if !h.Speaker("Kitchen Island").State().ButtonsLocked {
+		t.Fatalf("buttons not locked")
+	}
+
+	for _, tc := range []struct{ setting, value, want string }{
+		{"tv-autoplay", "on", "tv-autoplay: on"},
+		{"tv-ungroup", "on", "tv-ungroup: on"},
+		{"tv-autoplay-volume", "30", "tv-autoplay-volume: 30"},
+		{"tv-autoplay-volume", "off", "tv-autoplay-volume: off"},
+		{"tv-autoplay", "off", "tv-autoplay: off"},
+	} {
+		out, err := runFake(t, "device", "set", "--name", "Living Room", tc.setting, tc.value)
+		if err != nil || strings.TrimSpace(out) != tc.want {
+			t.Fatalf("set %s %s: %q, %v", tc.setting, tc.value, out, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"led", "dim"},
+		{"buttons", "maybe"},
+		{"tv-autoplay-volume", "150"},
+		{"led", "on", "now"},
+		{"volume", "10"},
+	} {
+		if _, err := runFake(t, append([]string{"device", "set", "--name", "Living Room"}, args...)...); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
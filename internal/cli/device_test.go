@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestE2EDeviceInfo(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	office := h.Speaker("Office")
+
+	out, err := runFake(t, "device", "info", "--name", "Office")
+	if err != nil {
+		t.Fatalf("device info: %v", err)
+	}
+	for _, want := range []string{"Name:", "Office", "Model:", "Sonos One", "Serial:", "MAC:", "Software:", "Uptime:", "3d 4h", "Network:", office.IP} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	out, err = runFake(t, "device", "info", "--name", "Office", "--format", "tsv")
+	if err != nil || !strings.Contains(out, "model_number\tS18\n") || !strings.Contains(out, "series_id\tP100\n") {
+		t.Fatalf("device info tsv: %q, %v", out, err)
+	}
+
+	office.SetStatusPages(false)
+	out, err = runFake(t, "device", "info", "--name", "Office", "--format", "json")
+	if err != nil {
+		t.Fatalf("device info json: %v", err)
+	}
+	if !strings.Contains(out, `"serialNumber"`) || strings.Contains(out, `"uptimeSeconds"`) || strings.Contains(out, `"network"`) {
+		t.Fatalf("unexpected json: %s", out)
+	}
+
+	if _, err := runFake(t, "device", "info"); err == nil {
+		t.Fatalf("expected target error")
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	t.Parallel()
+
+	for secs, want := range map[int64]string{
+		59:               "0m",
+		3660:             "1h 1m",
+		3*86400 + 4*3600: "3d 4h 0m",
+		10*86400 + 90*60: "10d 1h 30m",
+	} {
+		if got := formatUptime(secs); got != want {
+			t.Fatalf("formatUptime(%d) = %q, want %q", secs, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/appconfig"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+// newFakeHousehold starts a fake household and points discovery at it.
+// Tests are skipped where loopback aliases (127.x.y.z:1400) are unavailable.
+func newFakeHousehold(t *testing.T, rooms ...string) *sonostest.Household {
+	t.Helper()
+	h, err := sonostest.NewHousehold(rooms...)
+	if err != nil {
+		t.Skipf("fake household unavailable: %v", err)
+	}
+	t.Cleanup(h.Close)
+	t.Setenv(sonostest.SeedEnv, h.SeedIPs())
+
+	orig := loadAppConfig
+	t.Cleanup(func() { loadAppConfig = orig })
+	loadAppConfig = func() (appconfig.Config, error) { return appconfig.Config{}.Normalize(), nil }
+	return h
+}
+
+func runFake(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	root, _, err := newRootCmd()
+	if err != nil {
+		t.Fatalf("newRootCmd: %v", err)
+	}
+	var out captureWriter
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SilenceErrors = true
+	root.SetArgs(append(args, "--timeout", "2s"))
+	err = root.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestE2EPlaybackAndVolume(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "First", Artist: "Band", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Second", Artist: "Band", Duration: "0:04:00"},
+	)
+
+	if _, err := runFake(t, "play", "--name", "Kitchen"); err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	if got := kitchen.State().TransportState; got != "PLAYING" {
+		t.Fatalf("transport state: %q", got)
+	}
+	if _, err := runFake(t, "next", "--name", "Kitchen"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if _, err := runFake(t, "volume", "set", "--name", "Kitchen", "35"); err != nil {
+		t.Fatalf("volume set: %v", err)
+	}
+	if got := kitchen.State().Volume; got != 35 {
+		t.Fatalf("volume: %d", got)
+	}
+
+	out, err := runFake(t, "status", "--name", "Kitchen", "--format", "json")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out, `"PLAYING"`) || !strings.Contains(out, "Second") {
+		t.Fatalf("unexpected status output: %s", out)
+	}
+
+	out, err = runFake(t, "queue", "list", "--name", "Kitchen")
+	if err != nil {
+		t.Fatalf("queue list: %v", err)
+	}
+	if !strings.Contains(out, "First") || !strings.Contains(out, "Second") {
+		t.Fatalf("unexpected queue output: %s", out)
+	}
+
+	if _, err := runFake(t, "pause", "--name", "Kitchen"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if got := kitchen.State().TransportState; got != "PAUSED_PLAYBACK" {
+		t.Fatalf("transport state: %q", got)
+	}
+}
+
+func TestE2EGroupJoinRoutesToCoordinator(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+
+	if _, err := runFake(t, "group", "join", "--name", "Office", "--to", "Kitchen"); err != nil {
+		t.Fatalf("group join: %v", err)
+	}
+	if got := h.Speaker("Office").State().Coordinator; got != h.Speaker("Kitchen").UUID {
+		t.Fatalf("Office coordinator: %q", got)
+	}
+
+	// Transport commands addressed to a member reach the coordinator.
+	h.Speaker("Kitchen").SetQueue(sonostest.Track{URI: "http://example.com/1.mp3", Title: "First"})
+	if _, err := runFake(t, "play", "--name", "Office"); err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	if got := h.Speaker("Kitchen").State().TransportState; got != "PLAYING" {
+		t.Fatalf("coordinator state: %q", got)
+	}
+
+	out, err := runFake(t, "group", "status")
+	if err != nil {
+		t.Fatalf("group status: %v", err)
+	}
+	if !strings.Contains(out, "Kitchen") || !strings.Contains(out, "Office") {
+		t.Fatalf("unexpected group status: %s", out)
+	}
+}
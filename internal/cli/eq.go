@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type eqClient interface {
+	GetEQSetting(ctx context.Context, name string) (sonos.EQValue, error)
+	GetEQSettings(ctx context.Context) ([]sonos.EQValue, error)
+	SetEQSetting(ctx context.Context, name, value string) (sonos.EQValue, error)
+}
+
+// EQ is per speaker, so this targets the named room itself rather than its
+// group coordinator.
+var newEQClient = func(ctx context.Context, flags *rootFlags) (eqClient, error) {
+	return anySpeakerClient(ctx, flags)
+}
+
+func newEQCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eq",
+		Short: "Get or set EQ and home-theater audio settings",
+		Long: `Reads and changes a speaker's audio settings (RenderingControl), per room.
+
+Settings: ` + strings.Join(sonos.EQSettingNames(), ", ") + `.
+Levels take integers (bass/treble -10..10, balance -100..100 where negative is left,
+sub-gain/surround-level -15..15, height-level -10..10); switches take on/off.
+Home-theater settings only exist on soundbars/Amp; other models report them as not supported.`,
+	}
+	cmd.AddCommand(newEQGetCmd(flags))
+	cmd.AddCommand(newEQSetCmd(flags))
+	return cmd
+}
+
+func formatEQValue(v sonos.EQValue) string {
+	if !v.Supported {
+		return "not supported"
+	}
+	if v.Switch {
+		if v.Value != 0 {
+			return "on"
+		}
+		return "off"
+	}
+	return strconv.Itoa(v.Value)
+}
+
+func newEQGetCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "get [setting]",
+		Short:        "Show one EQ setting, or all of them",
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newEQClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			var values []sonos.EQValue
+			if len(args) == 1 {
+				v, err := c.GetEQSetting(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				values = []sonos.EQValue{v}
+			} else {
+				if values, err = c.GetEQSettings(ctx); err != nil {
+					return err
+				}
+			}
+
+			if isJSON(flags) {
+				if len(args) == 1 {
+					return writeJSON(cmd, values[0])
+				}
+				return writeJSON(cmd, values)
+			}
+			if len(args) == 1 && !isTSV(flags) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatEQValue(values[0]))
+				return nil
+			}
+			if isTSV(flags) {
+				for _, v := range values {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", v.Setting, formatEQValue(v))
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "SETTING\tVALUE")
+			for _, v := range values {
+				_, _ = fmt.Fprintf(w, "%s\t%s\n", v.Setting, formatEQValue(v))
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
+
+func newEQSetCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "set <setting> <value>",
+		Short:        "Change an EQ setting",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newEQClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			v, err := c.SetEQSetting(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "eq.set", map[string]any{"setting": v.Setting, "value": v.Value})
+		},
+	}
+	return allowNegativeArgs(cmd)
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestE2EEQGetAndSet(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Living Room")
+	tv := h.Speaker("Living Room")
+	tv.SetModel("Sonos Beam")
+
+	if _, err := runFake(t, "eq", "set", "--name", "Kitchen", "bass", "5"); err != nil {
+		t.Fatalf("eq set bass: %v", err)
+	}
+	out, err := runFake(t, "eq", "get", "--name", "Kitchen", "bass")
+	if err != nil || strings.TrimSpace(out) != "5" {
+		t.Fatalf("eq get bass = %q, %v", out, err)
+	}
+	if eq := h.Speaker("Kitchen").State().EQ; eq["Bass"] != 5 {
+		t.Fatalf("bass not applied: %+v", eq)
+	}
+
+	// Negative values are positionals, not shorthand flags, wherever the flags go.
+	if _, err := runFake(t, "eq", "set", "bass", "-5", "--name", "Kitchen"); err != nil {
+		t.Fatalf("eq set bass -5: %v", err)
+	}
+	if _, err := runFake(t, "eq", "set", "--name", "Kitchen", "treble", "-10"); err != nil {
+		t.Fatalf("eq set treble -10: %v", err)
+	}
+	if eq := h.Speaker("Kitchen").State().EQ; eq["Bass"] != -5 || eq["Treble"] != -10 {
+		t.Fatalf("negative values not applied: %+v", eq)
+	}
+
+	// EQ applies to the named room even when it is grouped under another coordinator.
+	if _, err := runFake(t, "group", "join", "--name", "Living Room", "--to", "Kitchen"); err != nil {
+		t.Fatalf("group join: %v", err)
+	}
+	if _, err := runFake(t, "eq", "set", "--name", "Living Room", "night-mode", "on"); err != nil {
+		t.Fatalf("eq set night-mode: %v", err)
+	}
+	if eq := tv.State().EQ; eq["NightMode"] != 1 {
+		t.Fatalf("night mode not applied to the soundbar: %+v", eq)
+	}
+
+	out, err = runFake(t, "eq", "get", "--name", "Kitchen")
+	if err != nil {
+		t.Fatalf("eq get: %v", err)
+	}
+	if !strings.Contains(out, "SETTING") || !strings.Contains(out, "loudness") || !strings.Contains(out, "not supported") {
+		t.Fatalf("unexpected eq table: %q", out)
+	}
+
+	_, err = runFake(t, "eq", "set", "--name", "Kitchen", "night-mode", "on")
+	if err == nil || err.Error() != "night-mode is not supported on Sonos One" {
+		t.Fatalf("expected clean unsupported error, got %v", err)
+	}
+	if _, err := runFake(t, "eq", "set", "--name", "Kitchen", "treble", "99"); err == nil || !strings.Contains(err.Error(), "-10 and 10") {
+		t.Fatalf("expected range error, got %v", err)
+	}
+}
@@ -55,10 +55,11 @@ func newGroupCmd(flags *rootFlags) *cobra.Command {
 }
 
 func newGroupStatusCmd(flags *rootFlags) *cobra.Command {
-	var all bool
+	var all, details bool
 	cmd := &cobra.Command{
 		Use:          "status",
 		Short:        "Show current groups and members",
+		Long:         "Shows each group and its rooms. Rooms made of several speakers (stereo pairs, home theaters with sub/surrounds) list their bonded devices and channel roles underneath; --all lists every device as a member instead. --details adds each device's firmware, boot sequence and network attributes and lists vanished devices.",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			tg, err := newTopologyGetter(cmd.Context(), flags.Timeout)
@@ -70,6 +71,17 @@ func newGroupStatusCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 
+			// Bonded devices are invisible members; collect them per room
+			// before the filter below drops them.
+			bonded := map[string][]sonos.Member{}
+			for _, g := range top.Groups {
+				for _, m := range g.Members {
+					if m.BondedTo != "" {
+						bonded[m.BondedTo] = append(bonded[m.BondedTo], m)
+					}
+				}
+			}
+
 			if !all {
 				for i := range top.Groups {
 					var visible []sonos.Member
@@ -96,7 +108,13 @@ func newGroupStatusCmd(flags *rootFlags) *cobra.Command {
 						if m.IsCoordinator {
 							role = "coordinator"
 						}
-						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\n", g.ID, coord.Name, coord.IP, m.Name, m.IP, role)
+						line := strings.Join([]string{g.ID, coord.Name, coord.IP, m.Name, m.IP, role}, "\t")
+						if details {
+							// The channel role goes with the details so the
+							// default six columns stay as they were.
+							line += "\t" + m.Role + "\t" + strings.Join(memberDetailTSV(m), "\t")
+						}
+						_, _ = fmt.Fprintln(cmd.OutOrStdout(), line)
 					}
 				}
 				return nil
@@ -114,14 +132,36 @@ func newGroupStatusCmd(flags *rootFlags) *cobra.Command {
 					if m.IsCoordinator {
 						mark = "*"
 					}
-					_, _ = fmt.Fprintf(w, "  %s\t%s\t(%s)\n", mark, m.Name, m.IP)
+					switch {
+					case details:
+						_, _ = fmt.Fprintf(w, "  %s\t%s\t(%s)\t%s\t%s\n", mark, m.Name, m.IP, m.Role, strings.Join(memberDetailPlain(m), "\t"))
+					case m.Role == "":
+						_, _ = fmt.Fprintf(w, "  %s\t%s\t(%s)\n", mark, m.Name, m.IP)
+						continue
+					default:
+						_, _ = fmt.Fprintf(w, "  %s\t%s\t(%s)\t%s\n", mark, m.Name, m.IP, m.Role)
+					}
+					if all {
+						continue
+					}
+					for _, b := range bonded[m.UUID] {
+						if details {
+							_, _ = fmt.Fprintf(w, "   \t  + %s\t(%s)\t\t%s\n", b.Role, b.IP, strings.Join(memberDetailPlain(b), "\t"))
+							continue
+						}
+						_, _ = fmt.Fprintf(w, "   \t  + %s\t(%s)\n", b.Role, b.IP)
+					}
 				}
 				_, _ = fmt.Fprintln(w)
 			}
+			if details {
+				writeVanishedPlain(w, top.Vanished)
+			}
 			return w.Flush()
 		},
 	}
 	cmd.Flags().BoolVar(&all, "all", false, "Include invisible/bonded devices (advanced)")
+	cmd.Flags().BoolVar(&details, "details", false, "Show firmware, boot sequence and network details per device")
 	return cmd
 }
 
@@ -12,6 +12,7 @@ import (
 type groupAudioClient interface {
 	GetGroupVolume(ctx context.Context) (int, error)
 	SetGroupVolume(ctx context.Context, volume int) error
+	SetRelativeGroupVolume(ctx context.Context, adjustment int) (int, error)
 	GetGroupMute(ctx context.Context) (bool, error)
 	SetGroupMute(ctx context.Context, mute bool) error
 }
@@ -75,9 +76,41 @@ func newGroupVolumeCmd(flags *rootFlags) *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(newGroupVolumeStepCmd(flags, "up", 1))
+	cmd.AddCommand(newGroupVolumeStepCmd(flags, "down", -1))
+	cmd.AddCommand(newVolumeFadeCmd(flags, true))
+
 	return cmd
 }
 
+func newGroupVolumeStepCmd(flags *rootFlags, direction string, sign int) *cobra.Command {
+	return &cobra.Command{
+		Use:          direction + " [step]",
+		Short:        fmt.Sprintf("Turn the group volume %s by a step (default %d)", direction, defaultVolumeStep),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			step, err := parseVolumeStep(args)
+			if err != nil {
+				return err
+			}
+			c, err := newGroupAudioClient(cmd.Context(), flags)
+			if err != nil {
+				return err
+			}
+			v, err := c.SetRelativeGroupVolume(cmd.Context(), sign*step)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, strconv.Itoa(v))
+			return writeOK(cmd, flags, "group.volume."+direction, map[string]any{"volume": v})
+		},
+	}
+}
+
 func newGroupMuteCmd(flags *rootFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mute",
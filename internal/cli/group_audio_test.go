@@ -31,6 +31,13 @@ func (f *fakeGroupAudioClient) SetGroupVolume(ctx context.Context, volume int) e
 	return nil
 }
 
+func (f *fakeGroupAudioClient) SetRelativeGroupVolume(ctx context.Context, adjustment int) (int, error) {
+	f.setVolCalls++
+	f.groupVolume += adjustment
+	f.setVolValue = f.groupVolume
+	return f.groupVolume, nil
+}
+
 func (f *fakeGroupAudioClient) GetGroupMute(ctx context.Context) (bool, error) {
 	f.getMuteCalls++
 	return f.groupMute, nil
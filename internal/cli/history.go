@@ -0,0 +1,436 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/STop211650/sonoscli/internal/history"
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+var newHistoryStore = func() (history.Store, error) {
+	return history.NewFileStore()
+}
+
+func newHistoryCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Record and report what played in each room",
+		Long: `history record listens to AVTransport events and appends every track played (room, grouped rooms,
+title, artist, album, service, start time and time listened) to an append-only JSON Lines file in the
+sonoscli config directory. The other subcommands query it; --since takes 7d, 2w, 12h, a date
+(2026-10-01) or an RFC3339 time, and --name limits the report to plays heard in that room.`,
+	}
+	cmd.AddCommand(newHistoryRecordCmd(flags))
+	cmd.AddCommand(newHistoryListCmd(flags))
+	cmd.AddCommand(newHistoryTopCmd(flags, "top-artists", "Most played artists", history.TopArtists))
+	cmd.AddCommand(newHistoryTopCmd(flags, "top-tracks", "Most played tracks", history.TopTracks))
+	cmd.AddCommand(newHistoryExportCmd(flags))
+	return cmd
+}
+
+func newHistoryRecordCmd(flags *rootFlags) *cobra.Command {
+	var duration time.Duration
+	minListen := 30 * time.Second
+
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record plays until stopped",
+		Long: `Subscribes to AVTransport events on every group's coordinator (or only the --name/--ip target's group)
+and records a play when the track changes, the room joins another group, or recording stops (Ctrl+C).
+Only time spent playing counts as listened; plays shorter than --min-listen are skipped. Grouping
+changes are followed, and a track already playing when recording starts is counted from then.
+
+Each recorded play is also printed. The service is taken from the track URI (sid= parameter, or the
+scheme for Spotify, the music library, radio, line-in and TV).
+
+Requires that Sonos speakers can reach your machine on the chosen callback port (firewall may
+prompt).`,
+		Example:      "  sonos history record\n  sonos history record --name Kitchen --min-listen 1m",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newHistoryStore()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
+			if duration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, duration)
+				defer cancel()
+			}
+
+			top, err := householdTopology(ctx, flags)
+			if err != nil {
+				return err
+			}
+			w := &householdWatcher{flags: flags, errOut: cmd.ErrOrStderr(), all: true, subs: map[string][]*sonos.EventSubscription{}}
+			if strings.TrimSpace(flags.Name) != "" || strings.TrimSpace(flags.IP) != "" {
+				m, err := resolveMember(top, flags.Name, flags.IP)
+				if err != nil {
+					return err
+				}
+				w.all, w.roomUUID = false, m.UUID
+			}
+			targets := watchTargets(top, w.all, w.roomUUID)
+			if len(targets) == 0 {
+				return errors.New("no coordinators found")
+			}
+			var remoteIP string
+			for ip := range targets {
+				if remoteIP == "" || ip < remoteIP {
+					remoteIP = ip
+				}
+			}
+			w.groupServices, w.householdServices = splitWatchServices([]sonos.EventService{sonos.ServiceAVTransport}, true)
+
+			em, err := sonos.NewEventManager(remoteIP, sonos.EventManagerOptions{})
+			if err != nil {
+				return err
+			}
+			defer closeEventManager(ctx, em, flags.Timeout)
+			w.em = em
+			if err := w.reconcile(ctx, top); err != nil && len(w.subs) == 0 {
+				return err
+			}
+
+			rec := history.NewRecorder(minListen)
+			save := func(p history.Play) {
+				if err := store.Append(p); err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "history: %v\n", err)
+					return
+				}
+				writeHistoryPlay(cmd, flags, p)
+			}
+			// Plays in progress are saved whichever way recording stops.
+			defer func() {
+				for _, p := range rec.Flush(time.Now()) {
+					save(p)
+				}
+			}()
+
+			if !isJSON(flags) && !isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Recording listening history (callback %s). Press Ctrl+C to stop.\n", em.CallbackURL())
+			}
+
+			states := map[string]*sonos.RoomState{}
+			rooms := map[string]string{} // room recorded per coordinator IP
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case sev, ok := <-em.Events():
+					if !ok {
+						return nil
+					}
+					now := time.Now()
+					if zgs := sev.Vars["zone_group_state"]; sev.Service == sonos.ServiceZoneGroupTopology && zgs != "" {
+						next, err := sonos.ParseZoneGroupState(zgs)
+						if err != nil {
+							continue
+						}
+						top = next
+						_ = w.reconcile(ctx, top)
+						// A coordinator that joined another group stopped
+						// playing its own track.
+						for ip, room := range rooms {
+							if _, ok := w.subs[ip]; ok {
+								continue
+							}
+							if p, ok := rec.Finish(room, now); ok {
+								save(p)
+							}
+							delete(rooms, ip)
+							delete(states, ip)
+						}
+						continue
+					}
+					if sev.Service != sonos.ServiceAVTransport {
+						continue
+					}
+					room := w.tag(sev.IP).Room
+					if room == "" {
+						continue
+					}
+					rooms[sev.IP] = room
+					state := states[sev.IP]
+					if state == nil {
+						state = sonos.NewRoomState()
+						states[sev.IP] = state
+					}
+					for _, re := range state.Apply(sev).Events {
+						switch e := re.(type) {
+						case sonos.TransportStateChanged:
+							rec.SetPlaying(room, e.State == "PLAYING", now)
+						case sonos.TrackChanged:
+							if p, ok := rec.SetTrack(room, historyMembers(top, sev.IP), historyTrack(e), now); ok {
+								save(p)
+							}
+						}
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&duration, "duration", 0, "Stop after this duration (0 = until Ctrl+C)")
+	cmd.Flags().DurationVar(&minListen, "min-listen", minListen, "Skip plays listened to for less than this")
+	return cmd
+}
+
+// historyTrack converts a track change into what the recorder keeps.
+func historyTrack(e sonos.TrackChanged) history.Track {
+	t := history.Track{URI: e.URI, Duration: e.Duration, Service: sonos.MusicServiceForURI(e.URI)}
+	if e.Item != nil {
+		t.Title, t.Artist, t.Album = e.Item.Title, e.Item.Artist, e.Item.Album
+	}
+	return t
+}
+
+// historyMembers returns the other visible rooms in the group coordinated by
+// ip, sorted by name.
+func historyMembers(top sonos.Topology, ip string) []string {
+	var out []string
+	for _, g := range top.Groups {
+		if g.Coordinator.IP != ip {
+			continue
+		}
+		for _, m := range g.Members {
+			if m.IsVisible && m.UUID != g.Coordinator.UUID {
+				out = append(out, m.Name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func writeHistoryPlay(cmd *cobra.Command, flags *rootFlags, p history.Play) {
+	switch {
+	case isJSON(flags):
+		_ = writeJSONLine(cmd, p)
+	case isTSV(flags):
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\t%d\n", p.Start.Format(time.RFC3339), p.Room, p.Artist, p.Title, p.Album, p.Service, p.ListenedSeconds)
+	default:
+		line := fmt.Sprintf("%s %s: %s (%s", p.Start.Local().Format("15:04:05"), p.Room, historyTitle(p), p.Listened())
+		if p.Service != "" {
+			line += ", " + p.Service
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), line+")")
+	}
+}
+
+// historyTitle renders "Artist - Title", or just the title.
+func historyTitle(p history.Play) string {
+	if p.Artist == "" {
+		return p.Title
+	}
+	return p.Artist + " - " + p.Title
+}
+
+// parseHistorySince parses --since: a number of days or weeks (7d, 2w), a Go
+// duration (12h), a date (2006-01-02, local time) or an RFC3339 time. An
+// empty value means all of history.
+func parseHistorySince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	day := 24 * time.Hour
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		if days, err := strconv.Atoi(n); err == nil && days >= 0 {
+			return now.Add(-time.Duration(days) * day), nil
+		}
+	}
+	if n, ok := strings.CutSuffix(s, "w"); ok {
+		if weeks, err := strconv.Atoi(n); err == nil && weeks >= 0 {
+			return now.Add(-time.Duration(weeks) * 7 * day), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (e.g. 7d, 12h, 2026-10-01)", s)
+}
+
+// loadHistory returns the plays since the --since value, limited to the
+// --name room when one is given.
+func loadHistory(flags *rootFlags, since string) ([]history.Play, error) {
+	from, err := parseHistorySince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	store, err := newHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	plays, err := store.List(from)
+	if err != nil {
+		return nil, err
+	}
+	room := strings.TrimSpace(flags.Name)
+	if room == "" {
+		return plays, nil
+	}
+	out := plays[:0]
+	for _, p := range plays {
+		if p.InRoom(room) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func newHistoryListCmd(flags *rootFlags) *cobra.Command {
+	var since string
+	var limit int
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List recorded plays, newest first",
+		Example:      "  sonos history list --since 7d\n  sonos history list --name Kitchen --limit 20",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plays, err := loadHistory(flags, since)
+			if err != nil {
+				return err
+			}
+			out := make([]history.Play, 0, len(plays))
+			for i := len(plays) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+				out = append(out, plays[i])
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, out)
+			}
+			if isTSV(flags) {
+				for _, p := range out {
+					writeHistoryPlay(cmd, flags, p)
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "START\tROOM\tARTIST\tTITLE\tALBUM\tSERVICE\tLISTENED\n")
+			for _, p := range out {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Start.Local().Format("2006-01-02 15:04"), p.Room, p.Artist, p.Title, p.Album, p.Service, p.Listened())
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only plays since this long ago or this date (e.g. 7d, 2026-10-01)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Max results to return (0 = all)")
+	return cmd
+}
+
+func newHistoryTopCmd(flags *rootFlags, use, short string, rank func([]history.Play, int) []history.Count) *cobra.Command {
+	var since string
+	var limit int
+	tracks := use == "top-tracks"
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		Long:         short + ", by number of plays, then time listened.",
+		Example:      "  sonos history " + use + " --since 7d\n  sonos history " + use + " --name Kitchen --limit 5",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plays, err := loadHistory(flags, since)
+			if err != nil {
+				return err
+			}
+			counts := rank(plays, limit)
+			if isJSON(flags) {
+				return writeJSON(cmd, counts)
+			}
+			listened := func(c history.Count) time.Duration { return time.Duration(c.ListenedSeconds) * time.Second }
+			if isTSV(flags) {
+				for _, c := range counts {
+					if tracks {
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%d\t%d\n", c.Title, c.Artist, c.Plays, c.ListenedSeconds)
+					} else {
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%d\n", c.Artist, c.Plays, c.ListenedSeconds)
+					}
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			if tracks {
+				_, _ = fmt.Fprintf(w, "TITLE\tARTIST\tPLAYS\tLISTENED\n")
+			} else {
+				_, _ = fmt.Fprintf(w, "ARTIST\tPLAYS\tLISTENED\n")
+			}
+			for _, c := range counts {
+				if tracks {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.Title, c.Artist, c.Plays, listened(c))
+				} else {
+					_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", c.Artist, c.Plays, listened(c))
+				}
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only plays since this long ago or this date (e.g. 7d, 2026-10-01)")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Max results to return (0 = all)")
+	return cmd
+}
+
+func newHistoryExportCmd(flags *rootFlags) *cobra.Command {
+	var since string
+	var asCSV bool
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export recorded plays",
+		Long: `Writes the recorded plays, oldest first, as JSON Lines (one play per line, the format of the history
+file) or, with --csv, as CSV with a header row for spreadsheets.`,
+		Example:      "  sonos history export --since 30d > plays.jsonl\n  sonos history export --csv > plays.csv",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plays, err := loadHistory(flags, since)
+			if err != nil {
+				return err
+			}
+			if !asCSV {
+				for _, p := range plays {
+					if err := writeJSONLine(cmd, p); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			w := csv.NewWriter(cmd.OutOrStdout())
+			_ = w.Write([]string{"start", "room", "members", "artist", "title", "album", "service", "listened_seconds", "duration", "uri"})
+			for _, p := range plays {
+				_ = w.Write([]string{
+					p.Start.Format(time.RFC3339), p.Room, strings.Join(p.Members, ";"), p.Artist, p.Title, p.Album,
+					p.Service, strconv.Itoa(p.ListenedSeconds), p.Duration, p.URI,
+				})
+			}
+			w.Flush()
+			return w.Error()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only plays since this long ago or this date (e.g. 7d, 2026-10-01)")
+	cmd.Flags().BoolVar(&asCSV, "csv", false, "Write CSV instead of JSON Lines")
+	return cmd
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/history"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type fakeHistoryStore struct {
+	mu    sync.Mutex
+	plays []history.Play
+}
+
+func (f *fakeHistoryStore) Append(play history.Play) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.plays = append(f.plays, play)
+	return nil
+}
+
+func (f *fakeHistoryStore) List(since time.Time) ([]history.Play, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []history.Play
+	for _, p := range f.plays {
+		if !p.Start.Before(since) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeHistoryStore) all() []history.Play {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]history.Play(nil), f.plays...)
+}
+
+func withFakeHistoryStore(t *testing.T, plays ...history.Play) *fakeHistoryStore {
+	t.Helper()
+	store := &fakeHistoryStore{plays: plays}
+	orig := newHistoryStore
+	t.Cleanup(func() { newHistoryStore = orig })
+	newHistoryStore = func() (history.Store, error) { return store, nil }
+	return store
+}
+
+func TestParseHistorySince(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"":                     {},
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"2w":                   now.Add(-14 * 24 * time.Hour),
+		"90m":                  now.Add(-90 * time.Minute),
+		"2026-10-01T08:00:00Z": time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		"2026-10-01":           time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+	}
+	for in, want := range cases {
+		got, err := parseHistorySince(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseHistorySince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"soon", "-3d", "-1h"} {
+		if _, err := parseHistorySince(bad, now); err == nil {
+			t.Errorf("parseHistorySince(%q): expected error", bad)
+		}
+	}
+}
+
+func historyTestPlays() []history.Play {
+	now := time.Now()
+	return []history.Play{
+		{Room: "Kitchen", Title: "Old", Artist: "Band", Start: now.Add(-30 * 24 * time.Hour), ListenedSeconds: 200},
+		{Room: "Kitchen", Members: []string{"Office"}, Title: "Song A", Artist: "Band", Service: "Spotify", Start: now.Add(-3 * time.Hour), ListenedSeconds: 180},
+		{Room: "Living Room", Title: "Song B", Artist: "Other", Service: "Apple Music", Start: now.Add(-2 * time.Hour), ListenedSeconds: 240},
+		{Room: "Kitchen", Title: "Song A", Artist: "Band", Service: "Spotify", Start: now.Add(-time.Hour), ListenedSeconds: 60},
+	}
+}
+
+func TestHistoryListSinceAndRoom(t *testing.T) {
+	withFakeHistoryStore(t, historyTestPlays()...)
+
+	out, err := runFake(t, "history", "list", "--since", "7d", "--format", "json")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var plays []history.Play
+	if err := json.Unmarshal([]byte(out), &plays); err != nil {
+		t.Fatalf("bad json %q: %v", out, err)
+	}
+	// Newest first, the 30-day-old play left out.
+	if len(plays) != 3 || plays[0].Start.Before(plays[1].Start) || plays[2].Title != "Song A" {
+		t.Fatalf("unexpected plays: %+v", plays)
+	}
+
+	// A grouped member heard the play too.
+	out, err = runFake(t, "history", "list", "--name", "Office", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("list --name: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "\tKitchen\tBand\tSong A\t\tSpotify\t180") {
+		t.Fatalf("unexpected tsv: %q", out)
+	}
+
+	out, err = runFake(t, "history", "list", "--limit", "1")
+	if err != nil {
+		t.Fatalf("list --limit: %v", err)
+	}
+	if !strings.HasPrefix(out, "START") || strings.Count(out, "\n") != 2 || !strings.Contains(out, "1m0s") {
+		t.Fatalf("unexpected table: %q", out)
+	}
+}
+
+func TestHistoryTopArtistsAndTracks(t *testing.T) {
+	withFakeHistoryStore(t, historyTestPlays()...)
+
+	out, err := runFake(t, "history", "top-artists", "--since", "7d", "--format", "json")
+	if err != nil {
+		t.Fatalf("top-artists: %v", err)
+	}
+	var counts []history.Count
+	if err := json.Unmarshal([]byte(out), &counts); err != nil {
+		t.Fatalf("bad json %q: %v", out, err)
+	}
+	if len(counts) != 2 || counts[0] != (history.Count{Artist: "Band", Plays: 2, ListenedSeconds: 240}) {
+		t.Fatalf("unexpected artists: %+v", counts)
+	}
+
+	out, err = runFake(t, "history", "top-tracks", "--limit", "1")
+	if err != nil {
+		t.Fatalf("top-tracks: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "TITLE") || !strings.HasPrefix(lines[1], "Song A") || !strings.Contains(lines[1], "Band") {
+		t.Fatalf("unexpected table: %q", out)
+	}
+}
+
+func TestHistoryExport(t *testing.T) {
+	withFakeHistoryStore(t, historyTestPlays()...)
+
+	out, err := runFake(t, "history", "export", "--since", "7d")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", out)
+	}
+	var first history.Play
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Title != "Song A" || first.Members[0] != "Office" {
+		t.Fatalf("unexpected first line %q: %v", lines[0], err)
+	}
+
+	out, err = runFake(t, "history", "export", "--csv", "--name", "Living Room")
+	if err != nil {
+		t.Fatalf("export --csv: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "start,room,members,artist,title") || !strings.Contains(lines[1], ",Living Room,,Other,Song B,,Apple Music,240,") {
+		t.Fatalf("unexpected csv: %q", out)
+	}
+}
+
+func TestHistoryRejectsBadSince(t *testing.T) {
+	withFakeHistoryStore(t)
+	_, err := runFake(t, "history", "list", "--since", "last week")
+	if err == nil || !strings.Contains(err.Error(), `invalid --since "last week"`) {
+		t.Fatalf("expected --since error, got %v", err)
+	}
+}
+
+func TestE2EHistoryRecord(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "x-sonos-spotify:spotify%3atrack%3a1?sid=12", Title: "First", Artist: "Band", Album: "Record", Duration: "0:03:00"},
+		sonostest.Track{URI: "x-file-cifs://nas/Music/second.flac", Title: "Second", Artist: "Band", Duration: "0:04:00"},
+	)
+	store := withFakeHistoryStore(t)
+
+	var wg sync.WaitGroup
+	var out string
+	var runErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		out, runErr = runFake(t, "history", "record", "--min-listen", "0", "--duration", "1500ms", "--format", "json")
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(kitchen.Subscriptions()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriptions = %v", kitchen.Subscriptions())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	kitchen.SetTransportState("PLAYING")
+	time.Sleep(300 * time.Millisecond)
+	if err := newSonosClient(kitchen.IP, time.Second).Next(context.Background()); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	wg.Wait()
+	if runErr != nil {
+		t.Fatalf("record: %v", runErr)
+	}
+	plays := store.all()
+	if len(plays) != 2 {
+		t.Fatalf("expected 2 plays, got %+v (output %q)", plays, out)
+	}
+	first, second := plays[0], plays[1]
+	if first.Room != "Kitchen" || first.Title != "First" || first.Artist != "Band" || first.Album != "Record" || first.Service != "Spotify" || first.Duration != "0:03:00" || first.Start.IsZero() {
+		t.Fatalf("unexpected first play: %+v", first)
+	}
+	if second.Title != "Second" || second.Service != "Music Library" || second.Start.Before(first.Start) {
+		t.Fatalf("unexpected second play: %+v", second)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"title":"First"`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if subs := kitchen.Subscriptions(); len(subs) != 0 {
+		t.Fatalf("subscriptions left: %v", subs)
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// inventoryWorkers bounds how many speakers are queried at once.
+const inventoryWorkers = 8
+
+var newInventoryClient = func(ip string, flags *rootFlags) deviceInfoClient {
+	return newSonosClient(ip, flags.Timeout)
+}
+
+type inventoryEntry struct {
+	sonos.DeviceInfo
+	Visible bool   `json:"visible"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newInventoryCmd(flags *rootFlags) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "List hardware and firmware details for every speaker",
+		Long: `Runs "device info" against every speaker in the household concurrently and prints one row
+per device (model, serial, MAC, versions, uptime). Use --format json or --format tsv to export
+for asset tracking. Speakers that cannot be queried are listed with their error and make the
+command exit non-zero.`,
+		Example:      "  sonos inventory\n  sonos inventory --all --format json > sonos-inventory.json\n  sonos inventory --format tsv",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			members, err := inventoryMembers(ctx, flags, all)
+			if err != nil {
+				return err
+			}
+			entries := collectInventory(ctx, flags, members)
+
+			failed := 0
+			for _, e := range entries {
+				if e.Error != "" {
+					failed++
+				}
+			}
+			if err := writeInventory(cmd, flags, entries); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d devices could not be queried", failed, len(entries))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Include invisible/bonded devices (satellites, subs, stereo pair members)")
+	return cmd
+}
+
+// householdTopology reads the topology from --ip or the first discovered
+// speaker.
+func householdTopology(ctx context.Context, flags *rootFlags) (sonos.Topology, error) {
+	ip := strings.TrimSpace(flags.IP)
+	if ip == "" {
+		devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
+		if err != nil {
+			return sonos.Topology{}, err
+		}
+		if len(devs) == 0 {
+			return sonos.Topology{}, errors.New("no speakers found")
+		}
+		ip = devs[0].IP
+	}
+	return newSonosClient(ip, flags.Timeout).GetTopology(ctx)
+}
+
+// inventoryMembers returns the household's devices with an IP, sorted by
+// name.
+func inventoryMembers(ctx context.Context, flags *rootFlags, all bool) ([]sonos.Member, error) {
+	top, err := householdTopology(ctx, flags)
+	if err != nil {
+		return nil, err
+	}
+	return topologyMembers(top, all), nil
+}
+
+func topologyMembers(top sonos.Topology, all bool) []sonos.Member {
+	var out []sonos.Member
+	for _, g := range top.Groups {
+		for _, m := range g.Members {
+			if m.IP == "" || (!all && !m.IsVisible) {
+				continue
+			}
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name == out[j].Name {
+			return out[i].IP < out[j].IP
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func collectInventory(ctx context.Context, flags *rootFlags, members []sonos.Member) []inventoryEntry {
+	entries := make([]inventoryEntry, len(members))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(inventoryWorkers, len(members)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				m := members[i]
+				info, err := newInventoryClient(m.IP, flags).GetDeviceInfo(ctx)
+				if err != nil {
+					info = sonos.DeviceInfo{IP: m.IP, Name: m.Name, UDN: m.UUID}
+					entries[i].Error = err.Error()
+				}
+				entries[i].DeviceInfo = info
+				entries[i].Visible = m.IsVisible
+			}
+		}()
+	}
+	for i := range members {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return entries
+}
+
+func writeInventory(cmd *cobra.Command, flags *rootFlags, entries []inventoryEntry) error {
+	if isJSON(flags) {
+		if entries == nil {
+			entries = []inventoryEntry{}
+		}
+		return writeJSON(cmd, entries)
+	}
+	if isTSV(flags) {
+		for _, e := range entries {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%v\t%s\n",
+				e.Name, e.IP, e.UDN, e.ModelName, e.ModelNumber, e.SerialNumber, e.MACAddress,
+				e.SoftwareVersion, e.HardwareVersion, e.SeriesID, e.UptimeSeconds, e.Visible, e.Error)
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tIP\tMODEL\tSERIAL\tMAC\tSOFTWARE\tUPTIME")
+	for _, e := range entries {
+		if e.Error != "" {
+			_, _ = fmt.Fprintf(w, "%s\t%s\terror: %s\t\t\t\t\n", e.Name, e.IP, e.Error)
+			continue
+		}
+		uptime := "-"
+		if e.UptimeSeconds > 0 {
+			uptime = formatUptime(e.UptimeSeconds)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Name, e.IP, e.ModelName, e.SerialNumber, e.MACAddress, e.SoftwareVersion, uptime)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+type failingDeviceInfoClient struct{}
+
+func (failingDeviceInfoClient) GetDeviceInfo(ctx context.Context) (sonos.DeviceInfo, error) {
+	return sonos.DeviceInfo{}, errors.New("connection refused")
+}
+
+func TestE2EInventory(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office", "Bedroom")
+
+	out, err := runFake(t, "inventory")
+	if err != nil {
+		t.Fatalf("inventory: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "NAME") || !strings.HasPrefix(lines[1], "Bedroom") || !strings.HasPrefix(lines[3], "Office") {
+		t.Fatalf("unexpected inventory:\n%s", out)
+	}
+
+	out, err = runFake(t, "inventory", "--format", "json")
+	if err != nil {
+		t.Fatalf("inventory json: %v", err)
+	}
+	if strings.Count(out, `"serialNumber"`) != 3 || !strings.Contains(out, `"visible": true`) {
+		t.Fatalf("unexpected json: %s", out)
+	}
+
+	out, err = runFake(t, "inventory", "--format", "tsv")
+	if err != nil || strings.Count(out, "\n") != 3 || !strings.Contains(out, "Kitchen\t"+h.Speaker("Kitchen").IP+"\t") {
+		t.Fatalf("inventory tsv: %q, %v", out, err)
+	}
+}
+
+func TestE2EInventoryReportsFailures(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	officeIP := h.Speaker("Office").IP
+
+	orig := newInventoryClient
+	t.Cleanup(func() { newInventoryClient = orig })
+	newInventoryClient = func(ip string, flags *rootFlags) deviceInfoClient {
+		if ip == officeIP {
+			return failingDeviceInfoClient{}
+		}
+		return orig(ip, flags)
+	}
+
+	out, err := runFake(t, "inventory")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected partial failure, got %v", err)
+	}
+	if !strings.Contains(out, "error: connection refused") || !strings.Contains(out, "Sonos One") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
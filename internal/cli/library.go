@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type libraryBrowser interface {
+	BrowseLibrary(ctx context.Context, objectID string, start, count int) (sonos.LibraryPage, error)
+	SearchLibrary(ctx context.Context, category, term string, start, count int) (sonos.LibraryPage, error)
+}
+
+type libraryEnqueuer interface {
+	EnqueueLibraryItem(ctx context.Context, item sonos.DIDLItem, opts sonos.EnqueueOptions) (int, error)
+}
+
+var newLibraryBrowser = func(ctx context.Context, flags *rootFlags) (libraryBrowser, error) {
+	return anySpeakerClient(ctx, flags)
+}
+
+var newLibraryEnqueuer = func(ctx context.Context, flags *rootFlags) (libraryEnqueuer, error) {
+	return coordinatorClient(ctx, flags)
+}
+
+func newLibraryCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "library",
+		Short: "Browse and search the local music library",
+		Long: `Browses the music library Sonos has indexed from your shares (ContentDirectory A: containers).
+
+Each listing shows object IDs that can be passed to ` + "`sonos library browse <id>`" + ` to drill down
+(artist -> albums -> tracks). Use --open/--enqueue with --index N to play or queue an entry.`,
+	}
+	for _, category := range sonos.LibraryCategories() {
+		cmd.AddCommand(newLibraryCategoryCmd(flags, category))
+	}
+	cmd.AddCommand(newLibraryBrowseCmd(flags))
+	cmd.AddCommand(newLibrarySearchCmd(flags))
+	return cmd
+}
+
+// libraryListOptions are the paging and selection flags shared by every
+// library subcommand.
+type libraryListOptions struct {
+	start     int
+	limit     int
+	all       bool
+	doOpen    bool
+	doEnqueue bool
+	index     int
+}
+
+func (o *libraryListOptions) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.start, "start", 0, "Starting index (0-based)")
+	cmd.Flags().IntVar(&o.limit, "limit", 50, "Max results to return")
+	cmd.Flags().BoolVar(&o.all, "all", false, "Fetch every page (up to TotalMatches)")
+	cmd.Flags().BoolVar(&o.doOpen, "open", false, "Play the selected entry now (requires --name/--ip)")
+	cmd.Flags().BoolVar(&o.doEnqueue, "enqueue", false, "Enqueue the selected entry (requires --name/--ip)")
+	cmd.Flags().IntVar(&o.index, "index", 1, "Which entry to use with --open/--enqueue (the INDEX column, 1-based)")
+}
+
+func newLibraryCategoryCmd(flags *rootFlags, category string) *cobra.Command {
+	var opts libraryListOptions
+	cmd := &cobra.Command{
+		Use:          category,
+		Short:        "List library " + category,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := sonos.LibraryContainerID(category)
+			if err != nil {
+				return err
+			}
+			return runLibraryList(cmd, flags, &opts, func(c libraryBrowser, start, count int) (sonos.LibraryPage, error) {
+				return c.BrowseLibrary(cmd.Context(), id, start, count)
+			})
+		},
+	}
+	opts.register(cmd)
+	return cmd
+}
+
+func newLibraryBrowseCmd(flags *rootFlags) *cobra.Command {
+	var opts libraryListOptions
+	cmd := &cobra.Command{
+		Use:          "browse <id>",
+		Short:        "List the children of a library object (e.g. A:ALBUM/...)",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := strings.TrimSpace(args[0])
+			if !sonos.IsLibraryID(id) {
+				return fmt.Errorf("not a library object ID: %q (expected A:...)", id)
+			}
+			return runLibraryList(cmd, flags, &opts, func(c libraryBrowser, start, count int) (sonos.LibraryPage, error) {
+				return c.BrowseLibrary(cmd.Context(), id, start, count)
+			})
+		},
+	}
+	opts.register(cmd)
+	return cmd
+}
+
+func newLibrarySearchCmd(flags *rootFlags) *cobra.Command {
+	var opts libraryListOptions
+	var category string
+	cmd := &cobra.Command{
+		Use:          "search <term>",
+		Short:        "Search the library by name",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := sonos.LibraryContainerID(category); err != nil {
+				return err
+			}
+			term := strings.TrimSpace(strings.Join(args, " "))
+			return runLibraryList(cmd, flags, &opts, func(c libraryBrowser, start, count int) (sonos.LibraryPage, error) {
+				return c.SearchLibrary(cmd.Context(), category, term, start, count)
+			})
+		},
+	}
+	opts.register(cmd)
+	cmd.Flags().StringVar(&category, "category", "tracks", "What to search: "+strings.Join(sonos.LibraryCategories(), "|"))
+	return cmd
+}
+
+type libraryFetch func(c libraryBrowser, start, count int) (sonos.LibraryPage, error)
+
+func runLibraryList(cmd *cobra.Command, flags *rootFlags, opts *libraryListOptions, fetch libraryFetch) error {
+	if opts.doOpen && opts.doEnqueue {
+		return errors.New("use only one of --open or --enqueue")
+	}
+	if (opts.doOpen || opts.doEnqueue) && flags.IP == "" && flags.Name == "" {
+		return errors.New("--open/--enqueue require --ip or --name")
+	}
+	ctx := cmd.Context()
+	c, err := newLibraryBrowser(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	if opts.doOpen || opts.doEnqueue {
+		if opts.index <= 0 {
+			opts.index = 1
+		}
+		page, err := fetch(c, opts.index-1, 1)
+		if err != nil {
+			return err
+		}
+		if len(page.Items) == 0 {
+			return fmt.Errorf("--index %d out of range (got %d results)", opts.index, page.TotalMatches)
+		}
+		selected := page.Items[0].Item
+		e, err := newLibraryEnqueuer(ctx, flags)
+		if err != nil {
+			return err
+		}
+		first, err := e.EnqueueLibraryItem(ctx, selected, sonos.EnqueueOptions{PlayNow: opts.doOpen})
+		if err != nil {
+			return err
+		}
+		if isJSON(flags) {
+			return writeJSON(cmd, map[string]any{
+				"selected":    selected,
+				"enqueuedPos": first,
+				"action": map[string]any{
+					"enqueue": true,
+					"playNow": opts.doOpen,
+				},
+			})
+		}
+		verb := "Enqueued"
+		if opts.doOpen {
+			verb = "Playing"
+		}
+		writePlainLine(cmd, flags, fmt.Sprintf("%s %s (queue position %d)", verb, selected.Title, first))
+		return nil
+	}
+
+	page, err := fetchLibraryPages(c, opts, fetch)
+	if err != nil {
+		return err
+	}
+	if isJSON(flags) {
+		return writeJSON(cmd, page)
+	}
+	if isTSV(flags) {
+		for _, it := range page.Items {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", it.Position, libraryKind(it.Item), it.Item.Title, it.Item.ID)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "INDEX\tTYPE\tTITLE\tARTIST\tID")
+	for _, it := range page.Items {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", it.Position, libraryKind(it.Item), it.Item.Title, it.Item.Artist, it.Item.ID)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if shown := opts.start + len(page.Items); !opts.all && shown < page.TotalMatches {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "showing %d-%d of %d (use --start %d or --all for more)\n", opts.start+1, shown, page.TotalMatches, shown)
+	}
+	return nil
+}
+
+// fetchLibraryPages returns one page, or with --all every page until
+// TotalMatches entries have been read.
+func fetchLibraryPages(c libraryBrowser, opts *libraryListOptions, fetch libraryFetch) (sonos.LibraryPage, error) {
+	if !opts.all {
+		return fetch(c, opts.start, opts.limit)
+	}
+	pageSize := opts.limit
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	var all sonos.LibraryPage
+	start := opts.start
+	for {
+		page, err := fetch(c, start, pageSize)
+		if err != nil {
+			return sonos.LibraryPage{}, err
+		}
+		all.Items = append(all.Items, page.Items...)
+		all.TotalMatches = page.TotalMatches
+		all.UpdateID = page.UpdateID
+		start += len(page.Items)
+		if len(page.Items) == 0 || start >= page.TotalMatches {
+			break
+		}
+	}
+	all.NumberReturned = len(all.Items)
+	return all, nil
+}
+
+// libraryKind turns a UPnP class into a short label for listings.
+func libraryKind(it sonos.DIDLItem) string {
+	class := it.Class
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		class = class[i+1:]
+	}
+	switch class {
+	case "musicArtist":
+		return "artist"
+	case "musicAlbum":
+		return "album"
+	case "musicGenre":
+		return "genre"
+	case "composer":
+		return "composer"
+	case "musicTrack":
+		return "track"
+	case "playlistContainer":
+		return "playlist"
+	case "":
+		return "-"
+	}
+	return class
+}
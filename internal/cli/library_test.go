@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type fakeLibraryBrowser struct {
+	total      int
+	fetchCalls int
+}
+
+func (f *fakeLibraryBrowser) BrowseLibrary(ctx context.Context, objectID string, start, count int) (sonos.LibraryPage, error) {
+	f.fetchCalls++
+	page := sonos.LibraryPage{TotalMatches: f.total}
+	for i := start; i < f.total && i < start+count; i++ {
+		page.Items = append(page.Items, sonos.LibraryItem{Position: i + 1, Item: sonos.DIDLItem{ID: objectID, Title: "Item"}})
+	}
+	page.NumberReturned = len(page.Items)
+	return page, nil
+}
+
+func (f *fakeLibraryBrowser) SearchLibrary(ctx context.Context, category, term string, start, count int) (sonos.LibraryPage, error) {
+	return f.BrowseLibrary(ctx, category+":"+term, start, count)
+}
+
+func TestLibraryAllPagesThroughTotalMatches(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatJSON}
+	cmd := newLibraryCategoryCmd(flags, "albums")
+
+	orig := newLibraryBrowser
+	t.Cleanup(func() { newLibraryBrowser = orig })
+	fb := &fakeLibraryBrowser{total: 7}
+	newLibraryBrowser = func(ctx context.Context, flags *rootFlags) (libraryBrowser, error) { return fb, nil }
+
+	var out captureWriter
+	cmd.SetArgs([]string{"--all", "--limit", "3"})
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fb.fetchCalls != 3 || !strings.Contains(out.String(), `"numberReturned": 7`) {
+		t.Fatalf("unexpected paging: calls=%d output=%s", fb.fetchCalls, out.String())
+	}
+}
+
+func TestLibraryOpenRequiresTarget(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second}
+	cmd := newLibrarySearchCmd(flags)
+	cmd.SetArgs([]string{"--open", "abbey"})
+	cmd.SetOut(newDiscardWriter())
+	cmd.SetErr(newDiscardWriter())
+	cmd.SilenceErrors = true
+	if err := cmd.ExecuteContext(context.Background()); err == nil || !strings.Contains(err.Error(), "--ip or --name") {
+		t.Fatalf("expected target error, got %v", err)
+	}
+}
+
+func TestE2ELibraryBrowseSearchAndOpen(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	h.SetLibrary(
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/1.flac", Title: "Come Together", Artist: "The Beatles", Album: "Abbey Road"},
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/2.flac", Title: "Something", Artist: "The Beatles", Album: "Abbey Road"},
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/3.flac", Title: "So What", Artist: "Miles Davis", Album: "Kind of Blue"},
+	)
+	kitchen := h.Speaker("Kitchen")
+
+	out, err := runFake(t, "library", "artists", "--name", "Kitchen")
+	if err != nil {
+		t.Fatalf("library artists: %v", err)
+	}
+	if !strings.Contains(out, "The Beatles") || !strings.Contains(out, "A:ARTIST/The%20Beatles") {
+		t.Fatalf("unexpected artists output: %q", out)
+	}
+
+	out, err = runFake(t, "library", "browse", "--name", "Kitchen", "A:ARTIST/The%20Beatles/Abbey%20Road")
+	if err != nil || !strings.Contains(out, "Come Together") || !strings.Contains(out, "Something") {
+		t.Fatalf("library browse = %q, %v", out, err)
+	}
+
+	out, err = runFake(t, "library", "tracks", "--name", "Kitchen", "--limit", "1")
+	if err != nil || !strings.Contains(out, "showing 1-1 of 3") {
+		t.Fatalf("library tracks paging = %q, %v", out, err)
+	}
+
+	out, err = runFake(t, "library", "search", "--name", "Kitchen", "--category", "albums", "abbey", "--open")
+	if err != nil || !strings.Contains(out, "Playing Abbey Road") {
+		t.Fatalf("library search --open = %q, %v", out, err)
+	}
+	st := kitchen.State()
+	if len(st.Queue) != 2 || st.TransportState != "PLAYING" || st.Queue[0].Title != "Come Together" {
+		t.Fatalf("unexpected state after open: %+v", st)
+	}
+
+	if _, err := runFake(t, "library", "search", "--name", "Kitchen", "so", "--enqueue", "--index", "2"); err != nil {
+		t.Fatalf("library search --enqueue: %v", err)
+	}
+	if q := kitchen.State().Queue; len(q) != 3 || q[2].Title != "So What" {
+		t.Fatalf("unexpected queue after enqueue: %+v", q)
+	}
+	if _, err := runFake(t, "library", "search", "--name", "Kitchen", "so", "--enqueue", "--index", "9"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected out of range error, got %v", err)
+	}
+	if _, err := runFake(t, "library", "browse", "--name", "Kitchen", "SQ:1"); err == nil {
+		t.Fatalf("expected non-library ID error")
+	}
+}
@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/appconfig"
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// volumeLimitNow is the clock used to decide which time windows are active.
+var volumeLimitNow = time.Now
+
+// limitsEnforceTick is how often `limits enforce` re-reads every limited
+// room, which also catches a quiet-hours window starting.
+var limitsEnforceTick = 30 * time.Second
+
+// volumeLimit returns the configured per-room limits as a sonos.VolumeLimit,
+// or nil when there are none.
+func (f *rootFlags) volumeLimit() sonos.VolumeLimit {
+	if f == nil || len(f.VolumeLimits) == 0 {
+		return nil
+	}
+	cfg := appconfig.Config{VolumeLimits: f.VolumeLimits}
+	return func(room string) (int, bool) {
+		return cfg.MaxVolume(room, volumeLimitNow())
+	}
+}
+
+// withVolumeLimit makes c enforce the configured limits on every volume it
+// writes.
+func withVolumeLimit(c *sonos.Client, flags *rootFlags) *sonos.Client {
+	if c != nil {
+		c.VolumeLimit = flags.volumeLimit()
+	}
+	return c
+}
+
+// capSceneVolume applies the limits to a volume about to be written through
+// a client that does not enforce them itself.
+func capSceneVolume(flags *rootFlags, room string, volume int) int {
+	if limit := flags.volumeLimit(); limit != nil {
+		if ceiling, ok := limit(room); ok && volume > ceiling {
+			return ceiling
+		}
+	}
+	return volume
+}
+
+func newLimitsCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "limits",
+		Short: "Per-room volume limits and quiet hours",
+		Long: `Manages per-room maximum volumes stored in the config file, optionally only during a
+daily time window (e.g. 22:00-07:00). Every command that sets volume (volume, group volume,
+scene apply, announce) caps to the limit in effect; "limits enforce" also pulls rooms back
+down when something else (the Sonos app, buttons, alarms) turns them up.`,
+	}
+	cmd.AddCommand(newLimitsListCmd(flags))
+	cmd.AddCommand(newLimitsSetCmd(flags))
+	cmd.AddCommand(newLimitsRemoveCmd(flags))
+	cmd.AddCommand(newLimitsEnforceCmd(flags))
+	return cmd
+}
+
+func formatLimitWindow(w string) string {
+	if w == "" {
+		return "always"
+	}
+	return w
+}
+
+func newLimitsListCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List volume limits",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := newConfigStore()
+			if err != nil {
+				return err
+			}
+			cfg, err := s.Load()
+			if err != nil {
+				return err
+			}
+			limits := cfg.VolumeLimits
+			if isJSON(flags) {
+				if limits == nil {
+					limits = []appconfig.VolumeLimit{}
+				}
+				return writeJSON(cmd, limits)
+			}
+			if isTSV(flags) {
+				for _, l := range limits {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%s\n", l.Room, l.Max, formatLimitWindow(l.Window))
+				}
+				return nil
+			}
+			if len(limits) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No volume limits configured.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ROOM\tMAX\tWINDOW")
+			for _, l := range limits {
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", l.Room, l.Max, formatLimitWindow(l.Window))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newLimitsSetCmd(flags *rootFlags) *cobra.Command {
+	var window string
+
+	cmd := &cobra.Command{
+		Use:          "set <room> <max>",
+		Short:        "Set a room's maximum volume (always, or within --window)",
+		Example:      "  sonos limits set \"Kids Room\" 40\n  sonos limits set \"Kids Room\" 20 --window 22:00-07:00",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxVolume, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid max volume %q", args[1])
+			}
+			limit := appconfig.VolumeLimit{Room: strings.TrimSpace(args[0]), Max: maxVolume, Window: strings.TrimSpace(window)}
+			if err := limit.Validate(); err != nil {
+				return err
+			}
+			s, err := newConfigStore()
+			if err != nil {
+				return err
+			}
+			cfg, err := s.Load()
+			if err != nil {
+				return err
+			}
+			// One rule per room and window: setting it again replaces it.
+			replaced := false
+			for i, l := range cfg.VolumeLimits {
+				if strings.EqualFold(l.Room, limit.Room) && l.Window == limit.Window {
+					cfg.VolumeLimits[i] = limit
+					replaced = true
+				}
+			}
+			if !replaced {
+				cfg.VolumeLimits = append(cfg.VolumeLimits, limit)
+			}
+			if err := s.Save(cfg); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "limits.set", map[string]any{"room": limit.Room, "max": limit.Max, "window": limit.Window})
+		},
+	}
+	cmd.Flags().StringVar(&window, "window", "", "Only apply during this daily window, HH:MM-HH:MM (may wrap midnight)")
+	return cmd
+}
+
+func newLimitsRemoveCmd(flags *rootFlags) *cobra.Command {
+	var window string
+
+	cmd := &cobra.Command{
+		Use:          "remove <room>",
+		Short:        "Remove a room's limits (all of them, or only the one for --window)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			room := strings.TrimSpace(args[0])
+			byWindow := cmd.Flags().Changed("window")
+			s, err := newConfigStore()
+			if err != nil {
+				return err
+			}
+			cfg, err := s.Load()
+			if err != nil {
+				return err
+			}
+			kept := cfg.VolumeLimits[:0]
+			removed := 0
+			for _, l := range cfg.VolumeLimits {
+				if strings.EqualFold(l.Room, room) && (!byWindow || l.Window == strings.TrimSpace(window)) {
+					removed++
+					continue
+				}
+				kept = append(kept, l)
+			}
+			if removed == 0 {
+				return errors.New("no matching volume limit for " + room)
+			}
+			cfg.VolumeLimits = kept
+			if err := s.Save(cfg); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "limits.remove", map[string]any{"room": room, "removed": removed})
+		},
+	}
+	cmd.Flags().StringVar(&window, "window", "", "Only remove the limit for this window")
+	return cmd
+}
+
+type limitsEnforcement struct {
+	Time   time.Time `json:"time"`
+	Room   string    `json:"room"`
+	From   int       `json:"from"`
+	To     int       `json:"to"`
+	Reason string    `json:"reason"` // "event" or "poll"
+}
+
+func newLimitsEnforceCmd(flags *rootFlags) *cobra.Command {
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "enforce",
+		Short: "Stay running and pull limited rooms back down",
+		Long: `Subscribes to RenderingControl events on every room that has a volume limit and
+lowers the volume as soon as an event reports it above the limit in effect. Rooms are
+also re-checked periodically so a quiet-hours window starting is applied promptly.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit := flags.volumeLimit()
+			if limit == nil {
+				return errors.New("no volume limits configured (see `sonos limits set`)")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			if duration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, duration)
+				defer cancel()
+			}
+
+			rooms, err := limitedRooms(ctx, flags)
+			if err != nil {
+				return err
+			}
+
+			em, err := sonos.NewEventManager(rooms[0].IP, sonos.EventManagerOptions{})
+			if err != nil {
+				return err
+			}
+			defer closeEventManager(ctx, em, flags.Timeout)
+
+			clients := map[string]*sonos.Client{}
+			ipToRoom := map[string]string{}
+			for _, m := range rooms {
+				c := newSonosClient(m.IP, flags.Timeout)
+				clients[m.Name] = c
+				if _, err := em.Subscribe(ctx, c, sonos.ServiceRenderingControl); err != nil {
+					return fmt.Errorf("subscribe %s: %w", m.Name, err)
+				}
+				ipToRoom[c.IP] = m.Name
+			}
+
+			report := func(e limitsEnforcement) {
+				if isJSON(flags) {
+					_ = writeJSONLine(cmd, e)
+					return
+				}
+				if isTSV(flags) {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%d\t%d\t%s\n", e.Time.Format(time.RFC3339), e.Room, e.From, e.To, e.Reason)
+					return
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s: volume %d above limit, lowered to %d\n", e.Time.Format(time.RFC3339), e.Room, e.From, e.To)
+			}
+			enforce := func(room string, observed int, reason string) {
+				ceiling, ok := limit(room)
+				if !ok || observed <= ceiling {
+					return
+				}
+				if err := clients[room].SetVolume(ctx, ceiling); err != nil {
+					if ctx.Err() == nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", room, err)
+					}
+					return
+				}
+				report(limitsEnforcement{Time: time.Now().UTC(), Room: room, From: observed, To: ceiling, Reason: reason})
+			}
+			poll := func() {
+				for _, m := range rooms {
+					if v, err := clients[m.Name].GetVolume(ctx); err == nil {
+						enforce(m.Name, v, "poll")
+					}
+				}
+			}
+
+			names := make([]string, 0, len(rooms))
+			for _, m := range rooms {
+				names = append(names, m.Name)
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Enforcing volume limits on %s (Ctrl+C to stop).", strings.Join(names, ", ")))
+			poll()
+
+			ticker := time.NewTicker(limitsEnforceTick)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					poll()
+				case ev, ok := <-em.Events():
+					if !ok {
+						return nil
+					}
+					room, ok := ipToRoom[ev.IP]
+					if !ok {
+						continue
+					}
+					if v, err := strconv.Atoi(ev.Vars["volume_master"]); err == nil {
+						enforce(room, v, "event")
+					}
+				}
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&duration, "duration", 0, "Stop after this duration (0 = until Ctrl+C)")
+	return cmd
+}
+
+// limitedRooms returns the visible rooms that have at least one limit
+// configured, sorted by name.
+func limitedRooms(ctx context.Context, flags *rootFlags) ([]sonos.Member, error) {
+	ip := strings.TrimSpace(flags.IP)
+	if ip == "" {
+		devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
+		if err != nil {
+			return nil, err
+		}
+		if len(devs) == 0 {
+			return nil, errors.New("no speakers found")
+		}
+		ip = devs[0].IP
+	}
+	top, err := newSonosClient(ip, flags.Timeout).GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []sonos.Member
+	for _, m := range top.ByName {
+		if m.IP == "" || !m.IsVisible {
+			continue
+		}
+		for _, l := range flags.VolumeLimits {
+			if strings.EqualFold(l.Room, m.Name) {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("none of the rooms with volume limits were found on the network")
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
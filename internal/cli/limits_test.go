@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/appconfig"
+)
+
+func withLimitsConfigStore(t *testing.T) *appconfig.FileStore {
+	t.Helper()
+	store, err := appconfig.NewFileStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	orig := newConfigStore
+	t.Cleanup(func() { newConfigStore = orig })
+	newConfigStore = func() (appconfig.Store, error) { return store, nil }
+	return store
+}
+
+func runLimitsCmd(t *testing.T, flags *rootFlags, args ...string) (string, error) {
+	t.Helper()
+	cmd := newLimitsCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestLimitsSetListRemove(t *testing.T) {
+	store := withLimitsConfigStore(t)
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatPlain}
+
+	if _, err := runLimitsCmd(t, flags, "set", "Kids", "40"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, err := runLimitsCmd(t, flags, "set", "Kids", "25", "--window", "22:00-07:00"); err != nil {
+		t.Fatalf("set window: %v", err)
+	}
+	if _, err := runLimitsCmd(t, flags, "set", "kids", "20", "--window", "22:00-07:00"); err != nil {
+		t.Fatalf("replace window: %v", err)
+	}
+	if _, err := runLimitsCmd(t, flags, "set", "Kids", "20", "--window", "late"); err == nil {
+		t.Fatalf("expected window error")
+	}
+
+	out, err := runLimitsCmd(t, flags, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out, "ROOM") || !strings.Contains(out, "always") || !strings.Contains(out, "22:00-07:00") {
+		t.Fatalf("unexpected list: %q", out)
+	}
+	cfg, _ := store.Load()
+	if len(cfg.VolumeLimits) != 2 || cfg.VolumeLimits[1].Max != 20 {
+		t.Fatalf("unexpected limits: %+v", cfg.VolumeLimits)
+	}
+
+	if _, err := runLimitsCmd(t, flags, "remove", "Kids", "--window", "22:00-07:00"); err != nil {
+		t.Fatalf("remove window: %v", err)
+	}
+	cfg, _ = store.Load()
+	if len(cfg.VolumeLimits) != 1 || cfg.VolumeLimits[0].Window != "" {
+		t.Fatalf("unexpected limits after remove: %+v", cfg.VolumeLimits)
+	}
+	if _, err := runLimitsCmd(t, flags, "remove", "Office"); err == nil {
+		t.Fatalf("expected no-match error")
+	}
+}
+
+func withVolumeLimitsConfig(t *testing.T, limits ...appconfig.VolumeLimit) {
+	t.Helper()
+	loadAppConfig = func() (appconfig.Config, error) {
+		return appconfig.Config{VolumeLimits: limits}.Normalize(), nil
+	}
+}
+
+func TestE2EVolumeLimitsCapCommands(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Kids")
+	withVolumeLimitsConfig(t,
+		appconfig.VolumeLimit{Room: "Kids", Max: 40},
+		appconfig.VolumeLimit{Room: "Kids", Max: 20, Window: "22:00-07:00"},
+	)
+	origNow := volumeLimitNow
+	t.Cleanup(func() { volumeLimitNow = origNow })
+	clock := time.Date(2026, 1, 5, 18, 0, 0, 0, time.Local)
+	volumeLimitNow = func() time.Time { return clock }
+	kids, kitchen := h.Speaker("Kids"), h.Speaker("Kitchen")
+
+	out, err := runFake(t, "volume", "set", "--name", "Kids", "70", "--format", "json")
+	if err != nil {
+		t.Fatalf("volume set: %v", err)
+	}
+	if v := kids.State().Volume; v != 40 || !strings.Contains(out, `"volume": 40`) {
+		t.Fatalf("daytime cap not applied: %d, %s", v, out)
+	}
+
+	withFastVolumeFade(t)
+	kids.SetVolume(30)
+	out, err = runFake(t, "volume", "fade", "--name", "Kids", "--to", "90", "--over", "40ms", "--format", "json")
+	if err != nil {
+		t.Fatalf("volume fade: %v", err)
+	}
+	if v := kids.State().Volume; v != 40 || !strings.Contains(out, `"to": 40`) || !strings.Contains(out, `"cancelled": false`) {
+		t.Fatalf("fade not capped: %d, %s", v, out)
+	}
+
+	clock = time.Date(2026, 1, 5, 23, 0, 0, 0, time.Local)
+	if _, err := runFake(t, "volume", "up", "--name", "Kids", "10"); err != nil {
+		t.Fatalf("volume up: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("quiet-hours cap not applied: %d", v)
+	}
+
+	if _, err := runFake(t, "group", "join", "--name", "Kids", "--to", "Kitchen"); err != nil {
+		t.Fatalf("group join: %v", err)
+	}
+	if _, err := runFake(t, "group", "volume", "set", "--name", "Kitchen", "80"); err != nil {
+		t.Fatalf("group volume set: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("group volume pushed Kids above limit: %d", v)
+	}
+	if v := kitchen.State().Volume; v <= 20 {
+		t.Fatalf("unlimited room should follow the group volume: %d", v)
+	}
+
+	// A group fade stops where Kids would have to be held back.
+	kids.SetVolume(10)
+	kitchen.SetVolume(30)
+	out, err = runFake(t, "group", "volume", "fade", "--name", "Kitchen", "--to", "90", "--over", "40ms", "--format", "json")
+	if err != nil {
+		t.Fatalf("group volume fade: %v", err)
+	}
+	if !strings.Contains(out, `"to": 30`) || !strings.Contains(out, `"cancelled": false`) || kids.State().Volume != 20 || kitchen.State().Volume != 40 {
+		t.Fatalf("group fade not capped: kids=%d kitchen=%d %s", kids.State().Volume, kitchen.State().Volume, out)
+	}
+}
+
+func TestCapSceneVolume(t *testing.T) {
+	origNow := volumeLimitNow
+	t.Cleanup(func() { volumeLimitNow = origNow })
+	volumeLimitNow = func() time.Time { return time.Date(2026, 1, 5, 23, 0, 0, 0, time.Local) }
+
+	flags := &rootFlags{VolumeLimits: []appconfig.VolumeLimit{{Room: "B", Max: 10, Window: "22:00-07:00"}}}
+	if got := capSceneVolume(flags, "B", 15); got != 10 {
+		t.Fatalf("capSceneVolume(B) = %d", got)
+	}
+	if got := capSceneVolume(flags, "A", 15); got != 15 {
+		t.Fatalf("capSceneVolume(A) = %d", got)
+	}
+	if got := capSceneVolume(&rootFlags{}, "B", 15); got != 15 {
+		t.Fatalf("capSceneVolume without limits = %d", got)
+	}
+}
+
+func TestE2ELimitsEnforcePullsVolumeDown(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Kids")
+	withVolumeLimitsConfig(t, appconfig.VolumeLimit{Room: "Kids", Max: 25})
+	kids := h.Speaker("Kids")
+	kids.SetVolume(60)
+
+	var wg sync.WaitGroup
+	var out string
+	var runErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		out, runErr = runFake(t, "limits", "enforce", "--duration", "1500ms", "--format", "json")
+	}()
+
+	waitFor := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for kids.State().Volume != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Kids volume = %d, want %d", kids.State().Volume, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor(25) // initial poll
+	time.Sleep(100 * time.Millisecond)
+	kids.SetVolume(50) // someone turns it up on the speaker
+	waitFor(25)        // pulled back by the RenderingControl event
+
+	wg.Wait()
+	if runErr != nil {
+		t.Fatalf("limits enforce: %v", runErr)
+	}
+	if strings.Count(out, `"room":"Kids"`) < 2 || !strings.Contains(out, `"reason":"event"`) {
+		t.Fatalf("unexpected enforce output: %s", out)
+	}
+	if v := h.Speaker("Kitchen").State().Volume; v != 20 {
+		t.Fatalf("unlimited room touched: %d", v)
+	}
+}
+
+func TestSleepFadeRestoreRespectsVolumeLimit(t *testing.T) {
+	h := newFakeHousehold(t, "Bedroom")
+	bedroom := h.Speaker("Bedroom")
+	bedroom.SetVolume(40)
+	origTick := sleepFadeTick
+	t.Cleanup(func() { sleepFadeTick = origTick })
+	sleepFadeTick = 10 * time.Millisecond
+
+	// Quiet hours start while the fade is running.
+	flags := &rootFlags{VolumeLimits: []appconfig.VolumeLimit{{Room: "Bedroom", Max: 15}}}
+	c := withVolumeLimit(newSonosClient(bedroom.IP, 2*time.Second), flags)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := runSleepFade(ctx, c, time.Hour, time.Hour); err != nil {
+		t.Fatalf("runSleepFade: %v", err)
+	}
+	if v := bedroom.State().Volume; v != 15 {
+		t.Fatalf("restore bypassed the limit: %d", v)
+	}
+}
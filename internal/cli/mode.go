@@ -11,9 +11,9 @@ import (
 
 func newModeCmd(flags *rootFlags) *cobra.Command {
 	return &cobra.Command{
-		Use:   "mode <get|shuffle|shuffle-norepeat|repeat|repeat-one|normal>",
-		Short: "Get or set play mode (shuffle/repeat)",
-		Long: `Controls playback mode (shuffle/repeat) on the group coordinator.
+		Use:   "mode <get|shuffle|shuffle-norepeat|repeat|repeat-one|normal|crossfade [on|off]>",
+		Short: "Get or set play mode (shuffle/repeat/crossfade)",
+		Long: `Controls playback mode (shuffle/repeat/crossfade) on the group coordinator.
 
 Modes:
   get              Show current play mode
@@ -21,9 +21,14 @@ Modes:
   shuffle-norepeat Enable shuffle without repeat (SHUFFLE_NOREPEAT)
   repeat           Enable repeat all without shuffle (REPEAT_ALL)
   repeat-one       Enable repeat single track (REPEAT_ONE)
-  normal           Disable shuffle and repeat (NORMAL)`,
-		Args: cobra.ExactArgs(1),
+  normal           Disable shuffle and repeat (NORMAL)
+  crossfade        Show whether crossfade is enabled
+  crossfade on|off Enable or disable crossfade between tracks`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 2 && !strings.EqualFold(args[0], "crossfade") {
+				return errors.New("only crossfade takes a value (on|off)")
+			}
 			ctx := cmd.Context()
 			c, err := coordinatorClient(ctx, flags)
 			if err != nil {
@@ -79,8 +84,41 @@ Modes:
 				}
 				return writeOK(cmd, flags, "mode.normal", map[string]any{"coordinatorIP": c.IP})
 
+			case "crossfade":
+				if len(args) == 1 {
+					on, err := c.GetCrossfadeMode(ctx)
+					if err != nil {
+						return err
+					}
+					if isJSON(flags) {
+						return writeJSON(cmd, map[string]any{
+							"crossfade":     on,
+							"coordinatorIP": c.IP,
+						})
+					}
+					if isTSV(flags) {
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "crossfade\t%v\n", on)
+						return nil
+					}
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), on)
+					return nil
+				}
+				var on bool
+				switch strings.ToLower(args[1]) {
+				case "on":
+					on = true
+				case "off":
+					on = false
+				default:
+					return errors.New("expected crossfade on|off")
+				}
+				if err := c.SetCrossfadeMode(ctx, on); err != nil {
+					return err
+				}
+				return writeOK(cmd, flags, "mode.crossfade", map[string]any{"coordinatorIP": c.IP, "crossfade": on})
+
 			default:
-				return errors.New("expected get|shuffle|shuffle-norepeat|repeat|repeat-one|normal")
+				return errors.New("expected get|shuffle|shuffle-norepeat|repeat|repeat-one|normal|crossfade")
 			}
 		},
 	}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestE2EModeCrossfade(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+
+	if out, err := runFake(t, "mode", "crossfade", "--name", "Kitchen"); err != nil || strings.TrimSpace(out) != "false" {
+		t.Fatalf("mode crossfade: %q, %v", out, err)
+	}
+	if _, err := runFake(t, "mode", "crossfade", "on", "--name", "Kitchen"); err != nil {
+		t.Fatalf("mode crossfade on: %v", err)
+	}
+	if !kitchen.State().Crossfade {
+		t.Fatalf("crossfade not enabled")
+	}
+	if out, err := runFake(t, "mode", "crossfade", "--name", "Kitchen", "--format", "json"); err != nil || !strings.Contains(out, `"crossfade": true`) {
+		t.Fatalf("mode crossfade json: %q, %v", out, err)
+	}
+	if _, err := runFake(t, "mode", "crossfade", "off", "--name", "Kitchen"); err != nil || kitchen.State().Crossfade {
+		t.Fatalf("mode crossfade off: %v", err)
+	}
+
+	if _, err := runFake(t, "mode", "crossfade", "maybe", "--name", "Kitchen"); err == nil {
+		t.Fatalf("expected value error")
+	}
+	if _, err := runFake(t, "mode", "shuffle", "on", "--name", "Kitchen"); err == nil {
+		t.Fatalf("expected extra-argument error")
+	}
+	if _, err := runFake(t, "mode", "shuffle", "--name", "Kitchen"); err != nil || kitchen.State().PlayMode != "SHUFFLE" {
+		t.Fatalf("mode shuffle: %v", err)
+	}
+}
@@ -103,3 +103,16 @@ func nameCompletionCachePath() (string, error) {
 	}
 	return filepath.Join(dir, "sonoscli", "name-completions.json"), nil
 }
+
+// invalidateNameCompletions drops the cached room names, e.g. after a rename,
+// so the next completion rediscovers them.
+func invalidateNameCompletions() error {
+	path, err := nameCompletionCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -31,3 +31,21 @@ func TestStoreNameCompletions_WritesPrivateFile(t *testing.T) {
 		}
 	}
 }
+
+func TestInvalidateNameCompletions(t *testing.T) {
+	t.Setenv("SONOSCLI_COMPLETION_CACHE_DIR", t.TempDir())
+
+	if err := invalidateNameCompletions(); err != nil {
+		t.Fatalf("invalidate without cache: %v", err)
+	}
+	now := time.Now()
+	if err := storeNameCompletions(now, []string{"Kitchen"}); err != nil {
+		t.Fatalf("store cache: %v", err)
+	}
+	if err := invalidateNameCompletions(); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	if _, ok := cachedNameCompletions(now); ok {
+		t.Fatalf("cache still present after invalidation")
+	}
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// negativeArg matches positionals such as -5, -10s or -1:00 that pflag would
+// otherwise read as shorthand flags.
+var negativeArg = regexp.MustCompile(`^-[0-9][0-9:.hms]*$`)
+
+// allowNegativeArgs lets cmd take negative numbers and offsets as positional
+// arguments (`eq set bass -5`, `seek -10s`). Cobra's flag parsing is turned
+// off for cmd and done in its Args hook instead, which runs before any
+// PreRun: negative values are held back while the flags are parsed, then put
+// back in place for the Args check and RunE.
+func allowNegativeArgs(cmd *cobra.Command) *cobra.Command {
+	validate, run := cmd.Args, cmd.RunE
+	var positional []string
+	var help bool
+	cmd.DisableFlagParsing = true
+	cmd.Args = func(c *cobra.Command, args []string) error {
+		var err error
+		if positional, err = parseNegativeArgs(c, args); err != nil {
+			return err
+		}
+		if help, _ = c.Flags().GetBool("help"); help || validate == nil {
+			return nil
+		}
+		return validate(c, positional)
+	}
+	cmd.RunE = func(c *cobra.Command, _ []string) error {
+		if help {
+			return c.Help()
+		}
+		return run(c, positional)
+	}
+	return cmd
+}
+
+// parseNegativeArgs parses c's flags from args and returns the positionals,
+// negative values included.
+func parseNegativeArgs(c *cobra.Command, args []string) ([]string, error) {
+	held := map[string]string{}
+	rest := make([]string, 0, len(args))
+	for i, a := range args {
+		if a == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if negativeArg.MatchString(a) && !(len(rest) > 0 && flagTakesValue(c, rest[len(rest)-1])) {
+			placeholder := fmt.Sprintf("\x00arg%d", len(held))
+			held[placeholder] = a
+			a = placeholder
+		}
+		rest = append(rest, a)
+	}
+
+	c.DisableFlagParsing = false
+	err := c.ParseFlags(rest)
+	c.DisableFlagParsing = true
+	if err != nil {
+		return nil, c.FlagErrorFunc()(c, err)
+	}
+	out := append([]string(nil), c.Flags().Args()...)
+	for i, a := range out {
+		if v, ok := held[a]; ok {
+			out[i] = v
+		}
+	}
+	return out, nil
+}
+
+// flagTakesValue reports whether arg is a flag that reads the next argument
+// as its value (--timeout 2s), so a negative value after it stays with it.
+func flagTakesValue(c *cobra.Command, arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	var name string
+	switch {
+	case strings.HasPrefix(arg, "--") && len(arg) > 2:
+		name = arg[2:]
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		if f := c.Flags().ShorthandLookup(arg[1:]); f != nil {
+			return f.NoOptDefVal == ""
+		}
+		return false
+	default:
+		return false
+	}
+	f := c.Flags().Lookup(name)
+	return f != nil && f.NoOptDefVal == ""
+}
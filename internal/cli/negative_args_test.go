@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAllowNegativeArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		want    []string
+		timeout time.Duration
+		name    string
+	}{
+		{args: []string{"bass", "-5"}, want: []string{"bass", "-5"}},
+		{args: []string{"-10s", "--name", "Kitchen"}, want: []string{"-10s"}, name: "Kitchen"},
+		{args: []string{"--name", "Kitchen", "balance", "-20", "--timeout", "2s"}, want: []string{"balance", "-20"}, name: "Kitchen", timeout: 2 * time.Second},
+		{args: []string{"-1:00", "--timeout=3s"}, want: []string{"-1:00"}, timeout: 3 * time.Second},
+		{args: []string{"--", "-h"}, want: []string{"-h"}},
+	} {
+		var got []string
+		var name string
+		var timeout time.Duration
+		root := &cobra.Command{Use: "root"}
+		root.PersistentFlags().StringVar(&name, "name", "", "")
+		root.PersistentFlags().DurationVar(&timeout, "timeout", 0, "")
+		root.AddCommand(allowNegativeArgs(&cobra.Command{
+			Use:  "set",
+			Args: cobra.MaximumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				got = args
+				return nil
+			},
+		}))
+		root.SetArgs(append([]string{"set"}, tc.args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%q: %v", tc.args, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) || name != tc.name || timeout != tc.timeout {
+			t.Fatalf("%q: args %q name %q timeout %s", tc.args, got, name, timeout)
+		}
+	}
+}
+
+func TestAllowNegativeArgsErrorsAndHelp(t *testing.T) {
+	newRoot := func() (*cobra.Command, *strings.Builder, *bool) {
+		ran := false
+		var out strings.Builder
+		root := &cobra.Command{Use: "root", SilenceErrors: true, SilenceUsage: true}
+		root.SetOut(&out)
+		root.AddCommand(allowNegativeArgs(&cobra.Command{
+			Use:   "set <value>",
+			Short: "Set a value",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ran = true
+				return nil
+			},
+		}))
+		return root, &out, &ran
+	}
+
+	root, _, ran := newRoot()
+	root.SetArgs([]string{"set", "-5", "-7"})
+	if err := root.Execute(); err == nil || *ran {
+		t.Fatalf("expected arg count error, got %v (ran %v)", err, *ran)
+	}
+	root, _, ran = newRoot()
+	root.SetArgs([]string{"set", "--bogus", "-5"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "unknown flag: --bogus") || *ran {
+		t.Fatalf("expected unknown flag error, got %v", err)
+	}
+	root, out, ran := newRoot()
+	root.SetArgs([]string{"set", "--help"})
+	if err := root.Execute(); err != nil || *ran || !strings.Contains(out.String(), "Set a value") {
+		t.Fatalf("help: %v, ran %v, out %q", err, *ran, out.String())
+	}
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/mediaserver"
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// playFilePollInterval is how often play-file/enqueue-file check whether the
+// queue has finished.
+var playFilePollInterval = 2 * time.Second
+
+type fileQueueClient interface {
+	AddURIToQueue(ctx context.Context, enqueuedURI, enqueuedMeta string, desiredFirstTrackNumber int, enqueueAsNext bool) (int, error)
+	PlayQueuePosition(ctx context.Context, position int) error
+	GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error)
+	GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error)
+}
+
+func newPlayFileCmd(flags *rootFlags) *cobra.Command {
+	return newFileQueueCmd(flags, true)
+}
+
+func newEnqueueFileCmd(flags *rootFlags) *cobra.Command {
+	return newFileQueueCmd(flags, false)
+}
+
+func newFileQueueCmd(flags *rootFlags, play bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "play-file <path...>",
+		Short: "Play local audio files, directories or M3U playlists",
+		Long: `Serves local audio files (MP3, FLAC, AAC/M4A, WAV, OGG) from an HTTP server on this
+machine, appends them to the room's queue and starts playing the first one.
+
+Directories are added recursively in name order and .m3u/.m3u8 playlists are expanded
+(relative entries resolve against the playlist; URLs are queued as-is). Title, artist,
+album and embedded cover art are read from the files' tags.
+
+The command keeps serving until the queue finishes or you press Ctrl+C, so the speakers
+must be able to reach this machine.`,
+		Example:      "  sonos play-file --name Office ./album/\n  sonos play-file --name Office song.flac other.mp3\n  sonos play-file --name Office party.m3u",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			entries, err := mediaserver.Expand(args)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return errors.New("no audio files found")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			listenIP, err := sonos.LocalIPFor(c.IP)
+			if err != nil {
+				return err
+			}
+			srv, err := mediaserver.Start(listenIP)
+			if err != nil {
+				return err
+			}
+			defer srv.Close()
+
+			first, err := enqueueFiles(ctx, c, srv, entries)
+			if err != nil {
+				return err
+			}
+			if play {
+				if err := c.PlayQueuePosition(ctx, first); err != nil {
+					return err
+				}
+			}
+
+			writePlainLine(cmd, flags, fmt.Sprintf("Serving %d track(s) from %s (queue position %d); press Ctrl+C to stop", len(entries), srv.BaseURL(), first))
+			if err := waitForQueueEnd(ctx, c); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+
+			action := "play-file"
+			if !play {
+				action = "enqueue-file"
+			}
+			return writeOK(cmd, flags, action, map[string]any{
+				"tracks":        len(entries),
+				"firstPosition": first,
+			})
+		},
+	}
+	if !play {
+		cmd.Use = "enqueue-file <path...>"
+		cmd.Short = "Add local audio files to the queue and serve them"
+		cmd.Long = `Like play-file, but only appends the files to the queue without starting playback.
+The files are served until the queue finishes or you press Ctrl+C.`
+		cmd.Example = "  sonos enqueue-file --name Office ./album/"
+	}
+	return cmd
+}
+
+// enqueueFiles appends entries to the queue, publishing local files on srv.
+// It returns the queue position of the first added track.
+func enqueueFiles(ctx context.Context, c fileQueueClient, srv *mediaserver.Server, entries []string) (int, error) {
+	first := 0
+	for _, e := range entries {
+		uri, meta := e, ""
+		if !mediaserver.IsRemote(e) {
+			t, err := srv.Add(e)
+			if err != nil {
+				return 0, err
+			}
+			uri, meta = t.URL, t.Meta()
+		}
+		pos, err := c.AddURIToQueue(ctx, uri, meta, 0, false)
+		if err != nil {
+			return 0, fmt.Errorf("enqueue %s: %w", e, err)
+		}
+		if first == 0 {
+			first = pos
+		}
+	}
+	if first == 0 {
+		first = 1
+	}
+	return first, nil
+}
+
+// waitForQueueEnd returns once the queue has been playing and then stops or
+// the room switches to another source. Until the queue has been seen playing
+// (e.g. after enqueue-file while the radio is on) it keeps waiting.
+func waitForQueueEnd(ctx context.Context, c fileQueueClient) error {
+	ticker := time.NewTicker(playFilePollInterval)
+	defer ticker.Stop()
+
+	started := false
+	for {
+		info, terr := c.GetTransportInfo(ctx)
+		media, merr := c.GetMediaInfo(ctx)
+		if terr == nil && merr == nil {
+			onQueue := strings.HasPrefix(media.CurrentURI, "x-rincon-queue:")
+			switch {
+			case onQueue && (info.State == "PLAYING" || info.State == "TRANSITIONING"):
+				started = true
+			case started && (!onQueue || info.State == "STOPPED"):
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
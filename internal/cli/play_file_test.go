@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+type fakeFileQueue struct {
+	mu     sync.Mutex
+	states []string // consumed one per poll; the last one sticks
+	uri    string
+}
+
+func (f *fakeFileQueue) AddURIToQueue(ctx context.Context, uri, meta string, desired int, asNext bool) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeFileQueue) PlayQueuePosition(ctx context.Context, position int) error { return nil }
+
+func (f *fakeFileQueue) GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.states[0]
+	if len(f.states) > 1 {
+		f.states = f.states[1:]
+	}
+	return sonos.TransportInfo{State: s}, nil
+}
+
+func (f *fakeFileQueue) GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return sonos.MediaInfo{CurrentURI: f.uri}, nil
+}
+
+func TestWaitForQueueEnd(t *testing.T) {
+	orig := playFilePollInterval
+	t.Cleanup(func() { playFilePollInterval = orig })
+	playFilePollInterval = time.Millisecond
+
+	// Stopped before playback started (enqueue-file while idle) keeps waiting.
+	c := &fakeFileQueue{states: []string{"STOPPED", "STOPPED", "PLAYING", "PAUSED_PLAYBACK", "STOPPED"}, uri: "x-rincon-queue:RINCON_1#0"}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := waitForQueueEnd(ctx, c); err != nil {
+		t.Fatalf("waitForQueueEnd: %v", err)
+	}
+
+	// Never reaching the queue waits for Ctrl+C.
+	c = &fakeFileQueue{states: []string{"PLAYING"}, uri: "x-rincon-mp3radio://example.com/live"}
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := waitForQueueEnd(ctx, c); err == nil {
+		t.Fatalf("expected to keep waiting while another source plays")
+	}
+}
+
+func TestPlayFileValidation(t *testing.T) {
+	dir := t.TempDir()
+	txt := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(txt, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := [][]string{
+		{"--name", "Office"},
+		{"--name", "Office", txt},
+		{"--name", "Office", filepath.Join(dir, "missing.mp3")},
+		{"--name", "Office", dir}, // empty directory
+		{filepath.Join(dir, "a.mp3")},
+	}
+	for _, args := range cases {
+		flags := &rootFlags{Timeout: time.Second}
+		cmd := newPlayFileCmd(flags)
+		cmd.Flags().StringVar(&flags.Name, "name", "", "")
+		cmd.SetOut(&captureWriter{})
+		cmd.SetErr(&captureWriter{})
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestE2EPlayFileServesQueue(t *testing.T) {
+	h := newFakeHousehold(t, "Office")
+	orig := playFilePollInterval
+	t.Cleanup(func() { playFilePollInterval = orig })
+	playFilePollInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x10" + "TIT2\x00\x00\x00\x06\x00\x00\x00Intro")
+	if err := os.WriteFile(filepath.Join(dir, "01.mp3"), append(tag, []byte("audio-1")...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "02 Outro.flac"), []byte("audio-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	office := h.Speaker("Office")
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := runFake(t, "play-file", "--name", "Office", "--format", "json", dir)
+		done <- result{out, err}
+	}()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(office.State().Queue) < 2 || office.State().TransportState != "PLAYING" {
+		if time.Now().After(deadline) {
+			t.Fatalf("play-file did not start: %+v", office.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	st := office.State()
+	if st.Queue[0].Title != "Intro" || st.Queue[1].Title != "02 Outro" || st.Track != 1 {
+		t.Fatalf("unexpected queue: %+v", st)
+	}
+	resp, err := http.Get(st.Queue[1].URI)
+	if err != nil {
+		t.Fatalf("fetch track: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "audio-2" || resp.Header.Get("Content-Type") != "audio/flac" {
+		t.Fatalf("unexpected served track: %q %q", body, resp.Header.Get("Content-Type"))
+	}
+
+	office.SetTransportState("STOPPED") // the queue finished
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("play-file did not finish")
+	}
+	if res.err != nil {
+		t.Fatalf("play-file: %v", res.err)
+	}
+	if !strings.Contains(res.out, `"action": "play-file"`) || !strings.Contains(res.out, `"tracks": 2`) {
+		t.Fatalf("unexpected output: %q", res.out)
+	}
+	if _, err := http.Get(st.Queue[0].URI); err == nil {
+		t.Fatalf("expected server to be closed")
+	}
+}
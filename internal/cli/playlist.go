@@ -0,0 +1,415 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type playlistClient interface {
+	ListPlaylists(ctx context.Context, start, count int) (sonos.PlaylistsPage, error)
+	ListPlaylistTracks(ctx context.Context, id string, start, count int) (sonos.QueuePage, error)
+	CreateSavedQueue(ctx context.Context, title string) (string, error)
+	AddToPlaylist(ctx context.Context, id string, uris ...string) error
+	RemoveFromPlaylist(ctx context.Context, id string, positions ...int) error
+	MovePlaylistTrack(ctx context.Context, id string, from, to int) error
+	DestroyObject(ctx context.Context, id string) error
+	AddURIToQueue(ctx context.Context, enqueuedURI, enqueuedMeta string, desiredFirstTrackNumber int, enqueueAsNext bool) (int, error)
+	PlayQueuePosition(ctx context.Context, position int) error
+}
+
+var newPlaylistClient = func(ctx context.Context, flags *rootFlags) (playlistClient, error) {
+	return coordinatorClient(ctx, flags)
+}
+
+func newPlaylistCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "playlist",
+		Short: "Manage Sonos playlists",
+		Long: `Lists, edits and plays Sonos playlists (saved queues, ContentDirectory SQ:).
+
+Playlists are referenced by title (case-insensitive) or by ID (e.g. SQ:3, as shown by
+` + "`sonos playlist list`" + `). Positions are 1-based.`,
+	}
+	cmd.AddCommand(newPlaylistListCmd(flags))
+	cmd.AddCommand(newPlaylistShowCmd(flags))
+	cmd.AddCommand(newPlaylistCreateCmd(flags))
+	cmd.AddCommand(newPlaylistAddCmd(flags))
+	cmd.AddCommand(newPlaylistRemoveCmd(flags))
+	cmd.AddCommand(newPlaylistMoveCmd(flags))
+	cmd.AddCommand(newPlaylistDeleteCmd(flags))
+	cmd.AddCommand(newPlaylistPlayCmd(flags))
+	cmd.AddCommand(newPlaylistEnqueueCmd(flags))
+	return cmd
+}
+
+func newPlaylistListCmd(flags *rootFlags) *cobra.Command {
+	var start int
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List Sonos playlists",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			c, err := newPlaylistClient(cmd.Context(), flags)
+			if err != nil {
+				return err
+			}
+			page, err := c.ListPlaylists(cmd.Context(), start, limit)
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, page)
+			}
+			if isTSV(flags) {
+				for _, it := range page.Items {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\n", it.Position, it.Item.ID, it.Item.Title)
+				}
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "POS\tID\tTITLE\n")
+			for _, it := range page.Items {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", it.Position, it.Item.ID, it.Item.Title)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&start, "start", 0, "Starting index (0-based)")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Max results to return")
+	return cmd
+}
+
+func newPlaylistShowCmd(flags *rootFlags) *cobra.Command {
+	var start int
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:          "show <playlist>",
+		Short:        "List the tracks in a playlist",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			page, err := c.ListPlaylistTracks(ctx, pl.ID, start, limit)
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, map[string]any{"playlist": pl, "tracks": page})
+			}
+			if isTSV(flags) {
+				for _, it := range page.Items {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", it.Position, it.Item.Title, it.Item.Artist, it.Item.URI)
+				}
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "POS\tTITLE\tARTIST\tURI\n")
+			for _, it := range page.Items {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", it.Position, it.Item.Title, it.Item.Artist, it.Item.URI)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&start, "start", 0, "Starting index (0-based)")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Max results to return")
+	return cmd
+}
+
+func newPlaylistCreateCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "create <name>",
+		Short:        "Create an empty playlist",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			title := strings.TrimSpace(args[0])
+			if title == "" {
+				return errors.New("playlist name is required")
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			id, err := c.CreateSavedQueue(ctx, title)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, id)
+			return writeOK(cmd, flags, "playlist.create", map[string]any{"id": id, "title": title})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistAddCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "add <playlist> <uri...>",
+		Short:        "Append URIs to a playlist",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.AddToPlaylist(ctx, pl.ID, args[1:]...); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.add", map[string]any{"id": pl.ID, "added": len(args) - 1})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistRemoveCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "remove <playlist> <pos...>",
+		Short:        "Remove tracks from a playlist (1-based positions)",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			positions, err := parsePositions(args[1:])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.RemoveFromPlaylist(ctx, pl.ID, positions...); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.remove", map[string]any{"id": pl.ID, "positions": positions})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistMoveCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "move <playlist> <from> <to>",
+		Short:        "Move a playlist track to another position (1-based)",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			positions, err := parsePositions(args[1:])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.MovePlaylistTrack(ctx, pl.ID, positions[0], positions[1]); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.move", map[string]any{"id": pl.ID, "from": positions[0], "to": positions[1]})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistDeleteCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "delete <playlist>",
+		Short:        "Delete a playlist",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.DestroyObject(ctx, pl.ID); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.delete", map[string]any{"id": pl.ID, "title": pl.Title})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistPlayCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "play <playlist>",
+		Short:        "Append a playlist to the queue and play it",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			first, err := c.AddURIToQueue(ctx, sonos.PlaylistURI(pl.ID), sonos.PlaylistMeta(pl.ID, pl.Title), 0, false)
+			if err != nil {
+				return err
+			}
+			if err := c.PlayQueuePosition(ctx, first); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.play", map[string]any{"id": pl.ID, "title": pl.Title, "enqueuedPos": first})
+		},
+	}
+	return cmd
+}
+
+func newPlaylistEnqueueCmd(flags *rootFlags) *cobra.Command {
+	var next bool
+
+	cmd := &cobra.Command{
+		Use:          "enqueue <playlist>",
+		Short:        "Append a playlist to the queue",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newPlaylistClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pl, err := resolvePlaylist(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			first, err := c.AddURIToQueue(ctx, sonos.PlaylistURI(pl.ID), sonos.PlaylistMeta(pl.ID, pl.Title), 0, next)
+			if err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "playlist.enqueue", map[string]any{"id": pl.ID, "title": pl.Title, "enqueuedPos": first})
+		},
+	}
+
+	cmd.Flags().BoolVar(&next, "next", false, "Insert after the current track instead of at the end")
+	return cmd
+}
+
+// resolvePlaylist finds a playlist by object ID (SQ:<n>) or by exact title
+// (case-insensitive). Duplicate titles must be disambiguated by ID.
+func resolvePlaylist(ctx context.Context, c playlistClient, ref string) (sonos.DIDLItem, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return sonos.DIDLItem{}, errors.New("playlist is required")
+	}
+	const pageSize = 100
+	var matches []sonos.DIDLItem
+	start := 0
+	for {
+		page, err := c.ListPlaylists(ctx, start, pageSize)
+		if err != nil {
+			return sonos.DIDLItem{}, err
+		}
+		for _, it := range page.Items {
+			if it.Item.ID == ref {
+				return it.Item, nil
+			}
+			if strings.EqualFold(it.Item.Title, ref) {
+				matches = append(matches, it.Item)
+			}
+		}
+		start += page.NumberReturned
+		if page.NumberReturned == 0 || start >= page.TotalMatches {
+			break
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return sonos.DIDLItem{}, errors.New("playlist not found: " + ref)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+		return sonos.DIDLItem{}, fmt.Errorf("several playlists are named %q; use an ID (%s)", ref, strings.Join(ids, ", "))
+	}
+}
+
+// parsePositions parses 1-based positions.
+func parsePositions(args []string) ([]int, error) {
+	out := make([]int, 0, len(args))
+	for _, a := range args {
+		n, err := strconv.Atoi(strings.TrimSpace(a))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid position %q (expected an integer >= 1)", a)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func TestParsePositions(t *testing.T) {
+	got, err := parsePositions([]string{"3", " 1"})
+	if err != nil || len(got) != 2 || got[0] != 3 || got[1] != 1 {
+		t.Fatalf("parsePositions = %v, %v", got, err)
+	}
+	for _, bad := range []string{"0", "-1", "x"} {
+		if _, err := parsePositions([]string{bad}); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestE2EPlaylistCommands(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	h.SetPlaylists(
+		sonostest.Playlist{Title: "Morning", Tracks: []sonostest.Track{
+			{URI: "http://example.com/m1.mp3", Title: "Sunrise", Artist: "Band"},
+			{URI: "http://example.com/m2.mp3", Title: "Coffee"},
+		}},
+		sonostest.Playlist{Title: "Dupe"},
+		sonostest.Playlist{Title: "dupe"},
+	)
+	kitchen := h.Speaker("Kitchen")
+
+	out, err := runFake(t, "playlist", "list", "--name", "Kitchen")
+	if err != nil {
+		t.Fatalf("playlist list: %v", err)
+	}
+	if !strings.Contains(out, "SQ:1") || !strings.Contains(out, "Morning") {
+		t.Fatalf("unexpected list output: %q", out)
+	}
+
+	out, err = runFake(t, "playlist", "show", "--name", "Kitchen", "morning")
+	if err != nil {
+		t.Fatalf("playlist show: %v", err)
+	}
+	if !strings.Contains(out, "Sunrise") || !strings.Contains(out, "Band") || !strings.Contains(out, "Coffee") {
+		t.Fatalf("unexpected show output: %q", out)
+	}
+	if _, err := runFake(t, "playlist", "show", "--name", "Kitchen", "dupe"); err == nil || !strings.Contains(err.Error(), "SQ:2, SQ:3") {
+		t.Fatalf("expected ambiguity error, got %v", err)
+	}
+	if _, err := runFake(t, "playlist", "show", "--name", "Kitchen", "SQ:3"); err != nil {
+		t.Fatalf("playlist show by id: %v", err)
+	}
+	if _, err := runFake(t, "playlist", "show", "--name", "Kitchen", "Nope"); err == nil {
+		t.Fatalf("expected not found error")
+	}
+
+	out, err = runFake(t, "playlist", "create", "--name", "Kitchen", "Evening")
+	if err != nil || strings.TrimSpace(out) != "SQ:4" {
+		t.Fatalf("playlist create = %q, %v", out, err)
+	}
+	if _, err := runFake(t, "playlist", "add", "--name", "Kitchen", "Evening", "http://example.com/e1.mp3", "http://example.com/e2.mp3", "http://example.com/e3.mp3"); err != nil {
+		t.Fatalf("playlist add: %v", err)
+	}
+	if _, err := runFake(t, "playlist", "move", "--name", "Kitchen", "Evening", "3", "1"); err != nil {
+		t.Fatalf("playlist move: %v", err)
+	}
+	if _, err := runFake(t, "playlist", "remove", "--name", "Kitchen", "Evening", "2"); err != nil {
+		t.Fatalf("playlist remove: %v", err)
+	}
+	pls := h.Playlists()
+	if evening := pls[3]; len(evening.Tracks) != 2 || evening.Tracks[0].URI != "http://example.com/e3.mp3" || evening.Tracks[1].URI != "http://example.com/e2.mp3" {
+		t.Fatalf("unexpected playlist after edits: %+v", evening)
+	}
+
+	kitchen.SetQueue(sonostest.Track{URI: "http://example.com/q.mp3", Title: "Already queued"})
+	out, err = runFake(t, "playlist", "play", "--name", "Kitchen", "--format", "json", "Morning")
+	if err != nil {
+		t.Fatalf("playlist play: %v", err)
+	}
+	if !strings.Contains(out, `"enqueuedPos": 2`) {
+		t.Fatalf("unexpected play output: %q", out)
+	}
+	st := kitchen.State()
+	if len(st.Queue) != 3 || st.Queue[1].Title != "Sunrise" || st.Track != 2 || st.TransportState != "PLAYING" {
+		t.Fatalf("unexpected state after play: %+v", st)
+	}
+	if _, err := runFake(t, "playlist", "enqueue", "--name", "Kitchen", "--next", "Evening"); err != nil {
+		t.Fatalf("playlist enqueue: %v", err)
+	}
+	if q := kitchen.State().Queue; len(q) != 5 || q[2].URI != "http://example.com/e3.mp3" {
+		t.Fatalf("unexpected queue after enqueue --next: %+v", q)
+	}
+
+	out, err = runFake(t, "queue", "save", "--name", "Kitchen", "Tonight")
+	if err != nil {
+		t.Fatalf("queue save: %v", err)
+	}
+	saved := h.Playlists()[4]
+	if strings.TrimSpace(out) != "SQ:5" || saved.Title != "Tonight" || len(saved.Tracks) != 5 {
+		t.Fatalf("unexpected saved playlist: %q %+v", out, saved)
+	}
+
+	if _, err := runFake(t, "playlist", "delete", "--name", "Kitchen", "Tonight"); err != nil {
+		t.Fatalf("playlist delete: %v", err)
+	}
+	if len(h.Playlists()) != 4 {
+		t.Fatalf("playlist not deleted: %+v", h.Playlists())
+	}
+}
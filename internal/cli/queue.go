@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -14,8 +15,13 @@ import (
 type queueClient interface {
 	ListQueue(ctx context.Context, start, count int) (sonos.QueuePage, error)
 	ClearQueue(ctx context.Context) error
-	RemoveQueuePosition(ctx context.Context, position int) error
 	PlayQueuePosition(ctx context.Context, position int) error
+	SaveQueue(ctx context.Context, title, id string) (string, error)
+	ListAllQueue(ctx context.Context) (sonos.QueuePage, error)
+	MoveQueueTrack(ctx context.Context, updateID, from, to int) (int, error)
+	RemoveQueueRanges(ctx context.Context, updateID int, ranges []sonos.QueueRange) (int, error)
+	InsertRefs(ctx context.Context, updateID, at int, refs []string) (sonos.QueueInsertResult, error)
+	ReorderQueue(ctx context.Context, updateID int, order []int) (int, error)
 }
 
 var newQueueClient = func(ctx context.Context, flags *rootFlags) (queueClient, error) {
@@ -31,6 +37,12 @@ func newQueueCmd(flags *rootFlags) *cobra.Command {
 	cmd.AddCommand(newQueueClearCmd(flags))
 	cmd.AddCommand(newQueuePlayCmd(flags))
 	cmd.AddCommand(newQueueRemoveCmd(flags))
+	cmd.AddCommand(newQueueSaveCmd(flags))
+	cmd.AddCommand(newQueueMoveCmd(flags))
+	cmd.AddCommand(newQueueInsertCmd(flags))
+	cmd.AddCommand(newQueueAddCmd(flags))
+	cmd.AddCommand(newQueueShuffleCmd(flags))
+	cmd.AddCommand(newQueueDedupeCmd(flags))
 	return cmd
 }
 
@@ -141,28 +153,69 @@ func newQueuePlayCmd(flags *rootFlags) *cobra.Command {
 }
 
 func newQueueRemoveCmd(flags *rootFlags) *cobra.Command {
+	var updateID int
 	cmd := &cobra.Command{
-		Use:          "remove <pos>",
-		Short:        "Remove a queue entry (1-based)",
+		Use:          "remove <positions>",
+		Short:        "Remove queue entries (1-based, e.g. 3 or 3-7,10)",
 		SilenceUsage: true,
-		Args:         cobra.ExactArgs(1),
+		Args:         cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := validateTarget(flags); err != nil {
 				return err
 			}
-			pos, err := strconv.Atoi(args[0])
+			ranges, err := sonos.ParseQueueRanges(strings.Join(args, ","))
 			if err != nil {
-				return errors.New("pos must be an integer (1-based)")
+				return err
 			}
 			ctx := cmd.Context()
 			c, err := newQueueClient(ctx, flags)
 			if err != nil {
 				return err
 			}
-			if err := c.RemoveQueuePosition(ctx, pos); err != nil {
+			id, err := queueUpdateID(ctx, c, updateID)
+			if err != nil {
+				return err
+			}
+			if id, err = c.RemoveQueueRanges(ctx, id, ranges); err != nil {
+				return err
+			}
+			extra := map[string]any{"ranges": ranges, "updateID": id}
+			if len(ranges) == 1 && ranges[0].Count == 1 {
+				// Single-position removals keep reporting "pos" as before.
+				extra["pos"] = ranges[0].Start
+			}
+			return writeOK(cmd, flags, "queue.remove", extra)
+		},
+	}
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
+
+func newQueueSaveCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "save <name>",
+		Short:        "Save the queue as a Sonos playlist",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			title := strings.TrimSpace(args[0])
+			if title == "" {
+				return errors.New("playlist name is required")
+			}
+			ctx := cmd.Context()
+			c, err := newQueueClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			id, err := c.SaveQueue(ctx, title, "")
+			if err != nil {
 				return err
 			}
-			return writeOK(cmd, flags, "queue.remove", map[string]any{"pos": pos})
+			writePlainLine(cmd, flags, id)
+			return writeOK(cmd, flags, "queue.save", map[string]any{"id": id, "title": title})
 		},
 	}
 	return cmd
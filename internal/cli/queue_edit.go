@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// queueShufflePerm returns the new order for queue shuffle (0-based); tests
+// replace it to get a deterministic order.
+var queueShufflePerm = rand.Perm
+
+// addUpdateIDFlag registers --update-id on a queue edit command. Passing the
+// UpdateID printed by `queue list --format json` makes the edit fail if another
+// controller changed the queue since it was listed.
+func addUpdateIDFlag(cmd *cobra.Command, updateID *int) {
+	cmd.Flags().IntVar(updateID, "update-id", 0, "Queue UpdateID the edit was planned against (0 = current)")
+}
+
+// queueUpdateID returns the UpdateID to edit against: the one given with
+// --update-id, or the queue's current one.
+func queueUpdateID(ctx context.Context, c queueClient, updateID int) (int, error) {
+	if updateID > 0 {
+		return updateID, nil
+	}
+	page, err := c.ListQueue(ctx, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	return page.UpdateID, nil
+}
+
+func parseQueuePosition(name, s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a queue position (1-based): %q", name, s)
+	}
+	return n, nil
+}
+
+func newQueueMoveCmd(flags *rootFlags) *cobra.Command {
+	var updateID int
+	cmd := &cobra.Command{
+		Use:          "move <from> <to>",
+		Short:        "Move a queue entry to another position (1-based)",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			from, err := parseQueuePosition("from", args[0])
+			if err != nil {
+				return err
+			}
+			to, err := parseQueuePosition("to", args[1])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newQueueClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			id, err := queueUpdateID(ctx, c, updateID)
+			if err != nil {
+				return err
+			}
+			id, err = c.MoveQueueTrack(ctx, id, from, to)
+			if err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "queue.move", map[string]any{"from": from, "to": to, "updateID": id})
+		},
+	}
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
+
+func newQueueInsertCmd(flags *rootFlags) *cobra.Command {
+	var at int
+	var updateID int
+	cmd := &cobra.Command{
+		Use:          "insert --at <pos> <uri-or-ref>...",
+		Short:        "Insert URIs, Spotify/Apple Music refs or playlists at a queue position",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			if at < 1 {
+				return errors.New("--at must be a queue position (1-based)")
+			}
+			return runQueueInsert(cmd, flags, at, updateID, args)
+		},
+	}
+	cmd.Flags().IntVar(&at, "at", 0, "Queue position (1-based) for the first inserted entry")
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
+
+func newQueueAddCmd(flags *rootFlags) *cobra.Command {
+	var fromFile string
+	var updateID int
+	cmd := &cobra.Command{
+		Use:          "add [uri-or-ref...]",
+		Short:        "Append URIs, Spotify/Apple Music refs or playlists to the queue",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			refs := append([]string(nil), args...)
+			if fromFile != "" {
+				fileRefs, err := readRefsFile(cmd, fromFile)
+				if err != nil {
+					return err
+				}
+				refs = append(refs, fileRefs...)
+			}
+			if len(refs) == 0 {
+				return errors.New("nothing to add (pass refs or --from-file)")
+			}
+			return runQueueInsert(cmd, flags, 0, updateID, refs)
+		},
+	}
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read refs from a file, one per line (- for stdin; # starts a comment)")
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
+
+func runQueueInsert(cmd *cobra.Command, flags *rootFlags, at, updateID int, refs []string) error {
+	ctx := cmd.Context()
+	c, err := newQueueClient(ctx, flags)
+	if err != nil {
+		return err
+	}
+	id, err := queueUpdateID(ctx, c, updateID)
+	if err != nil {
+		return err
+	}
+	res, err := c.InsertRefs(ctx, id, at, refs)
+	if err != nil {
+		return err
+	}
+	if isJSON(flags) {
+		return writeJSON(cmd, res)
+	}
+	writePlainLine(cmd, flags, fmt.Sprintf("added %d at position %d", res.NumTracksAdded, res.FirstTrackNumber))
+	return nil
+}
+
+// readRefsFile reads one ref per line, skipping blank lines and # comments.
+func readRefsFile(cmd *cobra.Command, path string) ([]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = cmd.InOrStdin()
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var refs []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func newQueueShuffleCmd(flags *rootFlags) *cobra.Command {
+	var updateID int
+	cmd := &cobra.Command{
+		Use:          "shuffle",
+		Short:        "Shuffle the queue order in place",
+		Long:         "Shuffles the queue itself (unlike `mode shuffle`, which only changes the play order), so the new order shows up in every Sonos app.",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newQueueClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			page, err := c.ListAllQueue(ctx)
+			if err != nil {
+				return err
+			}
+			if updateID > 0 && updateID != page.UpdateID {
+				return sonos.ErrQueueChanged
+			}
+			order := queueShufflePerm(len(page.Items))
+			for i := range order {
+				order[i]++
+			}
+			id, err := c.ReorderQueue(ctx, page.UpdateID, order)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("shuffled %d entries", len(order)))
+			return writeOK(cmd, flags, "queue.shuffle", map[string]any{"count": len(order), "updateID": id})
+		},
+	}
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
+
+func newQueueDedupeCmd(flags *rootFlags) *cobra.Command {
+	var updateID int
+	cmd := &cobra.Command{
+		Use:          "dedupe",
+		Short:        "Remove repeated queue entries, keeping the first of each",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newQueueClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			page, err := c.ListAllQueue(ctx)
+			if err != nil {
+				return err
+			}
+			if updateID > 0 && updateID != page.UpdateID {
+				return sonos.ErrQueueChanged
+			}
+			ranges := sonos.DuplicateQueueRanges(page.Items)
+			removed := 0
+			for _, r := range ranges {
+				removed += r.Count
+			}
+			id := page.UpdateID
+			if len(ranges) > 0 {
+				if id, err = c.RemoveQueueRanges(ctx, page.UpdateID, ranges); err != nil {
+					return err
+				}
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("removed %d duplicates", removed))
+			return writeOK(cmd, flags, "queue.dedupe", map[string]any{"removed": removed, "updateID": id})
+		},
+	}
+	addUpdateIDFlag(cmd, &updateID)
+	return cmd
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func fakeQueueTitles(s *sonostest.Speaker) string {
+	q := s.State().Queue
+	titles := make([]string, 0, len(q))
+	for _, tr := range q {
+		titles = append(titles, tr.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestReadRefsFileFromStdin(t *testing.T) {
+	cmd := newQueueAddCmd(&rootFlags{})
+	cmd.SetIn(strings.NewReader("# refs\nhttp://example.com/a.mp3\n\n  spotify:track:abc  \n"))
+	refs, err := readRefsFile(cmd, "-")
+	if err != nil {
+		t.Fatalf("readRefsFile: %v", err)
+	}
+	if len(refs) != 2 || refs[0] != "http://example.com/a.mp3" || refs[1] != "spotify:track:abc" {
+		t.Fatalf("unexpected refs: %q", refs)
+	}
+}
+
+func TestQueueInsertRequiresAt(t *testing.T) {
+	flags := &rootFlags{Name: "Kitchen", Timeout: 2 * time.Second}
+	cmd := newQueueInsertCmd(flags)
+
+	orig := newQueueClient
+	t.Cleanup(func() { newQueueClient = orig })
+	fc := &fakeQueueClient{}
+	newQueueClient = func(ctx context.Context, flags *rootFlags) (queueClient, error) { return fc, nil }
+
+	cmd.SetArgs([]string{"http://example.com/a.mp3"})
+	cmd.SetOut(newDiscardWriter())
+	cmd.SetErr(newDiscardWriter())
+	cmd.SilenceErrors = true
+	if err := cmd.ExecuteContext(context.Background()); err == nil || !strings.Contains(err.Error(), "--at") {
+		t.Fatalf("expected --at error, got %v", err)
+	}
+	if fc.insertCalls != 0 {
+		t.Fatalf("unexpected insert: %+v", fc)
+	}
+}
+
+func TestQueueMovePassesUpdateID(t *testing.T) {
+	flags := &rootFlags{Name: "Kitchen", Timeout: 2 * time.Second}
+	cmd := newQueueMoveCmd(flags)
+
+	orig := newQueueClient
+	t.Cleanup(func() { newQueueClient = orig })
+	fc := &fakeQueueClient{}
+	newQueueClient = func(ctx context.Context, flags *rootFlags) (queueClient, error) { return fc, nil }
+
+	cmd.SetArgs([]string{"4", "1", "--update-id", "9"})
+	cmd.SetOut(newDiscardWriter())
+	cmd.SetErr(newDiscardWriter())
+	cmd.SilenceErrors = true
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// An explicit --update-id is used as-is instead of re-reading the queue.
+	if fc.moveCalls != 1 || fc.lastPosition != 1 || fc.listCalls != 0 {
+		t.Fatalf("unexpected calls: %+v", fc)
+	}
+}
+
+func TestE2EQueueEditing(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	h.SetPlaylists(sonostest.Playlist{Title: "Mix", Tracks: []sonostest.Track{
+		{URI: "http://example.com/p1.mp3", Title: "P1"},
+	}})
+	kitchen := h.Speaker("Kitchen")
+	var tracks []sonostest.Track
+	for _, title := range []string{"A", "B", "C", "D", "E"} {
+		tracks = append(tracks, sonostest.Track{URI: "http://example.com/" + title + ".mp3", Title: title})
+	}
+	kitchen.SetQueue(tracks...)
+
+	if _, err := runFake(t, "queue", "move", "--name", "Kitchen", "5", "1"); err != nil {
+		t.Fatalf("queue move: %v", err)
+	}
+	if got := fakeQueueTitles(kitchen); got != "E,A,B,C,D" {
+		t.Fatalf("after move: %s", got)
+	}
+
+	out, err := runFake(t, "queue", "insert", "--name", "Kitchen", "--at", "2", "http://example.com/X.mp3", "SQ:1")
+	if err != nil {
+		t.Fatalf("queue insert: %v", err)
+	}
+	if !strings.Contains(out, "added 2 at position 2") {
+		t.Fatalf("unexpected insert output: %q", out)
+	}
+	if got := fakeQueueTitles(kitchen); got != "E,http://example.com/X.mp3,P1,A,B,C,D" {
+		t.Fatalf("after insert: %s", got)
+	}
+
+	out, err = runFake(t, "queue", "remove", "--name", "Kitchen", "2-3", "--format", "json")
+	if err != nil {
+		t.Fatalf("queue remove: %v", err)
+	}
+	if got := fakeQueueTitles(kitchen); got != "E,A,B,C,D" {
+		t.Fatalf("after remove: %s", got)
+	}
+	if strings.Contains(out, `"pos"`) || !strings.Contains(out, `"ranges"`) {
+		t.Fatalf("unexpected range remove output: %q", out)
+	}
+	out, err = runFake(t, "queue", "remove", "--name", "Kitchen", "5", "--format", "json")
+	if err != nil {
+		t.Fatalf("queue remove: %v", err)
+	}
+	if got := fakeQueueTitles(kitchen); got != "E,A,B,C" {
+		t.Fatalf("after remove: %s", got)
+	}
+	if !strings.Contains(out, `"pos": 5`) || !strings.Contains(out, `"updateID"`) {
+		t.Fatalf("unexpected single remove output: %q", out)
+	}
+
+	refs := filepath.Join(t.TempDir(), "refs.txt")
+	if err := os.WriteFile(refs, []byte("# dupes\nhttp://example.com/A.mp3\nhttp://example.com/B.mp3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runFake(t, "queue", "add", "--name", "Kitchen", "--from-file", refs, "http://example.com/F.mp3"); err != nil {
+		t.Fatalf("queue add: %v", err)
+	}
+	if n := len(kitchen.State().Queue); n != 7 {
+		t.Fatalf("expected 7 entries after add, got %d", n)
+	}
+
+	out, err = runFake(t, "queue", "dedupe", "--name", "Kitchen")
+	if err != nil || !strings.Contains(out, "removed 2 duplicates") {
+		t.Fatalf("queue dedupe = %q, %v", out, err)
+	}
+	if got := fakeQueueTitles(kitchen); got != "E,A,B,C,http://example.com/F.mp3" {
+		t.Fatalf("after dedupe: %s", got)
+	}
+
+	orig := queueShufflePerm
+	t.Cleanup(func() { queueShufflePerm = orig })
+	queueShufflePerm = func(n int) []int {
+		out := make([]int, n)
+		for i := range out {
+			out[i] = n - 1 - i
+		}
+		return out
+	}
+	if _, err := runFake(t, "queue", "shuffle", "--name", "Kitchen"); err != nil {
+		t.Fatalf("queue shuffle: %v", err)
+	}
+	if got := fakeQueueTitles(kitchen); got != "http://example.com/F.mp3,C,B,A,E" {
+		t.Fatalf("after shuffle: %s", got)
+	}
+
+	// An UpdateID from before the shuffle is rejected.
+	if _, err := runFake(t, "queue", "move", "--name", "Kitchen", "--update-id", "1", "1", "2"); err == nil || !strings.Contains(err.Error(), "another controller") {
+		t.Fatalf("expected stale UpdateID error, got %v", err)
+	}
+}
@@ -16,7 +16,13 @@ type fakeQueueClient struct {
 	clearCalls   int
 	removeCalls  int
 	playCalls    int
+	saveCalls    int
+	moveCalls    int
+	insertCalls  int
+	reorderCalls int
 	lastPosition int
+	lastTitle    string
+	lastRefs     []string
 	err          error
 }
 
@@ -30,10 +36,33 @@ func (f *fakeQueueClient) ClearQueue(ctx context.Context) error {
 	return f.err
 }
 
-func (f *fakeQueueClient) RemoveQueuePosition(ctx context.Context, position int) error {
+func (f *fakeQueueClient) ListAllQueue(ctx context.Context) (sonos.QueuePage, error) {
+	f.listCalls++
+	return f.page, f.err
+}
+
+func (f *fakeQueueClient) MoveQueueTrack(ctx context.Context, updateID, from, to int) (int, error) {
+	f.moveCalls++
+	f.lastPosition = to
+	return updateID + 1, f.err
+}
+
+func (f *fakeQueueClient) RemoveQueueRanges(ctx context.Context, updateID int, ranges []sonos.QueueRange) (int, error) {
 	f.removeCalls++
-	f.lastPosition = position
-	return f.err
+	f.lastPosition = ranges[0].Start
+	return updateID + 1, f.err
+}
+
+func (f *fakeQueueClient) InsertRefs(ctx context.Context, updateID, at int, refs []string) (sonos.QueueInsertResult, error) {
+	f.insertCalls++
+	f.lastPosition = at
+	f.lastRefs = refs
+	return sonos.QueueInsertResult{FirstTrackNumber: at, NumTracksAdded: len(refs), UpdateID: updateID + 1}, f.err
+}
+
+func (f *fakeQueueClient) ReorderQueue(ctx context.Context, updateID int, order []int) (int, error) {
+	f.reorderCalls++
+	return updateID + 1, f.err
 }
 
 func (f *fakeQueueClient) PlayQueuePosition(ctx context.Context, position int) error {
@@ -42,6 +71,12 @@ func (f *fakeQueueClient) PlayQueuePosition(ctx context.Context, position int) e
 	return f.err
 }
 
+func (f *fakeQueueClient) SaveQueue(ctx context.Context, title, id string) (string, error) {
+	f.saveCalls++
+	f.lastTitle = title
+	return "SQ:7", f.err
+}
+
 func TestQueueListRequiresTarget(t *testing.T) {
 	flags := &rootFlags{Timeout: 2 * time.Second}
 	cmd := newQueueListCmd(flags)
@@ -175,3 +210,27 @@ func TestQueueClientErrorPropagates(t *testing.T) {
 		t.Fatalf("expected boom, got %v", err)
 	}
 }
+
+func TestQueueSaveCallsClient(t *testing.T) {
+	flags := &rootFlags{Name: "Kitchen", Timeout: 2 * time.Second}
+	cmd := newQueueSaveCmd(flags)
+
+	orig := newQueueClient
+	t.Cleanup(func() { newQueueClient = orig })
+
+	fc := &fakeQueueClient{}
+	newQueueClient = func(ctx context.Context, flags *rootFlags) (queueClient, error) { return fc, nil }
+
+	var out captureWriter
+	cmd.SetArgs([]string{"Dinner party"})
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fc.saveCalls != 1 || fc.lastTitle != "Dinner party" || strings.TrimSpace(out.String()) != "SQ:7" {
+		t.Fatalf("unexpected calls: %+v output=%q", fc, out.String())
+	}
+}
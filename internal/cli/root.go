@@ -19,6 +19,9 @@ type rootFlags struct {
 	Format  string
 	JSON    bool // Deprecated: use --format json
 	Debug   bool
+
+	// VolumeLimits come from the config file; see limits.go.
+	VolumeLimits []appconfig.VolumeLimit
 }
 
 func Execute() error {
@@ -54,6 +57,7 @@ func newRootCmd() (*cobra.Command, *rootFlags, error) {
 		return nil, nil, err
 	}
 	cfg = cfg.Normalize()
+	flags.VolumeLimits = cfg.VolumeLimits
 
 	rootCmd := &cobra.Command{
 		Use:          "sonos",
@@ -122,6 +126,25 @@ func newRootCmd() (*cobra.Command, *rootFlags, error) {
 	rootCmd.AddCommand(newMuteCmd(flags))
 	rootCmd.AddCommand(newModeCmd(flags))
 	rootCmd.AddCommand(newWatchCmd(flags))
+	rootCmd.AddCommand(newAlarmCmd(flags))
+	rootCmd.AddCommand(newSleepCmd(flags))
+	rootCmd.AddCommand(newSnapshotCmd(flags))
+	rootCmd.AddCommand(newAnnounceCmd(flags))
+	rootCmd.AddCommand(newPlayFileCmd(flags))
+	rootCmd.AddCommand(newEnqueueFileCmd(flags))
+	rootCmd.AddCommand(newStreamCmd(flags))
+	rootCmd.AddCommand(newPlaylistCmd(flags))
+	rootCmd.AddCommand(newLibraryCmd(flags))
+	rootCmd.AddCommand(newEQCmd(flags))
+	rootCmd.AddCommand(newLimitsCmd(flags))
+	rootCmd.AddCommand(newSeekCmd(flags))
+	rootCmd.AddCommand(newSkipToCmd(flags))
+	rootCmd.AddCommand(newDeviceCmd(flags))
+	rootCmd.AddCommand(newInventoryCmd(flags))
+	rootCmd.AddCommand(newBondCmd(flags))
+	rootCmd.AddCommand(newTopologyCmd(flags))
+	rootCmd.AddCommand(newBatteryCmd(flags))
+	rootCmd.AddCommand(newHistoryCmd(flags))
 
 	return rootCmd, flags, nil
 }
@@ -258,5 +281,5 @@ func coordinatorClient(ctx context.Context, flags *rootFlags) (*sonos.Client, er
 	if err != nil {
 		return nil, err
 	}
-	return newSonosClient(ip, flags.Timeout), nil
+	return withVolumeLimit(newSonosClient(ip, flags.Timeout), flags), nil
 }
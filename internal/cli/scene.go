@@ -341,9 +341,13 @@ func newSceneApplyCmd(flags *rootFlags) *cobra.Command {
 				if ip == "" {
 					continue
 				}
+				room := dev.Name
+				if m, ok := uuidToMember[dev.UUID]; ok && m.Name != "" {
+					room = m.Name
+				}
 				c := newSceneSpeakerClient(ip, flags.Timeout)
 				_ = c.SetMute(cmd.Context(), dev.Mute)
-				_ = c.SetVolume(cmd.Context(), dev.Volume)
+				_ = c.SetVolume(cmd.Context(), capSceneVolume(flags, room, dev.Volume))
 			}
 
 			return writeOK(cmd, flags, "scene.apply", map[string]any{"name": scene.Name, "only": strings.TrimSpace(only)})
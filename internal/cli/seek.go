@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+func newSeekCmd(flags *rootFlags) *cobra.Command {
+	return allowNegativeArgs(&cobra.Command{
+		Use:   "seek <position>",
+		Short: "Seek within the current track",
+		Long: `Seeks the group coordinator within the current track (AVTransport Seek REL_TIME).
+
+Positions: absolute (1:23, 0:01:23, 90, 1m30s), relative to the current position (+30s, -10s,
+-1:00) or a percentage of the track (50%). Relative and percentage targets need a track with
+a known duration; results are clamped to the track.`,
+		Example:      "  sonos seek --name Kitchen 1:23\n  sonos seek --name Kitchen +30s\n  sonos seek --name Kitchen -10s\n  sonos seek --name Kitchen 50%",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			target, err := sonos.ParseSeekTarget(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			pos, err := c.Seek(ctx, target)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, sonos.FormatHMS(pos))
+			return writeOK(cmd, flags, "seek", map[string]any{
+				"coordinatorIP": c.IP,
+				"position":      sonos.FormatHMS(pos),
+				"seconds":       int(pos.Seconds()),
+			})
+		},
+	})
+}
+
+func newSkipToCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "skip-to <title>",
+		Short:        "Jump to the first queue track whose title contains the text",
+		Long:         "Searches the queue for a track title containing the text (case-insensitive) and jumps to it with AVTransport Seek TRACK_NR. If the group is playing something else, the queue is selected and started.",
+		Example:      "  sonos skip-to --name Kitchen \"bohemian\"",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			it, err := c.SkipToTrack(ctx, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Playing #%d %s", it.Position, it.Item.Title))
+			return writeOK(cmd, flags, "skip-to", map[string]any{
+				"coordinatorIP": c.IP,
+				"position":      it.Position,
+				"title":         it.Item.Title,
+			})
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func TestE2ESeekAndSkipTo(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "Intro", Duration: "0:04:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Bohemian Rhapsody", Duration: "0:06:00"},
+	)
+
+	if out, err := runFake(t, "seek", "--name", "Kitchen", "1:00"); err != nil || strings.TrimSpace(out) != "0:01:00" {
+		t.Fatalf("seek 1:00: %q, %v", out, err)
+	}
+	if out, err := runFake(t, "seek", "--name", "Kitchen", "+30s"); err != nil || strings.TrimSpace(out) != "0:01:30" {
+		t.Fatalf("seek +30s: %q, %v", out, err)
+	}
+
+	if out, err := runFake(t, "seek", "--name", "Kitchen", "-10s"); err != nil || strings.TrimSpace(out) != "0:01:20" {
+		t.Fatalf("seek -10s: %q, %v", out, err)
+	}
+
+	if out, err := runFake(t, "seek", "--name", "Kitchen", "50%", "--format", "json"); err != nil || !strings.Contains(out, `"position": "0:02:00"`) {
+		t.Fatalf("seek 50%%: %q, %v", out, err)
+	}
+
+	if out, err := runFake(t, "skip-to", "--name", "Kitchen", "bohemian"); err != nil || !strings.Contains(out, "#2 Bohemian Rhapsody") {
+		t.Fatalf("skip-to: %q, %v", out, err)
+	}
+	if st := kitchen.State(); st.Track != 2 {
+		t.Fatalf("expected track 2, got %+v", st)
+	}
+	if _, err := runFake(t, "skip-to", "--name", "Kitchen", "nothing"); err == nil {
+		t.Fatalf("expected no-match error")
+	}
+
+	kitchen.SetFault("Seek", "701")
+	if _, err := runFake(t, "seek", "--name", "Kitchen", "0:10"); !errors.Is(err, sonos.ErrSeekNotSupported) {
+		t.Fatalf("expected ErrSeekNotSupported, got %v", err)
+	}
+	if _, err := runFake(t, "seek", "--name", "Kitchen", "soon"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
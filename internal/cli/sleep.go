@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+type sleepClient interface {
+	ConfigureSleepTimer(ctx context.Context, d time.Duration) error
+	GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, bool, error)
+	GetGroupVolume(ctx context.Context) (int, error)
+	SetGroupVolume(ctx context.Context, volume int) error
+	GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error)
+}
+
+var newSleepClient = func(ctx context.Context, flags *rootFlags) (sleepClient, error) {
+	return coordinatorClient(ctx, flags)
+}
+
+// sleepFadeTick is how often --fade adjusts the volume and checks the timer.
+var sleepFadeTick = 5 * time.Second
+
+// sleepStopGrace bounds how long --fade waits for playback to stop after the
+// timer should have fired before restoring the volume anyway.
+var sleepStopGrace = 30 * time.Second
+
+func newSleepCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sleep",
+		Short: "Set, cancel, or show the sleep timer",
+		Long:  "Controls the group coordinator's sleep timer (AVTransport ConfigureSleepTimer). Playback stops when the timer runs out.",
+	}
+	cmd.AddCommand(newSleepSetCmd(flags))
+	cmd.AddCommand(newSleepOffCmd(flags))
+	cmd.AddCommand(newSleepStatusCmd(flags))
+	return cmd
+}
+
+func newSleepSetCmd(flags *rootFlags) *cobra.Command {
+	var fade time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "set <duration>",
+		Short: "Stop playback after a duration (e.g. 30m, 1h15m, 45)",
+		Long: `Sets the sleep timer. A bare number is read as minutes.
+
+With --fade, the command stays attached: over the last part of the timer it lowers
+the group volume to zero, then restores the original volume once playback stops
+(or if you press Ctrl+C or the timer is cancelled elsewhere).`,
+		Example:      "  sonos sleep set --name Bedroom 30m\n  sonos sleep set --name Bedroom 45m --fade 10m",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			d, err := parseSleepDuration(args[0])
+			if err != nil {
+				return err
+			}
+			if fade < 0 {
+				return errors.New("--fade must be positive")
+			}
+			if fade > d {
+				return errors.New("--fade must not be longer than the timer")
+			}
+
+			ctx := cmd.Context()
+			c, err := newSleepClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			if err := c.ConfigureSleepTimer(ctx, d); err != nil {
+				return err
+			}
+			if fade == 0 {
+				return writeOK(cmd, flags, "sleep.set", map[string]any{"duration": d.String(), "seconds": int(d.Seconds())})
+			}
+
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
+			writePlainLine(cmd, flags, fmt.Sprintf("Sleep timer set for %s; fading out over the last %s (Ctrl+C to stop fading).", d, fade))
+			restored, err := runSleepFade(ctx, c, d, fade)
+			if err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "sleep.set", map[string]any{
+				"duration":       d.String(),
+				"seconds":        int(d.Seconds()),
+				"fade":           fade.String(),
+				"restoredVolume": restored,
+			})
+		},
+	}
+	cmd.Flags().DurationVar(&fade, "fade", 0, "Fade the group volume out over the last part of the timer (e.g. 5m)")
+	return cmd
+}
+
+func newSleepOffCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "off",
+		Short:        "Cancel the sleep timer",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			c, err := newSleepClient(cmd.Context(), flags)
+			if err != nil {
+				return err
+			}
+			if err := c.ConfigureSleepTimer(cmd.Context(), 0); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "sleep.off", nil)
+		},
+	}
+}
+
+func newSleepStatusCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "status",
+		Short:        "Show the remaining sleep timer",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			c, err := newSleepClient(cmd.Context(), flags)
+			if err != nil {
+				return err
+			}
+			remaining, active, err := c.GetRemainingSleepTimerDuration(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, map[string]any{
+					"active":           active,
+					"remaining":        remaining.String(),
+					"remainingSeconds": int(remaining.Seconds()),
+				})
+			}
+			if isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "active\t%t\nremainingSeconds\t%d\n", active, int(remaining.Seconds()))
+				return nil
+			}
+			if !active {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Sleep timer: off")
+				return nil
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Sleep timer: %s remaining\n", remaining)
+			return nil
+		},
+	}
+}
+
+func parseSleepDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	if n, err := strconv.Atoi(s); err == nil {
+		d = time.Duration(n) * time.Minute
+	} else {
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 1h15m, 45)", s)
+		}
+	}
+	if d < time.Second || d > sonos.MaxSleepTimer {
+		return 0, fmt.Errorf("duration must be between 1s and %s", sonos.MaxSleepTimer)
+	}
+	return d, nil
+}
+
+// runSleepFade waits until the fade window starts, then lowers the group volume
+// linearly to zero. After the timer stops playback (or if it is cancelled, or
+// ctx ends) the original volume is restored. It returns the restored volume.
+func runSleepFade(ctx context.Context, c sleepClient, total, fade time.Duration) (int, error) {
+	start := time.Now()
+	original, err := c.GetGroupVolume(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	restore := func() error {
+		rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		return c.SetGroupVolume(rctx, original)
+	}
+
+	fadeStart := start.Add(total - fade)
+	end := start.Add(total)
+	current := original
+	ticker := time.NewTicker(sleepFadeTick)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if !now.Before(end) {
+			break
+		}
+		if !now.Before(fadeStart) {
+			left := float64(end.Sub(now)) / float64(fade)
+			target := int(math.Round(float64(original) * left))
+			if target < current {
+				if err := c.SetGroupVolume(ctx, target); err != nil && ctx.Err() == nil {
+					_ = restore()
+					return 0, err
+				}
+				current = target
+			}
+			// Stop fading if the timer was cancelled from another controller.
+			if _, active, err := c.GetRemainingSleepTimerDuration(ctx); err == nil && !active {
+				return original, restore()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return original, restore()
+		case <-ticker.C:
+		case <-time.After(time.Until(end)):
+		}
+	}
+
+	// The speaker stops playback when the timer fires; restore only afterwards
+	// so the volume does not jump back up mid-track.
+	deadline := time.Now().Add(sleepStopGrace)
+	for time.Now().Before(deadline) {
+		info, err := c.GetTransportInfo(ctx)
+		if err == nil && info.State != "PLAYING" && info.State != "TRANSITIONING" {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return original, restore()
+		case <-ticker.C:
+		}
+	}
+	return original, restore()
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+type fakeSleepClient struct {
+	mu         sync.Mutex
+	configured []time.Duration
+	remaining  time.Duration
+	active     bool
+	volume     int
+	volumes    []int
+	state      string
+	stopAfter  time.Time
+}
+
+func (f *fakeSleepClient) ConfigureSleepTimer(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configured = append(f.configured, d)
+	f.active = d > 0
+	f.remaining = d
+	return nil
+}
+
+func (f *fakeSleepClient) GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.remaining, f.active, nil
+}
+
+func (f *fakeSleepClient) GetGroupVolume(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.volume, nil
+}
+
+func (f *fakeSleepClient) SetGroupVolume(ctx context.Context, volume int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.volume = volume
+	f.volumes = append(f.volumes, volume)
+	return nil
+}
+
+func (f *fakeSleepClient) GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.stopAfter.IsZero() && time.Now().After(f.stopAfter) {
+		return sonos.TransportInfo{State: "STOPPED"}, nil
+	}
+	return sonos.TransportInfo{State: f.state}, nil
+}
+
+func withFakeSleepClient(t *testing.T, fake *fakeSleepClient) {
+	t.Helper()
+	orig := newSleepClient
+	t.Cleanup(func() { newSleepClient = orig })
+	newSleepClient = func(ctx context.Context, flags *rootFlags) (sleepClient, error) {
+		return fake, nil
+	}
+}
+
+func runSleepCmd(t *testing.T, flags *rootFlags, args ...string) (string, error) {
+	t.Helper()
+	cmd := newSleepCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestSleepSetOffStatus(t *testing.T) {
+	fake := &fakeSleepClient{}
+	withFakeSleepClient(t, fake)
+
+	out, err := runSleepCmd(t, &rootFlags{Name: "Bedroom", Format: formatJSON}, "set", "45")
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if len(fake.configured) != 1 || fake.configured[0] != 45*time.Minute || !strings.Contains(out, `"seconds": 2700`) {
+		t.Fatalf("unexpected set: %v %s", fake.configured, out)
+	}
+
+	out, err = runSleepCmd(t, &rootFlags{Name: "Bedroom", Format: formatPlain}, "status")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if strings.TrimSpace(out) != "Sleep timer: 45m0s remaining" {
+		t.Fatalf("unexpected status: %q", out)
+	}
+
+	if _, err := runSleepCmd(t, &rootFlags{Name: "Bedroom"}, "off"); err != nil {
+		t.Fatalf("off: %v", err)
+	}
+	if fake.configured[1] != 0 {
+		t.Fatalf("expected cancel, got %v", fake.configured)
+	}
+
+	out, err = runSleepCmd(t, &rootFlags{Name: "Bedroom", Format: formatTSV}, "status")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if out != "active\tfalse\nremainingSeconds\t0\n" {
+		t.Fatalf("unexpected tsv status: %q", out)
+	}
+	out, err = runSleepCmd(t, &rootFlags{Name: "Bedroom", Format: formatPlain}, "status")
+	if err != nil || strings.TrimSpace(out) != "Sleep timer: off" {
+		t.Fatalf("unexpected plain status: %q %v", out, err)
+	}
+}
+
+func TestSleepSetValidation(t *testing.T) {
+	withFakeSleepClient(t, &fakeSleepClient{})
+
+	cases := [][]string{
+		{"set", "soon"},
+		{"set", "0"},
+		{"set", "25h"},
+		{"set", "10m", "--fade", "20m"},
+		{"set", "10m", "--fade", "-1m"},
+	}
+	for _, args := range cases {
+		if _, err := runSleepCmd(t, &rootFlags{Name: "Bedroom"}, args...); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+	if _, err := runSleepCmd(t, &rootFlags{}, "status"); err == nil {
+		t.Fatalf("expected target error")
+	}
+}
+
+func TestParseSleepDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30":     30 * time.Minute,
+		"30m":    30 * time.Minute,
+		"1h15m":  75 * time.Minute,
+		" 90s ":  90 * time.Second,
+		"23h59m": 23*time.Hour + 59*time.Minute,
+	}
+	for in, want := range cases {
+		got, err := parseSleepDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("parseSleepDuration(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+}
+
+func TestSleepFadeLowersThenRestoresVolume(t *testing.T) {
+	origTick, origGrace := sleepFadeTick, sleepStopGrace
+	t.Cleanup(func() { sleepFadeTick, sleepStopGrace = origTick, origGrace })
+	sleepFadeTick = 10 * time.Millisecond
+	sleepStopGrace = time.Second
+
+	fake := &fakeSleepClient{volume: 40, state: "PLAYING", active: true, remaining: time.Minute}
+	fake.stopAfter = time.Now().Add(400 * time.Millisecond)
+
+	restored, err := runSleepFade(context.Background(), fake, 300*time.Millisecond, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("runSleepFade: %v", err)
+	}
+	if restored != 40 {
+		t.Fatalf("restored: %d", restored)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.volumes) < 3 {
+		t.Fatalf("expected several volume steps, got %v", fake.volumes)
+	}
+	for i := 1; i < len(fake.volumes)-1; i++ {
+		if fake.volumes[i] > fake.volumes[i-1] {
+			t.Fatalf("fade should only lower volume: %v", fake.volumes)
+		}
+	}
+	if fake.volumes[0] >= 40 || fake.volumes[len(fake.volumes)-2] > 10 {
+		t.Fatalf("unexpected fade steps: %v", fake.volumes)
+	}
+	if fake.volumes[len(fake.volumes)-1] != 40 || fake.volume != 40 {
+		t.Fatalf("volume not restored: %v", fake.volumes)
+	}
+}
+
+func TestSleepFadeRestoresWhenTimerCancelled(t *testing.T) {
+	origTick := sleepFadeTick
+	t.Cleanup(func() { sleepFadeTick = origTick })
+	sleepFadeTick = 10 * time.Millisecond
+
+	fake := &fakeSleepClient{volume: 30, state: "PLAYING", active: false}
+	restored, err := runSleepFade(context.Background(), fake, time.Hour, time.Hour)
+	if err != nil || restored != 30 {
+		t.Fatalf("runSleepFade: %d %v", restored, err)
+	}
+	if fake.volume != 30 {
+		t.Fatalf("volume not restored: %v", fake.volumes)
+	}
+}
+
+func TestSleepFadeRestoresOnCancel(t *testing.T) {
+	origTick := sleepFadeTick
+	t.Cleanup(func() { sleepFadeTick = origTick })
+	sleepFadeTick = 10 * time.Millisecond
+
+	fake := &fakeSleepClient{volume: 25, state: "PLAYING", active: true}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	restored, err := runSleepFade(ctx, fake, time.Hour, 2*time.Hour/3)
+	if err != nil || restored != 25 {
+		t.Fatalf("runSleepFade: %d %v", restored, err)
+	}
+	if len(fake.volumes) != 1 || fake.volumes[0] != 25 {
+		t.Fatalf("expected a single restore, got %v", fake.volumes)
+	}
+}
+
+func TestE2ESleepTimer(t *testing.T) {
+	h := newFakeHousehold(t, "Bedroom")
+
+	if _, err := runFake(t, "sleep", "set", "--name", "Bedroom", "30m"); err != nil {
+		t.Fatalf("sleep set: %v", err)
+	}
+	if d := h.Speaker("Bedroom").State().SleepTimer; d < 29*time.Minute || d > 30*time.Minute {
+		t.Fatalf("sleep timer: %s", d)
+	}
+	out, err := runFake(t, "sleep", "status", "--name", "Bedroom", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("sleep status: %v", err)
+	}
+	if !strings.HasPrefix(out, "active\ttrue\n") {
+		t.Fatalf("unexpected status: %q", out)
+	}
+	if _, err := runFake(t, "sleep", "off", "--name", "Bedroom"); err != nil {
+		t.Fatalf("sleep off: %v", err)
+	}
+	if d := h.Speaker("Bedroom").State().SleepTimer; d != 0 {
+		t.Fatalf("sleep timer still set: %s", d)
+	}
+}
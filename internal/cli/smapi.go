@@ -32,7 +32,7 @@ func newSMAPICmd(flags *rootFlags) *cobra.Command {
 
 func anySpeakerClient(ctx context.Context, flags *rootFlags) (*sonos.Client, error) {
 	if strings.TrimSpace(flags.IP) != "" {
-		return newSonosClient(strings.TrimSpace(flags.IP), flags.Timeout), nil
+		return withVolumeLimit(newSonosClient(strings.TrimSpace(flags.IP), flags.Timeout), flags), nil
 	}
 
 	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
@@ -43,14 +43,14 @@ func anySpeakerClient(ctx context.Context, flags *rootFlags) (*sonos.Client, err
 		return nil, errors.New("no speakers found")
 	}
 	if strings.TrimSpace(flags.Name) == "" {
-		return newSonosClient(devs[0].IP, flags.Timeout), nil
+		return withVolumeLimit(newSonosClient(devs[0].IP, flags.Timeout), flags), nil
 	}
 
 	// Prefer topology resolution by name (more reliable than SSDP name matching).
 	c := newSonosClient(devs[0].IP, flags.Timeout)
 	top, err := c.GetTopology(ctx)
 	if err != nil {
-		return c, nil
+		return withVolumeLimit(c, flags), nil
 	}
 	mem, ok := top.FindByName(flags.Name)
 	if !ok {
@@ -64,7 +64,7 @@ func anySpeakerClient(ctx context.Context, flags *rootFlags) (*sonos.Client, err
 		}
 	}
 	if ok && mem.IP != "" {
-		return newSonosClient(mem.IP, flags.Timeout), nil
+		return withVolumeLimit(newSonosClient(mem.IP, flags.Timeout), flags), nil
 	}
 	return nil, errors.New("speaker name not found: " + flags.Name)
 }
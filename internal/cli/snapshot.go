@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/snapshots"
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+var newSnapshotStore = func() (snapshots.Store, error) {
+	return snapshots.NewFileStore()
+}
+
+// snapshotTopology returns the current household topology, used to find
+// speakers whose IP changed since the snapshot was saved.
+var snapshotTopology = func(ctx context.Context, flags *rootFlags) (sonos.Topology, error) {
+	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
+	if err != nil {
+		return sonos.Topology{}, err
+	}
+	if len(devs) == 0 {
+		return sonos.Topology{}, errors.New("no speakers found")
+	}
+	return newSonosClient(devs[0].IP, flags.Timeout).GetTopology(ctx)
+}
+
+func newSnapshotCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save and restore what a group is playing",
+		Long: `Snapshots capture a group's playback state: source, queue, track, position, play mode,
+grouping and per-room volume/mute. Restoring puts it back the way the Sonos app resumes
+after an interruption: a queue resumes at the same track and position, a radio stream is
+re-tuned live, and line-in/TV inputs are selected again.
+
+Snapshots are stored by label; the label defaults to the --name (or --ip) target.`,
+	}
+	cmd.AddCommand(newSnapshotSaveCmd(flags))
+	cmd.AddCommand(newSnapshotRestoreCmd(flags))
+	cmd.AddCommand(newSnapshotListCmd(flags))
+	cmd.AddCommand(newSnapshotDeleteCmd(flags))
+	return cmd
+}
+
+func newSnapshotSaveCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "save [label]",
+		Short:        "Save the target group's playback state",
+		Example:      "  sonos snapshot save --name Kitchen\n  sonos snapshot save --name Kitchen before-party",
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			label := snapshotLabel(flags, args)
+
+			store, err := newSnapshotStore()
+			if err != nil {
+				return err
+			}
+			c, err := coordinatorClient(cmd.Context(), flags)
+			if err != nil {
+				return err
+			}
+			snap, err := sonos.TakeSnapshot(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+			entry := snapshots.Entry{Name: label, Room: snapshotRoom(snap), Snapshot: *snap}
+			if err := store.Put(entry); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Saved snapshot %q (%s, %s).", label, snap.Source, strings.ToLower(snap.TransportState)))
+			return writeOK(cmd, flags, "snapshot.save", map[string]any{
+				"name":   label,
+				"room":   entry.Room,
+				"source": snap.Source,
+				"state":  snap.TransportState,
+				"tracks": len(snap.Queue),
+			})
+		},
+	}
+}
+
+func newSnapshotRestoreCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "restore [label]",
+		Short:        "Restore a saved playback state",
+		Example:      "  sonos snapshot restore --name Kitchen\n  sonos snapshot restore before-party",
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			label := snapshotLabel(flags, args)
+			if label == "" {
+				return errors.New("provide a snapshot label, --name, or --ip")
+			}
+
+			store, err := newSnapshotStore()
+			if err != nil {
+				return err
+			}
+			entry, ok, err := store.Get(label)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("snapshot not found: " + label)
+			}
+
+			ctx := cmd.Context()
+			snap := entry.Snapshot
+			if top, err := snapshotTopology(ctx, flags); err == nil {
+				snap.Relocate(top)
+			}
+			snap.UseClient(withVolumeLimit(newSonosClient(snap.CoordinatorIP, flags.Timeout), flags))
+			if err := snap.Restore(ctx); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "snapshot.restore", map[string]any{
+				"name":   label,
+				"room":   entry.Room,
+				"source": snap.Source,
+			})
+		},
+	}
+}
+
+func newSnapshotListCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List saved snapshots",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newSnapshotStore()
+			if err != nil {
+				return err
+			}
+			metas, err := store.List()
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, metas)
+			}
+			if isTSV(flags) {
+				for _, m := range metas {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\n", m.Name, m.Room, m.Source, m.State, m.CreatedAt.Format(time.RFC3339))
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "NAME\tROOM\tSOURCE\tSTATE\tCREATED\n")
+			for _, m := range metas {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Name, m.Room, m.Source, m.State, m.CreatedAt.Format(time.RFC3339))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newSnapshotDeleteCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "delete <label>",
+		Short:        "Delete a saved snapshot",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := newSnapshotStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(args[0]); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "snapshot.delete", map[string]any{"name": args[0]})
+		},
+	}
+}
+
+// snapshotLabel is the explicit label, or else the target the snapshot was taken on.
+func snapshotLabel(flags *rootFlags, args []string) string {
+	if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+		return strings.TrimSpace(args[0])
+	}
+	if strings.TrimSpace(flags.Name) != "" {
+		return strings.TrimSpace(flags.Name)
+	}
+	return strings.TrimSpace(flags.IP)
+}
+
+func snapshotRoom(snap *sonos.Snapshot) string {
+	for _, m := range snap.Members {
+		if m.UUID == snap.CoordinatorUUID {
+			return m.Name
+		}
+	}
+	return ""
+}
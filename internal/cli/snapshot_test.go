@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/snapshots"
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type fakeSnapshotStore struct {
+	entries map[string]snapshots.Entry
+}
+
+func (f *fakeSnapshotStore) List() ([]snapshots.Meta, error) {
+	out := make([]snapshots.Meta, 0, len(f.entries))
+	for _, e := range f.entries {
+		out = append(out, snapshots.Meta{Name: e.Name, Room: e.Room, Source: e.Snapshot.Source, State: e.Snapshot.TransportState, CreatedAt: e.Snapshot.CreatedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (f *fakeSnapshotStore) Get(name string) (snapshots.Entry, bool, error) {
+	e, ok := f.entries[name]
+	return e, ok, nil
+}
+
+func (f *fakeSnapshotStore) Put(entry snapshots.Entry) error {
+	if f.entries == nil {
+		f.entries = map[string]snapshots.Entry{}
+	}
+	f.entries[entry.Name] = entry
+	return nil
+}
+
+func (f *fakeSnapshotStore) Delete(name string) error {
+	delete(f.entries, name)
+	return nil
+}
+
+func withFakeSnapshotStore(t *testing.T) *fakeSnapshotStore {
+	t.Helper()
+	store := &fakeSnapshotStore{}
+	orig := newSnapshotStore
+	t.Cleanup(func() { newSnapshotStore = orig })
+	newSnapshotStore = func() (snapshots.Store, error) { return store, nil }
+	return store
+}
+
+func TestSnapshotLabel(t *testing.T) {
+	cases := []struct {
+		flags rootFlags
+		args  []string
+		want  string
+	}{
+		{rootFlags{Name: "Kitchen"}, nil, "Kitchen"},
+		{rootFlags{Name: "Kitchen"}, []string{" party "}, "party"},
+		{rootFlags{IP: "192.168.1.10"}, nil, "192.168.1.10"},
+		{rootFlags{}, nil, ""},
+	}
+	for _, tc := range cases {
+		if got := snapshotLabel(&tc.flags, tc.args); got != tc.want {
+			t.Fatalf("snapshotLabel(%+v, %v) = %q, want %q", tc.flags, tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestSnapshotListAndDelete(t *testing.T) {
+	store := withFakeSnapshotStore(t)
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	_ = store.Put(snapshots.Entry{Name: "Kitchen", Room: "Kitchen", Snapshot: sonos.Snapshot{CreatedAt: created, Source: sonos.SourceQueue, TransportState: "PLAYING"}})
+
+	cmd := newSnapshotCmd(&rootFlags{Format: formatTSV})
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if got := out.String(); got != "Kitchen\tKitchen\tqueue\tPLAYING\t2025-01-02T03:04:05Z\n" {
+		t.Fatalf("unexpected list: %q", got)
+	}
+
+	cmd = newSnapshotCmd(&rootFlags{})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"delete", "Kitchen"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(store.entries) != 0 {
+		t.Fatalf("expected deletion, got %v", store.entries)
+	}
+}
+
+func TestSnapshotRestoreRequiresKnownLabel(t *testing.T) {
+	withFakeSnapshotStore(t)
+
+	for _, args := range [][]string{{"restore"}, {"restore", "missing"}} {
+		cmd := newSnapshotCmd(&rootFlags{})
+		cmd.SetOut(&captureWriter{})
+		cmd.SetErr(&captureWriter{})
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestE2ESnapshotSaveRestore(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	store := withFakeSnapshotStore(t)
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "First", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Second", Duration: "0:03:00"},
+	)
+	kitchen.SetVolume(18)
+
+	if _, err := runFake(t, "play", "--name", "Kitchen"); err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	if _, err := runFake(t, "next", "--name", "Kitchen"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	out, err := runFake(t, "snapshot", "save", "--name", "Kitchen")
+	if err != nil {
+		t.Fatalf("snapshot save: %v", err)
+	}
+	if !strings.Contains(out, `Saved snapshot "Kitchen" (queue, playing)`) {
+		t.Fatalf("unexpected save output: %q", out)
+	}
+	if e, ok := store.entries["Kitchen"]; !ok || e.Room != "Kitchen" || len(e.Snapshot.Queue) != 2 {
+		t.Fatalf("unexpected stored snapshot: %+v", store.entries)
+	}
+
+	if _, err := runFake(t, "play-uri", "--name", "Kitchen", "x-rincon-mp3radio://example.com/live"); err != nil {
+		t.Fatalf("play-uri: %v", err)
+	}
+	kitchen.SetVolume(70)
+
+	out, err = runFake(t, "snapshot", "restore", "--name", "Kitchen", "--format", "json")
+	if err != nil {
+		t.Fatalf("snapshot restore: %v", err)
+	}
+	if !strings.Contains(out, `"action": "snapshot.restore"`) {
+		t.Fatalf("unexpected restore output: %q", out)
+	}
+	st := kitchen.State()
+	if !strings.HasPrefix(st.AVTransportURI, "x-rincon-queue:") || st.Track != 2 || st.TransportState != "PLAYING" || st.Volume != 18 {
+		t.Fatalf("unexpected state after restore: %+v", st)
+	}
+}
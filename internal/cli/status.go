@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/STop211650/sonoscli/internal/sonos"
@@ -12,8 +13,12 @@ type statusClient interface {
 	GetDeviceDescription(ctx context.Context) (sonos.Device, error)
 	GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error)
 	GetPositionInfo(ctx context.Context) (sonos.PositionInfo, error)
+	GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error)
+	GetCurrentTransportActions(ctx context.Context) ([]string, error)
 	GetVolume(ctx context.Context) (int, error)
 	GetMute(ctx context.Context) (bool, error)
+	GetModelName(ctx context.Context) (string, error)
+	GetBatteryStatus(ctx context.Context) (sonos.BatteryStatus, error)
 }
 
 var newStatusClient = func(ctx context.Context, flags *rootFlags) (statusClient, error) {
@@ -24,10 +29,14 @@ type statusOutput struct {
 	Device      sonos.Device        `json:"device"`
 	Transport   sonos.TransportInfo `json:"transport"`
 	Position    sonos.PositionInfo  `json:"position"`
+	Media       sonos.MediaInfo     `json:"media"`
+	Actions     []string            `json:"actions"`
 	NowPlaying  *sonos.DIDLItem     `json:"nowPlaying,omitempty"`
 	AlbumArtURL string              `json:"albumArtURL,omitempty"`
 	Volume      int                 `json:"volume"`
 	Mute        bool                `json:"mute"`
+	// Battery is only set for portable speakers (Roam, Move).
+	Battery *sonos.BatteryStatus `json:"battery,omitempty"`
 }
 
 func newStatusCmd(flags *rootFlags) *cobra.Command {
@@ -35,7 +44,7 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 		Use:          "status",
 		Aliases:      []string{"now"},
 		Short:        "Show current playback status",
-		Long:         "Prints coordinator status (transport state, track URI, time, volume/mute). Parses TrackMetaData when available to show title/artist/album/album art. Use --format json for machine-readable output.",
+		Long:         "Prints coordinator status (transport state, track URI, time, volume/mute, loaded media and the transport actions the source currently accepts, e.g. Next/Seek/Pause). Parses TrackMetaData when available to show title/artist/album/album art. Use --format json for machine-readable output.",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := validateTarget(flags); err != nil {
@@ -50,8 +59,19 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			dev, _ := c.GetDeviceDescription(ctx)
 			transport, _ := c.GetTransportInfo(ctx)
 			position, _ := c.GetPositionInfo(ctx)
+			media, _ := c.GetMediaInfo(ctx)
+			actions, _ := c.GetCurrentTransportActions(ctx)
+			if actions == nil {
+				actions = []string{}
+			}
 			vol, _ := c.GetVolume(ctx)
 			mute, _ := c.GetMute(ctx)
+			var battery *sonos.BatteryStatus
+			if model, _ := c.GetModelName(ctx); sonos.IsPortableModel(model) {
+				if b, err := c.GetBatteryStatus(ctx); err == nil {
+					battery = &b
+				}
+			}
 
 			var nowPlaying *sonos.DIDLItem
 			var albumArtURL string
@@ -64,10 +84,13 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 				Device:      dev,
 				Transport:   transport,
 				Position:    position,
+				Media:       media,
+				Actions:     actions,
 				NowPlaying:  nowPlaying,
 				AlbumArtURL: albumArtURL,
 				Volume:      vol,
 				Mute:        mute,
+				Battery:     battery,
 			}
 
 			if isJSON(flags) {
@@ -101,6 +124,14 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "duration\t%s\n", position.TrackDuration)
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "volume\t%d\n", vol)
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "mute\t%v\n", mute)
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "source\t%s\n", media.CurrentURI)
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "tracks\t%d\n", media.NrTracks)
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "medium\t%s\n", media.PlayMedium)
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "actions\t%s\n", strings.Join(actions, ","))
+				if battery != nil {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "battery\t%d\n", battery.Level)
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "charging\t%v\n", battery.Charging)
+				}
 				return nil
 			}
 
@@ -128,6 +159,13 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Time:\t\t%s / %s\n", position.RelTime, position.TrackDuration)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Volume:\t\t%d\n", vol)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Mute:\t\t%v\n", mute)
+			if media.CurrentURI != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Source:\t\t%s (%d tracks)\n", media.CurrentURI, media.NrTracks)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Actions:\t%s\n", strings.Join(actions, ", "))
+			if battery != nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Battery:\t%s\n", batteryLine(*battery))
+			}
 			return nil
 		},
 	}
@@ -13,8 +13,12 @@ type fakeStatusClient struct {
 	dev       sonos.Device
 	transport sonos.TransportInfo
 	position  sonos.PositionInfo
+	media     sonos.MediaInfo
+	actions   []string
 	volume    int
 	mute      bool
+	model     string
+	battery   *sonos.BatteryStatus
 }
 
 func (f *fakeStatusClient) GetDeviceDescription(ctx context.Context) (sonos.Device, error) {
@@ -29,6 +33,14 @@ func (f *fakeStatusClient) GetPositionInfo(ctx context.Context) (sonos.PositionI
 	return f.position, nil
 }
 
+func (f *fakeStatusClient) GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error) {
+	return f.media, nil
+}
+
+func (f *fakeStatusClient) GetCurrentTransportActions(ctx context.Context) ([]string, error) {
+	return f.actions, nil
+}
+
 func (f *fakeStatusClient) GetVolume(ctx context.Context) (int, error) {
 	return f.volume, nil
 }
@@ -37,6 +49,17 @@ func (f *fakeStatusClient) GetMute(ctx context.Context) (bool, error) {
 	return f.mute, nil
 }
 
+func (f *fakeStatusClient) GetModelName(ctx context.Context) (string, error) {
+	return f.model, nil
+}
+
+func (f *fakeStatusClient) GetBatteryStatus(ctx context.Context) (sonos.BatteryStatus, error) {
+	if f.battery == nil {
+		return sonos.BatteryStatus{}, sonos.ErrNoBattery
+	}
+	return *f.battery, nil
+}
+
 func TestStatusShowsNowPlayingFields(t *testing.T) {
 	flags := &rootFlags{Name: "Office", Timeout: 2 * time.Second}
 
@@ -149,3 +172,40 @@ func TestStatusJSONIncludesNowPlaying(t *testing.T) {
 		t.Fatalf("missing albumArtURL: %s", s)
 	}
 }
+
+func TestStatusIncludesMediaInfoAndActions(t *testing.T) {
+	fake := &fakeStatusClient{
+		dev:       sonos.Device{Name: "Office", IP: "192.168.1.50"},
+		transport: sonos.TransportInfo{State: "PLAYING"},
+		media:     sonos.MediaInfo{NrTracks: 12, CurrentURI: "x-rincon-queue:RINCON_OFFICE1400#0", PlayMedium: "NETWORK"},
+		actions:   []string{"Set", "Stop", "Pause", "Play", "Seek", "Next"},
+	}
+	orig := newStatusClient
+	t.Cleanup(func() { newStatusClient = orig })
+	newStatusClient = func(ctx context.Context, flags *rootFlags) (statusClient, error) {
+		return fake, nil
+	}
+
+	for _, tc := range []struct {
+		format string
+		want   []string
+	}{
+		{formatPlain, []string{"Source:\t\tx-rincon-queue:RINCON_OFFICE1400#0 (12 tracks)", "Actions:\tSet, Stop, Pause, Play, Seek, Next"}},
+		{formatTSV, []string{"tracks\t12\n", "actions\tSet,Stop,Pause,Play,Seek,Next\n"}},
+		{formatJSON, []string{`"NrTracks": 12`, `"actions": [`, `"Seek"`}},
+	} {
+		cmd := newStatusCmd(&rootFlags{Name: "Office", Timeout: 2 * time.Second, Format: tc.format})
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.format, err)
+		}
+		for _, w := range tc.want {
+			if !strings.Contains(out.String(), w) {
+				t.Fatalf("%s: missing %q in %s", tc.format, w, out.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/mediaserver"
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+// streamPollInterval is how often stream checks the speaker, resuming playback
+// if it dropped the stream and noticing when another source was selected.
+var streamPollInterval = 2 * time.Second
+
+type streamTransport interface {
+	Play(ctx context.Context) error
+	StopOrNoop(ctx context.Context) error
+	GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error)
+	GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error)
+}
+
+func newStreamCmd(flags *rootFlags) *cobra.Command {
+	var (
+		codec string
+		title string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stream",
+		Short: "Stream live audio from stdin to a room",
+		Long: `Reads live audio from stdin and plays it on the target room as a radio-style stream
+served from this machine. The stream is unbounded: it runs until stdin ends, you press
+Ctrl+C, or another source is selected on the room.
+
+Use --codec wav for raw captures (a RIFF/WAVE header is required, as written by arecord
+or ffmpeg -f wav) and --codec mp3 for encoded input. If the speaker drops the connection
+it may reconnect at any time and joins at the live edge; if it stops playing while the
+stream is still selected, playback is started again.`,
+		Example: `  arecord -f cd -t wav | sonos stream --name Office --codec wav
+  ffmpeg -i input.flac -f mp3 - | sonos stream --name Office --codec mp3`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			st, err := mediaserver.NewStream(strings.ToLower(strings.TrimSpace(codec)), strings.TrimSpace(title))
+			if err != nil {
+				return err
+			}
+			in := cmd.InOrStdin()
+			if f, ok := in.(*os.File); ok {
+				if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+					return errors.New("stdin is a terminal; pipe audio into sonos stream (e.g. ffmpeg ... | sonos stream --codec mp3)")
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			listenIP, err := sonos.LocalIPFor(c.IP)
+			if err != nil {
+				return err
+			}
+			srv, err := mediaserver.Start(listenIP)
+			if err != nil {
+				return err
+			}
+			defer srv.Close()
+			defer st.Close()
+
+			runErr := make(chan error, 1)
+			go func() { runErr <- st.Run(in) }()
+
+			uri := sonos.ForceRadioURI(srv.AddStream(st))
+			if err := c.PlayURI(ctx, uri, sonos.BuildRadioMeta(title)); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Streaming stdin (%s) from %s; press Ctrl+C to stop", st.Ext(), srv.BaseURL()))
+
+			ended, err := superviseStream(ctx, c, uri, runErr, flags.Timeout)
+			if err != nil {
+				return err
+			}
+			n, _, connects := st.Stats()
+			return writeOK(cmd, flags, "stream", map[string]any{
+				"uri":      uri,
+				"codec":    st.Ext(),
+				"bytes":    n,
+				"connects": connects,
+				"ended":    ended,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&codec, "codec", mediaserver.CodecMP3, "Input format on stdin: wav|mp3")
+	cmd.Flags().StringVar(&title, "title", "sonos stream", "Title shown in the Sonos app")
+	return cmd
+}
+
+// superviseStream keeps the room on the stream until the input ends, ctx is
+// cancelled or another source is selected, and reports which one happened
+// ("input", "interrupted" or "source-changed"). A transport that stops while
+// the stream is still selected (the speaker gave up on the connection) is
+// played again.
+func superviseStream(ctx context.Context, c streamTransport, uri string, runErr <-chan error, timeout time.Duration) (string, error) {
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	stopSpeaker := func() {
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		if media, err := c.GetMediaInfo(sctx); err == nil && media.CurrentURI == uri {
+			_ = c.StopOrNoop(sctx)
+		}
+	}
+
+	for {
+		select {
+		case err := <-runErr:
+			stopSpeaker()
+			if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				return "", fmt.Errorf("read stdin: %w", err)
+			}
+			return "input", nil
+		case <-ctx.Done():
+			stopSpeaker()
+			return "interrupted", nil
+		case <-ticker.C:
+			media, err := c.GetMediaInfo(ctx)
+			if err != nil {
+				continue // speaker briefly unreachable; try again
+			}
+			if media.CurrentURI != uri {
+				return "source-changed", nil
+			}
+			if info, err := c.GetTransportInfo(ctx); err == nil && info.State == "STOPPED" {
+				_ = c.Play(ctx)
+			}
+		}
+	}
+}
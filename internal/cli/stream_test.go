@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+type fakeStreamTransport struct {
+	mu    sync.Mutex
+	uri   string
+	state string
+	plays int
+	stops int
+}
+
+func (f *fakeStreamTransport) Play(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.plays++
+	f.state = "PLAYING"
+	return nil
+}
+
+func (f *fakeStreamTransport) StopOrNoop(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stops++
+	f.state = "STOPPED"
+	return nil
+}
+
+func (f *fakeStreamTransport) GetTransportInfo(ctx context.Context) (sonos.TransportInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return sonos.TransportInfo{State: f.state}, nil
+}
+
+func (f *fakeStreamTransport) GetMediaInfo(ctx context.Context) (sonos.MediaInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return sonos.MediaInfo{CurrentURI: f.uri}, nil
+}
+
+func TestSuperviseStreamSourceChange(t *testing.T) {
+	orig := streamPollInterval
+	t.Cleanup(func() { streamPollInterval = orig })
+	streamPollInterval = time.Millisecond
+
+	const uri = "x-rincon-mp3radio://127.0.0.1:1234/stream/1/live.mp3"
+	c := &fakeStreamTransport{uri: "x-rincon-mp3radio://example.com/other", state: "PLAYING"}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ended, err := superviseStream(ctx, c, uri, make(chan error), time.Second)
+	if err != nil || ended != "source-changed" {
+		t.Fatalf("superviseStream = %q, %v", ended, err)
+	}
+	if c.stops != 0 {
+		t.Fatalf("must not stop another source")
+	}
+
+	c = &fakeStreamTransport{uri: uri, state: "PLAYING"}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	ended, err = superviseStream(ctx, c, uri, make(chan error), time.Second)
+	if err != nil || ended != "interrupted" || c.stops != 1 {
+		t.Fatalf("superviseStream = %q, %v (stops=%d)", ended, err, c.stops)
+	}
+}
+
+func TestStreamValidation(t *testing.T) {
+	cases := [][]string{
+		{"--codec", "mp3"},
+		{"--name", "Office", "--codec", "flac"},
+		{"--name", "Office", "extra"},
+	}
+	for _, args := range cases {
+		flags := &rootFlags{Timeout: time.Second}
+		cmd := newStreamCmd(flags)
+		cmd.Flags().StringVar(&flags.Name, "name", "", "")
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&captureWriter{})
+		cmd.SetErr(&captureWriter{})
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestE2EStreamFromStdin(t *testing.T) {
+	h := newFakeHousehold(t, "Office")
+	orig := streamPollInterval
+	t.Cleanup(func() { streamPollInterval = orig })
+	streamPollInterval = 10 * time.Millisecond
+
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { _ = pw.Close() })
+
+	root, _, err := newRootCmd()
+	if err != nil {
+		t.Fatalf("newRootCmd: %v", err)
+	}
+	var out captureWriter
+	root.SetIn(pr)
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SilenceErrors = true
+	root.SetArgs([]string{"stream", "--name", "Office", "--codec", "mp3", "--title", "Turntable", "--format", "json", "--timeout", "2s"})
+	done := make(chan error, 1)
+	go func() { done <- root.ExecuteContext(context.Background()) }()
+
+	office := h.Speaker("Office")
+	deadline := time.Now().Add(3 * time.Second)
+	for office.State().TransportState != "PLAYING" {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream did not start: %+v", office.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	st := office.State()
+	if !strings.HasPrefix(st.AVTransportURI, "x-rincon-mp3radio://") || !strings.Contains(st.AVTransportMeta, "<dc:title>Turntable</dc:title>") {
+		t.Fatalf("unexpected stream source: %+v", st)
+	}
+
+	// The speaker fetches the stream over plain HTTP and may reconnect.
+	streamURL := "http" + strings.TrimPrefix(st.AVTransportURI, "x-rincon-mp3radio")
+	for i, chunk := range []string{"frame-1", "frame-2"} {
+		resp, err := http.Get(streamURL)
+		if err != nil {
+			t.Fatalf("GET stream: %v", err)
+		}
+		if resp.Header.Get("Content-Type") != "audio/mpeg" {
+			t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+		}
+		if i > 0 {
+			// Reconnected: the backlog replays the earlier frame first.
+			readN(t, resp.Body, len("frame-1"))
+		}
+		go func() { _, _ = pw.Write([]byte(chunk)) }()
+		if got := string(readN(t, resp.Body, len(chunk))); got != chunk {
+			t.Fatalf("unexpected stream data: %q", got)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// The speaker gave up on the connection: playback is started again.
+	office.SetTransportState("STOPPED")
+	deadline = time.Now().Add(3 * time.Second)
+	for office.State().TransportState != "PLAYING" {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream not resumed: %+v", office.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_ = pw.Close() // end of input
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("stream did not finish")
+	}
+	if got := office.State().TransportState; got != "STOPPED" {
+		t.Fatalf("expected the room to stop after input ended, got %s", got)
+	}
+	for _, want := range []string{`"action": "stream"`, `"ended": "input"`, `"bytes": 14`, `"connects": 2`} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %s: %q", want, out.String())
+		}
+	}
+}
+
+func readN(t *testing.T, r io.Reader, n int) []byte {
+	t.Helper()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("read %d bytes: %v", n, err)
+	}
+	return buf
+}
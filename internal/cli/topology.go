@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/spf13/cobra"
+)
+
+func newTopologyCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Inspect the household topology (ZoneGroupState)",
+	}
+	cmd.AddCommand(newTopologyDumpCmd(flags))
+	return cmd
+}
+
+func newTopologyDumpCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dump",
+		Short: "Dump every device in the household with its details",
+		Long: `Dumps the household topology as the speakers report it: every device including invisible
+and bonded ones, with software version, boot sequence, channel maps, microphone and
+network attributes, plus the devices the household lost track of (VanishedDevices).
+
+--format json prints the full model. --format tsv prints one row per device:
+  device  groupID name ip uuid visible coordinator role softwareVersion bootSeq wirelessMode behindWifiExtender micEnabled orientation
+and one per vanished device:
+  vanished  uuid name reason`,
+		Example:      "  sonos topology dump\n  sonos topology dump --format json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tg, err := newTopologyGetter(cmd.Context(), flags.Timeout)
+			if err != nil {
+				return err
+			}
+			top, err := tg.GetTopology(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if isJSON(flags) {
+				return writeJSON(cmd, top)
+			}
+			out := cmd.OutOrStdout()
+			if isTSV(flags) {
+				for _, g := range top.Groups {
+					for _, m := range g.Members {
+						fields := []string{"device", g.ID, m.Name, m.IP, m.UUID, strconv.FormatBool(m.IsVisible), strconv.FormatBool(m.IsCoordinator), m.Role}
+						_, _ = fmt.Fprintln(out, strings.Join(append(fields, memberDetailTSV(m)...), "\t"))
+					}
+				}
+				for _, v := range top.Vanished {
+					_, _ = fmt.Fprintf(out, "vanished\t%s\t%s\t%s\n", v.UUID, v.Name, v.Reason)
+				}
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ROOM\tIP\tROLE\tVERSION\tBOOT\tNETWORK\tMIC")
+			versions := map[string]int{}
+			for _, g := range top.Groups {
+				for _, m := range g.Members {
+					role := m.Role
+					switch {
+					case m.IsCoordinator:
+						role = strings.TrimSpace("coordinator " + role)
+					case role == "" && !m.IsVisible:
+						role = "invisible"
+					}
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Name, m.IP, role, strings.Join(memberDetailPlain(m), "\t"))
+					if m.SoftwareVersion != "" {
+						versions[m.SoftwareVersion]++
+					}
+				}
+			}
+			if len(versions) > 1 {
+				_, _ = fmt.Fprintf(w, "\nFirmware differs:\t%s\n", formatVersionCounts(versions))
+			}
+			if len(top.Vanished) > 0 {
+				_, _ = fmt.Fprintln(w)
+				writeVanishedPlain(w, top.Vanished)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// memberDetailPlain renders the ZoneGroupState details shown by
+// `group status --details` and `topology dump`.
+func memberDetailPlain(m sonos.Member) []string {
+	boot := ""
+	if m.BootSeq > 0 {
+		boot = "boot " + strconv.Itoa(m.BootSeq)
+	}
+	network := "wireless " + strconv.Itoa(m.WirelessMode)
+	if m.BehindWifiExtender != 0 {
+		network += ", extender " + strconv.Itoa(m.BehindWifiExtender)
+	}
+	mic := "-"
+	if m.MicEnabled != nil {
+		mic = "mic " + onOffString(*m.MicEnabled)
+	}
+	return []string{m.SoftwareVersion, boot, network, mic}
+}
+
+func memberDetailTSV(m sonos.Member) []string {
+	mic := ""
+	if m.MicEnabled != nil {
+		mic = strconv.FormatBool(*m.MicEnabled)
+	}
+	return []string{
+		m.SoftwareVersion,
+		strconv.Itoa(m.BootSeq),
+		strconv.Itoa(m.WirelessMode),
+		strconv.Itoa(m.BehindWifiExtender),
+		mic,
+		strconv.Itoa(m.Orientation),
+	}
+}
+
+func writeVanishedPlain(w io.Writer, vanished []sonos.VanishedDevice) {
+	if len(vanished) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Vanished:")
+	for _, v := range vanished {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", v.Name, v.UUID, v.Reason)
+	}
+}
+
+func formatVersionCounts(versions map[string]int) string {
+	keys := make([]string, 0, len(versions))
+	for v := range versions {
+		keys = append(keys, v)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, v := range keys {
+		parts = append(parts, fmt.Sprintf("%s (%d)", v, versions[v]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+func TestE2ETopologyDump(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office", "Garage")
+	h.Speaker("Office").SetSoftwareVersion("86.2-65010")
+	garageUUID := h.Speaker("Garage").UUID
+	if err := h.Vanish("Garage", "powered off"); err != nil {
+		t.Fatalf("Vanish: %v", err)
+	}
+
+	out, err := runFake(t, "topology", "dump", "--format", "json")
+	if err != nil {
+		t.Fatalf("topology dump json: %v", err)
+	}
+	var top sonos.Topology
+	if err := json.Unmarshal([]byte(out), &top); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if len(top.Groups) != 2 {
+		t.Fatalf("groups = %d\n%s", len(top.Groups), out)
+	}
+	for _, g := range top.Groups {
+		m := g.Members[0]
+		if m.SoftwareVersion == "" || m.BootSeq == 0 || m.MicEnabled == nil {
+			t.Fatalf("missing details: %+v", m)
+		}
+	}
+	if len(top.Vanished) != 1 || top.Vanished[0].UUID != garageUUID || top.Vanished[0].Reason != "powered off" {
+		t.Fatalf("vanished = %+v", top.Vanished)
+	}
+
+	out, err = runFake(t, "topology", "dump")
+	if err != nil {
+		t.Fatalf("topology dump: %v", err)
+	}
+	for _, want := range []string{"VERSION", "86.2-65010", "Firmware differs:", "Vanished:", "Garage", "powered off"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("plain dump missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runFake(t, "topology", "dump", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("topology dump tsv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], "vanished\t"+garageUUID+"\tGarage\t") {
+		t.Fatalf("unexpected tsv:\n%s", out)
+	}
+	if cols := strings.Split(lines[0], "\t"); len(cols) != 14 || cols[0] != "device" {
+		t.Fatalf("unexpected device row: %q", lines[0])
+	}
+}
+
+func TestE2EGroupStatusDetails(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	if err := h.Vanish("Office", "lost"); err != nil {
+		t.Fatalf("Vanish: %v", err)
+	}
+
+	out, err := runFake(t, "group", "status", "--details")
+	if err != nil {
+		t.Fatalf("group status --details: %v", err)
+	}
+	for _, want := range []string{"Kitchen", "85.0-64110", "boot ", "mic on", "Vanished:", "Office", "lost"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("details missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runFake(t, "group", "status")
+	if err != nil {
+		t.Fatalf("group status: %v", err)
+	}
+	if strings.Contains(out, "85.0-64110") || strings.Contains(out, "Vanished:") {
+		t.Fatalf("details shown without --details:\n%s", out)
+	}
+
+	out, err = runFake(t, "group", "status", "--details", "--format", "tsv")
+	if err != nil {
+		t.Fatalf("group status --details tsv: %v", err)
+	}
+	if cols := strings.Split(strings.TrimSpace(out), "\t"); len(cols) != 13 || cols[7] != "85.0-64110" {
+		t.Fatalf("unexpected tsv: %q", out)
+	}
+}
@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/STop211650/sonoscli/internal/sonos"
 	"github.com/spf13/cobra"
 )
 
@@ -53,6 +56,10 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			v, err = c.CapVolume(ctx, v)
+			if err != nil {
+				return err
+			}
 			if err := c.SetVolume(ctx, v); err != nil {
 				return err
 			}
@@ -60,5 +67,108 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(newVolumeStepCmd(flags, "up", 1))
+	cmd.AddCommand(newVolumeStepCmd(flags, "down", -1))
+	cmd.AddCommand(newVolumeRampCmd(flags))
+	cmd.AddCommand(newVolumeFadeCmd(flags, false))
+
+	return cmd
+}
+
+const defaultVolumeStep = 5
+
+// parseVolumeStep reads the optional [step] argument of up/down.
+func parseVolumeStep(args []string) (int, error) {
+	if len(args) == 0 {
+		return defaultVolumeStep, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > 100 {
+		return 0, fmt.Errorf("invalid step %q (expected 1-100)", args[0])
+	}
+	return n, nil
+}
+
+func newVolumeStepCmd(flags *rootFlags, direction string, sign int) *cobra.Command {
+	return &cobra.Command{
+		Use:          direction + " [step]",
+		Short:        fmt.Sprintf("Turn the volume %s by a step (default %d)", direction, defaultVolumeStep),
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			step, err := parseVolumeStep(args)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			v, err := c.SetRelativeVolume(ctx, sign*step)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, strconv.Itoa(v))
+			return writeOK(cmd, flags, "volume."+direction, map[string]any{"coordinatorIP": c.IP, "volume": v})
+		},
+	}
+}
+
+func newVolumeRampCmd(flags *rootFlags) *cobra.Command {
+	var to int
+	var rampType string
+
+	cmd := &cobra.Command{
+		Use:   "ramp --to <0-100>",
+		Short: "Let the speaker ramp to a volume (RampToVolume)",
+		Long: `Asks the speaker to ramp the volume itself and returns immediately.
+
+Ramp types: sleep (steady ramp from the current volume), alarm (starts from 0 and rises
+slowly), auto (short ramp used when playback starts).`,
+		Example:      "  sonos volume ramp --name Kitchen --to 15\n  sonos volume ramp --name Bedroom --to 25 --type alarm",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("to") {
+				return errors.New("--to is required")
+			}
+			if to < 0 || to > 100 {
+				return errors.New("--to must be between 0 and 100")
+			}
+			rt, err := sonos.ParseRampType(rampType)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := coordinatorClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			to, err = c.CapVolume(ctx, to)
+			if err != nil {
+				return err
+			}
+			d, err := c.RampToVolume(ctx, rt, to)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Ramping to %d (about %s)", to, d))
+			return writeOK(cmd, flags, "volume.ramp", map[string]any{
+				"coordinatorIP": c.IP,
+				"volume":        to,
+				"rampType":      string(rt),
+				"rampSeconds":   int(d / time.Second),
+			})
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "Target volume (0-100)")
+	cmd.Flags().StringVar(&rampType, "type", "sleep", "Ramp type: sleep, alarm or auto")
 	return cmd
 }
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// volumeFadeTick is how often a fade re-evaluates the curve.
+var volumeFadeTick = 250 * time.Millisecond
+
+// volumeFadeTarget is the volume being faded: a room (RenderingControl) or a
+// whole group (GroupRenderingControl). limit, when set, maps a requested level
+// to the one a write actually reaches.
+type volumeFadeTarget struct {
+	get   func(ctx context.Context) (int, error)
+	set   func(ctx context.Context, volume int) error
+	limit func(ctx context.Context, volume int) (int, error)
+}
+
+// groupVolumeCapper is implemented by *sonos.Client; group fades stop where
+// the first limited room would have to be held back.
+type groupVolumeCapper interface {
+	CapGroupVolume(ctx context.Context, volume int) (int, error)
+}
+
+var newVolumeFadeTarget = func(ctx context.Context, flags *rootFlags, group bool) (volumeFadeTarget, error) {
+	if group {
+		c, err := newGroupAudioClient(ctx, flags)
+		if err != nil {
+			return volumeFadeTarget{}, err
+		}
+		target := volumeFadeTarget{get: c.GetGroupVolume, set: c.SetGroupVolume}
+		if lc, ok := c.(groupVolumeCapper); ok {
+			target.limit = lc.CapGroupVolume
+		}
+		return target, nil
+	}
+	c, err := coordinatorClient(ctx, flags)
+	if err != nil {
+		return volumeFadeTarget{}, err
+	}
+	return volumeFadeTarget{get: c.GetVolume, set: c.SetVolume, limit: c.CapVolume}, nil
+}
+
+func newVolumeFadeCmd(flags *rootFlags, group bool) *cobra.Command {
+	var to int
+	var over time.Duration
+	var curve string
+
+	what, action := "volume", "volume.fade"
+	if group {
+		what, action = "group volume", "group.volume.fade"
+	}
+
+	cmd := &cobra.Command{
+		Use:   "fade --to <0-100> --over <duration>",
+		Short: "Fade the " + what + " to a level over time",
+		Long: `Fades the ` + what + ` from its current level to --to, stepping from this machine.
+
+Curves: linear (even steps) or log (most of the change early, easing into the target).
+Ctrl+C stops the fade where it is, so the level never jumps.`,
+		Example:      "  sonos " + what + " fade --name Kitchen --to 5 --over 45s",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("to") {
+				return errors.New("--to is required")
+			}
+			if to < 0 || to > 100 {
+				return errors.New("--to must be between 0 and 100")
+			}
+			if over <= 0 {
+				return errors.New("--over must be positive")
+			}
+			shape, err := parseFadeCurve(curve)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			target, err := newVolumeFadeTarget(ctx, flags, group)
+			if err != nil {
+				return err
+			}
+			from, err := target.get(ctx)
+			if err != nil {
+				return err
+			}
+			if target.limit != nil {
+				if to, err = target.limit(ctx, to); err != nil {
+					return err
+				}
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Fading %s from %d to %d over %s (Ctrl+C to stop).", what, from, to, over))
+			reached, err := runVolumeFade(ctx, target, from, to, over, shape)
+			if err != nil {
+				return err
+			}
+			cancelled := reached != to
+			if cancelled {
+				writePlainLine(cmd, flags, fmt.Sprintf("Fade stopped at %d.", reached))
+			}
+			return writeOK(cmd, flags, action, map[string]any{
+				"from":      from,
+				"to":        to,
+				"volume":    reached,
+				"curve":     curve,
+				"cancelled": cancelled,
+			})
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "Target volume (0-100)")
+	cmd.Flags().DurationVar(&over, "over", 10*time.Second, "Fade duration (e.g. 45s, 2m)")
+	cmd.Flags().StringVar(&curve, "curve", "linear", "Fade curve: linear or log")
+	return cmd
+}
+
+// parseFadeCurve maps a --curve value to progress (0..1) -> fraction of the
+// change applied (0..1).
+func parseFadeCurve(s string) (func(float64) float64, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "linear", "":
+		return func(p float64) float64 { return p }, nil
+	case "log":
+		return func(p float64) float64 { return math.Log10(1 + 9*p) }, nil
+	}
+	return nil, fmt.Errorf("invalid curve %q (expected linear or log)", s)
+}
+
+// runVolumeFade moves the volume from -> to over the given duration, only
+// sending a request when the integer level changes. When ctx ends early it
+// stops where it is and makes sure the speaker holds the last level it was
+// given (a cancelled request may not have landed). It returns that level.
+func runVolumeFade(ctx context.Context, target volumeFadeTarget, from, to int, over time.Duration, curve func(float64) float64) (int, error) {
+	current := from
+	settle := func() (int, error) {
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		return current, target.set(sctx, current)
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(volumeFadeTick)
+	defer ticker.Stop()
+	for {
+		p := float64(time.Since(start)) / float64(over)
+		if p > 1 {
+			p = 1
+		}
+		level := from + int(math.Round(float64(to-from)*curve(p)))
+		if level != current {
+			if err := target.set(ctx, level); err != nil {
+				if ctx.Err() != nil {
+					return settle()
+				}
+				return current, err
+			}
+			current = level
+		}
+		if p >= 1 {
+			return current, nil
+		}
+		select {
+		case <-ctx.Done():
+			return settle()
+		case <-ticker.C:
+		}
+	}
+}
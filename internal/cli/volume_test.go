@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeFadeVolume struct {
+	mu     sync.Mutex
+	volume int
+	sets   []int
+}
+
+func (f *fakeFadeVolume) target() volumeFadeTarget {
+	return volumeFadeTarget{
+		get: func(ctx context.Context) (int, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.volume, nil
+		},
+		set: func(ctx context.Context, volume int) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.volume = volume
+			f.sets = append(f.sets, volume)
+			return nil
+		},
+	}
+}
+
+func withFastVolumeFade(t *testing.T) {
+	t.Helper()
+	orig := volumeFadeTick
+	t.Cleanup(func() { volumeFadeTick = orig })
+	volumeFadeTick = 5 * time.Millisecond
+}
+
+func TestRunVolumeFadeReachesTargetMonotonically(t *testing.T) {
+	withFastVolumeFade(t)
+	for _, curve := range []string{"linear", "log"} {
+		shape, err := parseFadeCurve(curve)
+		if err != nil {
+			t.Fatalf("parseFadeCurve(%s): %v", curve, err)
+		}
+		f := &fakeFadeVolume{volume: 30}
+		got, err := runVolumeFade(context.Background(), f.target(), 30, 10, 100*time.Millisecond, shape)
+		if err != nil || got != 10 || f.volume != 10 {
+			t.Fatalf("%s fade = %d, %v (volume %d)", curve, got, err, f.volume)
+		}
+		prev := 30
+		for _, v := range f.sets {
+			if v >= prev {
+				t.Fatalf("%s fade not strictly decreasing: %v", curve, f.sets)
+			}
+			prev = v
+		}
+	}
+	if _, err := parseFadeCurve("cubic"); err == nil {
+		t.Fatalf("expected invalid curve error")
+	}
+}
+
+func TestRunVolumeFadeCancelStopsInPlace(t *testing.T) {
+	withFastVolumeFade(t)
+	shape, _ := parseFadeCurve("linear")
+	f := &fakeFadeVolume{volume: 0}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	got, err := runVolumeFade(ctx, f.target(), 0, 100, 10*time.Second, shape)
+	if err != nil {
+		t.Fatalf("runVolumeFade: %v", err)
+	}
+	if got >= 10 || f.volume != got {
+		t.Fatalf("expected fade to stop early at the last level, got %d (volume %d)", got, f.volume)
+	}
+}
+
+func TestVolumeFadeValidatesFlags(t *testing.T) {
+	flags := &rootFlags{Name: "Kitchen", Timeout: 2 * time.Second}
+	for _, args := range [][]string{
+		{"--over", "1s"},
+		{"--to", "101"},
+		{"--to", "5", "--over", "0s"},
+		{"--to", "5", "--curve", "cubic"},
+	} {
+		cmd := newVolumeFadeCmd(flags, false)
+		cmd.SetArgs(args)
+		cmd.SetOut(newDiscardWriter())
+		cmd.SetErr(newDiscardWriter())
+		cmd.SilenceErrors = true
+		if err := cmd.ExecuteContext(context.Background()); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestGroupVolumeFadeUsesGroupTarget(t *testing.T) {
+	withFastVolumeFade(t)
+	f := &fakeFadeVolume{volume: 20}
+	orig := newVolumeFadeTarget
+	t.Cleanup(func() { newVolumeFadeTarget = orig })
+	var sawGroup bool
+	newVolumeFadeTarget = func(ctx context.Context, flags *rootFlags, group bool) (volumeFadeTarget, error) {
+		sawGroup = group
+		return f.target(), nil
+	}
+
+	cmd := newGroupVolumeCmd(&rootFlags{Name: "Kitchen", Timeout: 2 * time.Second, Format: formatJSON})
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"fade", "--to", "24", "--over", "30ms", "--curve", "log"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("group volume fade: %v", err)
+	}
+	if !sawGroup || f.volume != 24 || !strings.Contains(out.String(), `"action": "group.volume.fade"`) {
+		t.Fatalf("unexpected fade: group=%v volume=%d out=%s", sawGroup, f.volume, out.String())
+	}
+}
+
+func TestE2EVolumeStepsRampAndFade(t *testing.T) {
+	withFastVolumeFade(t)
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	kitchen, office := h.Speaker("Kitchen"), h.Speaker("Office")
+	kitchen.SetVolume(20)
+
+	out, err := runFake(t, "volume", "up", "--name", "Kitchen")
+	if err != nil || strings.TrimSpace(out) != "25" {
+		t.Fatalf("volume up = %q, %v", out, err)
+	}
+	if _, err := runFake(t, "volume", "down", "--name", "Kitchen", "8"); err != nil {
+		t.Fatalf("volume down: %v", err)
+	}
+	if v := kitchen.State().Volume; v != 17 {
+		t.Fatalf("volume after down = %d", v)
+	}
+	if _, err := runFake(t, "volume", "down", "--name", "Kitchen", "0"); err == nil {
+		t.Fatalf("expected invalid step error")
+	}
+
+	if _, err := runFake(t, "volume", "ramp", "--name", "Kitchen", "--to", "30", "--type", "auto"); err != nil {
+		t.Fatalf("volume ramp: %v", err)
+	}
+	if st := kitchen.State(); st.Volume != 30 || st.LastRampType != "AUTOPLAY_RAMP_TYPE" {
+		t.Fatalf("unexpected ramp state: %d %q", st.Volume, st.LastRampType)
+	}
+	if _, err := runFake(t, "volume", "ramp", "--name", "Kitchen", "--to", "30", "--type", "slow"); err == nil {
+		t.Fatalf("expected ramp type error")
+	}
+
+	out, err = runFake(t, "volume", "fade", "--name", "Kitchen", "--to", "12", "--over", "40ms")
+	if err != nil || !strings.Contains(out, "from 30 to 12") {
+		t.Fatalf("volume fade = %q, %v", out, err)
+	}
+	if v := kitchen.State().Volume; v != 12 {
+		t.Fatalf("volume after fade = %d", v)
+	}
+
+	office.SetVolume(32)
+	if _, err := runFake(t, "group", "join", "--name", "Office", "--to", "Kitchen"); err != nil {
+		t.Fatalf("group join: %v", err)
+	}
+	out, err = runFake(t, "group", "volume", "up", "--name", "Kitchen", "4")
+	if err != nil || strings.TrimSpace(out) != "26" {
+		t.Fatalf("group volume up = %q, %v", out, err)
+	}
+	if kitchen.State().Volume != 16 || office.State().Volume != 36 {
+		t.Fatalf("group step did not shift members: %d %d", kitchen.State().Volume, office.State().Volume)
+	}
+}
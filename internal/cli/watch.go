@@ -2,14 +2,13 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,166 +18,330 @@ import (
 )
 
 type watchEvent struct {
-	Time    time.Time         `json:"time"`
-	Service string            `json:"service"`
-	SID     string            `json:"sid"`
-	Seq     string            `json:"seq"`
-	Vars    map[string]string `json:"vars"`
+	Time         time.Time         `json:"time"`
+	Room         string            `json:"room,omitempty"`
+	GroupID      string            `json:"groupId,omitempty"`
+	Service      string            `json:"service"`
+	SID          string            `json:"sid"`
+	Seq          string            `json:"seq"`
+	Vars         map[string]string `json:"vars"`
+	Missed       int               `json:"missed,omitempty"`
+	Resubscribed bool              `json:"resubscribed,omitempty"`
+	// Changes are the typed changes (see sonos.RoomEvent) the event made
+	// to the room's state, each with its kind under "type".
+	Changes []map[string]any `json:"changes,omitempty"`
 }
 
-func listenIPForRemote(remoteIP string) (string, error) {
-	conn, err := net.Dial("udp", net.JoinHostPort(remoteIP, "1900"))
-	if err != nil {
-		return "", err
+// watchChanges flattens typed room events for JSON output.
+func watchChanges(events []sonos.RoomEvent) []map[string]any {
+	out := make([]map[string]any, 0, len(events))
+	for _, e := range events {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		m := map[string]any{}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			continue
+		}
+		m["type"] = e.Kind()
+		out = append(out, m)
 	}
-	defer conn.Close()
-	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
-	if !ok || udpAddr.IP == nil {
-		return "", errors.New("could not determine local listen ip")
+	return out
+}
+
+func newWatchEvent(ev sonos.Event) watchEvent {
+	vars := ev.Vars
+	if ev.Err != nil {
+		vars = map[string]string{"parse_error": ev.Err.Error()}
+	}
+	return watchEvent{
+		Time:         ev.Time,
+		Service:      string(ev.Service),
+		SID:          ev.SID,
+		Seq:          strconv.FormatUint(uint64(ev.Seq), 10),
+		Vars:         vars,
+		Missed:       ev.Missed,
+		Resubscribed: ev.Resubscribed,
 	}
-	return udpAddr.IP.String(), nil
+}
+
+// closeEventManager unsubscribes everything once the command is done, even
+// after Ctrl+C.
+func closeEventManager(ctx context.Context, em *sonos.EventManager, timeout time.Duration) {
+	cctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	em.Close(cctx)
 }
 
 func newWatchCmd(flags *rootFlags) *cobra.Command {
 	var duration time.Duration
+	var all bool
+	var serviceNames []string
+	var changesOnly bool
+	var onExprs []string
+	hookOpts := watchHookOptions{retries: 3, timeout: 30 * time.Second, maxConcurrent: 4}
 
 	cmd := &cobra.Command{
-		Use:          "watch",
-		Short:        "Watch live Sonos events",
-		Long:         "Subscribes to AVTransport and RenderingControl events and prints changes as they arrive (Ctrl+C to stop). Requires that Sonos speakers can reach your machine on the chosen callback port (firewall may prompt).",
+		Use:   "watch",
+		Short: "Watch live Sonos events",
+		Long: `Subscribes to AVTransport and RenderingControl events and prints changes as they arrive (Ctrl+C to stop).
+--all watches every group's coordinator; --service adds more services (grouprenderingcontrol, queue,
+contentdirectory, zonegrouptopology, alarmclock, deviceproperties; repeatable or comma-separated).
+Topology and alarm events are the same on every speaker and are subscribed once.
+
+Each event is tagged with the room and group ID it came from. When grouping changes, subscriptions
+follow: new coordinators are subscribed and speakers that stopped coordinating are dropped.
+Subscriptions are renewed automatically and re-established (with backoff) if a speaker reboots; lost
+events are reported. --changes-only prints only the variables that changed since the previous event
+of the same room and service, and skips events that changed nothing. JSON events also list the typed
+changes (transport_state_changed, track_changed with parsed metadata, volume_changed, mute_changed,
+play_mode_changed, queue_changed, topology_changed) under "changes".
+
+--on filters events (repeatable, all must match): key=value, key!=value or key, where key is a
+variable (transport_state, volume_master, ...), room, group, service or change (a change type).
+Combine with --changes-only to act on transitions only.
+
+--exec runs a shell command per event with the event JSON on stdin and SONOS_ROOM, SONOS_GROUP,
+SONOS_EVENT (service), SONOS_CHANGES (change types) and SONOS_STATE (the room's transport state) in
+the environment; its output goes to stderr. --webhook POSTs the same JSON, retrying network errors,
+429 and 5xx with backoff. --debounce delivers only the last event of a burst per room and service;
+hooks run on --max-concurrent workers (1 keeps them in order).
+
+Requires that Sonos speakers can reach your machine on the chosen callback port (firewall may
+prompt).
+
+TSV columns: time, service, SID, variable, value, room, group ID.`,
+		Example:      "  sonos watch --name \"Kitchen\"\n  sonos watch --all --format json\n  sonos watch --all --service queue,zonegrouptopology\n  sonos watch --all --changes-only --on transport_state=PLAYING --exec ./on-play.sh\n  sonos watch --name \"Living Room\" --webhook http://localhost:8123/api/webhook/sonos --debounce 2s",
+		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := validateTarget(flags); err != nil {
+			if !all {
+				if err := validateTarget(flags); err != nil {
+					return err
+				}
+			}
+			services := []sonos.EventService{sonos.ServiceAVTransport, sonos.ServiceRenderingControl}
+			printed := map[sonos.EventService]bool{}
+			for _, s := range services {
+				printed[s] = true
+			}
+			for _, list := range serviceNames {
+				for _, name := range strings.Split(list, ",") {
+					if strings.TrimSpace(name) == "" {
+						continue
+					}
+					s, err := sonos.ParseEventService(name)
+					if err != nil {
+						return err
+					}
+					if !printed[s] {
+						services = append(services, s)
+						printed[s] = true
+					}
+				}
+			}
+			filters, err := parseWatchFilters(onExprs)
+			if err != nil {
 				return err
 			}
 
 			ctx := cmd.Context()
 			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 			defer stop()
+			// Ctrl+C aborts running hooks; reaching --duration lets them
+			// finish.
+			hookCtx := ctx
 			if duration > 0 {
 				var cancel context.CancelFunc
 				ctx, cancel = context.WithTimeout(ctx, duration)
 				defer cancel()
 			}
 
-			c, err := coordinatorClient(ctx, flags)
-			if err != nil {
-				return err
-			}
-
-			listenIP, err := listenIPForRemote(c.IP)
-			if err != nil {
-				return err
-			}
-			ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
-			if err != nil {
-				return err
-			}
-			defer ln.Close()
-			port := ln.Addr().(*net.TCPAddr).Port
-
-			callbackURL := fmt.Sprintf("http://%s:%d/notify", listenIP, port)
-
-			events := make(chan watchEvent, 128)
-			var sidToService sync.Map // sid -> service name
-
-			mux := http.NewServeMux()
-			mux.HandleFunc("/notify", func(w http.ResponseWriter, r *http.Request) {
-				if r.Method != "NOTIFY" {
-					w.WriteHeader(http.StatusMethodNotAllowed)
-					return
+			// Without a topology (single room only) the watcher cannot follow
+			// grouping changes and just subscribes to the target.
+			var top sonos.Topology
+			var c *sonos.Client
+			var remoteIP string
+			follow := true
+			w := &householdWatcher{flags: flags, errOut: cmd.ErrOrStderr(), all: all, subs: map[string][]*sonos.EventSubscription{}}
+			if all {
+				var err error
+				top, err = householdTopology(ctx, flags)
+				if err != nil {
+					return err
 				}
-				sid := strings.TrimSpace(r.Header.Get("SID"))
-				seq := strings.TrimSpace(r.Header.Get("SEQ"))
-				body, _ := io.ReadAll(r.Body)
-				_ = r.Body.Close()
-
-				service := "unknown"
-				if v, ok := sidToService.Load(sid); ok {
-					service = v.(string)
+				targets := watchTargets(top, true, "")
+				if len(targets) == 0 {
+					return errors.New("no coordinators found")
 				}
-
-				vars, err := sonos.ParseEvent(body)
+				for ip := range targets {
+					if remoteIP == "" || ip < remoteIP {
+						remoteIP = ip
+					}
+				}
+			} else {
+				var err error
+				c, err = coordinatorClient(ctx, flags)
 				if err != nil {
-					vars = map[string]string{"parse_error": err.Error()}
+					return err
 				}
-
-				select {
-				case events <- watchEvent{
-					Time:    time.Now().UTC(),
-					Service: service,
-					SID:     sid,
-					Seq:     seq,
-					Vars:    vars,
-				}:
-				default:
-					// Drop if the consumer is too slow.
+				top, err = c.GetTopology(ctx)
+				if err == nil {
+					var m sonos.Member
+					m, err = resolveMember(top, flags.Name, flags.IP)
+					w.roomUUID = m.UUID
 				}
-
-				w.WriteHeader(http.StatusOK)
-			})
-
-			srv := &http.Server{
-				Handler:           mux,
-				ReadHeaderTimeout: 5 * time.Second,
+				follow = err == nil
+				remoteIP = c.IP
 			}
-			go func() { _ = srv.Serve(ln) }()
-			defer func() { _ = srv.Shutdown(context.Background()) }()
+			w.groupServices, w.householdServices = splitWatchServices(services, follow)
 
-			avtSub, err := c.SubscribeAVTransport(ctx, callbackURL, 0)
+			em, err := sonos.NewEventManager(remoteIP, sonos.EventManagerOptions{})
 			if err != nil {
 				return err
 			}
-			defer func() { _ = c.Unsubscribe(context.Background(), avtSub) }()
-			sidToService.Store(avtSub.SID, "avtransport")
+			defer closeEventManager(ctx, em, flags.Timeout)
+			w.em = em
 
-			rcSub, err := c.SubscribeRenderingControl(ctx, callbackURL, 0)
-			if err != nil {
-				return err
+			if follow {
+				if err := w.reconcile(ctx, top); err != nil && len(w.subs) == 0 {
+					return err
+				}
+			} else {
+				subs, err := w.subscribe(ctx, c.IP, append(w.groupServices, w.householdServices...))
+				if err != nil {
+					return err
+				}
+				w.subs[c.IP] = subs
+			}
+
+			var hooks *watchHooks
+			if hookOpts.exec != "" || hookOpts.webhook != "" {
+				// Hooks report on stderr from their workers; keep that from
+				// interleaving with the event output.
+				var mu sync.Mutex
+				cmd.SetOut(lockedWriter{mu: &mu, w: cmd.OutOrStdout()})
+				cmd.SetErr(lockedWriter{mu: &mu, w: cmd.ErrOrStderr()})
+				hooks = newWatchHooks(hookCtx, hookOpts, cmd.ErrOrStderr())
+				defer hooks.close()
 			}
-			defer func() { _ = c.Unsubscribe(context.Background(), rcSub) }()
-			sidToService.Store(rcSub.SID, "renderingcontrol")
 
 			if !isJSON(flags) && !isTSV(flags) {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching events (callback %s). Press Ctrl+C to stop.\n", callbackURL)
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching events (callback %s). Press Ctrl+C to stop.\n", em.CallbackURL())
 			}
 
+			// Room state is kept per subscribed speaker.
+			states := map[string]*sonos.RoomState{}
 			for {
 				select {
 				case <-ctx.Done():
 					return nil
-				case ev := <-events:
-					if isJSON(flags) {
-						_ = writeJSONLine(cmd, ev)
-						continue
+				case sev, ok := <-em.Events():
+					if !ok {
+						return nil
 					}
-					if isTSV(flags) {
-						keys := make([]string, 0, len(ev.Vars))
-						for k := range ev.Vars {
-							keys = append(keys, k)
-						}
-						sort.Strings(keys)
-						for _, k := range keys {
-							_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\n", ev.Time.Format(time.RFC3339Nano), ev.Service, ev.SID, k, ev.Vars[k])
+					var next *sonos.Topology
+					if zgs := sev.Vars["zone_group_state"]; sev.Service == sonos.ServiceZoneGroupTopology && zgs != "" {
+						if top, err := sonos.ParseZoneGroupState(zgs); err == nil {
+							if follow {
+								_ = w.reconcile(ctx, top)
+							}
+							next = &top
 						}
+					}
+					if !printed[sev.Service] {
 						continue
 					}
 
-					keys := make([]string, 0, len(ev.Vars))
-					for k := range ev.Vars {
-						keys = append(keys, k)
+					state := states[sev.IP]
+					if state == nil {
+						state = sonos.NewRoomState()
+						states[sev.IP] = state
 					}
-					sort.Strings(keys)
-					parts := make([]string, 0, len(keys))
-					for _, k := range keys {
-						parts = append(parts, fmt.Sprintf("%s=%s", k, ev.Vars[k]))
+					upd := state.Apply(sev)
+					ev := newWatchEvent(sev)
+					ev.Changes = watchChanges(upd.Events)
+					if changesOnly && sev.Err == nil {
+						if len(upd.Changed) == 0 && ev.Missed == 0 && !ev.Resubscribed {
+							continue
+						}
+						ev.Vars = upd.Changed
+					}
+					if _, ok := ev.Vars["zone_group_state"]; ok && next != nil {
+						ev.Vars = topologyEventVars(ev.Vars, *next)
+					}
+					tag := w.tag(sev.IP)
+					ev.Room, ev.GroupID = tag.Room, tag.GroupID
+					if !matchWatchFilters(filters, ev) {
+						continue
+					}
+					writeWatchEvent(cmd, flags, ev)
+					if hooks != nil {
+						hooks.handle(ev, state.Value(sonos.ServiceAVTransport, "transport_state"))
 					}
-					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s [%s] %s\n", ev.Time.Format(time.RFC3339), ev.Service, strings.Join(parts, " "))
 				}
 			}
 		},
 	}
 
 	cmd.Flags().DurationVar(&duration, "duration", 0, "Stop after this duration (0 = until Ctrl+C)")
+	cmd.Flags().BoolVar(&all, "all", false, "Watch every group in the household")
+	cmd.Flags().BoolVar(&changesOnly, "changes-only", false, "Only print variables that changed since the previous event")
+	cmd.Flags().StringSliceVar(&serviceNames, "service", nil, "Also subscribe to these services (repeatable, comma-separated)")
+	cmd.Flags().StringArrayVar(&onExprs, "on", nil, "Only handle events matching key=value, key!=value or key (repeatable)")
+	cmd.Flags().StringVar(&hookOpts.exec, "exec", "", "Run this shell command per event (event JSON on stdin)")
+	cmd.Flags().StringVar(&hookOpts.webhook, "webhook", "", "POST each event as JSON to this URL")
+	cmd.Flags().IntVar(&hookOpts.retries, "webhook-retries", hookOpts.retries, "Retries for failed webhook deliveries")
+	cmd.Flags().DurationVar(&hookOpts.timeout, "hook-timeout", hookOpts.timeout, "Time limit per hook command or webhook request")
+	cmd.Flags().DurationVar(&hookOpts.debounce, "debounce", 0, "Deliver only the last event of a burst per room and service")
+	cmd.Flags().IntVar(&hookOpts.maxConcurrent, "max-concurrent", hookOpts.maxConcurrent, "Hooks running at the same time")
 	return cmd
 }
+
+// topologyEventVars replaces the raw ZoneGroupState document with a
+// readable summary of the groups.
+func topologyEventVars(vars map[string]string, top sonos.Topology) map[string]string {
+	out := make(map[string]string, len(vars))
+	for k, v := range vars {
+		if k != "zone_group_state" {
+			out[k] = v
+		}
+	}
+	out["groups"] = topologySummary(top)
+	return out
+}
+
+func writeWatchEvent(cmd *cobra.Command, flags *rootFlags, ev watchEvent) {
+	if isJSON(flags) {
+		_ = writeJSONLine(cmd, ev)
+		return
+	}
+	keys := make([]string, 0, len(ev.Vars))
+	for k := range ev.Vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if isTSV(flags) {
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ev.Time.Format(time.RFC3339Nano), ev.Service, ev.SID, k, ev.Vars[k], ev.Room, ev.GroupID)
+		}
+		return
+	}
+
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, ev.Vars[k]))
+	}
+	switch {
+	case ev.Resubscribed:
+		parts = append(parts, "(resubscribed)")
+	case ev.Missed > 0:
+		parts = append(parts, fmt.Sprintf("(missed %d events)", ev.Missed))
+	}
+	room := ""
+	if ev.Room != "" {
+		room = ev.Room + " "
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s[%s] %s\n", ev.Time.Format(time.RFC3339), room, ev.Service, strings.Join(parts, " "))
+}
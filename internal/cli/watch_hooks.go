@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchHookQueue bounds the deliveries waiting for a free worker; beyond it
+// events are dropped with a warning instead of piling up behind a slow hook.
+const watchHookQueue = 256
+
+// webhookRetryBackoff is the delay before the first webhook retry; it
+// doubles with each attempt.
+var webhookRetryBackoff = time.Second
+
+// watchFilter is one --on expression: key=value, key!=value or just key.
+type watchFilter struct {
+	key    string
+	value  string
+	negate bool
+	exists bool
+}
+
+func parseWatchFilters(exprs []string) ([]watchFilter, error) {
+	out := make([]watchFilter, 0, len(exprs))
+	for _, expr := range exprs {
+		expr = strings.TrimSpace(expr)
+		var f watchFilter
+		switch {
+		case strings.Contains(expr, "!="):
+			k, v, _ := strings.Cut(expr, "!=")
+			f = watchFilter{key: k, value: v, negate: true}
+		case strings.Contains(expr, "="):
+			k, v, _ := strings.Cut(expr, "=")
+			f = watchFilter{key: k, value: v}
+		default:
+			f = watchFilter{key: expr, exists: true}
+		}
+		f.key, f.value = strings.TrimSpace(f.key), strings.TrimSpace(f.value)
+		if f.key == "" {
+			return nil, fmt.Errorf("invalid --on %q: want key=value, key!=value or key", expr)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// values returns what key refers to in ev: room, group, service, change (the
+// typed change kinds) or an event variable.
+func (f watchFilter) values(ev watchEvent) ([]string, bool) {
+	switch f.key {
+	case "room":
+		return []string{ev.Room}, true
+	case "group":
+		return []string{ev.GroupID}, true
+	case "service":
+		return []string{ev.Service}, true
+	case "change":
+		kinds := watchChangeKinds(ev)
+		return kinds, len(kinds) > 0
+	}
+	v, ok := ev.Vars[f.key]
+	return []string{v}, ok
+}
+
+func (f watchFilter) match(ev watchEvent) bool {
+	values, ok := f.values(ev)
+	if f.exists {
+		return ok
+	}
+	found := false
+	for _, v := range values {
+		if ok && strings.EqualFold(v, f.value) {
+			found = true
+			break
+		}
+	}
+	return found != f.negate
+}
+
+func matchWatchFilters(filters []watchFilter, ev watchEvent) bool {
+	for _, f := range filters {
+		if !f.match(ev) {
+			return false
+		}
+	}
+	return true
+}
+
+func watchChangeKinds(ev watchEvent) []string {
+	kinds := make([]string, 0, len(ev.Changes))
+	for _, c := range ev.Changes {
+		if k, ok := c["type"].(string); ok {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// lockedWriter serializes writes from hook workers with the event output.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+type watchHookOptions struct {
+	exec          string
+	webhook       string
+	retries       int
+	timeout       time.Duration
+	debounce      time.Duration
+	maxConcurrent int
+}
+
+type watchHookJob struct {
+	ev    watchEvent
+	state string
+}
+
+type pendingWatchHook struct {
+	job   watchHookJob
+	timer *time.Timer
+}
+
+// watchHooks runs --exec and --webhook for events, debounced per room and
+// service, on a bounded pool of workers. Ending ctx kills running commands,
+// aborts requests and retries, and skips what is still queued.
+type watchHooks struct {
+	ctx    context.Context
+	opts   watchHookOptions
+	errOut io.Writer
+	client *http.Client
+	jobs   chan watchHookJob
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[string]*pendingWatchHook
+	closed  bool
+}
+
+func newWatchHooks(ctx context.Context, opts watchHookOptions, errOut io.Writer) *watchHooks {
+	if opts.maxConcurrent <= 0 {
+		opts.maxConcurrent = 1
+	}
+	opts.retries = max(opts.retries, 0)
+	h := &watchHooks{
+		ctx:     ctx,
+		opts:    opts,
+		errOut:  errOut,
+		client:  &http.Client{Timeout: opts.timeout},
+		jobs:    make(chan watchHookJob, watchHookQueue),
+		pending: map[string]*pendingWatchHook{},
+	}
+	for range opts.maxConcurrent {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			for job := range h.jobs {
+				h.run(job)
+			}
+		}()
+	}
+	return h
+}
+
+// handle delivers ev now or, with a debounce, once its room and service have
+// been quiet for the debounce period; only the latest event is delivered.
+func (h *watchHooks) handle(ev watchEvent, state string) {
+	job := watchHookJob{ev: ev, state: state}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if h.opts.debounce <= 0 {
+		h.enqueueLocked(job)
+		return
+	}
+	key := ev.Room + "\x00" + ev.Service
+	if p := h.pending[key]; p != nil {
+		p.job = job
+		p.timer.Reset(h.opts.debounce)
+		return
+	}
+	p := &pendingWatchHook{job: job}
+	p.timer = time.AfterFunc(h.opts.debounce, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.pending[key] != p || h.closed {
+			return
+		}
+		delete(h.pending, key)
+		h.enqueueLocked(p.job)
+	})
+	h.pending[key] = p
+}
+
+func (h *watchHooks) enqueueLocked(job watchHookJob) {
+	select {
+	case h.jobs <- job:
+	default:
+		_, _ = fmt.Fprintf(h.errOut, "hook: queue full, dropping %s event for %s\n", job.ev.Service, job.ev.Room)
+	}
+}
+
+// close delivers the events still being debounced and waits for the running
+// hooks.
+func (h *watchHooks) close() {
+	h.mu.Lock()
+	for key, p := range h.pending {
+		p.timer.Stop()
+		h.enqueueLocked(p.job)
+		delete(h.pending, key)
+	}
+	h.closed = true
+	close(h.jobs)
+	h.mu.Unlock()
+	h.wg.Wait()
+}
+
+func (h *watchHooks) run(job watchHookJob) {
+	if h.ctx.Err() != nil {
+		return
+	}
+	payload, err := json.Marshal(job.ev)
+	if err != nil {
+		return
+	}
+	if h.opts.exec != "" {
+		if err := h.runExec(job, payload); err != nil && h.ctx.Err() == nil {
+			_, _ = fmt.Fprintf(h.errOut, "hook %s: %v\n", job.ev.Room, err)
+		}
+	}
+	if h.opts.webhook != "" {
+		if err := h.postWebhook(job, payload); err != nil && h.ctx.Err() == nil {
+			_, _ = fmt.Fprintf(h.errOut, "webhook %s: %v\n", job.ev.Room, err)
+		}
+	}
+}
+
+func (h *watchHooks) runExec(job watchHookJob, payload []byte) error {
+	ctx, cancel := context.WithTimeout(h.ctx, h.opts.timeout)
+	defer cancel()
+	name, args := "sh", []string{"-c", h.opts.exec}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", h.opts.exec}
+	}
+	c := exec.CommandContext(ctx, name, args...)
+	c.Stdin = bytes.NewReader(payload)
+	c.Stdout = h.errOut
+	c.Stderr = h.errOut
+	// Children of the shell may hold its output open after it is killed;
+	// don't wait on them.
+	c.WaitDelay = time.Second
+	c.Env = append(os.Environ(),
+		"SONOS_ROOM="+job.ev.Room,
+		"SONOS_GROUP="+job.ev.GroupID,
+		"SONOS_EVENT="+job.ev.Service,
+		"SONOS_CHANGES="+strings.Join(watchChangeKinds(job.ev), ","),
+		"SONOS_STATE="+job.state,
+	)
+	return c.Run()
+}
+
+// postWebhook POSTs payload, retrying network errors, 429 and 5xx with
+// exponential backoff.
+func (h *watchHooks) postWebhook(job watchHookJob, payload []byte) error {
+	backoff := webhookRetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= h.opts.retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-h.ctx.Done():
+				timer.Stop()
+				return h.ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		retry, err := h.postWebhookOnce(job, payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (h *watchHooks) postWebhookOnce(job watchHookJob, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodPost, h.opts.webhook, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sonos-Room", job.ev.Room)
+	req.Header.Set("X-Sonos-Event", job.ev.Service)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = errors.New(resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchFilters(t *testing.T) {
+	filters, err := parseWatchFilters([]string{"transport_state=playing", "room!=Office", "volume_master"})
+	if err != nil {
+		t.Fatalf("parseWatchFilters: %v", err)
+	}
+	ev := watchEvent{Room: "Kitchen", Service: "avtransport", Vars: map[string]string{"transport_state": "PLAYING", "volume_master": "20"}}
+	if !matchWatchFilters(filters, ev) {
+		t.Fatalf("expected match")
+	}
+	ev.Room = "Office"
+	if matchWatchFilters(filters, ev) {
+		t.Fatalf("room!=Office matched Office")
+	}
+	ev.Room = "Kitchen"
+	delete(ev.Vars, "volume_master")
+	if matchWatchFilters(filters, ev) {
+		t.Fatalf("matched without volume_master")
+	}
+
+	change, _ := parseWatchFilters([]string{"change=track_changed"})
+	ev.Changes = []map[string]any{{"type": "transport_state_changed"}, {"type": "track_changed"}}
+	if !matchWatchFilters(change, ev) {
+		t.Fatalf("change filter did not match")
+	}
+	if _, err := parseWatchFilters([]string{"=x"}); err == nil {
+		t.Fatalf("expected error for empty key")
+	}
+}
+
+func TestWatchHooksDebounceAndWebhookRetry(t *testing.T) {
+	orig := webhookRetryBackoff
+	t.Cleanup(func() { webhookRetryBackoff = orig })
+	webhookRetryBackoff = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	var attempts int
+	var bodies []watchEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		var ev watchEvent
+		if err := json.Unmarshal(raw, &ev); err != nil || r.Header.Get("X-Sonos-Room") != ev.Room {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bodies = append(bodies, ev)
+	}))
+	t.Cleanup(srv.Close)
+
+	var errOut syncBuffer
+	h := newWatchHooks(context.Background(), watchHookOptions{webhook: srv.URL, retries: 2, timeout: time.Second, debounce: 50 * time.Millisecond, maxConcurrent: 2}, &errOut)
+	for _, v := range []string{"10", "11", "12"} {
+		h.handle(watchEvent{Room: "Kitchen", Service: "renderingcontrol", Vars: map[string]string{"volume_master": v}}, "PLAYING")
+	}
+	h.handle(watchEvent{Room: "Office", Service: "renderingcontrol", Vars: map[string]string{"volume_master": "5"}}, "STOPPED")
+	time.Sleep(200 * time.Millisecond)
+	h.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 || len(bodies) != 2 {
+		t.Fatalf("attempts=%d bodies=%+v stderr=%q", attempts, bodies, errOut.String())
+	}
+	for _, ev := range bodies {
+		if ev.Room == "Kitchen" && ev.Vars["volume_master"] != "12" {
+			t.Fatalf("debounce delivered %+v, want the last event", ev)
+		}
+	}
+}
+
+func TestWatchHooksWebhookGivesUpOnClientError(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	var errOut syncBuffer
+	h := newWatchHooks(context.Background(), watchHookOptions{webhook: srv.URL, retries: 3, timeout: time.Second}, &errOut)
+	h.handle(watchEvent{Room: "Kitchen", Service: "avtransport"}, "")
+	h.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 1 || !strings.Contains(errOut.String(), "webhook Kitchen: 404 Not Found") {
+		t.Fatalf("attempts=%d stderr=%q", attempts, errOut.String())
+	}
+}
+
+func TestWatchHooksStopWhenContextEnds(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook script uses sh")
+	}
+	orig := webhookRetryBackoff
+	t.Cleanup(func() { webhookRetryBackoff = orig })
+	webhookRetryBackoff = time.Minute
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var errOut syncBuffer
+	h := newWatchHooks(ctx, watchHookOptions{exec: "sleep 60", webhook: srv.URL, retries: 5, timeout: time.Minute, maxConcurrent: 2}, &errOut)
+	h.handle(watchEvent{Room: "Kitchen", Service: "avtransport"}, "")
+	h.handle(watchEvent{Room: "Office", Service: "avtransport"}, "")
+	time.Sleep(100 * time.Millisecond)
+	h.handle(watchEvent{Room: "Den", Service: "avtransport"}, "") // still queued
+
+	start := time.Now()
+	cancel()
+	h.close()
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("close took %s after cancel", d)
+	}
+	if errOut.String() != "" {
+		t.Fatalf("unexpected hook errors after cancel: %q", errOut.String())
+	}
+}
+
+func TestE2EWatchExecHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook script uses sh")
+	}
+	h := newFakeHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	dir := t.TempDir()
+	envFile, eventsFile := filepath.Join(dir, "env.txt"), filepath.Join(dir, "events.jsonl")
+	hook := `echo "$SONOS_ROOM $SONOS_EVENT $SONOS_STATE $SONOS_CHANGES" >> ` + envFile + `; cat >> ` + eventsFile + `; echo >> ` + eventsFile
+
+	var wg sync.WaitGroup
+	var out string
+	var runErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		out, runErr = runFake(t, "watch", "--name", "Kitchen", "--changes-only", "--on", "transport_state=PLAYING",
+			"--exec", hook, "--duration", "1500ms", "--format", "json")
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(kitchen.Subscriptions()) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriptions = %v", kitchen.Subscriptions())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	kitchen.SetVolume(30) // filtered out
+	kitchen.SetTransportState("PLAYING")
+
+	wg.Wait()
+	if runErr != nil {
+		t.Fatalf("watch: %v (%s)", runErr, out)
+	}
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("hook did not run: %v (%s)", err, out)
+	}
+	if got := strings.TrimSpace(string(env)); got != "Kitchen avtransport PLAYING transport_state_changed" {
+		t.Fatalf("hook env = %q", got)
+	}
+	raw, _ := os.ReadFile(eventsFile)
+	var ev watchEvent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(raw))), &ev); err != nil || ev.Vars["transport_state"] != "PLAYING" {
+		t.Fatalf("hook stdin = %q (%v)", raw, err)
+	}
+	if strings.Contains(out, "volume_master") {
+		t.Fatalf("filtered event printed: %s", out)
+	}
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+// watchTag is what an event from a speaker is labelled with.
+type watchTag struct {
+	Room    string
+	GroupID string
+}
+
+// watchTargets returns the coordinators to subscribe to, keyed by IP: every
+// group's coordinator with all, otherwise the coordinator of the group
+// containing roomUUID.
+func watchTargets(top sonos.Topology, all bool, roomUUID string) map[string]watchTag {
+	out := map[string]watchTag{}
+	for _, g := range top.Groups {
+		if g.Coordinator.IP == "" {
+			continue
+		}
+		if !all {
+			found := false
+			for _, m := range g.Members {
+				if m.UUID == roomUUID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		out[g.Coordinator.IP] = watchTag{Room: g.Coordinator.Name, GroupID: g.ID}
+	}
+	return out
+}
+
+// watchTags labels every speaker IP in the topology with its room and group.
+func watchTags(top sonos.Topology) map[string]watchTag {
+	out := map[string]watchTag{}
+	for _, g := range top.Groups {
+		for _, m := range g.Members {
+			if m.IP != "" {
+				out[m.IP] = watchTag{Room: m.Name, GroupID: g.ID}
+			}
+		}
+	}
+	return out
+}
+
+// topologySummary renders the groups as "Kitchen + Office; Living Room",
+// coordinator first.
+func topologySummary(top sonos.Topology) string {
+	groups := make([]string, 0, len(top.Groups))
+	for _, g := range top.Groups {
+		names := []string{g.Coordinator.Name}
+		var others []string
+		for _, m := range g.Members {
+			if m.IsVisible && m.UUID != g.Coordinator.UUID {
+				others = append(others, m.Name)
+			}
+		}
+		sort.Strings(others)
+		groups = append(groups, strings.Join(append(names, others...), " + "))
+	}
+	sort.Strings(groups)
+	return strings.Join(groups, "; ")
+}
+
+// householdWatcher keeps an EventManager's subscriptions in line with the
+// topology: the per-group services on each watched coordinator and the
+// household-wide ones (topology, alarms) on a single anchor speaker.
+type householdWatcher struct {
+	em       *sonos.EventManager
+	flags    *rootFlags
+	errOut   io.Writer
+	all      bool
+	roomUUID string
+
+	groupServices     []sonos.EventService
+	householdServices []sonos.EventService
+
+	subs       map[string][]*sonos.EventSubscription // by coordinator IP
+	anchor     string
+	anchorSubs []*sonos.EventSubscription
+	tags       map[string]watchTag
+}
+
+// splitWatchServices separates the requested services into those subscribed
+// per coordinator and those subscribed once. follow adds ZoneGroupTopology,
+// which the watcher needs to track grouping changes.
+func splitWatchServices(services []sonos.EventService, follow bool) (group, household []sonos.EventService) {
+	seen := map[sonos.EventService]bool{}
+	add := func(s sonos.EventService) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		if s.HouseholdWide() {
+			household = append(household, s)
+		} else {
+			group = append(group, s)
+		}
+	}
+	for _, s := range services {
+		add(s)
+	}
+	if follow {
+		add(sonos.ServiceZoneGroupTopology)
+	}
+	return group, household
+}
+
+func (w *householdWatcher) tag(ip string) watchTag {
+	return w.tags[ip]
+}
+
+// reconcile subscribes to coordinators that appeared in top and drops those
+// that are gone or no longer coordinate. Failures are reported on errOut and
+// retried with the next topology change; the first error is also returned.
+func (w *householdWatcher) reconcile(ctx context.Context, top sonos.Topology) error {
+	w.tags = watchTags(top)
+	want := watchTargets(top, w.all, w.roomUUID)
+	var firstErr error
+	report := func(ip string, err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		name := w.tags[ip].Room
+		if name == "" {
+			name = ip
+		}
+		_, _ = fmt.Fprintf(w.errOut, "%s: %v\n", name, err)
+	}
+
+	for ip, subs := range w.subs {
+		if _, ok := want[ip]; ok {
+			continue
+		}
+		for _, s := range subs {
+			if err := w.em.Unsubscribe(ctx, s); err != nil {
+				report(ip, err)
+			}
+		}
+		delete(w.subs, ip)
+	}
+	ips := make([]string, 0, len(want))
+	for ip := range want {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	for _, ip := range ips {
+		if _, ok := w.subs[ip]; ok {
+			continue
+		}
+		subs, err := w.subscribe(ctx, ip, w.groupServices)
+		if err != nil {
+			report(ip, err)
+			continue
+		}
+		w.subs[ip] = subs
+	}
+
+	if len(w.householdServices) == 0 {
+		return firstErr
+	}
+	if w.anchor != "" {
+		if _, ok := top.FindByIP(w.anchor); ok {
+			return firstErr
+		}
+		for _, s := range w.anchorSubs {
+			_ = w.em.Unsubscribe(ctx, s)
+		}
+		w.anchor, w.anchorSubs = "", nil
+	}
+	for _, ip := range ips {
+		subs, err := w.subscribe(ctx, ip, w.householdServices)
+		if err != nil {
+			report(ip, err)
+			continue
+		}
+		w.anchor, w.anchorSubs = ip, subs
+		break
+	}
+	return firstErr
+}
+
+// subscribe subscribes ip to every service, undoing the ones that succeeded
+// if one fails.
+func (w *householdWatcher) subscribe(ctx context.Context, ip string, services []sonos.EventService) ([]*sonos.EventSubscription, error) {
+	c := newSonosClient(ip, w.flags.Timeout)
+	subs := make([]*sonos.EventSubscription, 0, len(services))
+	for _, service := range services {
+		s, err := w.em.Subscribe(ctx, c, service)
+		if err != nil {
+			for _, done := range subs {
+				_ = w.em.Unsubscribe(ctx, done)
+			}
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, nil
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+const watchTestZGS = `<ZoneGroupState><ZoneGroups>` +
+	`<ZoneGroup Coordinator="RINCON_A" ID="RINCON_A:1">` +
+	`<ZoneGroupMember UUID="RINCON_A" Location="http://10.0.0.1:1400/xml/device_description.xml" ZoneName="Kitchen"/>` +
+	`<ZoneGroupMember UUID="RINCON_B" Location="http://10.0.0.2:1400/xml/device_description.xml" ZoneName="Office"/>` +
+	`</ZoneGroup>` +
+	`<ZoneGroup Coordinator="RINCON_C" ID="RINCON_C:7">` +
+	`<ZoneGroupMember UUID="RINCON_C" Location="http://10.0.0.3:1400/xml/device_description.xml" ZoneName="Living Room"/>` +
+	`</ZoneGroup>` +
+	`</ZoneGroups></ZoneGroupState>`
+
+func TestWatchTargetsAndTags(t *testing.T) {
+	top, err := sonos.ParseZoneGroupState(watchTestZGS)
+	if err != nil {
+		t.Fatalf("ParseZoneGroupState: %v", err)
+	}
+
+	all := watchTargets(top, true, "")
+	if len(all) != 2 || all["10.0.0.1"] != (watchTag{Room: "Kitchen", GroupID: "RINCON_A:1"}) || all["10.0.0.3"].Room != "Living Room" {
+		t.Fatalf("unexpected targets: %+v", all)
+	}
+	// A grouped member is watched through its coordinator.
+	one := watchTargets(top, false, "RINCON_B")
+	if len(one) != 1 || one["10.0.0.1"].GroupID != "RINCON_A:1" {
+		t.Fatalf("unexpected targets for Office: %+v", one)
+	}
+
+	tags := watchTags(top)
+	if tags["10.0.0.2"] != (watchTag{Room: "Office", GroupID: "RINCON_A:1"}) {
+		t.Fatalf("unexpected tag: %+v", tags["10.0.0.2"])
+	}
+	if got := topologySummary(top); got != "Kitchen + Office; Living Room" {
+		t.Fatalf("topologySummary = %q", got)
+	}
+}
+
+func TestSplitWatchServices(t *testing.T) {
+	group, household := splitWatchServices([]sonos.EventService{
+		sonos.ServiceAVTransport, sonos.ServiceAlarmClock, sonos.ServiceQueue, sonos.ServiceAVTransport,
+	}, true)
+	if !slices.Equal(group, []sonos.EventService{sonos.ServiceAVTransport, sonos.ServiceQueue}) {
+		t.Fatalf("group services = %v", group)
+	}
+	if !slices.Equal(household, []sonos.EventService{sonos.ServiceAlarmClock, sonos.ServiceZoneGroupTopology}) {
+		t.Fatalf("household services = %v", household)
+	}
+}
+
+func TestWatchRejectsUnknownService(t *testing.T) {
+	newFakeHousehold(t, "Kitchen")
+	_, err := runFake(t, "watch", "--name", "Kitchen", "--service", "avtransport,bogus", "--duration", "10ms")
+	if err == nil || !strings.Contains(err.Error(), `unknown event service "bogus"`) {
+		t.Fatalf("expected unknown service error, got %v", err)
+	}
+}
+
+func TestE2EWatchAllFollowsGrouping(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen", "Office")
+	kitchen, office := h.Speaker("Kitchen"), h.Speaker("Office")
+
+	var wg sync.WaitGroup
+	var out string
+	var runErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		out, runErr = runFake(t, "watch", "--all", "--service", "zonegrouptopology,queue", "--duration", "2500ms", "--format", "json")
+	}()
+
+	waitSubs := func(s interface{ Subscriptions() []string }, want func([]string) bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !want(s.Subscriptions()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("subscriptions = %v", s.Subscriptions())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	hasAVT := func(subs []string) bool { return slices.Contains(subs, "AVTransport") }
+	waitSubs(kitchen, hasAVT)
+	waitSubs(office, hasAVT)
+	waitSubs(office, func(subs []string) bool { return slices.Contains(subs, "Queue") })
+
+	// Office joins Kitchen: it stops coordinating, so its group
+	// subscriptions are dropped.
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	waitSubs(office, func(subs []string) bool { return !hasAVT(subs) })
+
+	// And back out: subscribed again.
+	if err := newSonosClient(office.IP, time.Second).LeaveGroup(context.Background()); err != nil {
+		t.Fatalf("LeaveGroup: %v", err)
+	}
+	waitSubs(office, hasAVT)
+	time.Sleep(100 * time.Millisecond)
+	office.SetVolume(41)
+
+	wg.Wait()
+	if runErr != nil {
+		t.Fatalf("watch: %v", runErr)
+	}
+	var sawGrouped, sawVolume bool
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var ev watchEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("bad line %q: %v", line, err)
+		}
+		if ev.Room == "" || ev.GroupID == "" {
+			t.Fatalf("untagged event: %s", line)
+		}
+		if ev.Service == "zonegrouptopology" && ev.Vars["groups"] == "Kitchen + Office" {
+			sawGrouped = true
+		}
+		if ev.Service == "renderingcontrol" && ev.Room == "Office" && ev.Vars["volume_master"] == "41" {
+			sawVolume = true
+		}
+	}
+	if !sawGrouped || !sawVolume {
+		t.Fatalf("missing events (grouped %v, volume %v): %s", sawGrouped, sawVolume, out)
+	}
+	if subs := kitchen.Subscriptions(); len(subs) != 0 {
+		t.Fatalf("kitchen subscriptions left: %v", subs)
+	}
+}
+
+func TestE2EWatchChangesOnly(t *testing.T) {
+	h := newFakeHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(sonostest.Track{URI: "http://example.com/1.mp3", Title: "First", Artist: "Band", Duration: "0:03:00"})
+
+	var wg sync.WaitGroup
+	var out string
+	var runErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		out, runErr = runFake(t, "watch", "--name", "Kitchen", "--changes-only", "--duration", "1500ms", "--format", "json")
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(kitchen.Subscriptions()) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriptions = %v", kitchen.Subscriptions())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	kitchen.SetVolume(35)
+	kitchen.SetVolume(35) // no change: nothing printed
+	kitchen.SetTransportState("PLAYING")
+
+	wg.Wait()
+	if runErr != nil {
+		t.Fatalf("watch: %v", runErr)
+	}
+	var events []watchEvent
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var ev watchEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("bad line %q: %v", line, err)
+		}
+		events = append(events, ev)
+	}
+	// Initial AVTransport and RenderingControl state, then one event per change.
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %s", len(events), out)
+	}
+	var volume, transport *watchEvent
+	for i := range events[2:] {
+		switch ev := &events[2+i]; ev.Service {
+		case "renderingcontrol":
+			volume = ev
+		case "avtransport":
+			transport = ev
+		}
+	}
+	if volume == nil || len(volume.Vars) != 1 || volume.Vars["volume_master"] != "35" {
+		t.Fatalf("unexpected volume event: %+v", volume)
+	}
+	if c := volume.Changes; len(c) != 1 || c[0]["type"] != "volume_changed" || c[0]["volume"] != float64(35) || c[0]["previous"] != float64(20) {
+		t.Fatalf("unexpected volume changes: %+v", c)
+	}
+	if transport == nil || len(transport.Vars) != 1 || transport.Vars["transport_state"] != "PLAYING" {
+		t.Fatalf("unexpected transport event: %+v", transport)
+	}
+	if c := transport.Changes; len(c) != 1 || c[0]["type"] != "transport_state_changed" || c[0]["previous"] != "STOPPED" {
+		t.Fatalf("unexpected transport changes: %+v", c)
+	}
+	initial := events[0]
+	if initial.Service != "avtransport" {
+		initial = events[1]
+	}
+	var track map[string]any
+	for _, c := range initial.Changes {
+		if c["type"] == "track_changed" {
+			track = c
+		}
+	}
+	if item, _ := track["item"].(map[string]any); item["title"] != "First" || item["artist"] != "Band" {
+		t.Fatalf("unexpected initial changes: %+v", initial.Changes)
+	}
+}
@@ -0,0 +1,131 @@
+package history
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Track is the current track of a room as reported by its AVTransport events.
+type Track struct {
+	Title    string
+	Artist   string
+	Album    string
+	Service  string
+	URI      string
+	Duration string
+}
+
+// Recorder turns per-room track and transport changes into plays. Listening
+// time only accrues while a room is playing; a play is finished when the
+// track changes or on Flush, and kept if it was heard for at least
+// MinListen.
+type Recorder struct {
+	MinListen time.Duration
+	rooms     map[string]*roomPlayback
+}
+
+type roomPlayback struct {
+	playing bool
+	since   time.Time // when playing last became true
+	current *Play     // nil when the room has no track worth recording
+	heard   time.Duration
+}
+
+func NewRecorder(minListen time.Duration) *Recorder {
+	return &Recorder{MinListen: minListen, rooms: map[string]*roomPlayback{}}
+}
+
+func (r *Recorder) room(name string) *roomPlayback {
+	rp := r.rooms[name]
+	if rp == nil {
+		rp = &roomPlayback{}
+		r.rooms[name] = rp
+	}
+	return rp
+}
+
+// SetTrack records that room (grouped with members) moved to t, returning
+// the play it finished, if any. Tracks without a title (line-in, TV, empty
+// queue) are not recorded.
+func (r *Recorder) SetTrack(room string, members []string, t Track, now time.Time) (Play, bool) {
+	rp := r.room(room)
+	done, ok := r.finish(rp, now)
+	if t.Title == "" {
+		rp.current = nil
+		return done, ok
+	}
+	rp.current = &Play{
+		Room:     room,
+		Members:  slices.Clone(members),
+		Title:    t.Title,
+		Artist:   t.Artist,
+		Album:    t.Album,
+		Service:  t.Service,
+		URI:      t.URI,
+		Duration: t.Duration,
+	}
+	if rp.playing {
+		rp.current.Start = now
+	}
+	return done, ok
+}
+
+// SetPlaying records whether room is playing.
+func (r *Recorder) SetPlaying(room string, playing bool, now time.Time) {
+	rp := r.room(room)
+	if playing == rp.playing {
+		return
+	}
+	if playing {
+		rp.since = now
+		if rp.current != nil && rp.current.Start.IsZero() {
+			rp.current.Start = now
+		}
+	} else {
+		rp.heard += now.Sub(rp.since)
+	}
+	rp.playing = playing
+}
+
+// Flush finishes the play in progress in every room, e.g. when recording
+// stops, ordered by start time.
+func (r *Recorder) Flush(now time.Time) []Play {
+	var out []Play
+	for _, rp := range r.rooms {
+		if p, ok := r.finish(rp, now); ok {
+			out = append(out, p)
+		}
+		rp.current = nil
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// finish closes the current play of rp and reports whether it is long enough
+// to keep. Playback continues into whatever comes next.
+func (r *Recorder) finish(rp *roomPlayback, now time.Time) (Play, bool) {
+	heard := rp.heard
+	if rp.playing {
+		heard += now.Sub(rp.since)
+		rp.since = now
+	}
+	rp.heard = 0
+	if rp.current == nil || rp.current.Start.IsZero() || heard <= 0 || heard < r.MinListen {
+		return Play{}, false
+	}
+	p := *rp.current
+	p.ListenedSeconds = int(heard.Round(time.Second) / time.Second)
+	return p, true
+}
+
+// Finish ends the play in progress in room, e.g. when it joins another group,
+// and forgets the room.
+func (r *Recorder) Finish(room string, now time.Time) (Play, bool) {
+	rp := r.rooms[room]
+	if rp == nil {
+		return Play{}, false
+	}
+	delete(r.rooms, room)
+	return r.finish(rp, now)
+}
@@ -0,0 +1,76 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecorderCountsOnlyPlayingTime(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+	r := NewRecorder(30 * time.Second)
+
+	// Loaded while stopped: the play starts when playback does.
+	if _, ok := r.SetTrack("Kitchen", []string{"Office"}, Track{Title: "First", Artist: "Band", Service: "Spotify"}, at(0)); ok {
+		t.Fatalf("nothing should finish yet")
+	}
+	r.SetPlaying("Kitchen", true, at(10))
+	r.SetPlaying("Kitchen", false, at(70))
+	r.SetPlaying("Kitchen", true, at(100))
+	p, ok := r.SetTrack("Kitchen", nil, Track{Title: "Second", Artist: "Band"}, at(130))
+	if !ok {
+		t.Fatalf("expected the first track to be recorded")
+	}
+	if p.Title != "First" || p.Room != "Kitchen" || p.Members[0] != "Office" || p.Service != "Spotify" || !p.Start.Equal(at(10)) || p.ListenedSeconds != 90 {
+		t.Fatalf("unexpected play: %+v", p)
+	}
+
+	// Skipped after 5s: below MinListen.
+	if p, ok := r.SetTrack("Kitchen", nil, Track{Title: "Third"}, at(135)); ok {
+		t.Fatalf("skipped track recorded: %+v", p)
+	}
+
+	flushed := r.Flush(at(200))
+	if len(flushed) != 1 || flushed[0].Title != "Third" || !flushed[0].Start.Equal(at(135)) || flushed[0].ListenedSeconds != 65 {
+		t.Fatalf("unexpected flush: %+v", flushed)
+	}
+	if again := r.Flush(at(300)); len(again) != 0 {
+		t.Fatalf("flush twice: %+v", again)
+	}
+}
+
+func TestRecorderIgnoresUntitledAndUnplayedTracks(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder(0)
+	r.SetTrack("Living Room", nil, Track{Title: "Never played"}, t0)
+	if p, ok := r.SetTrack("Living Room", nil, Track{URI: "x-sonos-htastream:RINCON_1:spdif"}, t0.Add(time.Minute)); ok {
+		t.Fatalf("unplayed track recorded: %+v", p)
+	}
+	r.SetPlaying("Living Room", true, t0.Add(time.Minute))
+	if got := r.Flush(t0.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("untitled source recorded: %+v", got)
+	}
+}
+
+func TestRecorderFinishForgetsRoom(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder(time.Second)
+	r.SetPlaying("Office", true, t0)
+	r.SetTrack("Office", nil, Track{Title: "Song"}, t0)
+	p, ok := r.Finish("Office", t0.Add(45*time.Second))
+	if !ok || p.ListenedSeconds != 45 {
+		t.Fatalf("unexpected finish: %+v ok=%v", p, ok)
+	}
+	if _, ok := r.Finish("Office", t0.Add(time.Minute)); ok {
+		t.Fatalf("finished twice")
+	}
+	if got := r.Flush(t0.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("forgotten room flushed: %+v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"sort"
+	"strings"
+)
+
+// Count is one row of a top-artists or top-tracks report.
+type Count struct {
+	Title           string `json:"title,omitempty"`
+	Artist          string `json:"artist"`
+	Plays           int    `json:"plays"`
+	ListenedSeconds int    `json:"listenedSeconds"`
+}
+
+// TopArtists ranks artists by plays, then listening time. Plays without an
+// artist are left out; n <= 0 returns every artist.
+func TopArtists(plays []Play, n int) []Count {
+	return top(plays, n, func(p Play) (string, Count) {
+		if p.Artist == "" {
+			return "", Count{}
+		}
+		return strings.ToLower(p.Artist), Count{Artist: p.Artist}
+	})
+}
+
+// TopTracks ranks tracks (title and artist) like TopArtists.
+func TopTracks(plays []Play, n int) []Count {
+	return top(plays, n, func(p Play) (string, Count) {
+		return strings.ToLower(p.Title) + "\x00" + strings.ToLower(p.Artist), Count{Title: p.Title, Artist: p.Artist}
+	})
+}
+
+// top groups plays by key (skipping empty keys); rows are labelled as the
+// first play of each group.
+func top(plays []Play, n int, key func(Play) (string, Count)) []Count {
+	byKey := map[string]*Count{}
+	var order []string
+	for _, p := range plays {
+		k, label := key(p)
+		if k == "" {
+			continue
+		}
+		c := byKey[k]
+		if c == nil {
+			c = &label
+			byKey[k] = c
+			order = append(order, k)
+		}
+		c.Plays++
+		c.ListenedSeconds += p.ListenedSeconds
+	}
+	out := make([]Count, 0, len(order))
+	for _, k := range order {
+		out = append(out, *byKey[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Plays != out[j].Plays {
+			return out[i].Plays > out[j].Plays
+		}
+		return out[i].ListenedSeconds > out[j].ListenedSeconds
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopArtistsAndTracks(t *testing.T) {
+	t.Parallel()
+
+	plays := []Play{
+		{Title: "Song A", Artist: "Band", ListenedSeconds: 100},
+		{Title: "Song B", Artist: "Other", ListenedSeconds: 300},
+		{Title: "song a", Artist: "band", ListenedSeconds: 50},
+		{Title: "Song C", Artist: "Band", ListenedSeconds: 10},
+		{Title: "Untagged stream", ListenedSeconds: 900},
+	}
+
+	artists := TopArtists(plays, 0)
+	want := []Count{{Artist: "Band", Plays: 3, ListenedSeconds: 160}, {Artist: "Other", Plays: 1, ListenedSeconds: 300}}
+	if !reflect.DeepEqual(artists, want) {
+		t.Fatalf("TopArtists = %+v", artists)
+	}
+
+	tracks := TopTracks(plays, 2)
+	wantTracks := []Count{
+		{Title: "Song A", Artist: "Band", Plays: 2, ListenedSeconds: 150},
+		{Title: "Untagged stream", Plays: 1, ListenedSeconds: 900},
+	}
+	if !reflect.DeepEqual(tracks, wantTracks) {
+		t.Fatalf("TopTracks = %+v", tracks)
+	}
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Play is one track played in a room, as recorded by `sonos history record`.
+type Play struct {
+	Room string `json:"room"`
+	// Members lists the other rooms grouped with Room during the play.
+	Members []string `json:"members,omitempty"`
+	Title   string   `json:"title"`
+	Artist  string   `json:"artist,omitempty"`
+	Album   string   `json:"album,omitempty"`
+	Service string   `json:"service,omitempty"`
+	URI     string   `json:"uri,omitempty"`
+	// Start is when the track started playing; ListenedSeconds counts only
+	// the time it was actually playing (pauses excluded).
+	Start           time.Time `json:"start"`
+	ListenedSeconds int       `json:"listenedSeconds"`
+	// Duration is the track length reported by the speaker (H:MM:SS).
+	Duration string `json:"duration,omitempty"`
+}
+
+// Listened returns ListenedSeconds as a duration.
+func (p Play) Listened() time.Duration {
+	return time.Duration(p.ListenedSeconds) * time.Second
+}
+
+// InRoom reports whether room heard the play, as its room or a grouped member.
+func (p Play) InRoom(room string) bool {
+	if strings.EqualFold(p.Room, room) {
+		return true
+	}
+	for _, m := range p.Members {
+		if strings.EqualFold(m, room) {
+			return true
+		}
+	}
+	return false
+}
+
+type Store interface {
+	Append(play Play) error
+	// List returns the plays that started at or after since, oldest first.
+	List(since time.Time) ([]Play, error)
+}
+
+// FileStore keeps plays in an append-only JSON Lines file.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore() (*FileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: filepath.Join(dir, "sonoscli", "history.jsonl")}, nil
+}
+
+func (s *FileStore) Append(play Play) error {
+	if strings.TrimSpace(play.Room) == "" {
+		return errors.New("play room is required")
+	}
+	b, err := json.Marshal(play)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	// One write per line keeps concurrent recorders from interleaving.
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileStore) List(since time.Time) ([]Play, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var plays []Play
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var p Play
+		// A recorder killed mid-write leaves a truncated line; skip it
+		// rather than losing the rest of the history.
+		if err := json.Unmarshal([]byte(line), &p); err != nil {
+			continue
+		}
+		if p.Start.Before(since) {
+			continue
+		}
+		plays = append(plays, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(plays, func(i, j int) bool { return plays[i].Start.Before(plays[j].Start) })
+	return plays, nil
+}
@@ -0,0 +1,78 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreAppendAndList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s := &FileStore{path: filepath.Join(dir, "sub", "history.jsonl")}
+
+	if plays, err := s.List(time.Time{}); err != nil || len(plays) != 0 {
+		t.Fatalf("expected empty history, got plays=%v err=%v", plays, err)
+	}
+
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, title := range []string{"Second", "First", "Third"} {
+		start := base.Add(time.Duration([]int{2, 1, 3}[i]) * time.Hour)
+		if err := s.Append(Play{Room: "Kitchen", Title: title, Artist: "Band", Start: start, ListenedSeconds: 60}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := s.Append(Play{Title: "No room"}); err == nil {
+		t.Fatalf("expected error for a play without a room")
+	}
+
+	plays, err := s.List(time.Time{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(plays) != 3 || plays[0].Title != "First" || plays[2].Title != "Third" {
+		t.Fatalf("unexpected plays: %+v", plays)
+	}
+	if plays[0].Listened() != time.Minute {
+		t.Fatalf("Listened = %s", plays[0].Listened())
+	}
+
+	recent, err := s.List(base.Add(2 * time.Hour))
+	if err != nil || len(recent) != 2 || recent[0].Title != "Second" {
+		t.Fatalf("unexpected plays since: %+v err=%v", recent, err)
+	}
+
+	st, err := os.Stat(s.path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if st.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 perms, got %o", st.Mode().Perm())
+	}
+}
+
+func TestFileStoreSkipsTruncatedLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	data := `{"room":"Kitchen","title":"Song","start":"2026-10-01T12:00:00Z","listenedSeconds":90}` + "\n" + `{"room":"Kitc`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := &FileStore{path: path}
+	plays, err := s.List(time.Time{})
+	if err != nil || len(plays) != 1 || plays[0].Title != "Song" {
+		t.Fatalf("unexpected plays: %+v err=%v", plays, err)
+	}
+}
+
+func TestPlayInRoom(t *testing.T) {
+	t.Parallel()
+
+	p := Play{Room: "Kitchen", Members: []string{"Office"}}
+	if !p.InRoom("kitchen") || !p.InRoom("Office") || p.InRoom("Bedroom") {
+		t.Fatalf("unexpected InRoom results for %+v", p)
+	}
+}
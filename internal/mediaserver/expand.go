@@ -0,0 +1,114 @@
+package mediaserver
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsRemote reports whether an expanded entry is a URL rather than a local path.
+func IsRemote(entry string) bool {
+	return strings.Contains(entry, "://")
+}
+
+// Expand resolves paths into playable entries, in order. Directories are
+// walked recursively (sorted, supported audio files only) and .m3u/.m3u8
+// playlists are read; playlist entries may be relative paths or URLs.
+func Expand(paths []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range paths {
+		entries, err := expand(p, seen)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entries...)
+	}
+	return out, nil
+}
+
+func expand(p string, seen map[string]bool) ([]string, error) {
+	if IsRemote(p) {
+		return []string{p}, nil
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return expandDir(p)
+	}
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".m3u", ".m3u8":
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		if seen[abs] {
+			return nil, fmt.Errorf("playlist includes itself: %s", p)
+		}
+		seen[abs] = true
+		defer delete(seen, abs)
+		return expandM3U(abs, seen)
+	}
+	if ContentType(p) == "" {
+		return nil, fmt.Errorf("unsupported audio file %q (mp3, flac, aac, m4a, wav, ogg)", p)
+	}
+	return []string{p}, nil
+}
+
+func expandDir(dir string) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ContentType(path) != "" && !strings.HasPrefix(d.Name(), ".") {
+			out = append(out, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// WalkDir is lexical already; sort case-insensitively for stable album order.
+	sort.SliceStable(out, func(i, j int) bool { return strings.ToLower(out[i]) < strings.ToLower(out[j]) })
+	return out, nil
+}
+
+func expandM3U(path string, seen map[string]bool) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	base := filepath.Dir(path)
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !IsRemote(line) && !filepath.IsAbs(line) {
+			line = filepath.Join(base, filepath.FromSlash(line))
+		}
+		entries, err := expand(line, seen)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		out = append(out, entries...)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,63 @@
+package mediaserver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandDirectoriesAndPlaylists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mk := func(rel string) string {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	a2 := mk("album/02 b.flac")
+	a1 := mk("album/01 a.mp3")
+	mk("album/cover.jpg")
+	mk("album/.hidden/x.mp3")
+	cd2 := mk("album/CD2/01 c.ogg")
+	single := mk("single.wav")
+
+	m3u := filepath.Join(dir, "list.m3u8")
+	if err := os.WriteFile(m3u, []byte("#EXTM3U\n#EXTINF:1,Single\nsingle.wav\n\nhttp://example.com/live.mp3\nalbum/01 a.mp3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Expand([]string{filepath.Join(dir, "album"), m3u})
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	want := []string{a1, a2, cd2, single, "http://example.com/live.mp3", a1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expand:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	loop := filepath.Join(dir, "loop.m3u")
+	if err := os.WriteFile(loop, []byte("loop.m3u\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	txt := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(txt, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{loop, txt, filepath.Join(dir, "missing.mp3")} {
+		if _, err := Expand([]string{p}); err == nil {
+			t.Fatalf("expected error for %s", p)
+		}
+	}
+}
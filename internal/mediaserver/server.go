@@ -0,0 +1,225 @@
+// Package mediaserver serves local audio files to Sonos speakers over HTTP.
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+var contentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".aac":  "audio/aac",
+	".m4a":  "audio/mp4",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+}
+
+// ContentType returns the MIME type for a supported audio file, or "".
+func ContentType(path string) string {
+	return contentTypes[strings.ToLower(filepath.Ext(path))]
+}
+
+// Track is a file published by the server.
+type Track struct {
+	ID          string
+	Path        string
+	URL         string
+	ArtURL      string
+	ContentType string
+	Tags        Tags
+}
+
+// Meta returns DIDL-Lite metadata for the track.
+func (t *Track) Meta() string {
+	return sonos.BuildTrackMeta(sonos.TrackMeta{
+		URI:         t.URL,
+		MimeType:    t.ContentType,
+		Title:       t.Tags.Title,
+		Artist:      t.Tags.Artist,
+		Album:       t.Tags.Album,
+		AlbumArtURI: t.ArtURL,
+	})
+}
+
+// Server is an HTTP server publishing individual files (with Range support),
+// their embedded cover art and live streams. Only files added with Add and
+// streams added with AddStream are reachable.
+type Server struct {
+	baseURL string
+	srv     *http.Server
+
+	mu      sync.RWMutex
+	tracks  map[string]*Track
+	streams map[string]*Stream
+	nextID  int
+}
+
+// Start listens on listenIP (use the LAN-facing address speakers can reach)
+// with an ephemeral port.
+func Start(listenIP string) (*Server, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	s := &Server{
+		baseURL: fmt.Sprintf("http://%s", net.JoinHostPort(listenIP, strconv.Itoa(port))),
+		tracks:  map[string]*Track{},
+		streams: map[string]*Stream{},
+	}
+	s.srv = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() { _ = s.srv.Serve(ln) }()
+	return s, nil
+}
+
+// BaseURL is the server's root URL, e.g. http://192.168.1.20:53211.
+func (s *Server) BaseURL() string {
+	return s.baseURL
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+// Add publishes a local audio file and reads its tags.
+func (s *Server) Add(path string) (*Track, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, errors.New("not a file: " + path)
+	}
+	ct := ContentType(abs)
+	if ct == "" {
+		return nil, fmt.Errorf("unsupported audio file %q (mp3, flac, aac, m4a, wav, ogg)", path)
+	}
+	tags, err := ReadTags(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	t := &Track{
+		ID:          id,
+		Path:        abs,
+		URL:         s.baseURL + "/media/" + id + "/" + url.PathEscape(filepath.Base(abs)),
+		ContentType: ct,
+		Tags:        tags,
+	}
+	if len(tags.Picture) > 0 {
+		t.ArtURL = s.baseURL + "/art/" + id
+	}
+	s.tracks[id] = t
+	return t, nil
+}
+
+// AddStream publishes a live stream and returns its URL.
+func (s *Server) AddStream(st *Stream) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	s.streams[id] = st
+	return s.baseURL + "/stream/" + id + "/live." + st.Ext()
+}
+
+func (s *Server) track(id string) (*Track, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tracks[id]
+	return t, ok
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "media":
+		s.serveMedia(w, r, parts[1])
+	case len(parts) == 2 && parts[0] == "art":
+		s.serveArt(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "stream":
+		s.mu.RLock()
+		st, ok := s.streams[parts[1]]
+		s.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		st.ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveMedia(w http.ResponseWriter, r *http.Request, id string) {
+	t, ok := s.track(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(t.Path)
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusGone)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusGone)
+		return
+	}
+	w.Header().Set("Content-Type", t.ContentType)
+	// ServeContent handles Range/If-Range and HEAD.
+	http.ServeContent(w, r, filepath.Base(t.Path), fi.ModTime(), f)
+}
+
+func (s *Server) serveArt(w http.ResponseWriter, r *http.Request, id string) {
+	t, ok := s.track(id)
+	if !ok || len(t.Tags.Picture) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	ct := t.Tags.PictureMIME
+	if ct == "" {
+		ct = http.DetectContentType(t.Tags.Picture)
+	}
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Content-Length", strconv.Itoa(len(t.Tags.Picture)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(t.Tags.Picture)
+}
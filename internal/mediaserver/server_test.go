@@ -0,0 +1,105 @@
+package mediaserver
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServerServesRangesAndArt(t *testing.T) {
+	t.Parallel()
+
+	apic := append([]byte{0}, []byte("image/png\x00\x03\x00PNG!")...)
+	tag := id3Tag(3, id3Frame("TIT2", []byte("\x00Title")), id3Frame("APIC", apic))
+	audio := append(tag, []byte("0123456789")...)
+	path := writeFile(t, "my song.mp3", audio)
+
+	s, err := Start("127.0.0.1")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	tr, err := s.Add(path)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !strings.HasPrefix(tr.URL, s.BaseURL()+"/media/") || !strings.HasSuffix(tr.URL, "/my%20song.mp3") {
+		t.Fatalf("unexpected URL: %s", tr.URL)
+	}
+	if tr.ArtURL == "" || !strings.Contains(tr.Meta(), "<dc:title>Title</dc:title>") || !strings.Contains(tr.Meta(), "audio/mpeg") {
+		t.Fatalf("unexpected track: %+v meta=%s", tr, tr.Meta())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, tr.URL, nil)
+	req.Header.Set("Range", "bytes=2-5")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET range: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || resp.Header.Get("Content-Type") != "audio/mpeg" || string(body) != string(audio[2:6]) {
+		t.Fatalf("unexpected range response: %d %q %q", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("missing Accept-Ranges")
+	}
+
+	resp, err = http.Get(tr.ArtURL)
+	if err != nil {
+		t.Fatalf("GET art: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "image/png" || string(body) != "PNG!" {
+		t.Fatalf("unexpected art: %q %q", resp.Header.Get("Content-Type"), body)
+	}
+
+	for _, u := range []string{s.BaseURL() + "/media/99/x.mp3", s.BaseURL() + "/etc/passwd", s.BaseURL() + "/art/99"} {
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Fatalf("GET %s: %v", u, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("GET %s: status %d", u, resp.StatusCode)
+		}
+	}
+}
+
+func TestServerRejectsUnsupportedFiles(t *testing.T) {
+	t.Parallel()
+
+	s, err := Start("127.0.0.1")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	if _, err := s.Add(writeFile(t, "notes.txt", []byte("hi"))); err == nil {
+		t.Fatalf("expected unsupported error")
+	}
+	if _, err := s.Add(t.TempDir()); err == nil {
+		t.Fatalf("expected directory error")
+	}
+}
+
+func TestContentType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"a.MP3":  "audio/mpeg",
+		"b.flac": "audio/flac",
+		"c.aac":  "audio/aac",
+		"d.m4a":  "audio/mp4",
+		"e.wav":  "audio/wav",
+		"f.ogg":  "audio/ogg",
+		"g.txt":  "",
+	}
+	for in, want := range cases {
+		if got := ContentType(in); got != want {
+			t.Fatalf("ContentType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package mediaserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Stream codecs accepted by NewStream.
+const (
+	CodecMP3 = "mp3"
+	CodecWAV = "wav"
+)
+
+const (
+	streamChunkSize   = 8 << 10
+	streamBacklogSize = 64 << 10 // sent to (re)connecting clients so playback starts promptly
+	streamClientQueue = 64       // chunks buffered per client before it is dropped as too slow
+	maxWAVHeaderSize  = 1 << 20
+)
+
+// Stream fans a live, unbounded audio feed (e.g. stdin) out to any number of
+// HTTP clients. Clients may connect or reconnect at any time and join at the
+// live edge; reading the source never waits for a client. For WAV input the
+// RIFF header is replayed to every client so reconnects stay decodable.
+type Stream struct {
+	codec string
+	name  string
+
+	readyOnce sync.Once
+	ready     chan struct{}
+
+	mu          sync.Mutex
+	header      []byte
+	backlog     [][]byte
+	backlogSize int
+	clients     map[chan []byte]struct{}
+	done        bool
+	bytes       int64
+	connects    int
+}
+
+// NewStream returns a stream for codec (CodecMP3 or CodecWAV). name is
+// advertised to clients as icy-name.
+func NewStream(codec, name string) (*Stream, error) {
+	switch codec {
+	case CodecMP3, CodecWAV:
+	default:
+		return nil, fmt.Errorf("unsupported stream codec %q (expected wav|mp3)", codec)
+	}
+	return &Stream{
+		codec:   codec,
+		name:    name,
+		ready:   make(chan struct{}),
+		clients: map[chan []byte]struct{}{},
+	}, nil
+}
+
+// ContentType is the MIME type served for the stream.
+func (s *Stream) ContentType() string {
+	return contentTypes["."+s.codec]
+}
+
+// Ext is the file extension used in the stream URL.
+func (s *Stream) Ext() string {
+	return s.codec
+}
+
+// Run reads r until EOF or error, broadcasting it to connected clients, and
+// ends the stream afterwards.
+func (s *Stream) Run(r io.Reader) error {
+	defer s.Close()
+
+	align := 1
+	if s.codec == CodecWAV {
+		header, blockAlign, err := readWAVHeader(r)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.header = header
+		s.mu.Unlock()
+		if blockAlign > 0 {
+			align = blockAlign
+		}
+	}
+	s.readyOnce.Do(func() { close(s.ready) })
+
+	// Only whole PCM frames are broadcast so late joiners stay frame-aligned.
+	size := streamChunkSize
+	if align > size {
+		size = align
+	}
+	size -= size % align
+	buf := make([]byte, size)
+	pending := 0
+	for {
+		n, err := r.Read(buf[pending:])
+		pending += n
+		if whole := pending - pending%align; whole > 0 {
+			s.broadcast(bytes.Clone(buf[:whole]))
+			pending = copy(buf, buf[whole:pending])
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close ends the stream and disconnects all clients.
+func (s *Stream) Close() {
+	s.readyOnce.Do(func() { close(s.ready) })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	for ch := range s.clients {
+		close(ch)
+		delete(s.clients, ch)
+	}
+}
+
+// Stats reports the bytes read so far, the number of connected clients and
+// the total number of client connections (including reconnects).
+func (s *Stream) Stats() (bytesRead int64, clients, connects int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bytes, len(s.clients), s.connects
+}
+
+func (s *Stream) broadcast(chunk []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bytes += int64(len(chunk))
+	s.backlog = append(s.backlog, chunk)
+	s.backlogSize += len(chunk)
+	for s.backlogSize > streamBacklogSize && len(s.backlog) > 1 {
+		s.backlogSize -= len(s.backlog[0])
+		s.backlog = s.backlog[1:]
+	}
+	for ch := range s.clients {
+		select {
+		case ch <- chunk:
+		default:
+			// Too slow: drop it; the speaker reconnects at the live edge.
+			close(ch)
+			delete(s.clients, ch)
+		}
+	}
+}
+
+func (s *Stream) subscribe() (chan []byte, [][]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil, nil, false
+	}
+	ch := make(chan []byte, streamClientQueue)
+	s.clients[ch] = struct{}{}
+	s.connects++
+	initial := make([][]byte, 0, len(s.backlog)+1)
+	if len(s.header) > 0 {
+		initial = append(initial, s.header)
+	}
+	initial = append(initial, s.backlog...)
+	return ch, initial, true
+}
+
+func (s *Stream) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[ch]; ok {
+		close(ch)
+		delete(s.clients, ch)
+	}
+}
+
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	select {
+	case <-s.ready:
+	case <-r.Context().Done():
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", s.ContentType())
+	h.Set("Cache-Control", "no-cache, no-store")
+	if s.name != "" {
+		h.Set("icy-name", s.name)
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ch, initial, ok := s.subscribe()
+	if !ok {
+		http.Error(w, "stream ended", http.StatusGone)
+		return
+	}
+	defer s.unsubscribe(ch)
+
+	// No Content-Length: the response is chunked and unbounded.
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for _, chunk := range initial {
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// readWAVHeader consumes the RIFF header up to and including the data chunk
+// header. Sizes are rewritten to 0xFFFFFFFF (unknown length), the convention
+// for streamed WAV. It returns the PCM block alignment from the fmt chunk.
+func readWAVHeader(r io.Reader) ([]byte, int, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, fmt.Errorf("read WAV header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, 0, errors.New("input is not a WAV stream (expected a RIFF/WAVE header)")
+	}
+	binary.LittleEndian.PutUint32(header[4:8], 0xFFFFFFFF)
+
+	blockAlign := 0
+	for {
+		chunk := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, 0, fmt.Errorf("read WAV header: %w", err)
+		}
+		id := string(chunk[0:4])
+		if id == "data" {
+			binary.LittleEndian.PutUint32(chunk[4:8], 0xFFFFFFFF)
+			return append(header, chunk...), blockAlign, nil
+		}
+		size := int(binary.LittleEndian.Uint32(chunk[4:8]))
+		size += size % 2 // chunks are word-aligned
+		if len(header)+8+size > maxWAVHeaderSize {
+			return nil, 0, errors.New("WAV header too large")
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, 0, fmt.Errorf("read WAV header: %w", err)
+		}
+		if id == "fmt " && size >= 14 {
+			blockAlign = int(binary.LittleEndian.Uint16(body[12:14]))
+		}
+		header = append(append(header, chunk...), body...)
+	}
+}
@@ -0,0 +1,200 @@
+package mediaserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func wavHeader(dataSize uint32) []byte {
+	var b bytes.Buffer
+	le := func(v any) { _ = binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(36 + dataSize))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	le(uint32(16))
+	le(uint16(1))     // PCM
+	le(uint16(2))     // channels
+	le(uint32(44100)) // sample rate
+	le(uint32(44100 * 4))
+	le(uint16(4)) // block align
+	le(uint16(16))
+	b.WriteString("LIST")
+	le(uint32(3))
+	b.WriteString("abc\x00") // odd-sized chunk plus pad byte
+	b.WriteString("data")
+	le(dataSize)
+	return b.Bytes()
+}
+
+func startStream(t *testing.T, codec string) (*Server, *Stream, string, *io.PipeWriter, chan error) {
+	t.Helper()
+	s, err := Start("127.0.0.1")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	st, err := NewStream(codec, "Living room mic")
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	u := s.AddStream(st)
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { _ = pw.Close() })
+	done := make(chan error, 1)
+	go func() { done <- st.Run(pr) }()
+	return s, st, u, pw, done
+}
+
+func waitClients(t *testing.T, st *Stream, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, n, _ := st.Stats(); n == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d clients", want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readN(t *testing.T, r io.Reader, n int) []byte {
+	t.Helper()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("read %d bytes: %v", n, err)
+	}
+	return buf
+}
+
+func TestStreamWAVReplaysHeaderOnReconnect(t *testing.T) {
+	t.Parallel()
+
+	_, st, u, pw, done := startStream(t, CodecWAV)
+	if !strings.HasSuffix(u, "/live.wav") {
+		t.Fatalf("unexpected stream URL: %s", u)
+	}
+	hdr := wavHeader(1234)
+	if _, err := pw.Write(hdr); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	if resp.Header.Get("Content-Type") != "audio/wav" || resp.Header.Get("icy-name") != "Living room mic" || resp.ContentLength != -1 {
+		t.Fatalf("unexpected headers: %v (length %d)", resp.Header, resp.ContentLength)
+	}
+	got := readN(t, resp.Body, len(hdr))
+	if binary.LittleEndian.Uint32(got[4:8]) != 0xFFFFFFFF || binary.LittleEndian.Uint32(got[len(got)-4:]) != 0xFFFFFFFF {
+		t.Fatalf("WAV sizes not rewritten: % x", got)
+	}
+	if !bytes.Equal(got[8:len(got)-4], hdr[8:len(hdr)-4]) {
+		t.Fatalf("header chunks changed")
+	}
+
+	waitClients(t, st, 1)
+	// Six bytes hold one whole frame; the partial frame waits for the rest.
+	_, _ = pw.Write([]byte("ABCDEF"))
+	if got := readN(t, resp.Body, 4); string(got) != "ABCD" {
+		t.Fatalf("unexpected frame: %q", got)
+	}
+	_, _ = pw.Write([]byte("GH"))
+	if got := readN(t, resp.Body, 4); string(got) != "EFGH" {
+		t.Fatalf("unexpected frame: %q", got)
+	}
+	_ = resp.Body.Close()
+	waitClients(t, st, 0)
+
+	// A reconnecting speaker gets the header again, then the recent backlog.
+	resp, err = http.Get(u)
+	if err != nil {
+		t.Fatalf("GET again: %v", err)
+	}
+	defer resp.Body.Close()
+	readN(t, resp.Body, len(hdr))
+	if got := readN(t, resp.Body, 8); string(got) != "ABCDEFGH" {
+		t.Fatalf("unexpected backlog: %q", got)
+	}
+	waitClients(t, st, 1)
+	_, _ = pw.Write([]byte("IJKL"))
+	if got := readN(t, resp.Body, 4); string(got) != "IJKL" {
+		t.Fatalf("unexpected live data: %q", got)
+	}
+
+	_ = pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rest, _ := io.ReadAll(resp.Body); len(rest) != 0 {
+		t.Fatalf("unexpected trailing data: %q", rest)
+	}
+	if n, clients, connects := st.Stats(); n != 12 || clients != 0 || connects != 2 {
+		t.Fatalf("unexpected stats: bytes=%d clients=%d connects=%d", n, clients, connects)
+	}
+	gone, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET after end: %v", err)
+	}
+	_ = gone.Body.Close()
+	if gone.StatusCode != http.StatusGone {
+		t.Fatalf("expected 410 after the stream ended, got %d", gone.StatusCode)
+	}
+}
+
+func TestStreamMP3KeepsReadingWithoutClients(t *testing.T) {
+	t.Parallel()
+
+	_, st, u, pw, done := startStream(t, CodecMP3)
+	// Nobody is listening yet; writes must not block.
+	for i := 0; i < 20; i++ {
+		if _, err := pw.Write(bytes.Repeat([]byte{byte(i)}, 8<<10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "audio/mpeg" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	// Only the backlog (the most recent 64 KiB) is replayed.
+	backlog := readN(t, resp.Body, streamBacklogSize)
+	if backlog[0] != 12 || backlog[len(backlog)-1] != 19 {
+		t.Fatalf("unexpected backlog window: first=%d last=%d", backlog[0], backlog[len(backlog)-1])
+	}
+
+	st.Close()
+	if rest, _ := io.ReadAll(resp.Body); len(rest) != 0 {
+		t.Fatalf("unexpected data after Close: %d bytes", len(rest))
+	}
+	_ = pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestStreamRejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewStream("flac", ""); err == nil {
+		t.Fatalf("expected unsupported codec error")
+	}
+	st, err := NewStream(CodecWAV, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Run(strings.NewReader("ID3 not a wav file")); err == nil {
+		t.Fatalf("expected WAV header error")
+	}
+}
@@ -0,0 +1,501 @@
+package mediaserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// Tags is the subset of file metadata shown on Sonos.
+type Tags struct {
+	Title       string
+	Artist      string
+	Album       string
+	Picture     []byte
+	PictureMIME string
+}
+
+// maxTagBytes bounds how much of a file is read looking for tags (embedded
+// cover art is usually well below this).
+const maxTagBytes = 16 << 20
+
+// ReadTags reads ID3v2/ID3v1 (MP3, AAC), MP4 (M4A) iTunes metadata, FLAC and
+// Ogg Vorbis/Opus comments.
+// Missing titles fall back to the file name without extension.
+func ReadTags(path string) (Tags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	defer f.Close()
+
+	var t Tags
+	head := make([]byte, 10)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return Tags{}, err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("ID3")):
+		t, _ = readID3v2(f)
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		t, _ = readFLAC(f)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		t, _ = readOgg(f)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		t, _ = readMP4(f)
+	}
+	if t.Title == "" || t.Artist == "" {
+		if v1, ok := readID3v1(f); ok {
+			t.Title = firstNonEmpty(t.Title, v1.Title)
+			t.Artist = firstNonEmpty(t.Artist, v1.Artist)
+			t.Album = firstNonEmpty(t.Album, v1.Album)
+		}
+	}
+	if t.Title == "" {
+		base := filepath.Base(path)
+		t.Title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return t, nil
+}
+
+func readID3v2(r io.Reader) (Tags, error) {
+	var hdr [10]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return Tags{}, err
+	}
+	major := hdr[3]
+	flags := hdr[5]
+	size := synchsafe(hdr[6:10])
+	if size > maxTagBytes {
+		return Tags{}, errors.New("id3: tag too large")
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Tags{}, err
+	}
+	if flags&0x40 != 0 && len(body) >= 4 {
+		// Extended header: skip it.
+		ext := int(binary.BigEndian.Uint32(body[:4]))
+		if major == 4 {
+			ext = synchsafe(body[:4])
+		} else {
+			ext += 4
+		}
+		if ext > len(body) {
+			return Tags{}, errors.New("id3: bad extended header")
+		}
+		body = body[ext:]
+	}
+
+	var t Tags
+	idLen, hdrLen := 4, 10
+	if major == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	for len(body) >= hdrLen {
+		id := string(body[:idLen])
+		if id[0] == 0 {
+			break // padding
+		}
+		var fsize int
+		switch major {
+		case 2:
+			fsize = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 4:
+			fsize = synchsafe(body[4:8])
+		default:
+			fsize = int(binary.BigEndian.Uint32(body[4:8]))
+		}
+		if fsize < 0 || hdrLen+fsize > len(body) {
+			break
+		}
+		data := body[hdrLen : hdrLen+fsize]
+		body = body[hdrLen+fsize:]
+
+		switch id {
+		case "TIT2", "TT2":
+			t.Title = id3Text(data)
+		case "TPE1", "TP1":
+			t.Artist = id3Text(data)
+		case "TALB", "TAL":
+			t.Album = id3Text(data)
+		case "APIC", "PIC":
+			if t.Picture == nil {
+				t.Picture, t.PictureMIME = id3Picture(data, id == "PIC")
+			}
+		}
+	}
+	return t, nil
+}
+
+func synchsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// id3Text decodes a text frame; only the first of several values is kept.
+func id3Text(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	s, _ := id3String(data[0], data[1:])
+	return strings.TrimSpace(s)
+}
+
+// id3String decodes a null-terminated string in the given ID3 encoding and
+// returns it plus the bytes after the terminator.
+func id3String(enc byte, b []byte) (string, []byte) {
+	switch enc {
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		end := len(b)
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				end = i
+				break
+			}
+		}
+		rest := b[min(end+2, len(b)):]
+		return decodeUTF16(b[:end], enc == 2), rest
+	default: // ISO-8859-1, UTF-8
+		end := bytes.IndexByte(b, 0)
+		if end < 0 {
+			end = len(b)
+		}
+		rest := b[min(end+1, len(b)):]
+		if enc == 3 {
+			return string(b[:end]), rest
+		}
+		return latin1(b[:end]), rest
+	}
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	if len(b) >= 2 {
+		switch {
+		case b[0] == 0xff && b[1] == 0xfe:
+			bigEndian, b = false, b[2:]
+		case b[0] == 0xfe && b[1] == 0xff:
+			bigEndian, b = true, b[2:]
+		}
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if bigEndian {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		} else {
+			u = append(u, uint16(b[i+1])<<8|uint16(b[i]))
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+func id3Picture(data []byte, v22 bool) ([]byte, string) {
+	if len(data) < 2 {
+		return nil, ""
+	}
+	enc := data[0]
+	rest := data[1:]
+	var mimeType string
+	if v22 {
+		if len(rest) < 4 {
+			return nil, ""
+		}
+		mimeType = "image/" + strings.ToLower(string(rest[:3]))
+		if mimeType == "image/jpg" {
+			mimeType = "image/jpeg"
+		}
+		rest = rest[3:]
+	} else {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			return nil, ""
+		}
+		mimeType = string(rest[:end])
+		rest = rest[end+1:]
+	}
+	if len(rest) < 1 {
+		return nil, ""
+	}
+	_, rest = id3String(enc, rest[1:]) // picture type, then description
+	if len(rest) == 0 {
+		return nil, ""
+	}
+	if !strings.Contains(mimeType, "/") {
+		mimeType = "image/" + strings.ToLower(mimeType)
+	}
+	return append([]byte(nil), rest...), mimeType
+}
+
+func readID3v1(f io.ReadSeeker) (Tags, bool) {
+	if _, err := f.Seek(-128, io.SeekEnd); err != nil {
+		return Tags{}, false
+	}
+	var b [128]byte
+	if _, err := io.ReadFull(f, b[:]); err != nil || string(b[:3]) != "TAG" {
+		return Tags{}, false
+	}
+	field := func(s []byte) string {
+		if i := bytes.IndexByte(s, 0); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(latin1(s))
+	}
+	return Tags{Title: field(b[3:33]), Artist: field(b[33:63]), Album: field(b[63:93])}, true
+}
+
+func readFLAC(r io.Reader) (Tags, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return Tags{}, err
+	}
+	var t Tags
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return t, err
+		}
+		last := hdr[0]&0x80 != 0
+		kind := hdr[0] & 0x7f
+		size := int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3])
+		block := make([]byte, size)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return t, err
+		}
+		switch kind {
+		case 4: // VORBIS_COMMENT
+			applyVorbisComments(&t, block)
+		case 6: // PICTURE
+			if t.Picture == nil {
+				t.Picture, t.PictureMIME = flacPicture(block)
+			}
+		}
+		if last {
+			return t, nil
+		}
+	}
+}
+
+func flacPicture(b []byte) ([]byte, string) {
+	u32 := func() (int, bool) {
+		if len(b) < 4 {
+			return 0, false
+		}
+		v := int(binary.BigEndian.Uint32(b[:4]))
+		b = b[4:]
+		return v, true
+	}
+	skip := func(n int) bool {
+		if n < 0 || n > len(b) {
+			return false
+		}
+		b = b[n:]
+		return true
+	}
+	if _, ok := u32(); !ok { // picture type
+		return nil, ""
+	}
+	mlen, ok := u32()
+	if !ok || mlen > len(b) {
+		return nil, ""
+	}
+	mimeType := string(b[:mlen])
+	b = b[mlen:]
+	dlen, ok := u32()
+	if !ok || !skip(dlen) || !skip(16) { // description; width, height, depth, colors
+		return nil, ""
+	}
+	plen, ok := u32()
+	if !ok || plen > len(b) {
+		return nil, ""
+	}
+	return append([]byte(nil), b[:plen]...), mimeType
+}
+
+// readMP4 skips top-level boxes until moov (which may follow the media data)
+// and reads the iTunes-style items in moov/udta/meta/ilst.
+func readMP4(f io.ReadSeeker) (Tags, error) {
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(f, hdr[:]); err != nil {
+			return Tags{}, err
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		body := size - 8
+		switch {
+		case size == 0: // extends to the end of the file
+			body = maxTagBytes
+		case size == 1:
+			var ext [8]byte
+			if _, err := io.ReadFull(f, ext[:]); err != nil {
+				return Tags{}, err
+			}
+			body = int64(binary.BigEndian.Uint64(ext[:])) - 16
+		}
+		if body < 0 {
+			return Tags{}, errors.New("mp4: invalid box size")
+		}
+		if string(hdr[4:]) != "moov" {
+			if size == 0 {
+				return Tags{}, io.EOF
+			}
+			if _, err := f.Seek(body, io.SeekCurrent); err != nil {
+				return Tags{}, err
+			}
+			continue
+		}
+		if body > maxTagBytes {
+			return Tags{}, errors.New("mp4: moov box too large")
+		}
+		moov, err := io.ReadAll(io.LimitReader(f, body))
+		if err != nil {
+			return Tags{}, err
+		}
+		return mp4Tags(moov), nil
+	}
+}
+
+func mp4Tags(moov []byte) Tags {
+	var t Tags
+	meta := mp4Child(mp4Child(moov, "udta"), "meta")
+	// meta is a full box (version and flags) in MP4 files but a plain one
+	// in some QuickTime files.
+	if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+		meta = meta[4:]
+	}
+	eachMP4Box(mp4Child(meta, "ilst"), func(name string, item []byte) {
+		data := mp4Child(item, "data")
+		if len(data) < 8 { // type, locale
+			return
+		}
+		kind, value := binary.BigEndian.Uint32(data[:4])&0xffffff, data[8:]
+		switch name {
+		case "\xa9nam":
+			t.Title = firstNonEmpty(t.Title, string(value))
+		case "\xa9ART":
+			t.Artist = firstNonEmpty(t.Artist, string(value))
+		case "\xa9alb":
+			t.Album = firstNonEmpty(t.Album, string(value))
+		case "covr":
+			if t.Picture != nil {
+				return
+			}
+			switch kind {
+			case 13:
+				t.PictureMIME = "image/jpeg"
+			case 14:
+				t.PictureMIME = "image/png"
+			default:
+				return
+			}
+			t.Picture = append([]byte(nil), value...)
+		}
+	})
+	return t
+}
+
+// eachMP4Box calls fn with the type and payload of each box in b, stopping
+// at the first malformed one.
+func eachMP4Box(b []byte, fn func(name string, body []byte)) {
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b[:4]))
+		if size < 8 || size > len(b) {
+			return
+		}
+		fn(string(b[4:8]), b[8:size])
+		b = b[size:]
+	}
+}
+
+// mp4Child returns the payload of the first box named name in b.
+func mp4Child(b []byte, name string) []byte {
+	var out []byte
+	eachMP4Box(b, func(n string, body []byte) {
+		if out == nil && n == name {
+			out = body
+		}
+	})
+	return out
+}
+
+// readOgg finds the Vorbis or Opus comment header in the first pages. Page
+// boundaries are not reassembled, which is fine for the small text comments
+// but means art embedded in Ogg files is ignored.
+func readOgg(r io.Reader) (Tags, error) {
+	buf, err := io.ReadAll(io.LimitReader(r, 1<<16))
+	if err != nil {
+		return Tags{}, err
+	}
+	var t Tags
+	for _, marker := range [][]byte{[]byte("\x03vorbis"), []byte("OpusTags")} {
+		if i := bytes.Index(buf, marker); i >= 0 {
+			applyVorbisComments(&t, buf[i+len(marker):])
+			break
+		}
+	}
+	return t, nil
+}
+
+// applyVorbisComments parses a little-endian vendor string followed by
+// KEY=value comments.
+func applyVorbisComments(t *Tags, b []byte) {
+	next := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(b[:4]))
+		if n < 0 || 4+n > len(b) {
+			return "", false
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, true
+	}
+	if _, ok := next(); !ok { // vendor
+		return
+	}
+	if len(b) < 4 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(b[:4]))
+	b = b[4:]
+	for i := 0; i < count; i++ {
+		c, ok := next()
+		if !ok {
+			return
+		}
+		key, value, ok := strings.Cut(c, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			t.Title = firstNonEmpty(t.Title, value)
+		case "ARTIST":
+			t.Artist = firstNonEmpty(t.Artist, value)
+		case "ALBUM":
+			t.Album = firstNonEmpty(t.Album, value)
+		}
+	}
+}
+
+func firstNonEmpty(a, b string) string {
+	if strings.TrimSpace(a) != "" {
+		return a
+	}
+	return strings.TrimSpace(b)
+}
@@ -0,0 +1,213 @@
+package mediaserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func id3Frame(id string, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(id)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.Write([]byte{0, 0})
+	b.Write(data)
+	return b.Bytes()
+}
+
+func id3Tag(major byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, 16)...) // padding
+	n := len(body)
+	hdr := []byte{'I', 'D', '3', major, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	return append(hdr, body...)
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReadTagsID3v23(t *testing.T) {
+	t.Parallel()
+
+	utf16Artist := []byte{1, 0xff, 0xfe, 'B', 0, 0xe4, 0, 'n', 0, 'd', 0, 0, 0}
+	apic := append([]byte{0}, []byte("image/png\x00\x03cover\x00\x89PNGDATA")...)
+	tag := id3Tag(3,
+		id3Frame("TIT2", []byte("\x00Song Title\x00")),
+		id3Frame("TPE1", utf16Artist),
+		id3Frame("TALB", []byte("\x03Albüm")),
+		id3Frame("APIC", apic),
+	)
+	p := writeFile(t, "a.mp3", append(tag, []byte("audio")...))
+
+	got, err := ReadTags(p)
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "Song Title" || got.Artist != "Bänd" || got.Album != "Albüm" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+	if got.PictureMIME != "image/png" || string(got.Picture) != "\x89PNGDATA" {
+		t.Fatalf("unexpected picture: %q %q", got.PictureMIME, got.Picture)
+	}
+}
+
+func TestReadTagsID3v24AndV1Fallback(t *testing.T) {
+	t.Parallel()
+
+	// v2.4 frame sizes are synchsafe; album comes from the ID3v1 trailer.
+	frame := []byte("TIT2\x00\x00\x00\x06\x00\x00\x03Hello")
+	hdr := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, byte(len(frame))}
+	v1 := make([]byte, 128)
+	copy(v1, "TAG")
+	copy(v1[3:], "Old Title")
+	copy(v1[33:], "Old Artist")
+	copy(v1[63:], "Old Album")
+	data := append(append(append(hdr, frame...), []byte("audio")...), v1...)
+	got, err := ReadTags(writeFile(t, "b.mp3", data))
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "Hello" || got.Artist != "Old Artist" || got.Album != "Old Album" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+}
+
+func vorbisComments(comments ...string) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len("vendor")))
+	b.WriteString("vendor")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(comments)))
+	for _, c := range comments {
+		_ = binary.Write(&b, binary.LittleEndian, uint32(len(c)))
+		b.WriteString(c)
+	}
+	return b.Bytes()
+}
+
+func TestReadTagsFLAC(t *testing.T) {
+	t.Parallel()
+
+	var pic bytes.Buffer
+	be := func(v int) { _ = binary.Write(&pic, binary.BigEndian, uint32(v)) }
+	be(3)
+	be(len("image/jpeg"))
+	pic.WriteString("image/jpeg")
+	be(0)
+	be(1)
+	be(1)
+	be(24)
+	be(0)
+	be(4)
+	pic.WriteString("JPEG")
+
+	block := func(kind byte, last bool, data []byte) []byte {
+		if last {
+			kind |= 0x80
+		}
+		n := len(data)
+		return append([]byte{kind, byte(n >> 16), byte(n >> 8), byte(n)}, data...)
+	}
+	var f bytes.Buffer
+	f.WriteString("fLaC")
+	f.Write(block(0, false, make([]byte, 34)))
+	f.Write(block(4, false, vorbisComments("TITLE=Flac Song", "artist=Flac Artist", "ALBUM=Flac Album")))
+	f.Write(block(6, true, pic.Bytes()))
+	f.WriteString("frames")
+
+	got, err := ReadTags(writeFile(t, "c.flac", f.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "Flac Song" || got.Artist != "Flac Artist" || got.Album != "Flac Album" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+	if got.PictureMIME != "image/jpeg" || string(got.Picture) != "JPEG" {
+		t.Fatalf("unexpected picture: %q %q", got.PictureMIME, got.Picture)
+	}
+}
+
+func TestReadTagsOggAndFilenameFallback(t *testing.T) {
+	t.Parallel()
+
+	ogg := append([]byte("OggS\x00\x02pageheader\x01vorbisident OggS\x00\x00\x03vorbis"), vorbisComments("TITLE=Ogg Song", "ARTIST=Ogg Artist")...)
+	got, err := ReadTags(writeFile(t, "d.ogg", ogg))
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "Ogg Song" || got.Artist != "Ogg Artist" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+
+	got, err = ReadTags(writeFile(t, "01 - Untagged.wav", []byte("RIFF....WAVEfmt ")))
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "01 - Untagged" || got.Artist != "" {
+		t.Fatalf("unexpected fallback tags: %+v", got)
+	}
+}
+
+func mp4Box(name string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, uint32(8+len(body)))
+	b.WriteString(name)
+	b.Write(body)
+	return b.Bytes()
+}
+
+func mp4Item(name string, kind uint32, value string) []byte {
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.BigEndian, kind)
+	data.Write(make([]byte, 4)) // locale
+	data.WriteString(value)
+	return mp4Box(name, mp4Box("data", data.Bytes()))
+}
+
+func TestReadTagsMP4(t *testing.T) {
+	t.Parallel()
+
+	ilst := mp4Box("ilst",
+		mp4Item("\xa9nam", 1, "M4A Song"),
+		mp4Item("\xa9ART", 1, "M4A Artist"),
+		mp4Item("\xa9alb", 1, "M4A Album"),
+		mp4Item("covr", 14, "\x89PNGDATA"),
+	)
+	meta := mp4Box("meta", make([]byte, 4), mp4Box("hdlr", make([]byte, 25)), ilst)
+	var f bytes.Buffer
+	f.Write(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00")))
+	f.Write(mp4Box("mdat", []byte("audio frames"))) // moov after the media data
+	f.Write(mp4Box("moov", mp4Box("mvhd", make([]byte, 100)), mp4Box("udta", meta)))
+
+	got, err := ReadTags(writeFile(t, "e.m4a", f.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+	if got.Title != "M4A Song" || got.Artist != "M4A Artist" || got.Album != "M4A Album" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+	if got.PictureMIME != "image/png" || string(got.Picture) != "\x89PNGDATA" {
+		t.Fatalf("unexpected picture: %q %q", got.PictureMIME, got.Picture)
+	}
+
+	// Truncated or oversized boxes fall back to the file name.
+	trunc := f.Bytes()[:f.Len()-40]
+	bad := append(mp4Box("ftyp", []byte("M4A ")), []byte("\xff\xff\xff\xffmoov")...)
+	for name, data := range map[string][]byte{"Truncated.m4a": trunc, "Oversized.m4a": bad} {
+		got, err := ReadTags(writeFile(t, name, data))
+		if err != nil {
+			t.Fatalf("ReadTags(%s): %v", name, err)
+		}
+		if want := name[:len(name)-4]; got.Title != want || got.Picture != nil {
+			t.Fatalf("ReadTags(%s) = %+v", name, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+type Store interface {
+	List() ([]Meta, error)
+	Get(name string) (Entry, bool, error)
+	Put(entry Entry) error
+	Delete(name string) error
+}
+
+// Entry is a named playback snapshot.
+type Entry struct {
+	Name     string         `json:"name"`
+	Room     string         `json:"room,omitempty"`
+	Snapshot sonos.Snapshot `json:"snapshot"`
+}
+
+type Meta struct {
+	Name      string    `json:"name"`
+	Room      string    `json:"room,omitempty"`
+	Source    string    `json:"source"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type FileStore struct {
+	path string
+}
+
+func NewFileStore() (*FileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: filepath.Join(dir, "sonoscli", "snapshots.json")}, nil
+}
+
+func (s *FileStore) List() ([]Meta, error) {
+	data, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	metas := make([]Meta, 0, len(data))
+	for _, e := range data {
+		metas = append(metas, Meta{
+			Name:      e.Name,
+			Room:      e.Room,
+			Source:    e.Snapshot.Source,
+			State:     e.Snapshot.TransportState,
+			CreatedAt: e.Snapshot.CreatedAt,
+		})
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Name < metas[j].Name })
+	return metas, nil
+}
+
+func (s *FileStore) Get(name string) (Entry, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Entry{}, false, nil
+	}
+	data, err := s.readAll()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	e, ok := data[name]
+	return e, ok, nil
+}
+
+func (s *FileStore) Put(entry Entry) error {
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.Name == "" {
+		return errors.New("snapshot name is required")
+	}
+	if entry.Snapshot.CreatedAt.IsZero() {
+		entry.Snapshot.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	data[entry.Name] = entry
+	return s.writeAll(data)
+}
+
+func (s *FileStore) Delete(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("snapshot name is required")
+	}
+	data, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if _, ok := data[name]; !ok {
+		return nil
+	}
+	delete(data, name)
+	return s.writeAll(data)
+}
+
+type fileFormat struct {
+	Snapshots map[string]Entry `json:"snapshots"`
+}
+
+func (s *FileStore) readAll() (map[string]Entry, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Entry{}, nil
+		}
+		return nil, err
+	}
+	var ff fileFormat
+	if err := json.Unmarshal(b, &ff); err != nil {
+		return nil, fmt.Errorf("parse snapshots store: %w", err)
+	}
+	if ff.Snapshots == nil {
+		ff.Snapshots = map[string]Entry{}
+	}
+	return ff.Snapshots, nil
+}
+
+func (s *FileStore) writeAll(data map[string]Entry) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	ff := fileFormat{Snapshots: data}
+	b, err := json.MarshalIndent(ff, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,100 @@
+package snapshots
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+)
+
+func TestFileStoreCRUD(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s := &FileStore{path: filepath.Join(dir, "sub", "snapshots.json")}
+
+	if metas, err := s.List(); err != nil || len(metas) != 0 {
+		t.Fatalf("expected empty list, got metas=%v err=%v", metas, err)
+	}
+
+	entry := Entry{
+		Name: " Kitchen ",
+		Room: "Kitchen",
+		Snapshot: sonos.Snapshot{
+			CoordinatorUUID: "RINCON_1",
+			CoordinatorIP:   "192.0.2.10",
+			Source:          sonos.SourceQueue,
+			TransportState:  "PLAYING",
+			Track:           3,
+			RelTime:         "0:01:02",
+			Queue:           []sonos.SnapshotQueueItem{{URI: "http://example.com/a.mp3", Meta: "<DIDL-Lite/>"}},
+			Members:         []sonos.SnapshotMember{{UUID: "RINCON_1", IP: "192.0.2.10", Volume: 20}},
+		},
+	}
+	if err := s.Put(entry); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	got, ok, err := s.Get("Kitchen")
+	if err != nil || !ok {
+		t.Fatalf("get: ok=%v err=%v", ok, err)
+	}
+	if got.Snapshot.Track != 3 || len(got.Snapshot.Queue) != 1 || got.Snapshot.Members[0].Volume != 20 {
+		t.Fatalf("unexpected snapshot: %+v", got.Snapshot)
+	}
+	if got.Snapshot.CreatedAt.IsZero() {
+		t.Fatalf("expected createdAt to be set")
+	}
+
+	metas, err := s.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(metas) != 1 || metas[0].Name != "Kitchen" || metas[0].Source != sonos.SourceQueue || metas[0].State != "PLAYING" {
+		t.Fatalf("unexpected metas: %v", metas)
+	}
+
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected file mode: %v", fi.Mode().Perm())
+	}
+
+	if err := s.Delete("Kitchen"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok, err := s.Get("Kitchen"); err != nil || ok {
+		t.Fatalf("expected missing after delete, ok=%v err=%v", ok, err)
+	}
+	if err := s.Put(Entry{Name: "  "}); err == nil {
+		t.Fatalf("expected name error")
+	}
+}
+
+func TestFileStoreRejectsCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "snapshots.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := &FileStore{path: path}
+	if _, err := s.List(); err == nil || !strings.Contains(err.Error(), "parse snapshots store") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestNewFileStore_PathSuffix(t *testing.T) {
+	s, err := NewFileStore()
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	p := filepath.ToSlash(s.path)
+	if !strings.HasSuffix(p, "/sonoscli/snapshots.json") {
+		t.Fatalf("unexpected path: %q", p)
+	}
+}
@@ -0,0 +1,281 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AlarmBuzzerURI is the built-in Sonos chime used when an alarm has no source.
+const AlarmBuzzerURI = "x-rincon-buzzer:0"
+
+// Alarm is one entry from AlarmClock.ListAlarms. Alarms are stored
+// household-wide, so any speaker can list or change them.
+type Alarm struct {
+	ID                 int      `json:"id"`
+	StartTime          string   `json:"startTime"` // HH:MM:SS, speaker local time
+	Duration           string   `json:"duration"`  // HH:MM:SS
+	Recurrence         string   `json:"recurrence"`
+	Enabled            bool     `json:"enabled"`
+	RoomUUID           string   `json:"roomUUID"`
+	ProgramURI         string   `json:"programURI"`
+	ProgramMetaData    string   `json:"programMetaData,omitempty"`
+	PlayMode           PlayMode `json:"playMode"`
+	Volume             int      `json:"volume"`
+	IncludeLinkedZones bool     `json:"includeLinkedZones"`
+}
+
+// AlarmList is the parsed result of ListAlarms.
+type AlarmList struct {
+	Version string  `json:"version"`
+	Alarms  []Alarm `json:"alarms"`
+}
+
+// Find returns the alarm with the given ID.
+func (l AlarmList) Find(id int) (Alarm, bool) {
+	for _, a := range l.Alarms {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Alarm{}, false
+}
+
+func (c *Client) ListAlarms(ctx context.Context) (AlarmList, error) {
+	resp, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "ListAlarms", nil)
+	if err != nil {
+		return AlarmList{}, err
+	}
+	alarms, err := ParseAlarmList(resp["CurrentAlarmList"])
+	if err != nil {
+		return AlarmList{}, err
+	}
+	return AlarmList{
+		Version: strings.TrimSpace(resp["CurrentAlarmListVersion"]),
+		Alarms:  alarms,
+	}, nil
+}
+
+// CreateAlarm creates a new alarm and returns the ID assigned by the household.
+func (c *Client) CreateAlarm(ctx context.Context, alarm Alarm) (int, error) {
+	args, err := alarmArgs(alarm)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "CreateAlarm", args)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(resp["AssignedID"]))
+	if err != nil {
+		return 0, fmt.Errorf("invalid AssignedID in response: %q", resp["AssignedID"])
+	}
+	return id, nil
+}
+
+// UpdateAlarm replaces every field of the alarm identified by alarm.ID.
+func (c *Client) UpdateAlarm(ctx context.Context, alarm Alarm) error {
+	if alarm.ID <= 0 {
+		return errors.New("alarm id is required")
+	}
+	args, err := alarmArgs(alarm)
+	if err != nil {
+		return err
+	}
+	args["ID"] = strconv.Itoa(alarm.ID)
+	_, err = c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "UpdateAlarm", args)
+	return err
+}
+
+func (c *Client) DestroyAlarm(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors.New("alarm id is required")
+	}
+	_, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "DestroyAlarm", map[string]string{
+		"ID": strconv.Itoa(id),
+	})
+	return err
+}
+
+func alarmArgs(a Alarm) (map[string]string, error) {
+	start, err := NormalizeAlarmTime(a.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	duration := a.Duration
+	if strings.TrimSpace(duration) == "" {
+		duration = "01:00:00"
+	}
+	duration, err = NormalizeAlarmTime(duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %w", err)
+	}
+	recurrence, err := NormalizeRecurrence(a.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(a.RoomUUID) == "" {
+		return nil, errors.New("alarm room is required")
+	}
+	if a.Volume < 0 || a.Volume > 100 {
+		return nil, errors.New("alarm volume must be 0-100")
+	}
+	mode := a.PlayMode
+	if mode == "" {
+		mode = PlayModeNormal
+	}
+	switch mode {
+	case PlayModeNormal, PlayModeShuffle, PlayModeShuffleNoRepeat, PlayModeRepeatAll, PlayModeRepeatOne:
+	default:
+		return nil, fmt.Errorf("invalid play mode: %q", mode)
+	}
+	uri := a.ProgramURI
+	if uri == "" {
+		uri = AlarmBuzzerURI
+	}
+	return map[string]string{
+		"StartLocalTime":     start,
+		"Duration":           duration,
+		"Recurrence":         recurrence,
+		"Enabled":            boolToSonos(a.Enabled),
+		"RoomUUID":           a.RoomUUID,
+		"ProgramURI":         uri,
+		"ProgramMetaData":    a.ProgramMetaData,
+		"PlayMode":           string(mode),
+		"Volume":             strconv.Itoa(a.Volume),
+		"IncludeLinkedZones": boolToSonos(a.IncludeLinkedZones),
+	}, nil
+}
+
+func boolToSonos(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+type alarmListXML struct {
+	Alarms []struct {
+		ID                 string `xml:"ID,attr"`
+		StartTime          string `xml:"StartTime,attr"`
+		Duration           string `xml:"Duration,attr"`
+		Recurrence         string `xml:"Recurrence,attr"`
+		Enabled            string `xml:"Enabled,attr"`
+		RoomUUID           string `xml:"RoomUUID,attr"`
+		ProgramURI         string `xml:"ProgramURI,attr"`
+		ProgramMetaData    string `xml:"ProgramMetaData,attr"`
+		PlayMode           string `xml:"PlayMode,attr"`
+		Volume             string `xml:"Volume,attr"`
+		IncludeLinkedZones string `xml:"IncludeLinkedZones,attr"`
+	} `xml:"Alarm"`
+}
+
+// ParseAlarmList parses the CurrentAlarmList XML returned by ListAlarms.
+func ParseAlarmList(payload string) ([]Alarm, error) {
+	payload = strings.TrimSpace(payload)
+	if payload == "" {
+		return nil, nil
+	}
+	var doc alarmListXML
+	if err := xml.Unmarshal([]byte(payload), &doc); err != nil {
+		return nil, err
+	}
+	out := make([]Alarm, 0, len(doc.Alarms))
+	for _, a := range doc.Alarms {
+		id, err := strconv.Atoi(strings.TrimSpace(a.ID))
+		if err != nil {
+			return nil, fmt.Errorf("invalid alarm ID: %q", a.ID)
+		}
+		vol, _ := strconv.Atoi(strings.TrimSpace(a.Volume))
+		out = append(out, Alarm{
+			ID:                 id,
+			StartTime:          a.StartTime,
+			Duration:           a.Duration,
+			Recurrence:         a.Recurrence,
+			Enabled:            a.Enabled == "1",
+			RoomUUID:           a.RoomUUID,
+			ProgramURI:         a.ProgramURI,
+			ProgramMetaData:    a.ProgramMetaData,
+			PlayMode:           PlayMode(a.PlayMode),
+			Volume:             vol,
+			IncludeLinkedZones: a.IncludeLinkedZones == "1",
+		})
+	}
+	return out, nil
+}
+
+// NormalizeAlarmTime accepts H:MM, HH:MM or HH:MM:SS and returns HH:MM:SS.
+func NormalizeAlarmTime(s string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return "", fmt.Errorf("invalid time %q (expected HH:MM or HH:MM:SS)", s)
+	}
+	limits := []int{23, 59, 59}
+	vals := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > limits[i] || (i > 0 && len(p) != 2) {
+			return "", fmt.Errorf("invalid time %q (expected HH:MM or HH:MM:SS)", s)
+		}
+		vals[i] = n
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", vals[0], vals[1], vals[2]), nil
+}
+
+var recurrenceDays = map[string]byte{
+	"sun": '0', "mon": '1', "tue": '2', "wed": '3', "thu": '4', "fri": '5', "sat": '6',
+}
+
+// NormalizeRecurrence maps user input to a Sonos recurrence string.
+// Accepted: once, daily, weekdays, weekends, a raw ON_<digits> value (0=Sunday)
+// or a comma-separated day list such as "mon,wed,fri".
+func NormalizeRecurrence(s string) (string, error) {
+	v := strings.TrimSpace(s)
+	switch strings.ToUpper(v) {
+	case "", "ONCE":
+		return "ONCE", nil
+	case "DAILY":
+		return "DAILY", nil
+	case "WEEKDAYS":
+		return "WEEKDAYS", nil
+	case "WEEKENDS":
+		return "WEEKENDS", nil
+	}
+
+	var days [7]bool
+	if rest, ok := strings.CutPrefix(strings.ToUpper(v), "ON_"); ok {
+		if rest == "" {
+			return "", fmt.Errorf("invalid recurrence: %q", s)
+		}
+		for _, r := range rest {
+			if r < '0' || r > '6' {
+				return "", fmt.Errorf("invalid recurrence: %q", s)
+			}
+			days[r-'0'] = true
+		}
+	} else {
+		for _, part := range strings.Split(v, ",") {
+			key := strings.ToLower(strings.TrimSpace(part))
+			if len(key) > 3 {
+				key = key[:3]
+			}
+			d, ok := recurrenceDays[key]
+			if !ok {
+				return "", fmt.Errorf("invalid recurrence: %q (expected once|daily|weekdays|weekends|mon,tue,...)", s)
+			}
+			days[d-'0'] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("ON_")
+	for i, on := range days {
+		if on {
+			b.WriteByte(byte('0' + i))
+		}
+	}
+	return b.String(), nil
+}
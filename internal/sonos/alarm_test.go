@@ -0,0 +1,179 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlarmList(t *testing.T) {
+	t.Parallel()
+
+	payload := `<Alarms>` +
+		`<Alarm ID="3" StartTime="07:00:00" Duration="01:00:00" Recurrence="WEEKDAYS" Enabled="1" RoomUUID="RINCON_A" ProgramURI="x-rincon-buzzer:0" ProgramMetaData="" PlayMode="SHUFFLE_NOREPEAT" Volume="25" IncludeLinkedZones="0"/>` +
+		`<Alarm ID="7" StartTime="09:30:00" Duration="00:30:00" Recurrence="ON_06" Enabled="0" RoomUUID="RINCON_B" ProgramURI="x-sonosapi-stream:s1" ProgramMetaData="&lt;DIDL-Lite/&gt;" PlayMode="NORMAL" Volume="10" IncludeLinkedZones="1"/>` +
+		`</Alarms>`
+
+	alarms, err := ParseAlarmList(payload)
+	if err != nil {
+		t.Fatalf("ParseAlarmList: %v", err)
+	}
+	if len(alarms) != 2 {
+		t.Fatalf("len: %d", len(alarms))
+	}
+	a := alarms[0]
+	if a.ID != 3 || a.StartTime != "07:00:00" || !a.Enabled || a.PlayMode != PlayModeShuffleNoRepeat || a.Volume != 25 || a.IncludeLinkedZones {
+		t.Fatalf("unexpected alarm: %+v", a)
+	}
+	b := alarms[1]
+	if b.ID != 7 || b.Enabled || b.Recurrence != "ON_06" || b.ProgramMetaData != "<DIDL-Lite/>" || !b.IncludeLinkedZones {
+		t.Fatalf("unexpected alarm: %+v", b)
+	}
+
+	if _, err := ParseAlarmList(`<Alarms><Alarm ID="x"/></Alarms>`); err == nil {
+		t.Fatalf("expected error for invalid ID")
+	}
+	if got, err := ParseAlarmList("  "); err != nil || got != nil {
+		t.Fatalf("empty: %v %v", got, err)
+	}
+}
+
+func TestNormalizeRecurrence(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"":              "ONCE",
+		"once":          "ONCE",
+		"Daily":         "DAILY",
+		"weekdays":      "WEEKDAYS",
+		"WEEKENDS":      "WEEKENDS",
+		"on_531":        "ON_135",
+		"mon,wed,fri":   "ON_135",
+		"Sunday, Sat":   "ON_06",
+		"tue,tue,thurs": "ON_24",
+	}
+	for in, want := range cases {
+		got, err := NormalizeRecurrence(in)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeRecurrence(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"ON_", "ON_7", "someday", "mon,,fri"} {
+		if _, err := NormalizeRecurrence(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestNormalizeAlarmTime(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"7:00":     "07:00:00",
+		"07:05":    "07:05:00",
+		"23:59:30": "23:59:30",
+	}
+	for in, want := range cases {
+		got, err := NormalizeAlarmTime(in)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeAlarmTime(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "7", "24:00", "07:60", "07:5", "a:bc"} {
+		if _, err := NormalizeAlarmTime(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAlarmClockActions(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path != "/AlarmClock/Control" {
+			t.Fatalf("path: %s", r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		action := r.Header.Get("SOAPACTION")
+		switch {
+		case strings.Contains(action, "#ListAlarms"):
+			return httpResponse(200, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>
+<u:ListAlarmsResponse xmlns:u="urn:schemas-upnp-org:service:AlarmClock:1">
+<CurrentAlarmList>&lt;Alarms&gt;&lt;Alarm ID="1" StartTime="07:00:00" Duration="01:00:00" Recurrence="DAILY" Enabled="1" RoomUUID="RINCON_A" ProgramURI="x-rincon-buzzer:0" ProgramMetaData="" PlayMode="NORMAL" Volume="20" IncludeLinkedZones="0"/&gt;&lt;/Alarms&gt;</CurrentAlarmList>
+<CurrentAlarmListVersion>RINCON_A:12</CurrentAlarmListVersion>
+</u:ListAlarmsResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#CreateAlarm"):
+			return httpResponse(200, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>
+<u:CreateAlarmResponse xmlns:u="urn:schemas-upnp-org:service:AlarmClock:1"><AssignedID>5</AssignedID></u:CreateAlarmResponse>
+</s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#UpdateAlarm"), strings.Contains(action, "#DestroyAlarm"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+		default:
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+			return nil, nil
+		}
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+	ctx := context.Background()
+
+	list, err := c.ListAlarms(ctx)
+	if err != nil {
+		t.Fatalf("ListAlarms: %v", err)
+	}
+	if list.Version != "RINCON_A:12" || len(list.Alarms) != 1 || list.Alarms[0].Recurrence != "DAILY" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if _, ok := list.Find(1); !ok {
+		t.Fatalf("Find(1) failed")
+	}
+
+	id, err := c.CreateAlarm(ctx, Alarm{StartTime: "6:45", Recurrence: "mon,fri", RoomUUID: "RINCON_A", Volume: 15, Enabled: true})
+	if err != nil || id != 5 {
+		t.Fatalf("CreateAlarm: %d %v", id, err)
+	}
+	create := bodies[len(bodies)-1]
+	for _, want := range []string{
+		"<StartLocalTime>06:45:00</StartLocalTime>",
+		"<Duration>01:00:00</Duration>",
+		"<Recurrence>ON_15</Recurrence>",
+		"<ProgramURI>x-rincon-buzzer:0</ProgramURI>",
+		"<PlayMode>NORMAL</PlayMode>",
+		"<Enabled>1</Enabled>",
+	} {
+		if !strings.Contains(create, want) {
+			t.Fatalf("CreateAlarm body missing %s: %s", want, create)
+		}
+	}
+
+	a := list.Alarms[0]
+	a.Enabled = false
+	if err := c.UpdateAlarm(ctx, a); err != nil {
+		t.Fatalf("UpdateAlarm: %v", err)
+	}
+	if update := bodies[len(bodies)-1]; !strings.Contains(update, "<ID>1</ID>") || !strings.Contains(update, "<Enabled>0</Enabled>") {
+		t.Fatalf("unexpected UpdateAlarm body: %s", update)
+	}
+	if err := c.DestroyAlarm(ctx, 1); err != nil {
+		t.Fatalf("DestroyAlarm: %v", err)
+	}
+
+	if _, err := c.CreateAlarm(ctx, Alarm{StartTime: "07:00"}); err == nil {
+		t.Fatalf("expected missing room error")
+	}
+	if _, err := c.CreateAlarm(ctx, Alarm{StartTime: "07:00", RoomUUID: "R", Volume: 101}); err == nil {
+		t.Fatalf("expected volume error")
+	}
+	if err := c.UpdateAlarm(ctx, Alarm{}); err == nil {
+		t.Fatalf("expected missing id error")
+	}
+	if err := c.DestroyAlarm(ctx, 0); err == nil {
+		t.Fatalf("expected missing id error")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 func (c *Client) Play(ctx context.Context) error {
@@ -201,6 +202,34 @@ func (c *Client) GetTransportInfo(ctx context.Context) (TransportInfo, error) {
 	}, nil
 }
 
+// MediaInfo describes the source loaded into the transport (AVTransport
+// GetMediaInfo). For queue playback CurrentURI is x-rincon-queue:<UUID>#0.
+// Speakers usually report MediaDuration as NOT_IMPLEMENTED.
+type MediaInfo struct {
+	NrTracks           int
+	MediaDuration      string
+	CurrentURI         string
+	CurrentURIMetaData string
+	PlayMedium         string
+}
+
+func (c *Client) GetMediaInfo(ctx context.Context) (MediaInfo, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetMediaInfo", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return MediaInfo{}, err
+	}
+	n, _ := strconv.Atoi(resp["NrTracks"])
+	return MediaInfo{
+		NrTracks:           n,
+		MediaDuration:      resp["MediaDuration"],
+		CurrentURI:         resp["CurrentURI"],
+		CurrentURIMetaData: resp["CurrentURIMetaData"],
+		PlayMedium:         resp["PlayMedium"],
+	}, nil
+}
+
 // PlayMode represents the playback mode (shuffle/repeat settings).
 // Valid values: NORMAL, SHUFFLE, SHUFFLE_NOREPEAT, REPEAT_ALL, REPEAT_ONE
 type PlayMode string
@@ -249,3 +278,54 @@ func (c *Client) SetPlayMode(ctx context.Context, mode PlayMode) error {
 	})
 	return err
 }
+
+// GetCrossfadeMode reports whether crossfading between tracks is enabled.
+func (c *Client) GetCrossfadeMode(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetCrossfadeMode", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp["CrossfadeMode"] == "1", nil
+}
+
+// SetCrossfadeMode enables or disables crossfading between tracks.
+func (c *Client) SetCrossfadeMode(ctx context.Context, on bool) error {
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "SetCrossfadeMode", map[string]string{
+		"InstanceID":    "0",
+		"CrossfadeMode": boolToSonos(on),
+	})
+	return err
+}
+
+// GetCurrentTransportActions returns the transport actions the current source
+// accepts, e.g. [Set Stop Pause Play Seek Next Previous SeekTrackNr]. The
+// X_DLNA_SeekTime and X_DLNA_SeekTrackNr actions are reported as Seek and
+// SeekTrackNr.
+func (c *Client) GetCurrentTransportActions(ctx context.Context) ([]string, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetCurrentTransportActions", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseTransportActions(resp["Actions"]), nil
+}
+
+func parseTransportActions(v string) []string {
+	var out []string
+	for _, a := range strings.Split(v, ",") {
+		a = strings.TrimSpace(a)
+		switch a {
+		case "":
+			continue
+		case "X_DLNA_SeekTime":
+			a = "Seek"
+		case "X_DLNA_SeekTrackNr":
+			a = "SeekTrackNr"
+		}
+		out = append(out, a)
+	}
+	return out
+}
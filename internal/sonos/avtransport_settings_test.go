@@ -0,0 +1,60 @@
+package sonos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func TestCrossfadeMediaInfoAndActions(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	if on, err := c.GetCrossfadeMode(ctx); err != nil || on {
+		t.Fatalf("GetCrossfadeMode = %v, %v", on, err)
+	}
+	if err := c.SetCrossfadeMode(ctx, true); err != nil {
+		t.Fatalf("SetCrossfadeMode: %v", err)
+	}
+	if on, err := c.GetCrossfadeMode(ctx); err != nil || !on || !kitchen.State().Crossfade {
+		t.Fatalf("crossfade not enabled: %v, %v", on, err)
+	}
+
+	if actions, err := c.GetCurrentTransportActions(ctx); err != nil || !reflect.DeepEqual(actions, []string{"Set"}) {
+		t.Fatalf("actions with nothing loaded = %v, %v", actions, err)
+	}
+
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "One", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Two", Duration: "0:03:00"},
+	)
+	media, err := c.GetMediaInfo(ctx)
+	if err != nil || media.NrTracks != 2 || media.MediaDuration != "NOT_IMPLEMENTED" || media.PlayMedium != "NETWORK" {
+		t.Fatalf("GetMediaInfo = %+v, %v", media, err)
+	}
+	actions, err := c.GetCurrentTransportActions(ctx)
+	if err != nil {
+		t.Fatalf("GetCurrentTransportActions: %v", err)
+	}
+	want := []string{"Set", "Stop", "Pause", "Play", "Seek", "Next", "Previous", "SeekTrackNr"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+}
+
+func TestParseTransportActions(t *testing.T) {
+	t.Parallel()
+
+	got := parseTransportActions(" Set, Stop,,Play , X_DLNA_SeekTime")
+	if !reflect.DeepEqual(got, []string{"Set", "Stop", "Play", "Seek"}) {
+		t.Fatalf("parseTransportActions = %v", got)
+	}
+	if got := parseTransportActions(""); got != nil {
+		t.Fatalf("expected nil, got %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNoBattery is returned by GetBatteryStatus for speakers without a
+// battery (everything but the portable Roam and Move models).
+var ErrNoBattery = errors.New("speaker has no battery")
+
+// PowerSourceBattery is the PowerSource a portable reports when it runs on
+// its battery; anything else (SONOS_CHARGING_RING, USB_POWER) means it is
+// on external power.
+const PowerSourceBattery = "BATTERY"
+
+// BatteryStatus is a portable speaker's battery as reported on
+// /status/batterystatus.
+type BatteryStatus struct {
+	Level       int    `json:"level"` // percent
+	PowerSource string `json:"powerSource,omitempty"`
+	// Charging is set whenever the speaker is on external power; the
+	// speaker does not report a separate "full" state.
+	Charging    bool   `json:"charging"`
+	Temperature string `json:"temperature,omitempty"`
+	Health      string `json:"health,omitempty"`
+}
+
+// IsPortableModel reports whether a model name (from the device description,
+// e.g. "Sonos Roam SL") is one of the battery-powered speakers.
+func IsPortableModel(model string) bool {
+	model = strings.ToLower(model)
+	return strings.Contains(model, "roam") || strings.Contains(model, "move")
+}
+
+// GetBatteryStatus reads the battery status page. Speakers without a battery
+// serve an empty page or refuse it with a 4xx, both reported as ErrNoBattery.
+func (c *Client) GetBatteryStatus(ctx context.Context) (BatteryStatus, error) {
+	text, err := c.statusPage(ctx, "/status/batterystatus")
+	var pageErr *statusPageError
+	if errors.As(err, &pageErr) && pageErr.StatusCode >= 400 && pageErr.StatusCode < 500 {
+		return BatteryStatus{}, ErrNoBattery
+	}
+	if err != nil {
+		return BatteryStatus{}, err
+	}
+	return parseBatteryStatus(text)
+}
+
+func parseBatteryStatus(text string) (BatteryStatus, error) {
+	var page struct {
+		Battery *struct {
+			Data []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"Data"`
+		} `xml:"LocalBatteryStatus"`
+	}
+	if err := xml.Unmarshal([]byte(text), &page); err != nil {
+		return BatteryStatus{}, err
+	}
+	if page.Battery == nil || len(page.Battery.Data) == 0 {
+		return BatteryStatus{}, ErrNoBattery
+	}
+	var out BatteryStatus
+	for _, d := range page.Battery.Data {
+		v := strings.TrimSpace(d.Value)
+		switch d.Name {
+		case "Level":
+			out.Level, _ = strconv.Atoi(v)
+		case "PowerSource":
+			out.PowerSource = v
+		case "Temperature":
+			out.Temperature = v
+		case "Health":
+			out.Health = v
+		}
+	}
+	out.Charging = out.PowerSource != "" && out.PowerSource != PowerSourceBattery
+	return out, nil
+}
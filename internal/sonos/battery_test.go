@@ -0,0 +1,72 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func TestParseBatteryStatus(t *testing.T) {
+	page := `<?xml version="1.0" ?>
+<ZPSupportInfo>
+  <LocalBatteryStatus>
+    <Data name="Health">GREEN</Data>
+    <Data name="Level">87</Data>
+    <Data name="Temperature">NORMAL</Data>
+    <Data name="PowerSource">SONOS_CHARGING_RING</Data>
+  </LocalBatteryStatus>
+</ZPSupportInfo>`
+	got, err := parseBatteryStatus(page)
+	if err != nil {
+		t.Fatalf("parseBatteryStatus: %v", err)
+	}
+	want := BatteryStatus{Level: 87, PowerSource: "SONOS_CHARGING_RING", Charging: true, Temperature: "NORMAL", Health: "GREEN"}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	got, err = parseBatteryStatus(`<ZPSupportInfo><LocalBatteryStatus><Data name="Level">40</Data><Data name="PowerSource">BATTERY</Data></LocalBatteryStatus></ZPSupportInfo>`)
+	if err != nil || got.Charging || got.Level != 40 {
+		t.Fatalf("on battery: %+v, %v", got, err)
+	}
+
+	if _, err := parseBatteryStatus(`<?xml version="1.0" ?><ZPSupportInfo></ZPSupportInfo>`); !errors.Is(err, ErrNoBattery) {
+		t.Fatalf("empty page: %v", err)
+	}
+}
+
+func TestGetBatteryStatusFromFakeSpeaker(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Roam")
+	h.Speaker("Roam").SetBattery(sonostest.Battery{Level: 63, PowerSource: "BATTERY", Temperature: "NORMAL", Health: "GREEN"})
+	ctx := context.Background()
+
+	got, err := NewClient(h.Speaker("Roam").IP, 2*time.Second).GetBatteryStatus(ctx)
+	if err != nil || got.Level != 63 || got.Charging || got.Health != "GREEN" {
+		t.Fatalf("Roam battery: %+v, %v", got, err)
+	}
+	if _, err := NewClient(h.Speaker("Kitchen").IP, 2*time.Second).GetBatteryStatus(ctx); !errors.Is(err, ErrNoBattery) {
+		t.Fatalf("Kitchen battery error = %v, want ErrNoBattery", err)
+	}
+	h.Speaker("Kitchen").SetStatusPages(false)
+	if _, err := NewClient(h.Speaker("Kitchen").IP, 2*time.Second).GetBatteryStatus(ctx); !errors.Is(err, ErrNoBattery) {
+		t.Fatalf("Kitchen battery error on 403 = %v, want ErrNoBattery", err)
+	}
+}
+
+func TestIsPortableModel(t *testing.T) {
+	for model, want := range map[string]bool{
+		"Sonos Roam":    true,
+		"Sonos Roam SL": true,
+		"Sonos Move 2":  true,
+		"Sonos One":     false,
+		"Sonos Arc":     false,
+		"":              false,
+	} {
+		if got := IsPortableModel(model); got != want {
+			t.Errorf("IsPortableModel(%q) = %v", model, got)
+		}
+	}
+}
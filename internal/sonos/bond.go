@@ -0,0 +1,132 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Channel assignments used in ChannelMapSet / HTSatChanMapSet.
+const (
+	ChannelsLeft          = "LF,LF"
+	ChannelsRight         = "RF,RF"
+	ChannelsHomeTheater   = "LF,RF"
+	ChannelsSub           = "SW"
+	ChannelsSurroundLeft  = "LR"
+	ChannelsSurroundRight = "RR"
+)
+
+// BondChannel is one entry of a ChannelMapSet (stereo pair) or
+// HTSatChanMapSet (home theater): a device UUID and the channels it plays.
+type BondChannel struct {
+	UUID     string
+	Channels string
+}
+
+// ParseChannelMapSet parses "RINCON_A:LF,LF;RINCON_B:RF,RF".
+func ParseChannelMapSet(v string) []BondChannel {
+	var out []BondChannel
+	for _, part := range strings.Split(v, ";") {
+		uuid, channels, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || uuid == "" {
+			continue
+		}
+		out = append(out, BondChannel{UUID: uuid, Channels: channels})
+	}
+	return out
+}
+
+// FormatChannelMapSet is the inverse of ParseChannelMapSet.
+func FormatChannelMapSet(set []BondChannel) string {
+	parts := make([]string, 0, len(set))
+	for _, c := range set {
+		parts = append(parts, c.UUID+":"+c.Channels)
+	}
+	return strings.Join(parts, ";")
+}
+
+// BondRole names what a bonded device does in its room (left, right, sub,
+// surround-left, surround-right, or home-theater for the soundbar).
+func BondRole(channels string) string {
+	switch strings.ToUpper(strings.TrimSpace(channels)) {
+	case "":
+		return ""
+	case ChannelsLeft:
+		return "left"
+	case ChannelsRight:
+		return "right"
+	case ChannelsHomeTheater:
+		return "home-theater"
+	case ChannelsSub, "SW,SW":
+		return "sub"
+	case ChannelsSurroundLeft, "LR,LR":
+		return "surround-left"
+	case ChannelsSurroundRight, "RR,RR":
+		return "surround-right"
+	}
+	return strings.ToLower(channels)
+}
+
+// CreateStereoPair bonds rightUUID to this speaker, which becomes the left
+// channel and keeps its room name.
+func (c *Client) CreateStereoPair(ctx context.Context, leftUUID, rightUUID string) error {
+	if leftUUID == "" || rightUUID == "" || leftUUID == rightUUID {
+		return errors.New("stereo pair needs two different speakers")
+	}
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "CreateStereoPair", map[string]string{
+		"ChannelMapSet": FormatChannelMapSet([]BondChannel{
+			{UUID: leftUUID, Channels: ChannelsLeft},
+			{UUID: rightUUID, Channels: ChannelsRight},
+		}),
+	})
+	return err
+}
+
+// SeparateStereoPair splits the pair described by channelMapSet; it must be
+// sent to the pair's left (primary) speaker.
+func (c *Client) SeparateStereoPair(ctx context.Context, channelMapSet string) error {
+	if len(ParseChannelMapSet(channelMapSet)) < 2 {
+		return fmt.Errorf("invalid stereo pair channel map %q", channelMapSet)
+	}
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SeparateStereoPair", map[string]string{
+		"ChannelMapSet": channelMapSet,
+	})
+	return err
+}
+
+// AddHTSatellites attaches a sub and/or surrounds to the home-theater speaker
+// this client talks to (soundbarUUID).
+func (c *Client) AddHTSatellites(ctx context.Context, soundbarUUID string, satellites []BondChannel) error {
+	if len(satellites) == 0 {
+		return errors.New("no satellites to add")
+	}
+	set := append([]BondChannel{{UUID: soundbarUUID, Channels: ChannelsHomeTheater}}, satellites...)
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "AddHTSatellite", map[string]string{
+		"HTSatChanMapSet": FormatChannelMapSet(set),
+	})
+	return err
+}
+
+// RemoveHTSatellite detaches one sub or surround; it becomes a standalone room.
+func (c *Client) RemoveHTSatellite(ctx context.Context, satelliteUUID string) error {
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "RemoveHTSatellite", map[string]string{
+		"SatRoomUUID": satelliteUUID,
+	})
+	return err
+}
+
+// BondedDevices returns the other physical devices bonded into the room whose
+// primary device is primaryUUID (the right speaker of a stereo pair, a
+// home theater's sub and surrounds).
+func (t Topology) BondedDevices(primaryUUID string) []Member {
+	var out []Member
+	for _, g := range t.Groups {
+		for _, m := range g.Members {
+			if m.BondedTo == primaryUUID && m.UUID != primaryUUID {
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
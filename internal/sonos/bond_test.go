@@ -0,0 +1,122 @@
+package sonos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChannelMapSetRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	set := ParseChannelMapSet("RINCON_A:LF,LF; RINCON_B:RF,RF;bogus")
+	want := []BondChannel{{UUID: "RINCON_A", Channels: "LF,LF"}, {UUID: "RINCON_B", Channels: "RF,RF"}}
+	if !reflect.DeepEqual(set, want) {
+		t.Fatalf("ParseChannelMapSet = %+v", set)
+	}
+	if got := FormatChannelMapSet(set); got != "RINCON_A:LF,LF;RINCON_B:RF,RF" {
+		t.Fatalf("FormatChannelMapSet = %q", got)
+	}
+	for channels, role := range map[string]string{"LF,LF": "left", "RF,RF": "right", "LF,RF": "home-theater", "SW": "sub", "LR": "surround-left", "RR": "surround-right", "": ""} {
+		if got := BondRole(channels); got != role {
+			t.Fatalf("BondRole(%q) = %q, want %q", channels, got, role)
+		}
+	}
+}
+
+func TestParseZoneGroupStateXML_Bonds(t *testing.T) {
+	payload := `
+<ZoneGroupState>
+  <ZoneGroups>
+    <ZoneGroup Coordinator="RINCON_L1400" ID="RINCON_L1400:1">
+      <ZoneGroupMember ZoneName="Office" UUID="RINCON_L1400" Location="http://192.168.1.10:1400/xml/device_description.xml" Invisible="0" ChannelMapSet="RINCON_L1400:LF,LF;RINCON_R1400:RF,RF" />
+      <ZoneGroupMember ZoneName="Office" UUID="RINCON_R1400" Location="http://192.168.1.11:1400/xml/device_description.xml" Invisible="1" ChannelMapSet="RINCON_L1400:LF,LF;RINCON_R1400:RF,RF" />
+    </ZoneGroup>
+    <ZoneGroup Coordinator="RINCON_SB1400" ID="RINCON_SB1400:1">
+      <ZoneGroupMember ZoneName="Living Room" UUID="RINCON_SB1400" Location="http://192.168.1.20:1400/xml/device_description.xml" Invisible="0" HTSatChanMapSet="RINCON_SB1400:LF,RF;RINCON_SUB1400:SW;RINCON_SL1400:LR;RINCON_SR1400:RR">
+        <Satellite ZoneName="Living Room" UUID="RINCON_SUB1400" Location="http://192.168.1.21:1400/xml/device_description.xml" Invisible="1" HTSatChanMapSet="RINCON_SB1400:LF,RF;RINCON_SUB1400:SW" />
+        <Satellite ZoneName="Living Room" UUID="RINCON_SL1400" Location="http://192.168.1.22:1400/xml/device_description.xml" Invisible="1" />
+        <Satellite ZoneName="Living Room" UUID="RINCON_SR1400" Location="http://192.168.1.23:1400/xml/device_description.xml" Invisible="1" />
+      </ZoneGroupMember>
+    </ZoneGroup>
+  </ZoneGroups>
+</ZoneGroupState>`
+
+	top, err := parseZoneGroupStateXML(payload)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	left, _ := top.FindByIP("192.168.1.10")
+	right, _ := top.FindByIP("192.168.1.11")
+	if left.Role != "left" || left.BondedTo != "" || right.Role != "right" || right.BondedTo != "RINCON_L1400" {
+		t.Fatalf("stereo pair: %+v %+v", left, right)
+	}
+	sb, _ := top.FindByName("Living Room")
+	if sb.UUID != "RINCON_SB1400" || sb.Role != "home-theater" {
+		t.Fatalf("soundbar: %+v", sb)
+	}
+	var roles []string
+	for _, m := range top.BondedDevices("RINCON_SB1400") {
+		roles = append(roles, m.Role)
+	}
+	if !reflect.DeepEqual(roles, []string{"sub", "surround-left", "surround-right"}) {
+		t.Fatalf("satellite roles: %v", roles)
+	}
+	if len(top.BondedDevices("RINCON_L1400")) != 1 {
+		t.Fatalf("BondedDevices(stereo) = %+v", top.BondedDevices("RINCON_L1400"))
+	}
+}
+
+func TestStereoPairAndHTSatellites(t *testing.T) {
+	h := newSnapshotHousehold(t, "Office", "Office R", "Living Room", "Sub")
+	office, officeR := h.Speaker("Office"), h.Speaker("Office R")
+	lr, sub := h.Speaker("Living Room"), h.Speaker("Sub")
+	ctx := context.Background()
+	c := NewClient(office.IP, 2*time.Second)
+
+	if err := c.CreateStereoPair(ctx, office.UUID, officeR.UUID); err != nil {
+		t.Fatalf("CreateStereoPair: %v", err)
+	}
+	top, err := c.GetTopology(ctx)
+	if err != nil {
+		t.Fatalf("GetTopology: %v", err)
+	}
+	r, ok := top.FindByIP(officeR.IP)
+	if !ok || r.IsVisible || r.Role != "right" || r.BondedTo != office.UUID || r.Name != "Office" {
+		t.Fatalf("right speaker: %v %+v", ok, r)
+	}
+	if _, ok := top.FindByName("Office R"); ok {
+		t.Fatalf("bonded speaker still listed as a room")
+	}
+
+	lc := NewClient(lr.IP, 2*time.Second)
+	if err := lc.AddHTSatellites(ctx, lr.UUID, []BondChannel{{UUID: sub.UUID, Channels: ChannelsSub}}); err != nil {
+		t.Fatalf("AddHTSatellites: %v", err)
+	}
+	top, _ = c.GetTopology(ctx)
+	if sats := top.BondedDevices(lr.UUID); len(sats) != 1 || sats[0].Role != "sub" {
+		t.Fatalf("satellites: %+v", sats)
+	}
+	if err := lc.RemoveHTSatellite(ctx, sub.UUID); err != nil {
+		t.Fatalf("RemoveHTSatellite: %v", err)
+	}
+
+	var pair []BondChannel
+	top, _ = c.GetTopology(ctx)
+	for _, m := range append([]Member{top.ByIP[office.IP]}, top.BondedDevices(office.UUID)...) {
+		pair = append(pair, BondChannel{UUID: m.UUID, Channels: m.Channels})
+	}
+	if err := c.SeparateStereoPair(ctx, FormatChannelMapSet(pair)); err != nil {
+		t.Fatalf("SeparateStereoPair: %v", err)
+	}
+	top, _ = c.GetTopology(ctx)
+	for _, name := range []string{"Office", "Office R", "Sub"} {
+		if m, ok := top.FindByName(name); !ok || !m.IsVisible || m.Role != "" {
+			t.Fatalf("%s not standalone again: %v %+v", name, ok, m)
+		}
+	}
+	if err := c.SeparateStereoPair(ctx, "bogus"); err == nil {
+		t.Fatalf("expected channel map error")
+	}
+}
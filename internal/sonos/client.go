@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -11,6 +12,18 @@ type Client struct {
 	IP   string
 	Port int
 	HTTP *http.Client
+
+	// VolumeLimit, when set, caps every volume this client writes
+	// (SetVolume, SetRelativeVolume, RampToVolume and the group variants).
+	VolumeLimit VolumeLimit
+
+	// limitRoom and limitGroup cache what VolumeLimit is resolved against,
+	// so a command writing volume repeatedly (a fade) does not re-read the
+	// topology on every write.
+	limitMu      sync.Mutex
+	limitRoom    string
+	limitGroup   []Member
+	limitGroupAt time.Time
 }
 
 func NewClient(ip string, timeout time.Duration) *Client {
@@ -24,31 +24,46 @@ type deviceDescription struct {
 		DeviceType   string `xml:"deviceType"`
 		RoomName     string `xml:"roomName"`
 		Manufacturer string `xml:"manufacturer"`
+		ModelName    string `xml:"modelName"`
+		ModelNumber  string `xml:"modelNumber"`
+		DisplayName  string `xml:"displayName"`
+		SerialNum    string `xml:"serialNum"`
+		SoftwareVer  string `xml:"softwareVersion"`
+		HardwareVer  string `xml:"hardwareVersion"`
+		SeriesID     string `xml:"seriesid"`
 		UDN          string `xml:"UDN"`
 	} `xml:"device"`
 }
 
-func fetchDeviceDescription(ctx context.Context, httpClient *http.Client, locationURL string) (name, udn, ip string, err error) {
+func getDeviceDescription(ctx context.Context, httpClient *http.Client, locationURL string) (deviceDescription, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, locationURL, nil)
 	if err != nil {
-		return "", "", "", err
+		return deviceDescription{}, err
 	}
 	resp, err := doRequest(ctx, httpClient, req)
 	if err != nil {
-		return "", "", "", err
+		return deviceDescription{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return "", "", "", fmt.Errorf("device description: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		return deviceDescription{}, fmt.Errorf("device description: %s: %s", resp.Status, strings.TrimSpace(string(body)))
 	}
 	b, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
 	if err != nil {
-		return "", "", "", err
+		return deviceDescription{}, err
 	}
 
 	var dd deviceDescription
 	if err := xml.Unmarshal(b, &dd); err != nil {
+		return deviceDescription{}, err
+	}
+	return dd, nil
+}
+
+func fetchDeviceDescription(ctx context.Context, httpClient *http.Client, locationURL string) (name, udn, ip string, err error) {
+	dd, err := getDeviceDescription(ctx, httpClient, locationURL)
+	if err != nil {
 		return "", "", "", err
 	}
 
@@ -99,6 +114,15 @@ func defaultHTTPClient(timeout time.Duration) *http.Client {
 	}
 }
 
+// GetModelName returns the speaker's model name (e.g. "Sonos One").
+func (c *Client) GetModelName(ctx context.Context) (string, error) {
+	dd, err := getDeviceDescription(ctx, c.HTTP, c.baseURL()+"/xml/device_description.xml")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(dd.Device.ModelName), nil
+}
+
 func (c *Client) GetDeviceDescription(ctx context.Context) (Device, error) {
 	location := c.baseURL() + "/xml/device_description.xml"
 	name, udn, ip, err := fetchDeviceDescription(ctx, c.HTTP, location)
@@ -0,0 +1,205 @@
+package sonos
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeviceInfo is the hardware/firmware identity of one ZonePlayer, gathered
+// from the device description, DeviceProperties GetZoneInfo and
+// GetZoneAttributes, and (where the firmware still serves them) the /status
+// diagnostic pages.
+type DeviceInfo struct {
+	IP                     string             `json:"ip"`
+	Name                   string             `json:"name"`
+	UDN                    string             `json:"udn"`
+	ModelName              string             `json:"modelName"`
+	ModelNumber            string             `json:"modelNumber,omitempty"`
+	DisplayName            string             `json:"displayName,omitempty"`
+	SerialNumber           string             `json:"serialNumber,omitempty"`
+	MACAddress             string             `json:"macAddress,omitempty"`
+	SoftwareVersion        string             `json:"softwareVersion,omitempty"`
+	DisplaySoftwareVersion string             `json:"displaySoftwareVersion,omitempty"`
+	HardwareVersion        string             `json:"hardwareVersion,omitempty"`
+	SeriesID               string             `json:"seriesID,omitempty"`
+	Configuration          string             `json:"configuration,omitempty"`
+	UptimeSeconds          int64              `json:"uptimeSeconds,omitempty"`
+	Network                []NetworkInterface `json:"network,omitempty"`
+}
+
+// Uptime returns the reported uptime (0 when the status page was unavailable).
+func (d DeviceInfo) Uptime() time.Duration {
+	return time.Duration(d.UptimeSeconds) * time.Second
+}
+
+// NetworkInterface is one interface from the /status/ifconfig page.
+type NetworkInterface struct {
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	Netmask    string `json:"netmask,omitempty"`
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
+// GetDeviceInfo collects DeviceInfo for this speaker. The device description
+// and GetZoneInfo are required; zone attributes and the /status pages are
+// best-effort since newer firmware no longer serves most of them.
+func (c *Client) GetDeviceInfo(ctx context.Context) (DeviceInfo, error) {
+	dd, err := getDeviceDescription(ctx, c.HTTP, c.baseURL()+"/xml/device_description.xml")
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	zi, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetZoneInfo", nil)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	info := DeviceInfo{
+		IP:                     c.IP,
+		Name:                   strings.TrimSpace(dd.Device.RoomName),
+		UDN:                    strings.TrimPrefix(strings.TrimSpace(dd.Device.UDN), "uuid:"),
+		ModelName:              strings.TrimSpace(dd.Device.ModelName),
+		ModelNumber:            strings.TrimSpace(dd.Device.ModelNumber),
+		DisplayName:            strings.TrimSpace(dd.Device.DisplayName),
+		SerialNumber:           firstNonEmpty(zi["SerialNumber"], dd.Device.SerialNum),
+		MACAddress:             strings.TrimSpace(zi["MACAddress"]),
+		SoftwareVersion:        firstNonEmpty(zi["SoftwareVersion"], dd.Device.SoftwareVer),
+		DisplaySoftwareVersion: strings.TrimSpace(zi["DisplaySoftwareVersion"]),
+		HardwareVersion:        firstNonEmpty(zi["HardwareVersion"], dd.Device.HardwareVer),
+		SeriesID:               strings.TrimSpace(dd.Device.SeriesID),
+	}
+
+	if za, err := c.GetZoneAttributes(ctx); err == nil {
+		if za.Name != "" {
+			info.Name = za.Name
+		}
+		info.Configuration = strings.TrimSpace(za.Configuration)
+	} else {
+		slog.Debug("device info: zone attributes unavailable", "ip", c.IP, "err", errString(err))
+	}
+
+	if text, err := c.statusPage(ctx, "/status/proc/uptime"); err == nil {
+		info.UptimeSeconds = parseProcUptime(text)
+	} else {
+		slog.Debug("device info: uptime unavailable", "ip", c.IP, "err", errString(err))
+	}
+	if text, err := c.statusPage(ctx, "/status/ifconfig"); err == nil {
+		info.Network = parseIfconfig(text)
+	} else {
+		slog.Debug("device info: ifconfig unavailable", "ip", c.IP, "err", errString(err))
+	}
+	return info, nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// statusPage fetches one of the /status diagnostic pages. They wrap command
+// output as <ZPSupportInfo><Command cmdline="...">text</Command></ZPSupportInfo>
+// (or <File>); anything else is returned as-is.
+func (c *Client) statusPage(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL()+path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := doRequest(ctx, c.HTTP, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &statusPageError{Path: path, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	var page struct {
+		Command string `xml:"Command"`
+		File    string `xml:"File"`
+	}
+	if err := xml.Unmarshal(b, &page); err == nil {
+		if text := firstNonEmpty(page.Command, page.File); text != "" {
+			return text, nil
+		}
+	}
+	return string(b), nil
+}
+
+// statusPageError is a /status page answered with a non-2xx status.
+type statusPageError struct {
+	Path       string
+	StatusCode int
+	Status     string
+}
+
+func (e *statusPageError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Status)
+}
+
+// parseProcUptime reads the first field of /proc/uptime ("12345.67 23456.78").
+func parseProcUptime(text string) int64 {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return int64(secs)
+}
+
+// parseIfconfig extracts name, IPv4 address, netmask and MAC per interface
+// from busybox ifconfig output, skipping loopback.
+func parseIfconfig(text string) []NetworkInterface {
+	var out []NetworkInterface
+	var cur *NetworkInterface
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if line[0] != ' ' && line[0] != '\t' {
+			out = append(out, NetworkInterface{Name: strings.TrimSuffix(fields[0], ":")})
+			cur = &out[len(out)-1]
+		}
+		if cur == nil {
+			continue
+		}
+		for i, f := range fields {
+			switch {
+			case f == "HWaddr" && i+1 < len(fields):
+				cur.MACAddress = fields[i+1]
+			case strings.HasPrefix(f, "addr:") && i > 0 && fields[i-1] == "inet":
+				cur.Address = strings.TrimPrefix(f, "addr:")
+			case strings.HasPrefix(f, "Mask:"):
+				cur.Netmask = strings.TrimPrefix(f, "Mask:")
+			}
+		}
+	}
+	filtered := out[:0]
+	for _, n := range out {
+		if n.Name != "lo" {
+			filtered = append(filtered, n)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
@@ -0,0 +1,68 @@
+package sonos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetDeviceInfo(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	info, err := c.GetDeviceInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetDeviceInfo: %v", err)
+	}
+	if info.Name != "Kitchen" || info.UDN != kitchen.UUID || info.ModelName != "Sonos One" || info.ModelNumber != "S18" {
+		t.Fatalf("unexpected identity: %+v", info)
+	}
+	if info.SerialNumber == "" || info.MACAddress == "" || info.SoftwareVersion == "" || info.HardwareVersion == "" || info.SeriesID != "P100" {
+		t.Fatalf("missing zone info: %+v", info)
+	}
+	if info.Uptime() < 3*24*time.Hour {
+		t.Fatalf("unexpected uptime: %v", info.Uptime())
+	}
+	if len(info.Network) != 1 || info.Network[0].Address != kitchen.IP || info.Network[0].MACAddress != info.MACAddress {
+		t.Fatalf("unexpected network: %+v", info.Network)
+	}
+
+	kitchen.SetStatusPages(false)
+	info, err = c.GetDeviceInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetDeviceInfo without status pages: %v", err)
+	}
+	if info.UptimeSeconds != 0 || info.Network != nil || info.SerialNumber == "" {
+		t.Fatalf("status pages should be optional: %+v", info)
+	}
+}
+
+func TestParseIfconfig(t *testing.T) {
+	t.Parallel()
+
+	text := `eth0      Link encap:Ethernet  HWaddr 00:0E:58:AA:BB:CC
+          UP BROADCAST MULTICAST  MTU:1500  Metric:1
+
+ath0      Link encap:Ethernet  HWaddr 00:0E:58:AA:BB:CD
+          inet addr:192.168.1.20  Bcast:192.168.1.255  Mask:255.255.255.0
+
+lo        Link encap:Local Loopback
+          inet addr:127.0.0.1  Mask:255.0.0.0
+`
+	want := []NetworkInterface{
+		{Name: "eth0", MACAddress: "00:0E:58:AA:BB:CC"},
+		{Name: "ath0", Address: "192.168.1.20", Netmask: "255.255.255.0", MACAddress: "00:0E:58:AA:BB:CD"},
+	}
+	if got := parseIfconfig(text); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseIfconfig = %+v", got)
+	}
+	if got := parseIfconfig("lo Link encap:Local Loopback\n"); got != nil {
+		t.Fatalf("expected nil, got %+v", got)
+	}
+	if parseProcUptime("12345.67 23456.78") != 12345 || parseProcUptime("") != 0 {
+		t.Fatalf("parseProcUptime")
+	}
+}
@@ -0,0 +1,164 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// AutoplaySourceTV is the DeviceProperties autoplay source home-theater
+// products use for the TV input.
+const AutoplaySourceTV = "TV"
+
+// ZoneAttributes are the room settings from DeviceProperties GetZoneAttributes.
+type ZoneAttributes struct {
+	Name           string `json:"name"`
+	Icon           string `json:"icon,omitempty"`
+	Configuration  string `json:"configuration,omitempty"`
+	TargetRoomName string `json:"targetRoomName,omitempty"`
+}
+
+func (c *Client) GetZoneAttributes(ctx context.Context) (ZoneAttributes, error) {
+	resp, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetZoneAttributes", nil)
+	if err != nil {
+		return ZoneAttributes{}, err
+	}
+	return ZoneAttributes{
+		Name:           strings.TrimSpace(resp["CurrentZoneName"]),
+		Icon:           resp["CurrentIcon"],
+		Configuration:  resp["CurrentConfiguration"],
+		TargetRoomName: resp["CurrentTargetRoomName"],
+	}, nil
+}
+
+// SetRoomName renames the speaker's room, keeping its icon and configuration.
+func (c *Client) SetRoomName(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("room name is required")
+	}
+	cur, err := c.GetZoneAttributes(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetZoneAttributes", map[string]string{
+		"DesiredZoneName":       name,
+		"DesiredIcon":           cur.Icon,
+		"DesiredConfiguration":  cur.Configuration,
+		"DesiredTargetRoomName": name,
+	})
+	return err
+}
+
+// GetLEDState reports whether the status light is on.
+func (c *Client) GetLEDState(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetLEDState", nil)
+	if err != nil {
+		return false, err
+	}
+	return resp["CurrentLEDState"] == "On", nil
+}
+
+func (c *Client) SetLEDState(ctx context.Context, on bool) error {
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetLEDState", map[string]string{
+		"DesiredLEDState": onOff(on),
+	})
+	return err
+}
+
+// GetButtonLockState reports whether the physical controls are locked.
+func (c *Client) GetButtonLockState(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetButtonLockState", nil)
+	if err != nil {
+		return false, err
+	}
+	return resp["CurrentButtonLockState"] == "On", nil
+}
+
+func (c *Client) SetButtonLockState(ctx context.Context, locked bool) error {
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetButtonLockState", map[string]string{
+		"DesiredButtonLockState": onOff(locked),
+	})
+	return err
+}
+
+func onOff(v bool) string {
+	if v {
+		return "On"
+	}
+	return "Off"
+}
+
+// AutoplaySettings control what a speaker does when audio arrives on an
+// autoplay source (the TV input on home-theater products): which room starts
+// playing (empty RoomUUID: autoplay off), whether grouped rooms keep playing
+// along, and an optional fixed volume.
+type AutoplaySettings struct {
+	Source             string `json:"source"`
+	RoomUUID           string `json:"roomUUID"`
+	IncludeLinkedZones bool   `json:"includeLinkedZones"`
+	UseVolume          bool   `json:"useVolume"`
+	Volume             int    `json:"volume"`
+}
+
+func (c *Client) GetAutoplaySettings(ctx context.Context, source string) (AutoplaySettings, error) {
+	args := map[string]string{"Source": source}
+	out := AutoplaySettings{Source: source}
+	resp, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetAutoplayRoomUUID", args)
+	if err != nil {
+		return AutoplaySettings{}, err
+	}
+	out.RoomUUID = strings.TrimSpace(resp["RoomUUID"])
+	if resp, err = c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetAutoplayLinkedZones", args); err != nil {
+		return AutoplaySettings{}, err
+	}
+	out.IncludeLinkedZones = resp["IncludeLinkedZones"] == "1"
+	if resp, err = c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetUseAutoplayVolume", args); err != nil {
+		return AutoplaySettings{}, err
+	}
+	out.UseVolume = resp["UseVolume"] == "1"
+	if resp, err = c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "GetAutoplayVolume", args); err != nil {
+		return AutoplaySettings{}, err
+	}
+	out.Volume, _ = strconv.Atoi(resp["CurrentVolume"])
+	return out, nil
+}
+
+// SetAutoplayRoomUUID picks the room that starts playing; "" disables autoplay.
+func (c *Client) SetAutoplayRoomUUID(ctx context.Context, source, roomUUID string) error {
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetAutoplayRoomUUID", map[string]string{
+		"RoomUUID": roomUUID,
+		"Source":   source,
+	})
+	return err
+}
+
+func (c *Client) SetAutoplayLinkedZones(ctx context.Context, source string, include bool) error {
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetAutoplayLinkedZones", map[string]string{
+		"IncludeLinkedZones": boolToSonos(include),
+		"Source":             source,
+	})
+	return err
+}
+
+// SetAutoplayVolume fixes the autoplay volume; a negative volume switches
+// back to the room's current volume.
+func (c *Client) SetAutoplayVolume(ctx context.Context, source string, volume int) error {
+	if volume > 100 {
+		return errors.New("volume must be between 0 and 100")
+	}
+	if volume >= 0 {
+		if _, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetAutoplayVolume", map[string]string{
+			"Volume": strconv.Itoa(volume),
+			"Source": source,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := c.soapCall(ctx, controlDeviceProperties, urnDeviceProperties, "SetUseAutoplayVolume", map[string]string{
+		"UseVolume": boolToSonos(volume >= 0),
+		"Source":    source,
+	})
+	return err
+}
@@ -0,0 +1,81 @@
+package sonos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeviceSettings(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	if err := c.SetRoomName(ctx, "Kitchen Island"); err != nil {
+		t.Fatalf("SetRoomName: %v", err)
+	}
+	if za, err := c.GetZoneAttributes(ctx); err != nil || za.Name != "Kitchen Island" || za.Icon == "" {
+		t.Fatalf("GetZoneAttributes = %+v, %v", za, err)
+	}
+	if top, err := c.GetTopology(ctx); err != nil {
+		t.Fatalf("GetTopology: %v", err)
+	} else if _, ok := top.FindByName("Kitchen Island"); !ok {
+		t.Fatalf("rename not reflected in topology: %+v", top.ByName)
+	}
+	if err := c.SetRoomName(ctx, "  "); err == nil {
+		t.Fatalf("expected empty-name error")
+	}
+
+	if on, err := c.GetLEDState(ctx); err != nil || !on {
+		t.Fatalf("GetLEDState = %v, %v", on, err)
+	}
+	if err := c.SetLEDState(ctx, false); err != nil {
+		t.Fatalf("SetLEDState: %v", err)
+	}
+	if on, err := c.GetLEDState(ctx); err != nil || on {
+		t.Fatalf("LED still on: %v, %v", on, err)
+	}
+
+	if err := c.SetButtonLockState(ctx, true); err != nil {
+		t.Fatalf("SetButtonLockState: %v", err)
+	}
+	if locked, err := c.GetButtonLockState(ctx); err != nil || !locked || !kitchen.State().ButtonsLocked {
+		t.Fatalf("buttons not locked: %v, %v", locked, err)
+	}
+}
+
+func TestAutoplaySettings(t *testing.T) {
+	h := newSnapshotHousehold(t, "Living Room")
+	lr := h.Speaker("Living Room")
+	c := NewClient(lr.IP, 2*time.Second)
+	ctx := context.Background()
+
+	if err := c.SetAutoplayRoomUUID(ctx, AutoplaySourceTV, lr.UUID); err != nil {
+		t.Fatalf("SetAutoplayRoomUUID: %v", err)
+	}
+	if err := c.SetAutoplayLinkedZones(ctx, AutoplaySourceTV, true); err != nil {
+		t.Fatalf("SetAutoplayLinkedZones: %v", err)
+	}
+	if err := c.SetAutoplayVolume(ctx, AutoplaySourceTV, 35); err != nil {
+		t.Fatalf("SetAutoplayVolume: %v", err)
+	}
+	got, err := c.GetAutoplaySettings(ctx, AutoplaySourceTV)
+	if err != nil {
+		t.Fatalf("GetAutoplaySettings: %v", err)
+	}
+	want := AutoplaySettings{Source: AutoplaySourceTV, RoomUUID: lr.UUID, IncludeLinkedZones: true, UseVolume: true, Volume: 35}
+	if got != want {
+		t.Fatalf("GetAutoplaySettings = %+v, want %+v", got, want)
+	}
+
+	if err := c.SetAutoplayVolume(ctx, AutoplaySourceTV, -1); err != nil {
+		t.Fatalf("SetAutoplayVolume(off): %v", err)
+	}
+	if got, _ := c.GetAutoplaySettings(ctx, AutoplaySourceTV); got.UseVolume || got.Volume != 35 {
+		t.Fatalf("fixed volume not disabled: %+v", got)
+	}
+	if err := c.SetAutoplayVolume(ctx, AutoplaySourceTV, 101); err == nil {
+		t.Fatalf("expected range error")
+	}
+}
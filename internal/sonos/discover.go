@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net"
 	"net/url"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,8 +18,14 @@ import (
 type DiscoverOptions struct {
 	Timeout          time.Duration
 	IncludeInvisible bool
+	// SeedIPs are known speaker IPs queried for topology before SSDP. Useful on
+	// networks where multicast is blocked or speakers live on another subnet.
+	SeedIPs []string
 }
 
+// SeedIPsEnv lists extra seed IPs (comma-separated) for every Discover call.
+const SeedIPsEnv = "SONOSCLI_SEED_IPS"
+
 var (
 	ssdpDiscoverFunc              = ssdpDiscover
 	scanAnySpeakerIPFunc          = scanAnySpeakerIP
@@ -43,6 +50,15 @@ func Discover(ctx context.Context, opts DiscoverOptions) ([]Device, error) {
 	opCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	for _, ip := range seedIPs(opts.SeedIPs) {
+		out, err := discoverViaTopologyFromIPFunc(opCtx, timeout, ip, opts.IncludeInvisible)
+		if err == nil && len(out) > 0 {
+			slog.Debug("discover: topology via seed succeeded", "ip", ip, "devices", len(out))
+			return out, nil
+		}
+		slog.Debug("discover: topology via seed failed", "ip", ip, "err", errString(err))
+	}
+
 	ssdpTimeout := 1500 * time.Millisecond
 	if timeout <= 2*time.Second {
 		ssdpTimeout = timeout / 2
@@ -113,6 +129,26 @@ func Discover(ctx context.Context, opts DiscoverOptions) ([]Device, error) {
 	return sortDevices(byIP), nil
 }
 
+func seedIPs(explicit []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(ip string) {
+		ip = strings.TrimSpace(ip)
+		if ip == "" || seen[ip] {
+			return
+		}
+		seen[ip] = true
+		out = append(out, ip)
+	}
+	for _, ip := range explicit {
+		add(ip)
+	}
+	for _, ip := range strings.Split(os.Getenv(SeedIPsEnv), ",") {
+		add(ip)
+	}
+	return out
+}
+
 func discoverViaTopologyFromIP(ctx context.Context, timeout time.Duration, ip string, includeInvisible bool) ([]Device, error) {
 	c := newClientForDiscover(ip, timeout)
 	top, err := c.GetTopology(ctx)
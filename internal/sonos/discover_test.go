@@ -6,6 +6,8 @@ import (
 	"net"
 	"testing"
 	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
 )
 
 func TestPreferDeviceSet(t *testing.T) {
@@ -127,3 +129,45 @@ func TestIPTo24PrefixAndIsPortOpen(t *testing.T) {
 		t.Fatalf("expected port closed after close")
 	}
 }
+
+func TestDiscoverUsesSeedIPsBeforeSSDP(t *testing.T) {
+	origSSDP := ssdpDiscoverFunc
+	origTopFromIP := discoverViaTopologyFromIPFunc
+	t.Cleanup(func() {
+		ssdpDiscoverFunc = origSSDP
+		discoverViaTopologyFromIPFunc = origTopFromIP
+	})
+	t.Setenv(SeedIPsEnv, " 192.168.1.21 ,192.168.1.20")
+
+	ssdpDiscoverFunc = func(ctx context.Context, timeout time.Duration) ([]ssdpResult, error) {
+		t.Fatalf("ssdp should not run when a seed answers")
+		return nil, nil
+	}
+	var tried []string
+	discoverViaTopologyFromIPFunc = func(ctx context.Context, timeout time.Duration, ip string, includeInvisible bool) ([]Device, error) {
+		tried = append(tried, ip)
+		if ip != "192.168.1.21" {
+			return nil, errors.New("unreachable")
+		}
+		return []Device{{IP: ip, Name: "Office"}}, nil
+	}
+
+	devs, err := Discover(context.Background(), DiscoverOptions{Timeout: time.Second, SeedIPs: []string{"192.168.1.20"}})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(devs) != 1 || devs[0].Name != "Office" {
+		t.Fatalf("unexpected devices: %#v", devs)
+	}
+	if len(tried) != 2 || tried[0] != "192.168.1.20" || tried[1] != "192.168.1.21" {
+		t.Fatalf("unexpected seed order: %v", tried)
+	}
+}
+
+// sonostest cannot import this package (its own tests import sonostest), so
+// keep its copy of the variable name in step here.
+func TestSeedIPsEnvMatchesFakeHousehold(t *testing.T) {
+	if sonostest.SeedEnv != SeedIPsEnv {
+		t.Fatalf("sonostest.SeedEnv = %q, want %q", sonostest.SeedEnv, SeedIPsEnv)
+	}
+}
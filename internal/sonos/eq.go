@@ -0,0 +1,343 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EQ settings live on each speaker's RenderingControl service: bass, treble
+// and loudness have their own actions, balance is the difference between the
+// LF and RF channel volumes, and home-theater settings go through
+// GetEQ/SetEQ with an EQType. Speakers answer UPnP 402 for EQTypes their model
+// does not have; those are reported as *EQUnsupportedError.
+
+type eqKind int
+
+const (
+	eqLevel  eqKind = iota // integer within [min, max]
+	eqSwitch               // on/off (0/1)
+)
+
+type eqSetting struct {
+	name     string
+	eqType   string // GetEQ/SetEQ EQType; empty for dedicated actions
+	kind     eqKind
+	min, max int
+}
+
+var eqSettings = []eqSetting{
+	{name: "bass", kind: eqLevel, min: -10, max: 10},
+	{name: "treble", kind: eqLevel, min: -10, max: 10},
+	{name: "loudness", kind: eqSwitch},
+	{name: "balance", kind: eqLevel, min: -100, max: 100},
+	{name: "night-mode", eqType: "NightMode", kind: eqSwitch},
+	{name: "dialog-level", eqType: "DialogLevel", kind: eqSwitch},
+	{name: "sub-enabled", eqType: "SubEnable", kind: eqSwitch},
+	{name: "sub-gain", eqType: "SubGain", kind: eqLevel, min: -15, max: 15},
+	{name: "surround-enabled", eqType: "SurroundEnable", kind: eqSwitch},
+	{name: "surround-level", eqType: "SurroundLevel", kind: eqLevel, min: -15, max: 15},
+	{name: "height-level", eqType: "HeightChannelLevel", kind: eqLevel, min: -10, max: 10},
+}
+
+var eqAliases = map[string]string{
+	"speech-enhancement": "dialog-level",
+	"night":              "night-mode",
+	"sub":                "sub-enabled",
+	"surround":           "surround-enabled",
+	"height":             "height-level",
+}
+
+// EQSettingNames lists the settings understood by GetEQSetting/SetEQSetting.
+func EQSettingNames() []string {
+	out := make([]string, 0, len(eqSettings))
+	for _, s := range eqSettings {
+		out = append(out, s.name)
+	}
+	return out
+}
+
+func lookupEQSetting(name string) (eqSetting, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := eqAliases[name]; ok {
+		name = alias
+	}
+	for _, s := range eqSettings {
+		if s.name == name {
+			return s, nil
+		}
+	}
+	return eqSetting{}, fmt.Errorf("unknown EQ setting %q (expected one of: %s)", name, strings.Join(EQSettingNames(), ", "))
+}
+
+// EQUnsupportedError reports a setting the speaker's model does not have.
+type EQUnsupportedError struct {
+	Setting string
+	Model   string
+}
+
+func (e *EQUnsupportedError) Error() string {
+	model := e.Model
+	if model == "" {
+		model = "this speaker"
+	}
+	return fmt.Sprintf("%s is not supported on %s", e.Setting, model)
+}
+
+// EQValue is one setting as read from a speaker.
+type EQValue struct {
+	Setting   string `json:"setting"`
+	Value     int    `json:"value"`
+	Switch    bool   `json:"switch,omitempty"` // value is 0/1 (off/on)
+	Supported bool   `json:"supported"`
+}
+
+func (c *Client) GetBass(ctx context.Context) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetBass", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["CurrentBass"])
+}
+
+func (c *Client) SetBass(ctx context.Context, bass int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetBass", map[string]string{
+		"InstanceID":  "0",
+		"DesiredBass": strconv.Itoa(bass),
+	})
+	return err
+}
+
+func (c *Client) GetTreble(ctx context.Context) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetTreble", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["CurrentTreble"])
+}
+
+func (c *Client) SetTreble(ctx context.Context, treble int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetTreble", map[string]string{
+		"InstanceID":    "0",
+		"DesiredTreble": strconv.Itoa(treble),
+	})
+	return err
+}
+
+func (c *Client) GetLoudness(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetLoudness", map[string]string{
+		"InstanceID": "0",
+		"Channel":    "Master",
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp["CurrentLoudness"] == "1", nil
+}
+
+func (c *Client) SetLoudness(ctx context.Context, on bool) error {
+	v := "0"
+	if on {
+		v = "1"
+	}
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetLoudness", map[string]string{
+		"InstanceID":      "0",
+		"Channel":         "Master",
+		"DesiredLoudness": v,
+	})
+	return err
+}
+
+// GetEQ reads a home-theater EQ value (e.g. NightMode, SubGain).
+func (c *Client) GetEQ(ctx context.Context, eqType string) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetEQ", map[string]string{
+		"InstanceID": "0",
+		"EQType":     eqType,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["CurrentValue"])
+}
+
+// SetEQ writes a home-theater EQ value (e.g. NightMode, SubGain).
+func (c *Client) SetEQ(ctx context.Context, eqType string, value int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetEQ", map[string]string{
+		"InstanceID":   "0",
+		"EQType":       eqType,
+		"DesiredValue": strconv.Itoa(value),
+	})
+	return err
+}
+
+func (c *Client) getChannelVolume(ctx context.Context, channel string) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetVolume", map[string]string{
+		"InstanceID": "0",
+		"Channel":    channel,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["CurrentVolume"])
+}
+
+func (c *Client) setChannelVolume(ctx context.Context, channel string, volume int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetVolume", map[string]string{
+		"InstanceID":    "0",
+		"Channel":       channel,
+		"DesiredVolume": strconv.Itoa(volume),
+	})
+	return err
+}
+
+// GetBalance returns -100 (left only) .. 0 (centered) .. 100 (right only).
+func (c *Client) GetBalance(ctx context.Context) (int, error) {
+	left, err := c.getChannelVolume(ctx, "LF")
+	if err != nil {
+		return 0, err
+	}
+	right, err := c.getChannelVolume(ctx, "RF")
+	if err != nil {
+		return 0, err
+	}
+	return right - left, nil
+}
+
+// SetBalance turns one side down by |balance| and keeps the other at 100.
+func (c *Client) SetBalance(ctx context.Context, balance int) error {
+	if balance < -100 || balance > 100 {
+		return errors.New("balance must be between -100 and 100")
+	}
+	left, right := 100, 100
+	if balance > 0 {
+		left -= balance
+	} else {
+		right += balance
+	}
+	if err := c.setChannelVolume(ctx, "LF", left); err != nil {
+		return err
+	}
+	return c.setChannelVolume(ctx, "RF", right)
+}
+
+// GetEQSetting reads one setting by name (see EQSettingNames).
+func (c *Client) GetEQSetting(ctx context.Context, name string) (EQValue, error) {
+	s, err := lookupEQSetting(name)
+	if err != nil {
+		return EQValue{}, err
+	}
+	v, err := c.getEQSetting(ctx, s)
+	if err != nil {
+		return EQValue{}, c.eqError(ctx, s, err)
+	}
+	return EQValue{Setting: s.name, Value: v, Switch: s.kind == eqSwitch, Supported: true}, nil
+}
+
+// GetEQSettings reads every setting; the ones the model lacks are returned
+// with Supported false.
+func (c *Client) GetEQSettings(ctx context.Context) ([]EQValue, error) {
+	out := make([]EQValue, 0, len(eqSettings))
+	for _, s := range eqSettings {
+		v, err := c.getEQSetting(ctx, s)
+		if err != nil {
+			if !isEQUnsupported(err) {
+				return nil, err
+			}
+			out = append(out, EQValue{Setting: s.name, Switch: s.kind == eqSwitch})
+			continue
+		}
+		out = append(out, EQValue{Setting: s.name, Value: v, Switch: s.kind == eqSwitch, Supported: true})
+	}
+	return out, nil
+}
+
+// SetEQSetting parses value for the named setting (a number for levels,
+// on/off for switches) and applies it.
+func (c *Client) SetEQSetting(ctx context.Context, name, value string) (EQValue, error) {
+	s, err := lookupEQSetting(name)
+	if err != nil {
+		return EQValue{}, err
+	}
+	v, err := parseEQValue(s, value)
+	if err != nil {
+		return EQValue{}, err
+	}
+	if err := c.setEQSetting(ctx, s, v); err != nil {
+		return EQValue{}, c.eqError(ctx, s, err)
+	}
+	return EQValue{Setting: s.name, Value: v, Switch: s.kind == eqSwitch, Supported: true}, nil
+}
+
+func parseEQValue(s eqSetting, value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if s.kind == eqSwitch {
+		switch value {
+		case "on", "true", "1", "yes":
+			return 1, nil
+		case "off", "false", "0", "no":
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s must be on or off", s.name)
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < s.min || v > s.max {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d", s.name, s.min, s.max)
+	}
+	return v, nil
+}
+
+func (c *Client) getEQSetting(ctx context.Context, s eqSetting) (int, error) {
+	switch s.name {
+	case "bass":
+		return c.GetBass(ctx)
+	case "treble":
+		return c.GetTreble(ctx)
+	case "loudness":
+		on, err := c.GetLoudness(ctx)
+		if on {
+			return 1, err
+		}
+		return 0, err
+	case "balance":
+		return c.GetBalance(ctx)
+	}
+	return c.GetEQ(ctx, s.eqType)
+}
+
+func (c *Client) setEQSetting(ctx context.Context, s eqSetting, v int) error {
+	switch s.name {
+	case "bass":
+		return c.SetBass(ctx, v)
+	case "treble":
+		return c.SetTreble(ctx, v)
+	case "loudness":
+		return c.SetLoudness(ctx, v == 1)
+	case "balance":
+		return c.SetBalance(ctx, v)
+	}
+	return c.SetEQ(ctx, s.eqType, v)
+}
+
+// isEQUnsupported reports whether err is the speaker rejecting an EQType it
+// does not have. Values are range-checked before sending, so 402 here means
+// the setting itself is unknown to the model.
+func isEQUnsupported(err error) bool {
+	var upnpErr *UPnPError
+	return errors.As(err, &upnpErr) && upnpErr.Code == "402"
+}
+
+// eqError turns an "unsupported" UPnP fault into *EQUnsupportedError naming
+// the model; other errors pass through.
+func (c *Client) eqError(ctx context.Context, s eqSetting, err error) error {
+	if !isEQUnsupported(err) {
+		return err
+	}
+	model, _ := c.GetModelName(ctx)
+	return &EQUnsupportedError{Setting: s.name, Model: model}
+}
@@ -0,0 +1,99 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEQBasicSettings(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	for _, tc := range []struct{ name, value string }{
+		{"bass", "4"}, {"treble", "-3"}, {"loudness", "off"}, {"balance", "-30"},
+	} {
+		if _, err := c.SetEQSetting(ctx, tc.name, tc.value); err != nil {
+			t.Fatalf("SetEQSetting(%s): %v", tc.name, err)
+		}
+	}
+	eq := kitchen.State().EQ
+	if eq["Bass"] != 4 || eq["Treble"] != -3 || eq["Loudness"] != 0 || eq["LF"] != 100 || eq["RF"] != 70 {
+		t.Fatalf("unexpected EQ state: %+v", eq)
+	}
+	if v, err := c.GetEQSetting(ctx, "balance"); err != nil || v.Value != -30 {
+		t.Fatalf("GetEQSetting(balance) = %+v, %v", v, err)
+	}
+	if v, err := c.GetEQSetting(ctx, "Loudness"); err != nil || v.Value != 0 || !v.Switch {
+		t.Fatalf("GetEQSetting(loudness) = %+v, %v", v, err)
+	}
+
+	for _, bad := range []struct{ name, value string }{
+		{"bass", "11"}, {"loudness", "maybe"}, {"balance", "x"}, {"volume", "3"},
+	} {
+		if _, err := c.SetEQSetting(ctx, bad.name, bad.value); err == nil {
+			t.Fatalf("expected error for %s=%s", bad.name, bad.value)
+		}
+	}
+}
+
+func TestEQUnsupportedSettingsNameTheModel(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	_, err := c.SetEQSetting(ctx, "night-mode", "on")
+	var unsupported *EQUnsupportedError
+	if !errors.As(err, &unsupported) || err.Error() != "night-mode is not supported on Sonos One" {
+		t.Fatalf("expected EQUnsupportedError, got %v", err)
+	}
+	if strings.Contains(err.Error(), "402") {
+		t.Fatalf("raw UPnP error leaked: %v", err)
+	}
+
+	values, err := c.GetEQSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetEQSettings: %v", err)
+	}
+	supported := map[string]bool{}
+	for _, v := range values {
+		supported[v.Setting] = v.Supported
+	}
+	if !supported["bass"] || !supported["balance"] || supported["night-mode"] || supported["height-level"] {
+		t.Fatalf("unexpected support map: %+v", supported)
+	}
+}
+
+func TestEQHomeTheaterSettings(t *testing.T) {
+	h := newSnapshotHousehold(t, "Living Room")
+	tv := h.Speaker("Living Room")
+	tv.SetModel("Sonos Beam")
+	c := NewClient(tv.IP, 2*time.Second)
+	ctx := context.Background()
+
+	if _, err := c.SetEQSetting(ctx, "speech-enhancement", "on"); err != nil {
+		t.Fatalf("SetEQSetting(dialog): %v", err)
+	}
+	if _, err := c.SetEQSetting(ctx, "sub-gain", "-6"); err != nil {
+		t.Fatalf("SetEQSetting(sub-gain): %v", err)
+	}
+	if v, err := c.GetEQSetting(ctx, "dialog-level"); err != nil || v.Value != 1 {
+		t.Fatalf("GetEQSetting(dialog-level) = %+v, %v", v, err)
+	}
+	if eq := tv.State().EQ; eq["SubGain"] != -6 {
+		t.Fatalf("unexpected EQ state: %+v", eq)
+	}
+	if _, err := c.GetEQSetting(ctx, "height-level"); err == nil || !strings.Contains(err.Error(), "not supported on Sonos Beam") {
+		t.Fatalf("expected height unsupported on Beam, got %v", err)
+	}
+
+	tv.SetModel("Sonos Arc")
+	if _, err := c.SetEQSetting(ctx, "height", "3"); err != nil {
+		t.Fatalf("SetEQSetting(height) on Arc: %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventService names a UPnP service whose GENA events can be subscribed to.
+type EventService string
+
+const (
+	ServiceAVTransport           EventService = "avtransport"
+	ServiceRenderingControl      EventService = "renderingcontrol"
+	ServiceGroupRenderingControl EventService = "grouprenderingcontrol"
+	ServiceQueue                 EventService = "queue"
+	ServiceContentDirectory      EventService = "contentdirectory"
+	ServiceZoneGroupTopology     EventService = "zonegrouptopology"
+	ServiceAlarmClock            EventService = "alarmclock"
+	ServiceDeviceProperties      EventService = "deviceproperties"
+)
+
+// EventServices lists every service an EventManager can subscribe to.
+var EventServices = []EventService{
+	ServiceAVTransport,
+	ServiceRenderingControl,
+	ServiceGroupRenderingControl,
+	ServiceQueue,
+	ServiceContentDirectory,
+	ServiceZoneGroupTopology,
+	ServiceAlarmClock,
+	ServiceDeviceProperties,
+}
+
+var eventServicePaths = map[EventService]string{
+	ServiceAVTransport:           eventAVTransport,
+	ServiceRenderingControl:      eventRenderingControl,
+	ServiceGroupRenderingControl: eventGroupRendering,
+	ServiceQueue:                 eventQueue,
+	ServiceContentDirectory:      eventContentDirectory,
+	ServiceZoneGroupTopology:     eventZoneGroupTopology,
+	ServiceAlarmClock:            eventAlarmClock,
+	ServiceDeviceProperties:      eventDeviceProperties,
+}
+
+// ParseEventService accepts a service name case-insensitively, with or
+// without dashes ("GroupRenderingControl", "group-rendering-control").
+func ParseEventService(name string) (EventService, error) {
+	key := EventService(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "")))
+	if _, ok := eventServicePaths[key]; !ok {
+		return "", fmt.Errorf("unknown event service %q", name)
+	}
+	return key, nil
+}
+
+// HouseholdWide reports whether every speaker sends the same events for the
+// service (topology, alarms), so one subscription covers the household.
+func (s EventService) HouseholdWide() bool {
+	return s == ServiceZoneGroupTopology || s == ServiceAlarmClock
+}
+
+// DefaultEventTimeout is the subscription lifetime an EventManager asks for
+// unless EventManagerOptions.Timeout says otherwise.
+const DefaultEventTimeout = 30 * time.Minute
+
+// Event is one GENA NOTIFY received by an EventManager.
+type Event struct {
+	Time    time.Time
+	IP      string // speaker the subscription was made on
+	Service EventService
+	SID     string
+	Seq     uint32
+	Vars    map[string]string // see ParseEvent
+	Err     error             // set (and Vars empty) when the payload did not parse
+
+	// Missed counts events lost before this one, from SEQ gaps or because
+	// the Events channel was full.
+	Missed int
+	// Resubscribed marks the first event of a subscription that was
+	// re-established after a failed renewal (e.g. the speaker rebooted).
+	Resubscribed bool
+}
+
+// EventManagerOptions tune an EventManager. Zero values use the defaults.
+type EventManagerOptions struct {
+	// Timeout is requested on SUBSCRIBE and renewals (default DefaultEventTimeout).
+	Timeout time.Duration
+	// RenewFraction of the granted timeout after which a subscription is
+	// renewed (default 0.5).
+	RenewFraction float64
+	// RetryBackoff is the delay before the second resubscribe attempt; it
+	// doubles up to MaxRetryBackoff (defaults 1s and 1m).
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// Buffer is the capacity of the Events channel (default 128).
+	Buffer int
+}
+
+// EventManager owns a local callback server for GENA events and keeps its
+// subscriptions alive: it renews them before they expire, resubscribes with
+// backoff when a renewal fails, tracks SEQ numbers to detect lost events and
+// delivers everything on one channel.
+type EventManager struct {
+	opts        EventManagerOptions
+	callbackURL string
+	srv         *http.Server
+	events      chan Event
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	mu      sync.Mutex
+	subs    map[*EventSubscription]struct{}
+	bySID   map[string]*EventSubscription
+	pending map[string]*pendingEvents // NOTIFYs that beat their SUBSCRIBE response
+	retired map[string]time.Time      // SIDs unsubscribed or replaced, and when
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// EventSubscription is a subscription managed by an EventManager. Its SID
+// changes when it has to be re-established.
+type EventSubscription struct {
+	client  *Client
+	service EventService
+	path    string
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	// Guarded by EventManager.mu.
+	sub          Subscription
+	lastSeq      uint32
+	haveSeq      bool
+	missed       int
+	resubscribed bool
+}
+
+// Service returns the subscribed service.
+func (s *EventSubscription) Service() EventService { return s.service }
+
+// IP returns the speaker the subscription was made on.
+func (s *EventSubscription) IP() string { return s.client.IP }
+
+// maxPendingEvents bounds the SIDs, and the NOTIFYs per SID, kept for
+// subscriptions not registered yet.
+const maxPendingEvents = 32
+
+// pendingEventTTL is how long NOTIFYs for an unknown SID are kept waiting for
+// its SUBSCRIBE response; a response that never came (lost, timed out) must
+// not hold a slot for good.
+const pendingEventTTL = 30 * time.Second
+
+// pendingEvents are the NOTIFYs received for one SID not registered yet.
+type pendingEvents struct {
+	since  time.Time
+	events []Event
+}
+
+// LocalIPFor returns the local address used to reach remoteIP, which is
+// where speakers can call back.
+func LocalIPFor(remoteIP string) (string, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(remoteIP, "1900"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || udpAddr.IP == nil {
+		return "", errors.New("could not determine local listen ip")
+	}
+	return udpAddr.IP.String(), nil
+}
+
+// NewEventManager starts a callback server on the local address that routes
+// to remoteIP. Call Close to unsubscribe everything and stop it.
+func NewEventManager(remoteIP string, opts EventManagerOptions) (*EventManager, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultEventTimeout
+	}
+	if opts.RenewFraction <= 0 || opts.RenewFraction >= 1 {
+		opts.RenewFraction = 0.5
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.MaxRetryBackoff < opts.RetryBackoff {
+		opts.MaxRetryBackoff = max(time.Minute, opts.RetryBackoff)
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 128
+	}
+
+	listenIP, err := LocalIPFor(remoteIP)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &EventManager{
+		opts:        opts,
+		callbackURL: fmt.Sprintf("http://%s:%d/notify", listenIP, port),
+		events:      make(chan Event, opts.Buffer),
+		ctx:         ctx,
+		cancel:      cancel,
+		subs:        map[*EventSubscription]struct{}{},
+		bySID:       map[string]*EventSubscription{},
+		pending:     map[string]*pendingEvents{},
+		retired:     map[string]time.Time{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/notify", m.handleNotify)
+	m.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() { _ = m.srv.Serve(ln) }()
+	return m, nil
+}
+
+// CallbackURL is the URL speakers deliver events to.
+func (m *EventManager) CallbackURL() string { return m.callbackURL }
+
+// Events delivers events until Close.
+func (m *EventManager) Events() <-chan Event { return m.events }
+
+// Subscribe subscribes to service on the speaker behind c and keeps the
+// subscription alive until Unsubscribe or Close.
+func (m *EventManager) Subscribe(ctx context.Context, c *Client, service EventService) (*EventSubscription, error) {
+	path, ok := eventServicePaths[service]
+	if !ok {
+		return nil, fmt.Errorf("unknown event service %q", service)
+	}
+	sub, err := c.Subscribe(ctx, path, m.callbackURL, m.opts.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe %s on %s: %w", service, c.IP, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		m.retireLocked(sub.SID)
+		_ = c.Unsubscribe(context.WithoutCancel(ctx), sub)
+		return nil, errors.New("event manager closed")
+	}
+	subCtx, cancel := context.WithCancel(m.ctx)
+	s := &EventSubscription{client: c, service: service, path: path, cancel: cancel, done: make(chan struct{})}
+	m.subs[s] = struct{}{}
+	m.registerLocked(s, sub)
+	m.wg.Add(1)
+	go m.keepAlive(subCtx, s)
+	return s, nil
+}
+
+// Unsubscribe stops renewing s and cancels it on the speaker.
+func (m *EventManager) Unsubscribe(ctx context.Context, s *EventSubscription) error {
+	s.cancel()
+	<-s.done
+	m.mu.Lock()
+	_, ok := m.subs[s]
+	delete(m.subs, s)
+	delete(m.bySID, s.sub.SID)
+	m.retireLocked(s.sub.SID)
+	sub := s.sub
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.client.Unsubscribe(ctx, sub)
+}
+
+// Close unsubscribes everything (best-effort, bounded by ctx), stops the
+// callback server and closes the Events channel.
+func (m *EventManager) Close(ctx context.Context) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	subs := make([]*EventSubscription, 0, len(m.subs))
+	for s := range m.subs {
+		subs = append(subs, s)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = m.Unsubscribe(ctx, s)
+		}()
+	}
+	wg.Wait()
+	m.cancel()
+	m.wg.Wait()
+	_ = m.srv.Shutdown(ctx)
+	close(m.events)
+}
+
+// registerLocked routes sub's SID to s and replays NOTIFYs that arrived
+// before the SUBSCRIBE response did.
+func (m *EventManager) registerLocked(s *EventSubscription, sub Subscription) {
+	if s.sub.SID != "" && s.sub.SID != sub.SID {
+		delete(m.bySID, s.sub.SID)
+		m.retireLocked(s.sub.SID)
+	}
+	s.sub = sub
+	s.haveSeq = false
+	m.bySID[sub.SID] = s
+	delete(m.retired, sub.SID)
+	if early := m.pending[sub.SID]; early != nil {
+		delete(m.pending, sub.SID)
+		for _, ev := range early.events {
+			m.deliverLocked(s, ev)
+		}
+	}
+}
+
+// retireLocked makes NOTIFYs for sid, which is no longer ours, be ignored
+// until the speaker would have let it expire anyway.
+func (m *EventManager) retireLocked(sid string) {
+	if sid == "" {
+		return
+	}
+	delete(m.pending, sid)
+	m.retired[sid] = time.Now()
+}
+
+// holdLocked keeps ev until its SID is registered. Expired entries go
+// first; when the table is still full the oldest SID makes room.
+func (m *EventManager) holdLocked(ev Event) {
+	now := time.Now()
+	for sid, t := range m.retired {
+		if now.Sub(t) > m.opts.Timeout {
+			delete(m.retired, sid)
+		}
+	}
+	if _, ok := m.retired[ev.SID]; ok || ev.SID == "" {
+		return
+	}
+	p := m.pending[ev.SID]
+	if p == nil {
+		oldest := ""
+		for sid, q := range m.pending {
+			if now.Sub(q.since) > pendingEventTTL {
+				delete(m.pending, sid)
+			} else if oldest == "" || q.since.Before(m.pending[oldest].since) {
+				oldest = sid
+			}
+		}
+		if len(m.pending) >= maxPendingEvents {
+			delete(m.pending, oldest)
+		}
+		p = &pendingEvents{since: now}
+		m.pending[ev.SID] = p
+	}
+	if len(p.events) < maxPendingEvents {
+		p.events = append(p.events, ev)
+	}
+}
+
+func (m *EventManager) handleNotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "NOTIFY" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, 8<<20))
+	_ = r.Body.Close()
+
+	ev := Event{
+		Time: time.Now().UTC(),
+		SID:  strings.TrimSpace(r.Header.Get("SID")),
+	}
+	if seq, err := strconv.ParseUint(strings.TrimSpace(r.Header.Get("SEQ")), 10, 32); err == nil {
+		ev.Seq = uint32(seq)
+	}
+	ev.Vars, ev.Err = ParseEvent(body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	s, ok := m.bySID[ev.SID]
+	if !ok {
+		m.holdLocked(ev)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	m.deliverLocked(s, ev)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *EventManager) deliverLocked(s *EventSubscription, ev Event) {
+	ev.IP = s.client.IP
+	ev.Service = s.service
+	// Out-of-order or restarted sequence numbers are not counted as gaps.
+	if want := nextSeq(s.lastSeq); s.haveSeq && ev.Seq > want {
+		s.missed += int(ev.Seq - want)
+	}
+	s.lastSeq, s.haveSeq = ev.Seq, true
+	ev.Missed = s.missed
+	ev.Resubscribed = s.resubscribed
+	select {
+	case m.events <- ev:
+		s.missed = 0
+		s.resubscribed = false
+	default:
+		// The consumer is too slow; the next delivered event reports it.
+		s.missed++
+	}
+}
+
+// nextSeq is the SEQ after seq; GENA wraps to 1, not 0.
+func nextSeq(seq uint32) uint32 {
+	if seq == math.MaxUint32 {
+		return 1
+	}
+	return seq + 1
+}
+
+// keepAlive renews s at RenewFraction of the granted timeout and, when a
+// renewal fails, unsubscribes the old SID and subscribes again.
+func (m *EventManager) keepAlive(ctx context.Context, s *EventSubscription) {
+	defer m.wg.Done()
+	defer close(s.done)
+	for {
+		m.mu.Lock()
+		sub := s.sub
+		m.mu.Unlock()
+		granted := sub.Timeout
+		if granted <= 0 {
+			granted = m.opts.Timeout
+		}
+		if !sleepCtx(ctx, time.Duration(float64(granted)*m.opts.RenewFraction)) {
+			return
+		}
+
+		renewed, err := s.client.Renew(ctx, sub, m.opts.Timeout)
+		if err == nil {
+			m.mu.Lock()
+			s.sub.Timeout = renewed.Timeout
+			m.mu.Unlock()
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Debug("events: renew failed, resubscribing", "ip", s.client.IP, "service", s.service, "err", errString(err))
+		// The old SID may still be live on the speaker (the renewal failed
+		// for another reason); drop it so it does not keep sending events
+		// until it expires. A speaker that already forgot it just says 412.
+		_ = s.client.Unsubscribe(ctx, sub)
+		if !m.resubscribe(ctx, s) {
+			return
+		}
+	}
+}
+
+// resubscribe replaces s's subscription, retrying with exponential backoff
+// until it succeeds or ctx ends.
+func (m *EventManager) resubscribe(ctx context.Context, s *EventSubscription) bool {
+	backoff := m.opts.RetryBackoff
+	for {
+		sub, err := s.client.Subscribe(ctx, s.path, m.callbackURL, m.opts.Timeout)
+		if err == nil {
+			m.mu.Lock()
+			s.resubscribed = true
+			m.registerLocked(s, sub)
+			m.mu.Unlock()
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		slog.Debug("events: resubscribe failed", "ip", s.client.IP, "service", s.service, "retryIn", backoff, "err", errString(err))
+		if !sleepCtx(ctx, backoff) {
+			return false
+		}
+		backoff = min(2*backoff, m.opts.MaxRetryBackoff)
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
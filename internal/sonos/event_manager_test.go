@@ -0,0 +1,247 @@
+package sonos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalIPFor_Localhost(t *testing.T) {
+	ip, err := LocalIPFor("127.0.0.1")
+	if err != nil {
+		t.Fatalf("LocalIPFor: %v", err)
+	}
+	if ip != "127.0.0.1" {
+		t.Fatalf("unexpected ip: %q", ip)
+	}
+}
+
+func TestParseEventService(t *testing.T) {
+	for in, want := range map[string]EventService{
+		"AVTransport":             ServiceAVTransport,
+		"group-rendering-control": ServiceGroupRenderingControl,
+		" zonegrouptopology ":     ServiceZoneGroupTopology,
+	} {
+		got, err := ParseEventService(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseEventService(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseEventService("bogus"); err == nil {
+		t.Fatalf("expected error for unknown service")
+	}
+	for _, s := range EventServices {
+		if _, ok := eventServicePaths[s]; !ok {
+			t.Fatalf("no event path for %s", s)
+		}
+	}
+}
+
+func nextEvent(t *testing.T, em *EventManager, match func(Event) bool) Event {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case ev, ok := <-em.Events():
+			if !ok {
+				t.Fatalf("events channel closed")
+			}
+			if match == nil || match(ev) {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for event")
+		}
+	}
+}
+
+func TestEventManagerRenewsBeforeExpiry(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	em, err := NewEventManager(kitchen.IP, EventManagerOptions{Timeout: 2 * time.Second, RenewFraction: 0.25})
+	if err != nil {
+		t.Fatalf("NewEventManager: %v", err)
+	}
+	defer em.Close(context.Background())
+
+	c := NewClient(kitchen.IP, 2*time.Second)
+	if _, err := em.Subscribe(context.Background(), c, ServiceRenderingControl); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	ev := nextEvent(t, em, nil)
+	if ev.Service != ServiceRenderingControl || ev.IP != kitchen.IP || ev.Seq != 0 || ev.Vars["volume_master"] != "20" {
+		t.Fatalf("unexpected initial event: %+v", ev)
+	}
+
+	// Well past the 2s the speaker granted: only renewals keep it alive.
+	time.Sleep(2500 * time.Millisecond)
+	if subs := kitchen.Subscriptions(); len(subs) != 1 {
+		t.Fatalf("subscription expired: %v", subs)
+	}
+	kitchen.SetVolume(33)
+	ev = nextEvent(t, em, func(ev Event) bool { return ev.Vars["volume_master"] == "33" })
+	if ev.Missed != 0 || ev.Resubscribed {
+		t.Fatalf("unexpected event flags: %+v", ev)
+	}
+}
+
+func TestEventManagerResubscribesAfterReboot(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	em, err := NewEventManager(kitchen.IP, EventManagerOptions{Timeout: time.Second, RenewFraction: 0.2, RetryBackoff: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewEventManager: %v", err)
+	}
+
+	c := NewClient(kitchen.IP, 2*time.Second)
+	if _, err := em.Subscribe(context.Background(), c, ServiceAVTransport); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	first := nextEvent(t, em, nil)
+
+	kitchen.DropSubscriptions() // renewals now fail with 412
+	ev := nextEvent(t, em, func(ev Event) bool { return ev.Resubscribed })
+	if ev.SID == first.SID || ev.Seq != 0 || ev.Service != ServiceAVTransport {
+		t.Fatalf("unexpected resubscribe event: %+v (first %+v)", ev, first)
+	}
+
+	em.Close(context.Background())
+	if subs := kitchen.Subscriptions(); len(subs) != 0 {
+		t.Fatalf("subscriptions left after Close: %v", subs)
+	}
+	if _, ok := <-em.Events(); ok {
+		t.Fatalf("events channel still open after Close")
+	}
+}
+
+func TestEventManagerDropsOldSIDWhenRenewFails(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	em, err := NewEventManager(kitchen.IP, EventManagerOptions{Timeout: 4 * time.Second, RenewFraction: 0.1, RetryBackoff: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewEventManager: %v", err)
+	}
+	defer em.Close(context.Background())
+
+	c := NewClient(kitchen.IP, 2*time.Second)
+	if _, err := em.Subscribe(context.Background(), c, ServiceRenderingControl); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	first := nextEvent(t, em, nil)
+
+	kitchen.FailRenewals(1) // the old subscription stays live on the speaker
+	ev := nextEvent(t, em, func(ev Event) bool { return ev.Resubscribed })
+	if ev.SID == first.SID {
+		t.Fatalf("expected a new SID: %+v", ev)
+	}
+	if subs := kitchen.Subscriptions(); len(subs) != 1 {
+		t.Fatalf("old subscription still live: %v", subs)
+	}
+}
+
+func TestEventManagerCountsSeqGapsAndKeepsEarlyEvents(t *testing.T) {
+	body := `<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"><e:property><LastChange>` +
+		`&lt;Event&gt;&lt;InstanceID val=&quot;0&quot;&gt;&lt;TransportState val=&quot;PLAYING&quot;/&gt;&lt;/InstanceID&gt;&lt;/Event&gt;` +
+		`</LastChange></e:property></e:propertyset>`
+	notify := func(t *testing.T, callback string, seq int) {
+		t.Helper()
+		req, _ := http.NewRequest("NOTIFY", callback, strings.NewReader(body))
+		req.Header.Set("SID", "uuid:sub-1")
+		req.Header.Set("SEQ", strconv.Itoa(seq))
+		resp, err := (&http.Client{Transport: &http.Transport{}}).Do(req)
+		if err != nil {
+			t.Errorf("notify: %v", err)
+			return
+		}
+		_ = resp.Body.Close()
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "SUBSCRIBE":
+			// The initial event arrives before the SUBSCRIBE response.
+			notify(t, strings.Trim(r.Header.Get("CALLBACK"), "<>"), 0)
+			w.Header().Set("SID", "uuid:sub-1")
+			w.Header().Set("TIMEOUT", "Second-1800")
+		case "UNSUBSCRIBE":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	c := &Client{IP: u.Hostname(), Port: port, HTTP: srv.Client()}
+
+	em, err := NewEventManager(c.IP, EventManagerOptions{})
+	if err != nil {
+		t.Fatalf("NewEventManager: %v", err)
+	}
+	defer em.Close(context.Background())
+	if _, err := em.Subscribe(context.Background(), c, ServiceAVTransport); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if ev := nextEvent(t, em, nil); ev.Seq != 0 || ev.Vars["transport_state"] != "PLAYING" || ev.Missed != 0 {
+		t.Fatalf("early event: %+v", ev)
+	}
+	notify(t, em.CallbackURL(), 1)
+	if ev := nextEvent(t, em, nil); ev.Seq != 1 || ev.Missed != 0 {
+		t.Fatalf("event 1: %+v", ev)
+	}
+	notify(t, em.CallbackURL(), 4)
+	if ev := nextEvent(t, em, nil); ev.Seq != 4 || ev.Missed != 2 {
+		t.Fatalf("event 4: %+v", ev)
+	}
+}
+
+func TestEventManagerIgnoresStaleSIDs(t *testing.T) {
+	em, err := NewEventManager("127.0.0.1", EventManagerOptions{})
+	if err != nil {
+		t.Fatalf("NewEventManager: %v", err)
+	}
+	defer em.Close(context.Background())
+	notify := func(sid string) {
+		t.Helper()
+		req, _ := http.NewRequest("NOTIFY", em.CallbackURL(), strings.NewReader(`<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"/>`))
+		req.Header.Set("SID", sid)
+		req.Header.Set("SEQ", "0")
+		resp, err := (&http.Client{Transport: &http.Transport{}}).Do(req)
+		if err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	pending := func(sid string) bool {
+		em.mu.Lock()
+		defer em.mu.Unlock()
+		_, ok := em.pending[sid]
+		return ok
+	}
+
+	// SIDs whose SUBSCRIBE response never arrived fill the table; a new
+	// subscription's early NOTIFY still gets a slot.
+	for i := range maxPendingEvents + 8 {
+		notify("uuid:lost-" + strconv.Itoa(i))
+	}
+	notify("uuid:new")
+	em.mu.Lock()
+	size := len(em.pending)
+	em.mu.Unlock()
+	if !pending("uuid:new") || size > maxPendingEvents {
+		t.Fatalf("new SID not held (pending %d)", size)
+	}
+
+	// Unsubscribed or replaced SIDs are not held at all.
+	em.mu.Lock()
+	em.retireLocked("uuid:old")
+	em.mu.Unlock()
+	notify("uuid:old")
+	if pending("uuid:old") {
+		t.Fatalf("NOTIFY for a retired SID was held")
+	}
+}
@@ -9,11 +9,14 @@ import (
 )
 
 // ParseEvent decodes a UPnP event propertyset payload into a flat map.
-// If a LastChange property is present, it is decoded and flattened.
+// If a LastChange property is present, it is decoded and flattened; other
+// properties (GroupVolume, ZoneGroupState, ContainerUpdateIDs, ...) are kept
+// under their snake_case name with the raw text value.
 func ParseEvent(payload []byte) (map[string]string, error) {
 	out := map[string]string{}
 
 	dec := xml.NewDecoder(bytes.NewReader(payload))
+	inProperty := false
 	for {
 		tok, err := dec.Token()
 		if err != nil {
@@ -22,18 +25,36 @@ func ParseEvent(payload []byte) (map[string]string, error) {
 			}
 			return nil, err
 		}
-		start, ok := tok.(xml.StartElement)
-		if !ok {
-			continue
-		}
-		if strings.EqualFold(start.Name.Local, "LastChange") {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if strings.EqualFold(t.Name.Local, "property") {
+				inProperty = true
+				continue
+			}
+			if !inProperty {
+				continue
+			}
 			var raw string
-			if err := dec.DecodeElement(&raw, &start); err != nil {
+			if err := dec.DecodeElement(&raw, &t); err != nil {
 				return nil, err
 			}
-			inner := html.UnescapeString(strings.TrimSpace(raw))
-			for k, v := range parseLastChange(inner) {
-				out[k] = v
+			if strings.EqualFold(t.Name.Local, "LastChange") {
+				// The decoder already unescaped the document once; only
+				// double-escaped payloads need another pass (doing it anyway
+				// would break the DIDL nested in CurrentTrackMetaData).
+				inner := strings.TrimSpace(raw)
+				if strings.HasPrefix(inner, "&lt;") {
+					inner = html.UnescapeString(inner)
+				}
+				for k, v := range parseLastChange(inner) {
+					out[k] = v
+				}
+				continue
+			}
+			out[camelToSnake(t.Name.Local)] = strings.TrimSpace(raw)
+		case xml.EndElement:
+			if strings.EqualFold(t.Name.Local, "property") {
+				inProperty = false
 			}
 		}
 	}
@@ -57,17 +78,19 @@ func parseLastChange(innerXML string) map[string]string {
 			if !inInstance {
 				continue
 			}
-			var val string
-			var channel string
+			var val, channel string
+			hasVal := false
 			for _, a := range t.Attr {
 				switch strings.ToLower(a.Name.Local) {
 				case "val":
-					val = a.Value
+					val, hasVal = a.Value, true
 				case "channel":
 					channel = a.Value
 				}
 			}
-			if val == "" {
+			// An empty val is a real value (a cleared queue empties
+			// CurrentTrackMetaData); only elements without one are skipped.
+			if !hasVal {
 				continue
 			}
 			key := camelToSnake(t.Name.Local)
@@ -1,6 +1,9 @@
 package sonos
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestParseSecondTimeout(t *testing.T) {
 	cases := []struct {
@@ -74,3 +77,54 @@ func TestParseEventChannelFlatten(t *testing.T) {
 		t.Fatalf("mute_master=%q", vars["mute_master"])
 	}
 }
+
+func TestParseEventPlainProperties(t *testing.T) {
+	payload := []byte(`<?xml version="1.0"?>
+<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0">
+  <e:property><GroupVolume>23</GroupVolume></e:property>
+  <e:property><ContainerUpdateIDs>Q:0,7</ContainerUpdateIDs></e:property>
+  <e:property><ZoneGroupState>&lt;ZoneGroupState&gt;&lt;ZoneGroups/&gt;&lt;/ZoneGroupState&gt;</ZoneGroupState></e:property>
+</e:propertyset>`)
+
+	vars, err := ParseEvent(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars["group_volume"] != "23" || vars["container_update_ids"] != "Q:0,7" {
+		t.Fatalf("unexpected vars: %v", vars)
+	}
+	if vars["zone_group_state"] != "<ZoneGroupState><ZoneGroups/></ZoneGroupState>" {
+		t.Fatalf("zone_group_state=%q", vars["zone_group_state"])
+	}
+}
+
+func TestParseEventKeepsNestedTrackMetadata(t *testing.T) {
+	payload := []byte(`<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"><e:property><LastChange>` +
+		`&lt;Event&gt;&lt;InstanceID val=&quot;0&quot;&gt;` +
+		`&lt;CurrentTrackMetaData val=&quot;&amp;lt;DIDL-Lite&amp;gt;&amp;lt;item id=&amp;quot;-1&amp;quot;&amp;gt;&amp;lt;dc:title&amp;gt;Song&amp;lt;/dc:title&amp;gt;&amp;lt;/item&amp;gt;&amp;lt;/DIDL-Lite&amp;gt;&quot;/&gt;` +
+		`&lt;/InstanceID&gt;&lt;/Event&gt;</LastChange></e:property></e:propertyset>`)
+
+	vars, err := ParseEvent(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `<DIDL-Lite><item id="-1"><dc:title>Song</dc:title></item></DIDL-Lite>`; vars["current_track_meta_data"] != want {
+		t.Fatalf("current_track_meta_data=%q", vars["current_track_meta_data"])
+	}
+}
+
+func TestParseEventKeepsEmptyValues(t *testing.T) {
+	payload := []byte(`<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"><e:property><LastChange>` +
+		`&lt;Event&gt;&lt;InstanceID val=&quot;0&quot;&gt;` +
+		`&lt;TransportState val=&quot;STOPPED&quot;/&gt;&lt;CurrentTrackMetaData val=&quot;&quot;/&gt;&lt;AVTransportURI val=&quot;&quot;/&gt;&lt;NoValue/&gt;` +
+		`&lt;/InstanceID&gt;&lt;/Event&gt;</LastChange></e:property></e:propertyset>`)
+
+	vars, err := ParseEvent(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"transport_state": "STOPPED", "current_track_meta_data": "", "avtransport_uri": ""}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("vars = %#v", vars)
+	}
+}
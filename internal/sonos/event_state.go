@@ -0,0 +1,218 @@
+package sonos
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RoomEvent is a typed change derived from GENA events by a RoomState.
+type RoomEvent interface {
+	// Kind names the change ("transport_state_changed", ...).
+	Kind() string
+}
+
+// TransportStateChanged reports a new TransportState (PLAYING, PAUSED_PLAYBACK,
+// STOPPED, TRANSITIONING). Previous is empty for the first event.
+type TransportStateChanged struct {
+	State    string `json:"state"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// TrackChanged reports a new current track, with its DIDL-Lite metadata
+// parsed when the event carried any.
+type TrackChanged struct {
+	Number   int       `json:"number,omitempty"`
+	URI      string    `json:"uri,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+	Item     *DIDLItem `json:"item,omitempty"`
+}
+
+// VolumeChanged reports a new volume on one channel: "master", "lf", "rf"
+// from RenderingControl, or "group" from GroupRenderingControl.
+type VolumeChanged struct {
+	Channel  string `json:"channel"`
+	Volume   int    `json:"volume"`
+	Previous *int   `json:"previous,omitempty"`
+}
+
+// MuteChanged reports a new mute state on one channel (see VolumeChanged).
+type MuteChanged struct {
+	Channel string `json:"channel"`
+	Muted   bool   `json:"muted"`
+}
+
+// PlayModeChanged reports a new shuffle/repeat mode.
+type PlayModeChanged struct {
+	Mode     PlayMode `json:"mode"`
+	Previous PlayMode `json:"previous,omitempty"`
+}
+
+// QueueChanged reports that the queue was edited; UpdateID is the queue's
+// new update ID.
+type QueueChanged struct {
+	UpdateID string `json:"updateId"`
+}
+
+// TopologyChanged reports a change in how the household is grouped.
+type TopologyChanged struct {
+	Topology Topology `json:"topology"`
+}
+
+func (TransportStateChanged) Kind() string { return "transport_state_changed" }
+func (TrackChanged) Kind() string          { return "track_changed" }
+func (VolumeChanged) Kind() string         { return "volume_changed" }
+func (MuteChanged) Kind() string           { return "mute_changed" }
+func (PlayModeChanged) Kind() string       { return "play_mode_changed" }
+func (QueueChanged) Kind() string          { return "queue_changed" }
+func (TopologyChanged) Kind() string       { return "topology_changed" }
+
+// RoomUpdate is what one event changed in a RoomState.
+type RoomUpdate struct {
+	// Changed holds the variables whose value differs from the previous
+	// event of the same service (all of them for the first one).
+	Changed map[string]string
+	Events  []RoomEvent
+}
+
+// RoomState reduces the events of one room into typed changes. It remembers
+// the last value of every variable per service, as events may only carry
+// what changed.
+type RoomState struct {
+	vars     map[EventService]map[string]string
+	topology string // group layout of the last ZoneGroupState seen
+}
+
+// NewRoomState returns an empty state: the first event of each service
+// reports everything it carries as changed.
+func NewRoomState() *RoomState {
+	return &RoomState{vars: map[EventService]map[string]string{}}
+}
+
+// Value returns the last value of a service's variable ("" if never seen).
+func (s *RoomState) Value(service EventService, name string) string {
+	return s.vars[service][name]
+}
+
+// Apply merges ev into the state and reports what changed. Events that did
+// not parse change nothing.
+func (s *RoomState) Apply(ev Event) RoomUpdate {
+	upd := RoomUpdate{Changed: map[string]string{}}
+	if ev.Err != nil {
+		return upd
+	}
+	prev := s.vars[ev.Service]
+	if prev == nil {
+		prev = map[string]string{}
+		s.vars[ev.Service] = prev
+	}
+	before := make(map[string]string, len(ev.Vars))
+	for k, v := range ev.Vars {
+		// A variable first seen empty is not a change; one that later
+		// becomes empty is.
+		old, ok := prev[k]
+		if old == v && (ok || v == "") {
+			prev[k] = v
+			continue
+		}
+		before[k] = old
+		upd.Changed[k] = v
+		prev[k] = v
+	}
+	upd.Events = s.roomEvents(upd.Changed, before, prev)
+	return upd
+}
+
+// roomEvents derives typed events from the changed variables, in a fixed
+// order: topology, transport, play mode, track, volume, mute, queue.
+func (s *RoomState) roomEvents(changed, before, cur map[string]string) []RoomEvent {
+	var out []RoomEvent
+	if zgs, ok := changed["zone_group_state"]; ok {
+		if top, err := ParseZoneGroupState(zgs); err == nil {
+			if key := groupLayout(top); key != s.topology {
+				s.topology = key
+				out = append(out, TopologyChanged{Topology: top})
+			}
+		}
+	}
+	if v, ok := changed["transport_state"]; ok {
+		out = append(out, TransportStateChanged{State: v, Previous: before["transport_state"]})
+	}
+	if v, ok := changed["current_play_mode"]; ok {
+		out = append(out, PlayModeChanged{Mode: PlayMode(v), Previous: PlayMode(before["current_play_mode"])})
+	}
+	_, number := changed["current_track"]
+	_, uri := changed["current_track_uri"]
+	_, meta := changed["current_track_meta_data"]
+	if number || uri || meta {
+		tc := TrackChanged{URI: cur["current_track_uri"], Duration: cur["current_track_duration"]}
+		tc.Number, _ = strconv.Atoi(cur["current_track"])
+		if items, err := ParseDIDLItems(cur["current_track_meta_data"]); err == nil && len(items) > 0 {
+			tc.Item = &items[0]
+		}
+		out = append(out, tc)
+	}
+
+	keys := make([]string, 0, len(changed))
+	for k := range changed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		channel, ok := strings.CutPrefix(k, "volume_")
+		if k == "group_volume" {
+			channel, ok = "group", true
+		}
+		if !ok {
+			continue
+		}
+		v, err := strconv.Atoi(changed[k])
+		if err != nil {
+			continue
+		}
+		vc := VolumeChanged{Channel: channel, Volume: v}
+		if p, err := strconv.Atoi(before[k]); err == nil {
+			vc.Previous = &p
+		}
+		out = append(out, vc)
+	}
+	for _, k := range keys {
+		channel, ok := strings.CutPrefix(k, "mute_")
+		if k == "group_mute" {
+			channel, ok = "group", true
+		}
+		if ok {
+			out = append(out, MuteChanged{Channel: channel, Muted: changed[k] == "1"})
+		}
+	}
+
+	if v, ok := changed["update_id"]; ok {
+		out = append(out, QueueChanged{UpdateID: v})
+	}
+	if v, ok := changed["container_update_ids"]; ok {
+		// "Q:0,7,SQ:,3": container/update ID pairs; only the queue matters.
+		parts := strings.Split(v, ",")
+		for i := 0; i+1 < len(parts); i += 2 {
+			if parts[i] == "Q:0" {
+				out = append(out, QueueChanged{UpdateID: parts[i+1]})
+			}
+		}
+	}
+	return out
+}
+
+// groupLayout identifies which speakers are grouped under which
+// coordinator, ignoring the details that change on every event.
+func groupLayout(top Topology) string {
+	groups := make([]string, 0, len(top.Groups))
+	for _, g := range top.Groups {
+		uuids := make([]string, 0, len(g.Members))
+		for _, m := range g.Members {
+			uuids = append(uuids, m.UUID)
+		}
+		sort.Strings(uuids)
+		groups = append(groups, g.Coordinator.UUID+":"+strings.Join(uuids, ","))
+	}
+	sort.Strings(groups)
+	return strings.Join(groups, ";")
+}
@@ -0,0 +1,126 @@
+package sonos
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRoomStateReportsOnlyChanges(t *testing.T) {
+	meta := `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">` +
+		`<item id="-1" parentID="-1"><dc:title>Song</dc:title><dc:creator>Band</dc:creator><upnp:album>Record</upnp:album></item></DIDL-Lite>`
+	s := NewRoomState()
+
+	upd := s.Apply(Event{Service: ServiceAVTransport, Vars: map[string]string{
+		"transport_state":         "STOPPED",
+		"current_play_mode":       "NORMAL",
+		"current_track":           "1",
+		"current_track_uri":       "http://example.com/a.mp3",
+		"current_track_meta_data": meta,
+	}})
+	if len(upd.Changed) != 5 || len(upd.Events) != 3 {
+		t.Fatalf("first event: %+v", upd)
+	}
+	track, ok := upd.Events[2].(TrackChanged)
+	if !ok || track.Number != 1 || track.Item == nil || track.Item.Title != "Song" || track.Item.Artist != "Band" {
+		t.Fatalf("unexpected track event: %#v", upd.Events[2])
+	}
+
+	upd = s.Apply(Event{Service: ServiceAVTransport, Vars: map[string]string{
+		"transport_state":         "PLAYING",
+		"current_play_mode":       "NORMAL",
+		"current_track":           "1",
+		"current_track_uri":       "http://example.com/a.mp3",
+		"current_track_meta_data": meta,
+	}})
+	if !reflect.DeepEqual(upd.Changed, map[string]string{"transport_state": "PLAYING"}) {
+		t.Fatalf("changed = %v", upd.Changed)
+	}
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{TransportStateChanged{State: "PLAYING", Previous: "STOPPED"}}) {
+		t.Fatalf("events = %#v", upd.Events)
+	}
+
+	// Services are tracked separately; an unchanged event changes nothing.
+	s.Apply(Event{Service: ServiceRenderingControl, Vars: map[string]string{"volume_master": "20", "mute_master": "0"}})
+	upd = s.Apply(Event{Service: ServiceRenderingControl, Vars: map[string]string{"volume_master": "25", "mute_master": "0"}})
+	want := 20
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{VolumeChanged{Channel: "master", Volume: 25, Previous: &want}}) {
+		t.Fatalf("volume events = %#v", upd.Events)
+	}
+	if upd = s.Apply(Event{Service: ServiceRenderingControl, Vars: map[string]string{"volume_master": "25"}}); len(upd.Changed) != 0 || len(upd.Events) != 0 {
+		t.Fatalf("repeat event: %+v", upd)
+	}
+}
+
+func TestRoomStateReportsClearedValues(t *testing.T) {
+	s := NewRoomState()
+	// Empty values in the initial event are not changes.
+	upd := s.Apply(Event{Service: ServiceAVTransport, Vars: map[string]string{
+		"transport_state":         "STOPPED",
+		"avtransport_uri":        "",
+		"current_track_uri":       "http://example.com/a.mp3",
+		"current_track_meta_data": "<DIDL-Lite/>",
+	}})
+	if _, ok := upd.Changed["avtransport_uri"]; ok || len(upd.Changed) != 3 {
+		t.Fatalf("first event changed = %v", upd.Changed)
+	}
+
+	// Clearing the queue empties the track; that is reported.
+	upd = s.Apply(Event{Service: ServiceAVTransport, Vars: map[string]string{
+		"transport_state":         "STOPPED",
+		"current_track_uri":       "",
+		"current_track_meta_data": "",
+	}})
+	if !reflect.DeepEqual(upd.Changed, map[string]string{"current_track_uri": "", "current_track_meta_data": ""}) {
+		t.Fatalf("changed = %v", upd.Changed)
+	}
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{TrackChanged{}}) {
+		t.Fatalf("events = %#v", upd.Events)
+	}
+	if upd = s.Apply(Event{Service: ServiceAVTransport, Vars: map[string]string{"current_track_uri": ""}}); len(upd.Changed) != 0 {
+		t.Fatalf("repeat empty event: %+v", upd)
+	}
+}
+
+func TestRoomStateGroupQueueAndTopology(t *testing.T) {
+	s := NewRoomState()
+	upd := s.Apply(Event{Service: ServiceGroupRenderingControl, Vars: map[string]string{"group_volume": "30", "group_mute": "1", "group_volume_changeable": "1"}})
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{VolumeChanged{Channel: "group", Volume: 30}, MuteChanged{Channel: "group", Muted: true}}) {
+		t.Fatalf("group events = %#v", upd.Events)
+	}
+
+	upd = s.Apply(Event{Service: ServiceQueue, Vars: map[string]string{"update_id": "4"}})
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{QueueChanged{UpdateID: "4"}}) {
+		t.Fatalf("queue events = %#v", upd.Events)
+	}
+	upd = s.Apply(Event{Service: ServiceContentDirectory, Vars: map[string]string{"container_update_ids": "SQ:,3,Q:0,9"}})
+	if !reflect.DeepEqual(upd.Events, []RoomEvent{QueueChanged{UpdateID: "9"}}) {
+		t.Fatalf("content directory events = %#v", upd.Events)
+	}
+
+	zgs := func(bootSeq string) string {
+		return `<ZoneGroupState><ZoneGroups><ZoneGroup Coordinator="RINCON_A" ID="RINCON_A:1">` +
+			`<ZoneGroupMember UUID="RINCON_A" Location="http://10.0.0.1:1400/xml/device_description.xml" ZoneName="Kitchen" BootSeq="` + bootSeq + `"/>` +
+			`</ZoneGroup></ZoneGroups></ZoneGroupState>`
+	}
+	upd = s.Apply(Event{Service: ServiceZoneGroupTopology, Vars: map[string]string{"zone_group_state": zgs("1")}})
+	if len(upd.Events) != 1 {
+		t.Fatalf("topology events = %#v", upd.Events)
+	}
+	if tc := upd.Events[0].(TopologyChanged); len(tc.Topology.Groups) != 1 || tc.Topology.Groups[0].Coordinator.Name != "Kitchen" {
+		t.Fatalf("unexpected topology: %+v", tc)
+	}
+	// Same grouping, different details: the variable changed, the layout did not.
+	upd = s.Apply(Event{Service: ServiceZoneGroupTopology, Vars: map[string]string{"zone_group_state": zgs("2")}})
+	if len(upd.Changed) != 1 || len(upd.Events) != 0 {
+		t.Fatalf("detail-only topology update: %+v", upd)
+	}
+}
+
+func TestRoomStateIgnoresParseErrors(t *testing.T) {
+	s := NewRoomState()
+	upd := s.Apply(Event{Service: ServiceAVTransport, Err: errors.New("boom")})
+	if len(upd.Changed) != 0 || len(upd.Events) != 0 {
+		t.Fatalf("unexpected update: %+v", upd)
+	}
+}
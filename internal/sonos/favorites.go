@@ -55,6 +55,12 @@ func (c *Client) PlayFavorite(ctx context.Context, favorite DIDLItem) error {
 	return c.PlayURI(ctx, uri, favorite.ResMD)
 }
 
+// FavoriteURI returns the playable URI of a favorite, falling back to the URI
+// inside its r:resMD metadata.
+func FavoriteURI(favorite DIDLItem) string {
+	return favoriteURI(favorite)
+}
+
 func favoriteURI(favorite DIDLItem) string {
 	if favorite.URI != "" {
 		return favorite.URI
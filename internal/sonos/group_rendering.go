@@ -26,6 +26,9 @@ func (c *Client) GetGroupVolume(ctx context.Context) (int, error) {
 	return n, nil
 }
 
+// SetGroupVolume sets the group volume. When a member's room has a
+// VolumeLimit the members are written one by one instead, so the limited room
+// is capped rather than raised and pulled back down.
 func (c *Client) SetGroupVolume(ctx context.Context, volume int) error {
 	if volume < 0 {
 		volume = 0
@@ -33,15 +36,45 @@ func (c *Client) SetGroupVolume(ctx context.Context, volume int) error {
 	if volume > 100 {
 		volume = 100
 	}
+	members, err := c.groupLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if members != nil {
+		_, err := setLimitedGroupVolume(ctx, members, func(int) int { return volume })
+		return err
+	}
 	// SnapshotGroupVolume first (Sonos convention).
 	_ = c.SnapshotGroupVolume(ctx)
-	_, err := c.soapCall(ctx, controlGroupRendering, urnGroupRenderingControl, "SetGroupVolume", map[string]string{
+	_, err = c.soapCall(ctx, controlGroupRendering, urnGroupRenderingControl, "SetGroupVolume", map[string]string{
 		"InstanceID":    "0",
 		"DesiredVolume": strconv.Itoa(volume),
 	})
 	return err
 }
 
+// SetRelativeGroupVolume shifts the group volume by adjustment (may be
+// negative) and returns the new group volume. Limited rooms are handled as
+// in SetGroupVolume.
+func (c *Client) SetRelativeGroupVolume(ctx context.Context, adjustment int) (int, error) {
+	members, err := c.groupLimits(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if members != nil {
+		return setLimitedGroupVolume(ctx, members, func(current int) int { return current + adjustment })
+	}
+	_ = c.SnapshotGroupVolume(ctx)
+	resp, err := c.soapCall(ctx, controlGroupRendering, urnGroupRenderingControl, "SetRelativeGroupVolume", map[string]string{
+		"InstanceID": "0",
+		"Adjustment": strconv.Itoa(adjustment),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["NewVolume"])
+}
+
 func (c *Client) GetGroupMute(ctx context.Context) (bool, error) {
 	resp, err := c.soapCall(ctx, controlGroupRendering, urnGroupRenderingControl, "GetGroupMute", map[string]string{
 		"InstanceID": "0",
@@ -66,3 +66,22 @@ func TestGroupRenderingVolumeAndMute(t *testing.T) {
 		t.Fatalf("SetGroupMute: %v", err)
 	}
 }
+
+func TestSetRelativeGroupVolumeShiftsMembers(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Office")
+	kitchen, office := h.Speaker("Kitchen"), h.Speaker("Office")
+	kitchen.SetVolume(20)
+	office.SetVolume(40)
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	c := NewClient(kitchen.IP, 2*time.Second)
+
+	v, err := c.SetRelativeGroupVolume(context.Background(), -10)
+	if err != nil || v != 20 {
+		t.Fatalf("SetRelativeGroupVolume = %d, %v", v, err)
+	}
+	if kitchen.State().Volume != 10 || office.State().Volume != 30 {
+		t.Fatalf("members not shifted: %d %d", kitchen.State().Volume, office.State().Volume)
+	}
+}
@@ -0,0 +1,153 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// The local music library (shares indexed by Sonos) lives under the
+// ContentDirectory "A:" containers. Browsing "<container>:<term>" instead of
+// a container returns its entries matching term, which is how Sonos apps
+// search the library.
+
+var libraryContainers = map[string]string{
+	"artists":      "A:ARTIST",
+	"albumartists": "A:ALBUMARTIST",
+	"albums":       "A:ALBUM",
+	"tracks":       "A:TRACKS",
+	"genres":       "A:GENRE",
+	"composers":    "A:COMPOSER",
+	"playlists":    "A:PLAYLISTS",
+}
+
+// LibraryCategories returns the supported library categories, sorted.
+func LibraryCategories() []string {
+	out := make([]string, 0, len(libraryContainers))
+	for k := range libraryContainers {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LibraryContainerID maps a category (artists, albums, tracks, ...) to its
+// A: container ID.
+func LibraryContainerID(category string) (string, error) {
+	id, ok := libraryContainers[strings.ToLower(strings.TrimSpace(category))]
+	if !ok {
+		return "", fmt.Errorf("unknown library category %q (expected one of: %s)", category, strings.Join(LibraryCategories(), ", "))
+	}
+	return id, nil
+}
+
+// IsLibraryID reports whether id is an object in the local music library.
+func IsLibraryID(id string) bool {
+	return strings.HasPrefix(id, "A:")
+}
+
+type LibraryItem struct {
+	Position int      `json:"position"` // 1-based
+	Item     DIDLItem `json:"item"`
+}
+
+type LibraryPage struct {
+	Items          []LibraryItem `json:"items"`
+	NumberReturned int           `json:"numberReturned"`
+	TotalMatches   int           `json:"totalMatches"`
+	UpdateID       int           `json:"updateID"`
+}
+
+// BrowseLibrary lists the children of a library object (e.g. A:ALBUM or an
+// album ID returned by a previous browse).
+func (c *Client) BrowseLibrary(ctx context.Context, objectID string, start, count int) (LibraryPage, error) {
+	objectID = strings.TrimSpace(objectID)
+	if !IsLibraryID(objectID) {
+		return LibraryPage{}, fmt.Errorf("not a library object ID: %q (expected A:...)", objectID)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if count <= 0 {
+		count = 100
+	}
+	br, err := c.Browse(ctx, objectID, start, count)
+	if err != nil {
+		return LibraryPage{}, err
+	}
+	didlItems, err := ParseDIDLItems(br.Result)
+	if err != nil {
+		return LibraryPage{}, err
+	}
+	items := make([]LibraryItem, 0, len(didlItems))
+	for i, it := range didlItems {
+		items = append(items, LibraryItem{
+			Position: start + i + 1,
+			Item:     it,
+		})
+	}
+	return LibraryPage{
+		Items:          items,
+		NumberReturned: br.NumberReturned,
+		TotalMatches:   br.TotalMatches,
+		UpdateID:       br.UpdateID,
+	}, nil
+}
+
+// SearchLibrary returns the entries of a category whose name matches term.
+func (c *Client) SearchLibrary(ctx context.Context, category, term string, start, count int) (LibraryPage, error) {
+	id, err := LibraryContainerID(category)
+	if err != nil {
+		return LibraryPage{}, err
+	}
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return LibraryPage{}, errors.New("search term is required")
+	}
+	return c.BrowseLibrary(ctx, id+":"+url.PathEscape(term), start, count)
+}
+
+// LibraryMeta builds the DIDL-Lite metadata for enqueuing a library item or
+// container with AddURIToQueue.
+func LibraryMeta(item DIDLItem) string {
+	parentID := item.ID
+	if i := strings.LastIndex(parentID, "/"); i >= 0 {
+		parentID = parentID[:i]
+	}
+	class := item.Class
+	if class == "" {
+		class = "object.item.audioItem.musicTrack"
+	}
+	return `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="` + xmlEscapeAttr(item.ID) + `" parentID="` + xmlEscapeAttr(parentID) + `" restricted="true">` +
+		`<dc:title>` + xmlEscapeText(item.Title) + `</dc:title>` +
+		`<upnp:class>` + xmlEscapeText(class) + `</upnp:class>` +
+		`<desc id="cdudn" nameSpace="urn:schemas-rinconnetworks-com:metadata-1-0/">RINCON_AssociatedZPUDN</desc>` +
+		`</item></DIDL-Lite>`
+}
+
+// EnqueueLibraryItem adds a library track or container (album, artist, ...)
+// to the queue. With opts.PlayNow it starts playing the first added track.
+func (c *Client) EnqueueLibraryItem(ctx context.Context, item DIDLItem, opts EnqueueOptions) (int, error) {
+	if item.URI == "" {
+		return 0, fmt.Errorf("library item %q is not playable", item.ID)
+	}
+	desiredPos := opts.Position
+	if desiredPos < 0 {
+		desiredPos = 0
+	}
+	first, err := c.AddURIToQueue(ctx, item.URI, LibraryMeta(item), desiredPos, opts.AsNext)
+	if err != nil {
+		return 0, err
+	}
+	if opts.PlayNow {
+		if first > 0 {
+			return first, c.playFromQueueTrack(ctx, first)
+		}
+		return first, c.Play(ctx)
+	}
+	return first, nil
+}
@@ -0,0 +1,108 @@
+package sonos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func libraryTitles(p LibraryPage) string {
+	titles := make([]string, 0, len(p.Items))
+	for _, it := range p.Items {
+		titles = append(titles, it.Item.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func newLibraryHousehold(t *testing.T) (*sonostest.Speaker, *Client) {
+	t.Helper()
+	h := newSnapshotHousehold(t, "Kitchen")
+	h.SetLibrary(
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/1.flac", Title: "Come Together", Artist: "The Beatles", Album: "Abbey Road", Genre: "Rock"},
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/2.flac", Title: "Something", Artist: "The Beatles", Album: "Abbey Road", Genre: "Rock"},
+		sonostest.LibraryTrack{URI: "x-file-cifs://nas/music/3.flac", Title: "So What", Artist: "Miles Davis", Album: "Kind of Blue", Genre: "Jazz", Composer: "Miles Davis"},
+	)
+	kitchen := h.Speaker("Kitchen")
+	return kitchen, NewClient(kitchen.IP, 2*time.Second)
+}
+
+func TestLibraryContainerID(t *testing.T) {
+	if id, err := LibraryContainerID(" Albums "); err != nil || id != "A:ALBUM" {
+		t.Fatalf("LibraryContainerID = %q, %v", id, err)
+	}
+	if _, err := LibraryContainerID("podcasts"); err == nil || !strings.Contains(err.Error(), "artists") {
+		t.Fatalf("expected error listing categories, got %v", err)
+	}
+}
+
+func TestBrowseAndSearchLibrary(t *testing.T) {
+	_, c := newLibraryHousehold(t)
+	ctx := context.Background()
+
+	artists, err := c.BrowseLibrary(ctx, "A:ARTIST", 0, 0)
+	if err != nil {
+		t.Fatalf("BrowseLibrary: %v", err)
+	}
+	if got := libraryTitles(artists); got != "Miles Davis,The Beatles" || artists.TotalMatches != 2 {
+		t.Fatalf("artists = %s (%d)", got, artists.TotalMatches)
+	}
+	beatles := artists.Items[1].Item
+	if beatles.ID != "A:ARTIST/The%20Beatles" || !strings.HasPrefix(beatles.URI, "x-rincon-playlist:") {
+		t.Fatalf("unexpected artist item: %+v", beatles)
+	}
+
+	albums, err := c.BrowseLibrary(ctx, beatles.ID, 0, 10)
+	if err != nil || libraryTitles(albums) != "Abbey Road" {
+		t.Fatalf("artist albums = %+v, %v", albums, err)
+	}
+	tracks, err := c.BrowseLibrary(ctx, albums.Items[0].Item.ID, 1, 10)
+	if err != nil || libraryTitles(tracks) != "Something" || tracks.Items[0].Position != 2 || tracks.TotalMatches != 2 {
+		t.Fatalf("album tracks page = %+v, %v", tracks, err)
+	}
+
+	found, err := c.SearchLibrary(ctx, "tracks", "so", 0, 10)
+	if err != nil || libraryTitles(found) != "Something,So What" {
+		t.Fatalf("SearchLibrary(tracks) = %+v, %v", found, err)
+	}
+	found, err = c.SearchLibrary(ctx, "albums", "kind of", 0, 10)
+	if err != nil || libraryTitles(found) != "Kind of Blue" {
+		t.Fatalf("SearchLibrary(albums) = %+v, %v", found, err)
+	}
+
+	if _, err := c.BrowseLibrary(ctx, "SQ:1", 0, 10); err == nil {
+		t.Fatalf("expected non-library ID error")
+	}
+	if _, err := c.SearchLibrary(ctx, "albums", " ", 0, 10); err == nil {
+		t.Fatalf("expected empty term error")
+	}
+}
+
+func TestEnqueueLibraryItem(t *testing.T) {
+	kitchen, c := newLibraryHousehold(t)
+	ctx := context.Background()
+
+	albums, err := c.BrowseLibrary(ctx, "A:ALBUM", 0, 10)
+	if err != nil {
+		t.Fatalf("BrowseLibrary: %v", err)
+	}
+	first, err := c.EnqueueLibraryItem(ctx, albums.Items[0].Item, EnqueueOptions{PlayNow: true})
+	if err != nil || first != 1 {
+		t.Fatalf("EnqueueLibraryItem = %d, %v", first, err)
+	}
+	st := kitchen.State()
+	if len(st.Queue) != 2 || st.Queue[0].Title != "Come Together" || st.TransportState != "PLAYING" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	if _, err := c.EnqueueLibraryItem(ctx, DIDLItem{ID: "A:GENRE"}, EnqueueOptions{}); err == nil {
+		t.Fatalf("expected not playable error")
+	}
+
+	meta := LibraryMeta(DIDLItem{ID: "A:ALBUM/Rock%20&%20Roll", Title: "Rock & Roll", Class: "object.container.album.musicAlbum"})
+	if !strings.Contains(meta, `parentID="A:ALBUM"`) || !strings.Contains(meta, "Rock &amp; Roll") || !strings.Contains(meta, "musicAlbum") {
+		t.Fatalf("unexpected meta: %s", meta)
+	}
+}
@@ -0,0 +1,61 @@
+package sonos
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// musicServiceIDs maps the sid= parameter of service URIs to a service name.
+// These are the public SMAPI service IDs; the sn/SA_RINCON numbers used in
+// share-link metadata are account-specific and not listed here.
+var musicServiceIDs = map[int]string{
+	2:   "Deezer",
+	12:  "Spotify",
+	160: "SoundCloud",
+	174: "TIDAL",
+	201: "Amazon Music",
+	204: "Apple Music",
+	254: "TuneIn",
+	284: "YouTube Music",
+	303: "Sonos Radio",
+	333: "TuneIn",
+}
+
+// MusicServiceForURI names the service a track URI plays from: the sid=
+// parameter when present (x-sonos-http:song%3a...mp4?sid=204 is Apple Music),
+// otherwise the URI scheme. It returns "" when the source is unknown.
+func MusicServiceForURI(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	if _, query, ok := strings.Cut(uri, "?"); ok {
+		if values, err := url.ParseQuery(query); err == nil {
+			if sid, err := strconv.Atoi(values.Get("sid")); err == nil {
+				if name, ok := musicServiceIDs[sid]; ok {
+					return name
+				}
+				return "sid " + strconv.Itoa(sid)
+			}
+		}
+	}
+	lower := strings.ToLower(uri)
+	switch {
+	case strings.HasPrefix(lower, "x-sonos-spotify:"),
+		strings.HasPrefix(lower, "x-sonos-vli:") && strings.Contains(lower, "spotify"):
+		return "Spotify"
+	case strings.HasPrefix(lower, "x-sonos-vli:"), strings.HasPrefix(lower, "x-sonos-airplay:"):
+		return "AirPlay"
+	case strings.HasPrefix(lower, "x-file-cifs:"), strings.HasPrefix(lower, "x-smb:"):
+		return "Music Library"
+	case strings.HasPrefix(lower, "x-rincon-stream:"):
+		return "Line-In"
+	case strings.HasPrefix(lower, "x-sonos-htastream:"):
+		return "TV"
+	case SourceKind(uri) == SourceStream:
+		return "Radio"
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		return "Web"
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package sonos
+
+import "testing"
+
+func TestMusicServiceForURI(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"": "",
+		"x-sonos-http:song%3a1440838039.mp4?sid=204&flags=8224&sn=10":     "Apple Music",
+		"x-sonos-spotify:spotify%3atrack%3a6rqhFgbbKwnb9MLmUQDhG6?sid=12": "Spotify",
+		"x-sonos-spotify:spotify%3atrack%3a6rqhFgbbKwnb9MLmUQDhG6":        "Spotify",
+		"x-sonosapi-stream:s12345?sid=254&flags=8224&sn=0":                "TuneIn",
+		"x-sonos-http:track%3a123.mp3?sid=9999":                           "sid 9999",
+		"x-sonos-vli:RINCON_1:2,spotify:abc":                              "Spotify",
+		"x-file-cifs://nas/Music/Band/Record/01.flac":                     "Music Library",
+		"x-rincon-stream:RINCON_1":                                        "Line-In",
+		"x-sonos-htastream:RINCON_1:spdif":                                "TV",
+		"x-rincon-mp3radio://stream.example.com/live":                     "Radio",
+		"http://example.com/a.mp3":                                        "Web",
+		"x-rincon:RINCON_1":                                               "",
+	}
+	for uri, want := range cases {
+		if got := MusicServiceForURI(uri); got != want {
+			t.Errorf("MusicServiceForURI(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
@@ -35,6 +35,42 @@ func BuildRadioMeta(title string) string {
 		`</item></DIDL-Lite>`
 }
 
+// TrackMeta describes a plain audio file for BuildTrackMeta.
+type TrackMeta struct {
+	URI         string
+	MimeType    string // e.g. audio/mpeg
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtURI string
+}
+
+// BuildTrackMeta builds DIDL-Lite metadata for a music track served over HTTP
+// (e.g. by a local media server), so Sonos shows title/artist/album/art.
+func BuildTrackMeta(t TrackMeta) string {
+	mimeType := strings.TrimSpace(t.MimeType)
+	if mimeType == "" {
+		mimeType = "*"
+	}
+	var b strings.Builder
+	b.WriteString(`<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">`)
+	b.WriteString(`<item id="-1" parentID="-1" restricted="true">`)
+	b.WriteString(`<res protocolInfo="http-get:*:` + xmlEscapeAttr(mimeType) + `:*">` + xmlEscapeText(t.URI) + `</res>`)
+	if t.AlbumArtURI != "" {
+		b.WriteString(`<upnp:albumArtURI>` + xmlEscapeText(t.AlbumArtURI) + `</upnp:albumArtURI>`)
+	}
+	b.WriteString(`<dc:title>` + xmlEscapeText(t.Title) + `</dc:title>`)
+	b.WriteString(`<upnp:class>object.item.audioItem.musicTrack</upnp:class>`)
+	if t.Artist != "" {
+		b.WriteString(`<dc:creator>` + xmlEscapeText(t.Artist) + `</dc:creator>`)
+	}
+	if t.Album != "" {
+		b.WriteString(`<upnp:album>` + xmlEscapeText(t.Album) + `</upnp:album>`)
+	}
+	b.WriteString(`</item></DIDL-Lite>`)
+	return b.String()
+}
+
 func (c *Client) PlayURI(ctx context.Context, uri, meta string) error {
 	if err := c.SetAVTransportURI(ctx, uri, meta); err != nil {
 		return err
@@ -34,6 +34,34 @@ func TestBuildRadioMeta(t *testing.T) {
 	}
 }
 
+func TestBuildTrackMeta(t *testing.T) {
+	t.Parallel()
+
+	meta := BuildTrackMeta(TrackMeta{
+		URI:         "http://192.168.1.5:5000/media/1/a&b.mp3",
+		MimeType:    "audio/mpeg",
+		Title:       "Song <1>",
+		Artist:      "Band",
+		Album:       "Album",
+		AlbumArtURI: "http://192.168.1.5:5000/art/1",
+	})
+	if !containsAll(meta, []string{
+		`protocolInfo="http-get:*:audio/mpeg:*"`,
+		"/media/1/a&amp;b.mp3</res>",
+		"<dc:title>Song &lt;1&gt;</dc:title>",
+		"<dc:creator>Band</dc:creator>",
+		"<upnp:album>Album</upnp:album>",
+		"<upnp:albumArtURI>http://192.168.1.5:5000/art/1</upnp:albumArtURI>",
+		"object.item.audioItem.musicTrack",
+	}) {
+		t.Fatalf("unexpected meta: %s", meta)
+	}
+	items, err := ParseDIDLItems(meta)
+	if err != nil || len(items) != 1 || items[0].Title != "Song <1>" {
+		t.Fatalf("meta does not round-trip: %+v %v", items, err)
+	}
+}
+
 func containsAll(s string, subs []string) bool {
 	for _, sub := range subs {
 		if !strings.Contains(s, sub) {
@@ -0,0 +1,252 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sonos playlists are "saved queues": ContentDirectory containers SQ:<n>
+// listed under SQ:. They are edited through AVTransport actions that take the
+// playlist's current UpdateID, so concurrent edits fail instead of clobbering.
+
+type PlaylistItem struct {
+	Position int      `json:"position"` // 1-based
+	Item     DIDLItem `json:"item"`
+}
+
+type PlaylistsPage struct {
+	Items          []PlaylistItem `json:"items"`
+	NumberReturned int            `json:"numberReturned"`
+	TotalMatches   int            `json:"totalMatches"`
+	UpdateID       int            `json:"updateID"`
+}
+
+// IsPlaylistID reports whether id looks like a Sonos playlist object ID (SQ:<n>).
+func IsPlaylistID(id string) bool {
+	n, ok := strings.CutPrefix(id, "SQ:")
+	if !ok || n == "" {
+		return false
+	}
+	_, err := strconv.Atoi(n)
+	return err == nil
+}
+
+// PlaylistURI returns the URI that enqueues a whole playlist with AddURIToQueue.
+func PlaylistURI(id string) string {
+	return "file:///jffs/settings/savedqueues.rsq#" + strings.TrimPrefix(id, "SQ:")
+}
+
+// PlaylistMeta builds the DIDL-Lite metadata that accompanies PlaylistURI.
+func PlaylistMeta(id, title string) string {
+	return `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="` + xmlEscapeAttr(id) + `" parentID="SQ:" restricted="true">` +
+		`<dc:title>` + xmlEscapeText(title) + `</dc:title>` +
+		`<upnp:class>object.container.playlistContainer</upnp:class>` +
+		`<desc id="cdudn" nameSpace="urn:schemas-rinconnetworks-com:metadata-1-0/">RINCON_AssociatedZPUDN</desc>` +
+		`</item></DIDL-Lite>`
+}
+
+func (c *Client) ListPlaylists(ctx context.Context, start, count int) (PlaylistsPage, error) {
+	if start < 0 {
+		start = 0
+	}
+	if count <= 0 {
+		count = 100
+	}
+	br, err := c.Browse(ctx, "SQ:", start, count)
+	if err != nil {
+		return PlaylistsPage{}, err
+	}
+	didlItems, err := ParseDIDLItems(br.Result)
+	if err != nil {
+		return PlaylistsPage{}, err
+	}
+	items := make([]PlaylistItem, 0, len(didlItems))
+	for i, it := range didlItems {
+		items = append(items, PlaylistItem{
+			Position: start + i + 1,
+			Item:     it,
+		})
+	}
+	return PlaylistsPage{
+		Items:          items,
+		NumberReturned: br.NumberReturned,
+		TotalMatches:   br.TotalMatches,
+		UpdateID:       br.UpdateID,
+	}, nil
+}
+
+// ListPlaylistTracks browses the tracks of playlist id (SQ:<n>). The page's
+// UpdateID is the one saved-queue edits expect.
+func (c *Client) ListPlaylistTracks(ctx context.Context, id string, start, count int) (QueuePage, error) {
+	if !IsPlaylistID(id) {
+		return QueuePage{}, fmt.Errorf("invalid playlist id %q (expected SQ:<n>)", id)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if count <= 0 {
+		count = 100
+	}
+	br, err := c.Browse(ctx, id, start, count)
+	if err != nil {
+		return QueuePage{}, err
+	}
+	didlItems, err := ParseDIDLItems(br.Result)
+	if err != nil {
+		return QueuePage{}, err
+	}
+	items := make([]QueueItem, 0, len(didlItems))
+	for i, it := range didlItems {
+		items = append(items, QueueItem{
+			Position: start + i + 1,
+			Item:     it,
+		})
+	}
+	return QueuePage{
+		Items:          items,
+		NumberReturned: br.NumberReturned,
+		TotalMatches:   br.TotalMatches,
+		UpdateID:       br.UpdateID,
+	}, nil
+}
+
+// CreateSavedQueue creates an empty playlist and returns its object ID.
+func (c *Client) CreateSavedQueue(ctx context.Context, title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("playlist title is required")
+	}
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "CreateSavedQueue", map[string]string{
+		"InstanceID":          "0",
+		"Title":               title,
+		"EnqueuedURI":         "",
+		"EnqueuedURIMetaData": "",
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp["AssignedObjectID"], nil
+}
+
+// AddURIToSavedQueue appends uri to playlist id and returns the new UpdateID.
+func (c *Client) AddURIToSavedQueue(ctx context.Context, id string, updateID int, uri, meta string) (int, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "AddURIToSavedQueue", map[string]string{
+		"InstanceID":          "0",
+		"ObjectID":            id,
+		"UpdateID":            strconv.Itoa(updateID),
+		"EnqueuedURI":         uri,
+		"EnqueuedURIMetaData": meta,
+		"AddAtIndex":          "4294967295", // append
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(resp["NewUpdateID"])
+	return n, nil
+}
+
+// ReorderTracksInSavedQueue moves the tracks at the comma-separated 0-based
+// indexes in trackList to the matching positions in newPositionList. An empty
+// newPositionList removes the tracks instead. It returns the new UpdateID.
+func (c *Client) ReorderTracksInSavedQueue(ctx context.Context, id string, updateID int, trackList, newPositionList string) (int, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "ReorderTracksInSavedQueue", map[string]string{
+		"InstanceID":      "0",
+		"ObjectID":        id,
+		"UpdateID":        strconv.Itoa(updateID),
+		"TrackList":       trackList,
+		"NewPositionList": newPositionList,
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(resp["NewUpdateID"])
+	return n, nil
+}
+
+// SaveQueue saves the current queue as a playlist. An empty id creates a new
+// playlist; otherwise playlist id is overwritten. It returns the object ID.
+func (c *Client) SaveQueue(ctx context.Context, title, id string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("playlist title is required")
+	}
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "SaveQueue", map[string]string{
+		"InstanceID": "0",
+		"Title":      title,
+		"ObjectID":   id,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp["AssignedObjectID"], nil
+}
+
+// DestroyObject deletes a ContentDirectory object such as a playlist.
+func (c *Client) DestroyObject(ctx context.Context, id string) error {
+	_, err := c.soapCall(ctx, controlContentDirectory, urnContentDirectory, "DestroyObject", map[string]string{
+		"ObjectID": id,
+	})
+	return err
+}
+
+// AddToPlaylist appends URIs to playlist id, fetching its UpdateID first.
+func (c *Client) AddToPlaylist(ctx context.Context, id string, uris ...string) error {
+	page, err := c.ListPlaylistTracks(ctx, id, 0, 1)
+	if err != nil {
+		return err
+	}
+	updateID := page.UpdateID
+	for _, uri := range uris {
+		if updateID, err = c.AddURIToSavedQueue(ctx, id, updateID, uri, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveFromPlaylist removes the tracks at the given 1-based positions.
+func (c *Client) RemoveFromPlaylist(ctx context.Context, id string, positions ...int) error {
+	page, err := c.ListPlaylistTracks(ctx, id, 0, 1)
+	if err != nil {
+		return err
+	}
+	sorted := append([]int(nil), positions...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	// Check every position first so a bad one leaves the playlist untouched.
+	for _, pos := range sorted {
+		if pos < 1 || pos > page.TotalMatches {
+			return fmt.Errorf("position %d out of range (playlist has %d tracks)", pos, page.TotalMatches)
+		}
+	}
+	updateID := page.UpdateID
+	for i, pos := range sorted {
+		if i > 0 && pos == sorted[i-1] {
+			continue
+		}
+		// Highest first, so earlier removals do not shift later positions.
+		if updateID, err = c.ReorderTracksInSavedQueue(ctx, id, updateID, strconv.Itoa(pos-1), ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MovePlaylistTrack moves the track at 1-based position from to position to.
+func (c *Client) MovePlaylistTrack(ctx context.Context, id string, from, to int) error {
+	page, err := c.ListPlaylistTracks(ctx, id, 0, 1)
+	if err != nil {
+		return err
+	}
+	for _, pos := range []int{from, to} {
+		if pos < 1 || pos > page.TotalMatches {
+			return fmt.Errorf("position %d out of range (playlist has %d tracks)", pos, page.TotalMatches)
+		}
+	}
+	_, err = c.ReorderTracksInSavedQueue(ctx, id, page.UpdateID, strconv.Itoa(from-1), strconv.Itoa(to-1))
+	return err
+}
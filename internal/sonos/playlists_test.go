@@ -0,0 +1,131 @@
+package sonos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func playlistTitles(p QueuePage) string {
+	titles := make([]string, 0, len(p.Items))
+	for _, it := range p.Items {
+		titles = append(titles, it.Item.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestPlaylistLifecycle(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	ctx := context.Background()
+	c := NewClient(kitchen.IP, 2*time.Second)
+
+	id, err := c.CreateSavedQueue(ctx, "Dinner")
+	if err != nil || !IsPlaylistID(id) {
+		t.Fatalf("CreateSavedQueue = %q, %v", id, err)
+	}
+	if err := c.AddToPlaylist(ctx, id, "http://example.com/a.mp3", "http://example.com/b.mp3", "http://example.com/c.mp3"); err != nil {
+		t.Fatalf("AddToPlaylist: %v", err)
+	}
+
+	list, err := c.ListPlaylists(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListPlaylists: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Item.ID != id || list.Items[0].Item.Title != "Dinner" || list.Items[0].Item.URI != PlaylistURI(id) {
+		t.Fatalf("unexpected playlists: %+v", list)
+	}
+
+	if err := c.MovePlaylistTrack(ctx, id, 3, 1); err != nil {
+		t.Fatalf("MovePlaylistTrack: %v", err)
+	}
+	tracks, err := c.ListPlaylistTracks(ctx, id, 0, 10)
+	if err != nil {
+		t.Fatalf("ListPlaylistTracks: %v", err)
+	}
+	if got := playlistTitles(tracks); got != "http://example.com/c.mp3,http://example.com/a.mp3,http://example.com/b.mp3" {
+		t.Fatalf("unexpected order after move: %s", got)
+	}
+
+	// An invalid position fails before anything is removed.
+	if err := c.RemoveFromPlaylist(ctx, id, 3, 0); err == nil {
+		t.Fatalf("expected out-of-range error")
+	}
+	if tracks, _ := c.ListPlaylistTracks(ctx, id, 0, 10); tracks.TotalMatches != 3 {
+		t.Fatalf("playlist edited by a failed remove: %+v", tracks)
+	}
+	if err := c.RemoveFromPlaylist(ctx, id, 1, 3); err != nil {
+		t.Fatalf("RemoveFromPlaylist: %v", err)
+	}
+	tracks, _ = c.ListPlaylistTracks(ctx, id, 0, 10)
+	if got := playlistTitles(tracks); got != "http://example.com/a.mp3" {
+		t.Fatalf("unexpected tracks after remove: %s", got)
+	}
+	if err := c.RemoveFromPlaylist(ctx, id, 5); err == nil {
+		t.Fatalf("expected out-of-range error")
+	}
+
+	// A stale UpdateID is rejected rather than overwriting concurrent edits.
+	if _, err := c.AddURIToSavedQueue(ctx, id, tracks.UpdateID-1, "http://example.com/x.mp3", ""); err == nil {
+		t.Fatalf("expected stale UpdateID error")
+	}
+
+	if err := c.DestroyObject(ctx, id); err != nil {
+		t.Fatalf("DestroyObject: %v", err)
+	}
+	if list, _ := c.ListPlaylists(ctx, 0, 10); len(list.Items) != 0 {
+		t.Fatalf("playlist not deleted: %+v", list)
+	}
+}
+
+func TestSaveQueueAndEnqueuePlaylist(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "One"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Two"},
+	)
+	ctx := context.Background()
+	c := NewClient(kitchen.IP, 2*time.Second)
+
+	id, err := c.SaveQueue(ctx, "Keep", "")
+	if err != nil {
+		t.Fatalf("SaveQueue: %v", err)
+	}
+	tracks, err := c.ListPlaylistTracks(ctx, id, 0, 10)
+	if err != nil || playlistTitles(tracks) != "One,Two" {
+		t.Fatalf("saved playlist = %+v, %v", tracks, err)
+	}
+
+	if err := c.ClearQueue(ctx); err != nil {
+		t.Fatalf("ClearQueue: %v", err)
+	}
+	first, err := c.AddURIToQueue(ctx, PlaylistURI(id), PlaylistMeta(id, "Keep"), 0, false)
+	if err != nil || first != 1 {
+		t.Fatalf("AddURIToQueue(playlist) = %d, %v", first, err)
+	}
+	if q := kitchen.State().Queue; len(q) != 2 || q[1].Title != "Two" {
+		t.Fatalf("unexpected queue: %+v", q)
+	}
+	if _, err := c.ListPlaylistTracks(ctx, "Q:0", 0, 10); err == nil {
+		t.Fatalf("expected invalid playlist id error")
+	}
+}
+
+func TestPlaylistHelpers(t *testing.T) {
+	for in, want := range map[string]bool{"SQ:3": true, "SQ:": false, "SQ:x": false, "FV:2": false} {
+		if got := IsPlaylistID(in); got != want {
+			t.Fatalf("IsPlaylistID(%q) = %v", in, got)
+		}
+	}
+	if got := PlaylistURI("SQ:12"); got != "file:///jffs/settings/savedqueues.rsq#12" {
+		t.Fatalf("PlaylistURI = %q", got)
+	}
+	meta := PlaylistMeta("SQ:12", "Rock & Roll")
+	if !strings.Contains(meta, `id="SQ:12"`) || !strings.Contains(meta, "Rock &amp; Roll") || !strings.Contains(meta, "playlistContainer") {
+		t.Fatalf("unexpected meta: %s", meta)
+	}
+}
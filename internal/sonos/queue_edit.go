@@ -0,0 +1,426 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrQueueChanged is returned by queue edits when the queue's UpdateID no
+// longer matches the one the edit was planned against, i.e. another
+// controller changed the queue in the meantime.
+var ErrQueueChanged = errors.New("queue was changed by another controller; list it again and retry")
+
+// maxURIsPerAdd is the most URIs Sonos accepts in one AddMultipleURIsToQueue call.
+const maxURIsPerAdd = 16
+
+// QueueAddResult is the outcome of AddMultipleURIsToQueue.
+type QueueAddResult struct {
+	FirstTrackNumber int
+	NumTracksAdded   int
+	NewQueueLength   int
+	NewUpdateID      int
+}
+
+// QueueRange is a run of Count queue entries starting at 1-based Start.
+type QueueRange struct {
+	Start int `json:"start"`
+	Count int `json:"count"`
+}
+
+// ParseQueueRanges parses a position list such as "3-7,10" into ranges.
+func ParseQueueRanges(spec string) ([]QueueRange, error) {
+	var out []QueueRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid queue position %q (expected N or N-M, 1-based)", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid queue range %q (expected N-M with N <= M)", part)
+			}
+		}
+		out = append(out, QueueRange{Start: start, Count: end - start + 1})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no queue positions given")
+	}
+	return out, nil
+}
+
+// ReorderTracksInQueue moves count tracks starting at 1-based start so they
+// sit before the track currently at insertBefore (len+1 moves to the end).
+// It returns the queue's new UpdateID, or 0 if the speaker did not report
+// one; passing 0 as updateID skips the speaker's check.
+func (c *Client) ReorderTracksInQueue(ctx context.Context, start, count, insertBefore, updateID int) (int, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "ReorderTracksInQueue", map[string]string{
+		"InstanceID":     "0",
+		"StartingIndex":  strconv.Itoa(start),
+		"NumberOfTracks": strconv.Itoa(count),
+		"InsertBefore":   strconv.Itoa(insertBefore),
+		"UpdateID":       strconv.Itoa(updateID),
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(resp["NewUpdateID"])
+	return n, nil
+}
+
+// RemoveTrackRangeFromQueue removes count tracks starting at 1-based start and
+// returns the queue's new UpdateID.
+func (c *Client) RemoveTrackRangeFromQueue(ctx context.Context, updateID, start, count int) (int, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "RemoveTrackRangeFromQueue", map[string]string{
+		"InstanceID":     "0",
+		"UpdateID":       strconv.Itoa(updateID),
+		"StartingIndex":  strconv.Itoa(start),
+		"NumberOfTracks": strconv.Itoa(count),
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(resp["NewUpdateID"])
+	return n, nil
+}
+
+// AddMultipleURIsToQueue enqueues up to 16 URIs in one call. metas may be nil
+// or hold one entry per URI. desiredFirstTrackNumber 0 appends.
+func (c *Client) AddMultipleURIsToQueue(ctx context.Context, updateID int, uris, metas []string, desiredFirstTrackNumber int, enqueueAsNext bool) (QueueAddResult, error) {
+	if len(uris) == 0 {
+		return QueueAddResult{}, errors.New("no URIs to enqueue")
+	}
+	if len(uris) > maxURIsPerAdd {
+		return QueueAddResult{}, fmt.Errorf("at most %d URIs per call", maxURIsPerAdd)
+	}
+	if metas != nil && len(metas) != len(uris) {
+		return QueueAddResult{}, errors.New("metadata count does not match URI count")
+	}
+	if metas == nil {
+		metas = make([]string, len(uris))
+	}
+	asNext := "0"
+	if enqueueAsNext {
+		asNext = "1"
+	}
+	// Both lists are space-separated; spaces inside URIs must be escaped.
+	escapedURIs := make([]string, len(uris))
+	for i, u := range uris {
+		escapedURIs[i] = strings.ReplaceAll(u, " ", "%20")
+	}
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "AddMultipleURIsToQueue", map[string]string{
+		"InstanceID":                      "0",
+		"UpdateID":                        strconv.Itoa(updateID),
+		"NumberOfURIs":                    strconv.Itoa(len(uris)),
+		"EnqueuedURIs":                    strings.Join(escapedURIs, " "),
+		"EnqueuedURIsMetaData":            strings.Join(metas, " "),
+		"ContainerURI":                    "",
+		"ContainerMetaData":               "",
+		"DesiredFirstTrackNumberEnqueued": strconv.Itoa(desiredFirstTrackNumber),
+		"EnqueueAsNext":                   asNext,
+	})
+	if err != nil {
+		return QueueAddResult{}, err
+	}
+	var out QueueAddResult
+	out.FirstTrackNumber, _ = strconv.Atoi(resp["FirstTrackNumberEnqueued"])
+	out.NumTracksAdded, _ = strconv.Atoi(resp["NumTracksAdded"])
+	out.NewQueueLength, _ = strconv.Atoi(resp["NewQueueLength"])
+	out.NewUpdateID, _ = strconv.Atoi(resp["NewUpdateID"])
+	return out, nil
+}
+
+// ListAllQueue returns every queue entry in one page.
+func (c *Client) ListAllQueue(ctx context.Context) (QueuePage, error) {
+	const pageSize = 100
+	var all QueuePage
+	for {
+		page, err := c.ListQueue(ctx, len(all.Items), pageSize)
+		if err != nil {
+			return QueuePage{}, err
+		}
+		if len(all.Items) == 0 {
+			all.UpdateID = page.UpdateID
+		} else if page.UpdateID != all.UpdateID {
+			return QueuePage{}, ErrQueueChanged
+		}
+		all.Items = append(all.Items, page.Items...)
+		all.TotalMatches = page.TotalMatches
+		if page.NumberReturned == 0 || len(all.Items) >= page.TotalMatches {
+			break
+		}
+	}
+	all.NumberReturned = len(all.Items)
+	return all, nil
+}
+
+// editQueue runs one edit if the queue is still at updateID and returns the
+// first entry page read afterwards, whose UpdateID and TotalMatches describe
+// the edited queue. Actions that take an UpdateID are also rejected by the
+// speaker itself when it is stale.
+func (c *Client) editQueue(ctx context.Context, updateID int, edit func(updateID int) error) (QueuePage, error) {
+	page, err := c.ListQueue(ctx, 0, 1)
+	if err != nil {
+		return QueuePage{}, err
+	}
+	if page.UpdateID != updateID {
+		return QueuePage{}, ErrQueueChanged
+	}
+	if err := edit(updateID); err != nil {
+		return QueuePage{}, err
+	}
+	return c.ListQueue(ctx, 0, 1)
+}
+
+// MoveQueueTrack moves the track at 1-based position from so that it ends up
+// at position to. It returns the new UpdateID.
+func (c *Client) MoveQueueTrack(ctx context.Context, updateID, from, to int) (int, error) {
+	if from < 1 || to < 1 {
+		return 0, errors.New("positions must be >= 1")
+	}
+	if from == to {
+		return updateID, nil
+	}
+	insertBefore := to
+	if from < to {
+		insertBefore = to + 1
+	}
+	page, err := c.editQueue(ctx, updateID, func(id int) error {
+		_, err := c.ReorderTracksInQueue(ctx, from, 1, insertBefore, id)
+		return err
+	})
+	return page.UpdateID, err
+}
+
+// RemoveQueueRanges removes the given ranges (in any order, overlaps allowed)
+// and returns the new UpdateID.
+func (c *Client) RemoveQueueRanges(ctx context.Context, updateID int, ranges []QueueRange) (int, error) {
+	for _, r := range mergeQueueRanges(ranges) {
+		page, err := c.editQueue(ctx, updateID, func(id int) error {
+			_, err := c.RemoveTrackRangeFromQueue(ctx, id, r.Start, r.Count)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		updateID = page.UpdateID
+	}
+	return updateID, nil
+}
+
+// mergeQueueRanges merges overlapping/adjacent ranges and orders them from the
+// end of the queue, so removing one does not shift the next.
+func mergeQueueRanges(ranges []QueueRange) []QueueRange {
+	sorted := append([]QueueRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var merged []QueueRange
+	for _, r := range sorted {
+		if r.Count <= 0 {
+			continue
+		}
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].Start+merged[n-1].Count {
+			if end := r.Start + r.Count; end > merged[n-1].Start+merged[n-1].Count {
+				merged[n-1].Count = end - merged[n-1].Start
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	for i, j := 0, len(merged)-1; i < j; i, j = i+1, j-1 {
+		merged[i], merged[j] = merged[j], merged[i]
+	}
+	return merged
+}
+
+// QueueInsertResult describes where InsertRefs placed its tracks.
+type QueueInsertResult struct {
+	FirstTrackNumber int `json:"firstTrackNumber"`
+	NumTracksAdded   int `json:"numTracksAdded"`
+	UpdateID         int `json:"updateID"`
+}
+
+// InsertRefs enqueues refs in order so the first lands at 1-based position
+// at (0 appends). Plain URIs are sent in batches through
+// AddMultipleURIsToQueue; Spotify/Apple Music refs and playlist IDs (SQ:N)
+// are enqueued one by one through AddURIToQueue.
+func (c *Client) InsertRefs(ctx context.Context, updateID, at int, refs []string) (QueueInsertResult, error) {
+	res := QueueInsertResult{UpdateID: updateID}
+	length := -1
+	apply := func(edit func(id, desired int) error) error {
+		if length < 0 {
+			page, err := c.ListQueue(ctx, 0, 1)
+			if err != nil {
+				return err
+			}
+			length = page.TotalMatches
+		}
+		desired := 0
+		if at > 0 {
+			desired = at + res.NumTracksAdded
+		}
+		page, err := c.editQueue(ctx, res.UpdateID, func(id int) error { return edit(id, desired) })
+		if err != nil {
+			return err
+		}
+		added := page.TotalMatches - length
+		if res.NumTracksAdded == 0 && added > 0 {
+			res.FirstTrackNumber = desired
+			if desired == 0 || desired > length {
+				res.FirstTrackNumber = length + 1
+			}
+		}
+		res.NumTracksAdded += added
+		res.UpdateID = page.UpdateID
+		length = page.TotalMatches
+		return nil
+	}
+
+	var batch []string
+	flush := func() error {
+		for len(batch) > 0 {
+			n := min(len(batch), maxURIsPerAdd)
+			uris := batch[:n]
+			if err := apply(func(id, desired int) error {
+				_, err := c.AddMultipleURIsToQueue(ctx, id, uris, nil, desired, false)
+				return err
+			}); err != nil {
+				return err
+			}
+			batch = batch[n:]
+		}
+		return nil
+	}
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		var edit func(id, desired int) error
+		switch {
+		case IsPlaylistID(ref):
+			edit = func(_, desired int) error {
+				_, err := c.AddURIToQueue(ctx, PlaylistURI(ref), PlaylistMeta(ref, ""), desired, false)
+				return err
+			}
+		case isSpotifyRef(ref):
+			edit = func(_, desired int) error {
+				_, err := c.EnqueueSpotify(ctx, ref, EnqueueOptions{Position: desired})
+				return err
+			}
+		case isAppleMusicRef(ref):
+			edit = func(_, desired int) error {
+				_, err := c.EnqueueAppleMusic(ctx, ref, EnqueueOptions{Position: desired})
+				return err
+			}
+		default:
+			batch = append(batch, ref)
+			continue
+		}
+		if err := flush(); err != nil {
+			return QueueInsertResult{}, err
+		}
+		if err := apply(edit); err != nil {
+			return QueueInsertResult{}, err
+		}
+	}
+	if err := flush(); err != nil {
+		return QueueInsertResult{}, err
+	}
+	return res, nil
+}
+
+func isSpotifyRef(ref string) bool {
+	_, ok := ParseSpotifyRef(ref)
+	return ok
+}
+
+func isAppleMusicRef(ref string) bool {
+	_, ok := ParseAppleMusicRef(ref)
+	return ok
+}
+
+// ReorderQueue rearranges the queue so that it holds the current tracks in
+// order, where order lists current 1-based positions (a permutation of
+// 1..len). Tracks are moved one at a time; it returns the new UpdateID.
+//
+// The queue is checked against updateID once; each move then passes on the
+// UpdateID the previous one returned (unchecked when the speaker reports
+// none), so a long reorder costs one call per moved track.
+func (c *Client) ReorderQueue(ctx context.Context, updateID int, order []int) (int, error) {
+	seen := make([]bool, len(order)+1)
+	for _, p := range order {
+		if p < 1 || p > len(order) || seen[p] {
+			return 0, errors.New("order must be a permutation of the queue positions")
+		}
+		seen[p] = true
+	}
+	page, err := c.ListQueue(ctx, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	if page.UpdateID != updateID {
+		return 0, ErrQueueChanged
+	}
+	moved := false
+	// current[i] is the original position of the track now at i+1.
+	current := make([]int, len(order))
+	for i := range current {
+		current[i] = i + 1
+	}
+	for i, want := range order {
+		j := i
+		for current[j] != want {
+			j++
+		}
+		if j == i {
+			continue
+		}
+		// Moving from j+1 (after i+1) to i+1: insert before position i+1.
+		if updateID, err = c.ReorderTracksInQueue(ctx, j+1, 1, i+1, updateID); err != nil {
+			return 0, err
+		}
+		moved = true
+		copy(current[i+1:j+1], current[i:j])
+		current[i] = want
+	}
+	if !moved || updateID != 0 {
+		return updateID, nil
+	}
+	if page, err = c.ListQueue(ctx, 0, 1); err != nil {
+		return 0, err
+	}
+	return page.UpdateID, nil
+}
+
+// DuplicateQueueRanges returns the ranges holding tracks whose URI already
+// appeared earlier in items.
+func DuplicateQueueRanges(items []QueueItem) []QueueRange {
+	seen := map[string]bool{}
+	var out []QueueRange
+	for _, it := range items {
+		key := it.Item.URI
+		if key == "" {
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Start+out[n-1].Count == it.Position {
+			out[n-1].Count++
+			continue
+		}
+		out = append(out, QueueRange{Start: it.Position, Count: 1})
+	}
+	return out
+}
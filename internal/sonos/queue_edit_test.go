@@ -0,0 +1,201 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func queueTitles(t *testing.T, s *sonostest.Speaker) string {
+	t.Helper()
+	q := s.State().Queue
+	titles := make([]string, 0, len(q))
+	for _, tr := range q {
+		titles = append(titles, tr.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func newQueueEditSpeaker(t *testing.T, titles ...string) (*sonostest.Speaker, *Client) {
+	t.Helper()
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	tracks := make([]sonostest.Track, 0, len(titles))
+	for _, title := range titles {
+		tracks = append(tracks, sonostest.Track{URI: "http://example.com/" + title + ".mp3", Title: title})
+	}
+	kitchen.SetQueue(tracks...)
+	return kitchen, NewClient(kitchen.IP, 2*time.Second)
+}
+
+func currentUpdateID(t *testing.T, c *Client) int {
+	t.Helper()
+	page, err := c.ListQueue(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("ListQueue: %v", err)
+	}
+	return page.UpdateID
+}
+
+func TestParseQueueRanges(t *testing.T) {
+	got, err := ParseQueueRanges("3-7, 10,,12-12")
+	if err != nil {
+		t.Fatalf("ParseQueueRanges: %v", err)
+	}
+	want := []QueueRange{{Start: 3, Count: 5}, {Start: 10, Count: 1}, {Start: 12, Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseQueueRanges = %+v", got)
+	}
+	for _, bad := range []string{"", "0", "7-3", "x", "2-", "-2"} {
+		if _, err := ParseQueueRanges(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestMergeQueueRanges(t *testing.T) {
+	got := mergeQueueRanges([]QueueRange{{Start: 10, Count: 1}, {Start: 3, Count: 5}, {Start: 5, Count: 4}, {Start: 8, Count: 1}})
+	want := []QueueRange{{Start: 10, Count: 1}, {Start: 3, Count: 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeQueueRanges = %+v", got)
+	}
+}
+
+func TestMoveQueueTrack(t *testing.T) {
+	kitchen, c := newQueueEditSpeaker(t, "A", "B", "C", "D")
+	ctx := context.Background()
+
+	id, err := c.MoveQueueTrack(ctx, currentUpdateID(t, c), 1, 3)
+	if err != nil {
+		t.Fatalf("MoveQueueTrack: %v", err)
+	}
+	if got := queueTitles(t, kitchen); got != "B,C,A,D" {
+		t.Fatalf("after move 1->3: %s", got)
+	}
+	if _, err := c.MoveQueueTrack(ctx, id, 4, 1); err != nil {
+		t.Fatalf("MoveQueueTrack: %v", err)
+	}
+	if got := queueTitles(t, kitchen); got != "D,B,C,A" {
+		t.Fatalf("after move 4->1: %s", got)
+	}
+
+	// The first UpdateID is stale now.
+	if _, err := c.MoveQueueTrack(ctx, id, 1, 2); !errors.Is(err, ErrQueueChanged) {
+		t.Fatalf("expected ErrQueueChanged, got %v", err)
+	}
+	// The speaker rejects stale UpdateIDs itself as well.
+	if _, err := c.ReorderTracksInQueue(ctx, 1, 1, 3, id); err == nil {
+		t.Fatalf("expected stale UpdateID error from speaker")
+	}
+}
+
+func TestRemoveQueueRanges(t *testing.T) {
+	kitchen, c := newQueueEditSpeaker(t, "1", "2", "3", "4", "5", "6", "7", "8", "9", "10")
+	ctx := context.Background()
+
+	ranges, _ := ParseQueueRanges("3-7,10")
+	id, err := c.RemoveQueueRanges(ctx, currentUpdateID(t, c), ranges)
+	if err != nil {
+		t.Fatalf("RemoveQueueRanges: %v", err)
+	}
+	if got := queueTitles(t, kitchen); got != "1,2,8,9" {
+		t.Fatalf("after remove: %s", got)
+	}
+	if id != currentUpdateID(t, c) {
+		t.Fatalf("returned UpdateID %d is not current", id)
+	}
+	if _, err := c.RemoveQueueRanges(ctx, id-1, []QueueRange{{Start: 1, Count: 1}}); !errors.Is(err, ErrQueueChanged) {
+		t.Fatalf("expected ErrQueueChanged, got %v", err)
+	}
+}
+
+func TestInsertRefs(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	h.SetPlaylists(sonostest.Playlist{Title: "Mix", Tracks: []sonostest.Track{
+		{URI: "http://example.com/p1.mp3", Title: "P1"},
+		{URI: "http://example.com/p2.mp3", Title: "P2"},
+	}})
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/a.mp3", Title: "A"},
+		sonostest.Track{URI: "http://example.com/b.mp3", Title: "B"},
+	)
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	refs := []string{"http://example.com/x.mp3", "SQ:1", "http://example.com/y.mp3"}
+	res, err := c.InsertRefs(ctx, currentUpdateID(t, c), 2, refs)
+	if err != nil {
+		t.Fatalf("InsertRefs: %v", err)
+	}
+	if res.FirstTrackNumber != 2 || res.NumTracksAdded != 4 || res.UpdateID != currentUpdateID(t, c) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := queueTitles(t, kitchen); got != "A,http://example.com/x.mp3,P1,P2,http://example.com/y.mp3,B" {
+		t.Fatalf("after insert: %s", got)
+	}
+
+	many := make([]string, 20)
+	for i := range many {
+		many[i] = "http://example.com/many.mp3"
+	}
+	res, err = c.InsertRefs(ctx, res.UpdateID, 0, many)
+	if err != nil || res.FirstTrackNumber != 7 || res.NumTracksAdded != 20 {
+		t.Fatalf("InsertRefs(append) = %+v, %v", res, err)
+	}
+	if _, err := c.InsertRefs(ctx, res.UpdateID-1, 0, refs); !errors.Is(err, ErrQueueChanged) {
+		t.Fatalf("expected ErrQueueChanged, got %v", err)
+	}
+}
+
+func TestReorderQueueAndDuplicates(t *testing.T) {
+	kitchen, c := newQueueEditSpeaker(t, "A", "B", "C", "D", "E")
+	ctx := context.Background()
+
+	id, err := c.ReorderQueue(ctx, currentUpdateID(t, c), []int{5, 3, 1, 4, 2})
+	if err != nil {
+		t.Fatalf("ReorderQueue: %v", err)
+	}
+	if got := queueTitles(t, kitchen); got != "E,C,A,D,B" {
+		t.Fatalf("after reorder: %s", got)
+	}
+	if want := currentUpdateID(t, c); id != want {
+		t.Fatalf("ReorderQueue returned UpdateID %d, queue is at %d", id, want)
+	}
+	if _, err := c.ReorderQueue(ctx, id-1, []int{2, 1, 3, 4, 5}); !errors.Is(err, ErrQueueChanged) {
+		t.Fatalf("expected ErrQueueChanged, got %v", err)
+	}
+	if _, err := c.ReorderQueue(ctx, currentUpdateID(t, c), []int{1, 1, 2, 3, 4}); err == nil {
+		t.Fatalf("expected permutation error")
+	}
+
+	items := []QueueItem{
+		{Position: 1, Item: DIDLItem{URI: "a"}},
+		{Position: 2, Item: DIDLItem{URI: "b"}},
+		{Position: 3, Item: DIDLItem{URI: "a"}},
+		{Position: 4, Item: DIDLItem{URI: "b"}},
+		{Position: 5, Item: DIDLItem{URI: "c"}},
+		{Position: 6, Item: DIDLItem{URI: "c"}},
+	}
+	want := []QueueRange{{Start: 3, Count: 2}, {Start: 6, Count: 1}}
+	if got := DuplicateQueueRanges(items); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DuplicateQueueRanges = %+v", got)
+	}
+}
+
+func TestListAllQueue(t *testing.T) {
+	titles := make([]string, 130)
+	for i := range titles {
+		titles[i] = "T" + strings.Repeat("x", i%3)
+	}
+	_, c := newQueueEditSpeaker(t, titles...)
+	page, err := c.ListAllQueue(context.Background())
+	if err != nil || len(page.Items) != 130 || page.TotalMatches != 130 {
+		t.Fatalf("ListAllQueue = %d items, %v", len(page.Items), err)
+	}
+}
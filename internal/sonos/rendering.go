@@ -2,7 +2,10 @@ package sonos
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func (c *Client) GetVolume(ctx context.Context) (int, error) {
@@ -24,7 +27,11 @@ func (c *Client) SetVolume(ctx context.Context, volume int) error {
 	if volume > 100 {
 		volume = 100
 	}
-	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetVolume", map[string]string{
+	volume, err := c.CapVolume(ctx, volume)
+	if err != nil {
+		return err
+	}
+	_, err = c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetVolume", map[string]string{
 		"InstanceID":    "0",
 		"Channel":       "Master",
 		"DesiredVolume": strconv.Itoa(volume),
@@ -32,6 +39,84 @@ func (c *Client) SetVolume(ctx context.Context, volume int) error {
 	return err
 }
 
+// SetRelativeVolume changes the volume by adjustment (may be negative) and
+// returns the volume the speaker settled on. With a volume limit in effect
+// the step is shortened so the room never goes above it.
+func (c *Client) SetRelativeVolume(ctx context.Context, adjustment int) (int, error) {
+	limit, ok, err := c.VolumeCap(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		current, err := c.GetVolume(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if current+adjustment > limit {
+			adjustment = limit - current
+		}
+	}
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetRelativeVolume", map[string]string{
+		"InstanceID": "0",
+		"Channel":    "Master",
+		"Adjustment": strconv.Itoa(adjustment),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp["NewVolume"])
+}
+
+// RampType selects the speaker-side volume ramp used by RampToVolume.
+type RampType string
+
+const (
+	RampSleepTimer RampType = "SLEEP_TIMER_RAMP_TYPE" // fast-ish linear ramp
+	RampAlarm      RampType = "ALARM_RAMP_TYPE"       // starts at 0, slow rise
+	RampAutoplay   RampType = "AUTOPLAY_RAMP_TYPE"    // short ramp from the current volume
+)
+
+// ParseRampType accepts sleep, alarm or auto (or the raw Sonos names).
+func ParseRampType(s string) (RampType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "sleep", "sleep-timer", "sleep_timer_ramp_type":
+		return RampSleepTimer, nil
+	case "alarm", "alarm_ramp_type":
+		return RampAlarm, nil
+	case "auto", "autoplay", "autoplay_ramp_type":
+		return RampAutoplay, nil
+	}
+	return "", fmt.Errorf("invalid ramp type %q (expected sleep, alarm or auto)", s)
+}
+
+// RampToVolume asks the speaker to ramp to volume itself and returns how long
+// the ramp will take.
+func (c *Client) RampToVolume(ctx context.Context, rampType RampType, volume int) (time.Duration, error) {
+	if volume < 0 {
+		volume = 0
+	}
+	if volume > 100 {
+		volume = 100
+	}
+	volume, err := c.CapVolume(ctx, volume)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "RampToVolume", map[string]string{
+		"InstanceID":       "0",
+		"Channel":          "Master",
+		"RampType":         string(rampType),
+		"DesiredVolume":    strconv.Itoa(volume),
+		"ResetVolumeAfter": "0",
+		"ProgramURI":       "",
+	})
+	if err != nil {
+		return 0, err
+	}
+	secs, _ := strconv.Atoi(resp["RampTime"])
+	return time.Duration(secs) * time.Second, nil
+}
+
 func (c *Client) GetMute(ctx context.Context) (bool, error) {
 	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetMute", map[string]string{
 		"InstanceID": "0",
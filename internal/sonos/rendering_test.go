@@ -58,3 +58,40 @@ func TestRenderingVolumeAndMute(t *testing.T) {
 		t.Fatalf("SetMute: %v", err)
 	}
 }
+
+func TestRenderingRelativeVolumeAndRamp(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetVolume(20)
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	v, err := c.SetRelativeVolume(ctx, 7)
+	if err != nil || v != 27 {
+		t.Fatalf("SetRelativeVolume(+7) = %d, %v", v, err)
+	}
+	if v, err = c.SetRelativeVolume(ctx, -50); err != nil || v != 0 {
+		t.Fatalf("SetRelativeVolume(-50) = %d, %v", v, err)
+	}
+
+	d, err := c.RampToVolume(ctx, RampAlarm, 15)
+	if err != nil || d != 15*time.Second {
+		t.Fatalf("RampToVolume = %s, %v", d, err)
+	}
+	if st := kitchen.State(); st.Volume != 15 || st.LastRampType != "ALARM_RAMP_TYPE" {
+		t.Fatalf("unexpected state after ramp: volume=%d type=%q", st.Volume, st.LastRampType)
+	}
+}
+
+func TestParseRampType(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]RampType{"sleep": RampSleepTimer, "Alarm": RampAlarm, "auto": RampAutoplay, "AUTOPLAY_RAMP_TYPE": RampAutoplay} {
+		if got, err := ParseRampType(in); err != nil || got != want {
+			t.Fatalf("ParseRampType(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseRampType("slow"); err == nil {
+		t.Fatalf("expected error for unknown ramp type")
+	}
+}
@@ -0,0 +1,169 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSeekNotSupported is returned when the current source cannot be scrubbed
+// (radio streams, line-in/TV, some cloud services answer UPnP 701).
+var ErrSeekNotSupported = errors.New("the current source does not support seeking")
+
+// ErrNoTrackDuration is returned for relative or percentage seeks when the
+// speaker does not report a duration for the current track.
+var ErrNoTrackDuration = errors.New("the current track has no known duration (live stream?)")
+
+// SeekKind says how a SeekTarget is resolved.
+type SeekKind int
+
+const (
+	SeekAbsolute SeekKind = iota // Offset from the start of the track
+	SeekRelative                 // Offset (may be negative) from the current position
+	SeekPercent                  // Percent of the track duration
+)
+
+// SeekTarget is a parsed seek position such as "1:23", "+30s", "-10s" or "50%".
+type SeekTarget struct {
+	Kind    SeekKind
+	Offset  time.Duration
+	Percent float64
+}
+
+// ParseSeekTarget accepts an absolute time (1:23, 0:01:23, 83, 83s, 1m23s),
+// a relative one prefixed with + or - (+30s, -1:00) or a percentage (50%).
+func ParseSeekTarget(s string) (SeekTarget, error) {
+	s = strings.TrimSpace(s)
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v < 0 || v > 100 {
+			return SeekTarget{}, fmt.Errorf("invalid seek percentage %q (expected 0-100%%)", s)
+		}
+		return SeekTarget{Kind: SeekPercent, Percent: v}, nil
+	}
+	kind, sign := SeekAbsolute, time.Duration(1)
+	if rest, ok := strings.CutPrefix(s, "+"); ok {
+		kind, s = SeekRelative, rest
+	} else if rest, ok := strings.CutPrefix(s, "-"); ok {
+		kind, s, sign = SeekRelative, rest, -1
+	}
+	d, err := parseSeekDuration(s)
+	if err != nil {
+		return SeekTarget{}, err
+	}
+	return SeekTarget{Kind: kind, Offset: sign * d}, nil
+}
+
+func parseSeekDuration(s string) (time.Duration, error) {
+	if strings.Contains(s, ":") {
+		if d, ok := ParseHMS(s); ok {
+			return d, nil
+		}
+	} else if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, nil
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid seek target %q (e.g. 1:23, +30s, -10s, 50%%)", s)
+}
+
+// ParseHMS parses the H:MM:SS (or M:SS) times used by AVTransport.
+func ParseHMS(s string) (time.Duration, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	total := 0
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return time.Duration(total) * time.Second, true
+}
+
+// FormatHMS renders d as H:MM:SS, the format Seek REL_TIME expects.
+func FormatHMS(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs < 0 {
+		secs = 0
+	}
+	return fmt.Sprintf("%d:%02d:%02d", secs/3600, (secs/60)%60, secs%60)
+}
+
+// Seek moves within the current track. Relative and percentage targets are
+// resolved against GetPositionInfo; the result is clamped to the track. It
+// returns the position sought to.
+func (c *Client) Seek(ctx context.Context, target SeekTarget) (time.Duration, error) {
+	pos := target.Offset
+	if target.Kind != SeekAbsolute {
+		info, err := c.GetPositionInfo(ctx)
+		if err != nil {
+			return 0, err
+		}
+		dur, ok := ParseHMS(info.TrackDuration)
+		if !ok || dur <= 0 {
+			return 0, ErrNoTrackDuration
+		}
+		switch target.Kind {
+		case SeekPercent:
+			pos = time.Duration(math.Round(float64(dur/time.Second)*target.Percent/100)) * time.Second
+		case SeekRelative:
+			cur, _ := ParseHMS(info.RelTime)
+			pos = cur + target.Offset
+		}
+		pos = min(max(pos, 0), dur)
+	}
+	if err := c.SeekRelTime(ctx, FormatHMS(pos)); err != nil {
+		return 0, seekError(err, pos)
+	}
+	return pos, nil
+}
+
+// seekError turns the UPnP faults speakers use for unseekable sources (701)
+// and out-of-range targets (711) into readable errors.
+func seekError(err error, pos time.Duration) error {
+	var upnpErr *UPnPError
+	if !errors.As(err, &upnpErr) {
+		return err
+	}
+	switch upnpErr.Code {
+	case "701":
+		return ErrSeekNotSupported
+	case "711":
+		return fmt.Errorf("cannot seek to %s: outside the current track", FormatHMS(pos))
+	}
+	return err
+}
+
+// SkipToTrack jumps to the first queue entry whose title contains query
+// (case-insensitive) with SeekTrackNumber. If the group is not playing from
+// its queue, the queue is selected and started at that entry.
+func (c *Client) SkipToTrack(ctx context.Context, query string) (QueueItem, error) {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return QueueItem{}, errors.New("track title is required")
+	}
+	q, err := c.ListAllQueue(ctx)
+	if err != nil {
+		return QueueItem{}, err
+	}
+	for _, it := range q.Items {
+		if !strings.Contains(strings.ToLower(it.Item.Title), needle) {
+			continue
+		}
+		err := c.SeekTrackNumber(ctx, it.Position)
+		var upnpErr *UPnPError
+		if errors.As(err, &upnpErr) && upnpErr.Code == "701" {
+			err = c.playFromQueueTrack(ctx, it.Position)
+		}
+		return it, err
+	}
+	return QueueItem{}, fmt.Errorf("no queue track matches %q", query)
+}
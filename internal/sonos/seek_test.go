@@ -0,0 +1,114 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func TestParseSeekTarget(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]SeekTarget{
+		"1:23":    {Kind: SeekAbsolute, Offset: 83 * time.Second},
+		"0:01:23": {Kind: SeekAbsolute, Offset: 83 * time.Second},
+		"90":      {Kind: SeekAbsolute, Offset: 90 * time.Second},
+		"1m5s":    {Kind: SeekAbsolute, Offset: 65 * time.Second},
+		"+30s":    {Kind: SeekRelative, Offset: 30 * time.Second},
+		"-10s":    {Kind: SeekRelative, Offset: -10 * time.Second},
+		"-1:00":   {Kind: SeekRelative, Offset: -time.Minute},
+		"50%":     {Kind: SeekPercent, Percent: 50},
+	} {
+		got, err := ParseSeekTarget(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseSeekTarget(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "1:75", "150%", "+", "--5s"} {
+		if _, err := ParseSeekTarget(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+	if FormatHMS(3723*time.Second) != "1:02:03" {
+		t.Fatalf("FormatHMS = %q", FormatHMS(3723*time.Second))
+	}
+}
+
+func TestSeekResolvesAgainstPosition(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "Intro", Duration: "0:04:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Main Theme", Duration: "0:03:00"},
+	)
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		target string
+		want   string
+	}{
+		{"1:00", "0:01:00"},
+		{"+30s", "0:01:30"},
+		{"-2m", "0:00:00"},
+		{"50%", "0:02:00"},
+		{"+10m", "0:04:00"},
+	} {
+		target, err := ParseSeekTarget(tc.target)
+		if err != nil {
+			t.Fatalf("ParseSeekTarget(%s): %v", tc.target, err)
+		}
+		pos, err := c.Seek(ctx, target)
+		if err != nil {
+			t.Fatalf("Seek(%s): %v", tc.target, err)
+		}
+		if FormatHMS(pos) != tc.want || kitchen.State().RelTime != tc.want {
+			t.Fatalf("Seek(%s) = %s, speaker at %s; want %s", tc.target, FormatHMS(pos), kitchen.State().RelTime, tc.want)
+		}
+	}
+
+	kitchen.SetFault("Seek", "701")
+	_, err := c.Seek(ctx, SeekTarget{Kind: SeekAbsolute, Offset: time.Second})
+	if !errors.Is(err, ErrSeekNotSupported) {
+		t.Fatalf("expected ErrSeekNotSupported, got %v", err)
+	}
+	kitchen.SetFault("Seek", "711")
+	if _, err := c.Seek(ctx, SeekTarget{Kind: SeekAbsolute, Offset: time.Hour}); err == nil || !strings.Contains(err.Error(), "outside the current track") {
+		t.Fatalf("expected out-of-track error, got %v", err)
+	}
+}
+
+func TestSkipToTrack(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	kitchen := h.Speaker("Kitchen")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/1.mp3", Title: "Intro", Duration: "0:04:00"},
+		sonostest.Track{URI: "http://example.com/2.mp3", Title: "Main Theme", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/3.mp3", Title: "Theme Reprise", Duration: "0:02:00"},
+	)
+	c := NewClient(kitchen.IP, 2*time.Second)
+	ctx := context.Background()
+
+	it, err := c.SkipToTrack(ctx, "theme")
+	if err != nil || it.Position != 2 || kitchen.State().Track != 2 {
+		t.Fatalf("SkipToTrack(theme) = %+v, %v (track %d)", it, err, kitchen.State().Track)
+	}
+	if _, err := c.SkipToTrack(ctx, "outro"); err == nil || !strings.Contains(err.Error(), "no queue track matches") {
+		t.Fatalf("expected no-match error, got %v", err)
+	}
+
+	// Not playing from the queue: the queue is selected and started.
+	if err := c.SetAVTransportURI(ctx, "x-rincon-mp3radio://example.com/live", ""); err != nil {
+		t.Fatalf("SetAVTransportURI: %v", err)
+	}
+	if _, err := c.SkipToTrack(ctx, "reprise"); err != nil {
+		t.Fatalf("SkipToTrack from stream: %v", err)
+	}
+	if st := kitchen.State(); st.Track != 3 || !strings.HasPrefix(st.AVTransportURI, "x-rincon-queue:") || st.TransportState != "PLAYING" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
@@ -9,8 +9,15 @@ const (
 	controlMusicServices     = "/MusicServices/Control"
 	controlDeviceProperties  = "/DeviceProperties/Control"
 	controlSystemProperties  = "/SystemProperties/Control"
+	controlAlarmClock        = "/AlarmClock/Control"
 	eventAVTransport         = "/MediaRenderer/AVTransport/Event"
 	eventRenderingControl    = "/MediaRenderer/RenderingControl/Event"
+	eventGroupRendering      = "/MediaRenderer/GroupRenderingControl/Event"
+	eventQueue               = "/MediaRenderer/Queue/Event"
+	eventContentDirectory    = "/MediaServer/ContentDirectory/Event"
+	eventZoneGroupTopology   = "/ZoneGroupTopology/Event"
+	eventAlarmClock          = "/AlarmClock/Event"
+	eventDeviceProperties    = "/DeviceProperties/Event"
 	urnAVTransport           = "urn:schemas-upnp-org:service:AVTransport:1"
 	urnRenderingControl      = "urn:schemas-upnp-org:service:RenderingControl:1"
 	urnGroupRenderingControl = "urn:schemas-upnp-org:service:GroupRenderingControl:1"
@@ -19,4 +26,5 @@ const (
 	urnMusicServices         = "urn:schemas-upnp-org:service:MusicServices:1"
 	urnDeviceProperties      = "urn:schemas-upnp-org:service:DeviceProperties:1"
 	urnSystemProperties      = "urn:schemas-upnp-org:service:SystemProperties:1"
+	urnAlarmClock            = "urn:schemas-upnp-org:service:AlarmClock:1"
 )
@@ -0,0 +1,64 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxSleepTimer is the longest sleep timer a speaker accepts.
+const MaxSleepTimer = 24*time.Hour - time.Second
+
+// ConfigureSleepTimer stops playback after d. A zero duration cancels the timer.
+func (c *Client) ConfigureSleepTimer(ctx context.Context, d time.Duration) error {
+	if d < 0 || d > MaxSleepTimer {
+		return fmt.Errorf("sleep timer must be between 0 and %s", MaxSleepTimer)
+	}
+	value := ""
+	if d > 0 {
+		value = formatHHMMSS(d)
+	}
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "ConfigureSleepTimer", map[string]string{
+		"InstanceID":            "0",
+		"NewSleepTimerDuration": value,
+	})
+	return err
+}
+
+// GetRemainingSleepTimerDuration returns the time left on the sleep timer.
+// active is false when no timer is set.
+func (c *Client) GetRemainingSleepTimerDuration(ctx context.Context) (remaining time.Duration, active bool, err error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetRemainingSleepTimerDuration", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	v := strings.TrimSpace(resp["RemainingSleepTimerDuration"])
+	if v == "" {
+		return 0, false, nil
+	}
+	d, err := parseHHMMSS(v)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid RemainingSleepTimerDuration: %q", v)
+	}
+	return d, true, nil
+}
+
+func formatHHMMSS(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+func parseHHMMSS(s string) (time.Duration, error) {
+	var h, m, sec int
+	if _, err := fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec); err != nil {
+		return 0, err
+	}
+	if h < 0 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, errors.New("out of range")
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
@@ -0,0 +1,78 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigureSleepTimer(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if !strings.Contains(r.Header.Get("SOAPACTION"), "AVTransport:1#ConfigureSleepTimer") {
+			t.Fatalf("SOAPACTION: %q", r.Header.Get("SOAPACTION"))
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	if err := c.ConfigureSleepTimer(context.Background(), 90*time.Minute+5*time.Second); err != nil {
+		t.Fatalf("ConfigureSleepTimer: %v", err)
+	}
+	if !strings.Contains(bodies[0], "<NewSleepTimerDuration>01:30:05</NewSleepTimerDuration>") {
+		t.Fatalf("unexpected body: %s", bodies[0])
+	}
+	if err := c.ConfigureSleepTimer(context.Background(), 0); err != nil {
+		t.Fatalf("ConfigureSleepTimer(0): %v", err)
+	}
+	if !strings.Contains(bodies[1], "<NewSleepTimerDuration></NewSleepTimerDuration>") {
+		t.Fatalf("unexpected cancel body: %s", bodies[1])
+	}
+	if err := c.ConfigureSleepTimer(context.Background(), 25*time.Hour); err == nil {
+		t.Fatalf("expected range error")
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("unexpected calls: %d", len(bodies))
+	}
+}
+
+func TestGetRemainingSleepTimerDuration(t *testing.T) {
+	t.Parallel()
+
+	value := "0:29:58"
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if !strings.Contains(r.Header.Get("SOAPACTION"), "AVTransport:1#GetRemainingSleepTimerDuration") {
+			t.Fatalf("SOAPACTION: %q", r.Header.Get("SOAPACTION"))
+		}
+		return httpResponse(200, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>
+<u:GetRemainingSleepTimerDurationResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1">
+<RemainingSleepTimerDuration>`+value+`</RemainingSleepTimerDuration>
+<CurrentSleepTimerGeneration>3</CurrentSleepTimerGeneration>
+</u:GetRemainingSleepTimerDurationResponse></s:Body></s:Envelope>`), nil
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	d, active, err := c.GetRemainingSleepTimerDuration(context.Background())
+	if err != nil || !active || d != 29*time.Minute+58*time.Second {
+		t.Fatalf("got %s %v %v", d, active, err)
+	}
+
+	value = ""
+	d, active, err = c.GetRemainingSleepTimerDuration(context.Background())
+	if err != nil || active || d != 0 {
+		t.Fatalf("inactive: got %s %v %v", d, active, err)
+	}
+
+	value = "soon"
+	if _, _, err := c.GetRemainingSleepTimerDuration(context.Background()); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
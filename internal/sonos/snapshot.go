@@ -0,0 +1,403 @@
+package sonos
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot source kinds. They decide how Restore reinstates playback.
+const (
+	SourceNone   = "none"
+	SourceQueue  = "queue"
+	SourceStream = "stream"
+	SourceLineIn = "linein"
+	SourceTV     = "tv"
+	SourceTrack  = "track"
+)
+
+// SnapshotQueueItem is one queue entry with its original DIDL-Lite metadata.
+type SnapshotQueueItem struct {
+	URI  string `json:"uri"`
+	Meta string `json:"meta,omitempty"`
+}
+
+// SnapshotMember is the per-room volume/mute recorded with a snapshot.
+type SnapshotMember struct {
+	UUID   string `json:"uuid"`
+	Name   string `json:"name,omitempty"`
+	IP     string `json:"ip"`
+	Volume int    `json:"volume"`
+	Mute   bool   `json:"mute"`
+}
+
+// Snapshot records everything needed to resume a group's playback after an
+// interruption (an announcement, a doorbell, another source).
+type Snapshot struct {
+	CreatedAt       time.Time           `json:"createdAt"`
+	CoordinatorUUID string              `json:"coordinatorUUID"`
+	CoordinatorIP   string              `json:"coordinatorIP"`
+	Source          string              `json:"source"`
+	TransportURI    string              `json:"transportURI"`
+	TransportMeta   string              `json:"transportMeta,omitempty"`
+	TransportState  string              `json:"transportState"`
+	PlayMode        PlayMode            `json:"playMode,omitempty"`
+	Track           int                 `json:"track,omitempty"`
+	RelTime         string              `json:"relTime,omitempty"`
+	QueueUpdateID   int                 `json:"queueUpdateID,omitempty"`
+	Queue           []SnapshotQueueItem `json:"queue,omitempty"`
+	Members         []SnapshotMember    `json:"members"`
+
+	httpClient  *http.Client
+	port        int
+	volumeLimit VolumeLimit
+}
+
+// TakeSnapshot records the playback state of the group coordinated by c.
+// c must address the group coordinator.
+func TakeSnapshot(ctx context.Context, c *Client) (*Snapshot, error) {
+	top, err := c.GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	group, ok := top.GroupForIP(c.IP)
+	if !ok {
+		return nil, errors.New("speaker not found in topology: " + c.IP)
+	}
+	if group.Coordinator.IP != c.IP {
+		return nil, errors.New("snapshot must be taken on the group coordinator (" + group.Coordinator.Name + ")")
+	}
+
+	media, err := c.GetMediaInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := c.GetPositionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.GetTransportInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		CreatedAt:       time.Now().UTC(),
+		CoordinatorUUID: group.Coordinator.UUID,
+		CoordinatorIP:   c.IP,
+		Source:          SourceKind(media.CurrentURI),
+		TransportURI:    media.CurrentURI,
+		TransportMeta:   media.CurrentURIMetaData,
+		TransportState:  info.State,
+		httpClient:      c.HTTP,
+		port:            c.Port,
+		volumeLimit:     c.VolumeLimit,
+	}
+
+	switch s.Source {
+	case SourceQueue, SourceTrack:
+		settings, err := c.GetTransportSettings(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.PlayMode = settings.PlayMode
+		s.Track, _ = strconv.Atoi(pos.Track)
+		s.RelTime = pos.RelTime
+	}
+	if s.Source == SourceQueue {
+		if err := s.captureQueue(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range group.Members {
+		if !m.IsVisible || m.UUID == "" {
+			continue
+		}
+		mc := s.client(m.IP)
+		vol, err := mc.GetVolume(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mute, err := mc.GetMute(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.Members = append(s.Members, SnapshotMember{UUID: m.UUID, Name: m.Name, IP: m.IP, Volume: vol, Mute: mute})
+	}
+	return s, nil
+}
+
+// SourceKind classifies a transport URI the way Restore treats it.
+func SourceKind(uri string) string {
+	switch {
+	case uri == "":
+		return SourceNone
+	case strings.HasPrefix(uri, "x-rincon-queue:"):
+		return SourceQueue
+	case strings.HasPrefix(uri, "x-rincon-stream:"):
+		return SourceLineIn
+	case strings.HasPrefix(uri, "x-sonos-htastream:"):
+		return SourceTV
+	case strings.HasPrefix(uri, "x-rincon-mp3radio:"),
+		strings.HasPrefix(uri, "x-sonosapi-stream:"),
+		strings.HasPrefix(uri, "x-sonosapi-radio:"),
+		strings.HasPrefix(uri, "x-sonosapi-hls:"),
+		strings.HasPrefix(uri, "x-sonosapi-hls-static:"),
+		strings.HasPrefix(uri, "x-sonos-http:sonos-radio"),
+		strings.HasPrefix(uri, "aac:"),
+		strings.HasPrefix(uri, "hls-radio:"):
+		return SourceStream
+	default:
+		return SourceTrack
+	}
+}
+
+func (s *Snapshot) captureQueue(ctx context.Context, c *Client) error {
+	const pageSize = 100
+	start := 0
+	for {
+		br, err := c.Browse(ctx, "Q:0", start, pageSize)
+		if err != nil {
+			return err
+		}
+		if start == 0 {
+			s.QueueUpdateID = br.UpdateID
+		}
+		items, err := splitDIDLItems(br.Result)
+		if err != nil {
+			return err
+		}
+		s.Queue = append(s.Queue, items...)
+		start += br.NumberReturned
+		if br.NumberReturned == 0 || start >= br.TotalMatches {
+			return nil
+		}
+	}
+}
+
+// UseClient makes Restore talk to speakers with c's HTTP client, port and
+// volume limit (snapshots loaded from disk otherwise fall back to NewClient
+// defaults).
+func (s *Snapshot) UseClient(c *Client) {
+	s.httpClient = c.HTTP
+	s.port = c.Port
+	s.volumeLimit = c.VolumeLimit
+}
+
+// Relocate refreshes member IPs from the current topology (DHCP may have
+// moved speakers since the snapshot was saved).
+func (s *Snapshot) Relocate(top Topology) {
+	if m, ok := top.FindByUUID(s.CoordinatorUUID); ok && m.IP != "" {
+		s.CoordinatorIP = m.IP
+	}
+	for i, m := range s.Members {
+		if cur, ok := top.FindByUUID(m.UUID); ok && cur.IP != "" {
+			s.Members[i].IP = cur.IP
+		}
+	}
+}
+
+// Restore reinstates the snapshot: grouping of the recorded members, the
+// source (queue, stream, line-in/TV or single track), position, play mode and
+// per-member volume/mute, then resumes playback if it was playing.
+func (s *Snapshot) Restore(ctx context.Context) error {
+	if s.CoordinatorIP == "" || s.CoordinatorUUID == "" {
+		return errors.New("snapshot has no coordinator")
+	}
+	c := s.client(s.CoordinatorIP)
+
+	// Re-join members that left the group in the meantime and drop rooms that
+	// joined it since.
+	if top, err := c.GetTopology(ctx); err == nil {
+		group, _ := top.GroupForIP(s.CoordinatorIP)
+		recorded := map[string]bool{}
+		for _, m := range s.Members {
+			recorded[m.UUID] = true
+		}
+		inGroup := map[string]bool{}
+		for _, m := range group.Members {
+			inGroup[m.UUID] = true
+		}
+		if group.Coordinator.UUID != s.CoordinatorUUID {
+			if err := c.LeaveGroup(ctx); err != nil {
+				return err
+			}
+			inGroup = map[string]bool{s.CoordinatorUUID: true}
+		} else {
+			for _, m := range group.Members {
+				if !m.IsVisible || m.UUID == s.CoordinatorUUID || recorded[m.UUID] {
+					continue
+				}
+				if err := s.client(m.IP).LeaveGroup(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		for _, m := range s.Members {
+			if m.UUID == s.CoordinatorUUID || inGroup[m.UUID] {
+				continue
+			}
+			if err := s.client(m.IP).JoinGroup(ctx, s.CoordinatorUUID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.restoreSource(ctx, c); err != nil {
+		return err
+	}
+
+	for _, m := range s.Members {
+		mc := s.client(m.IP)
+		if err := mc.SetVolume(ctx, m.Volume); err != nil {
+			return err
+		}
+		if err := mc.SetMute(ctx, m.Mute); err != nil {
+			return err
+		}
+	}
+
+	if s.Source != SourceNone && (s.TransportState == "PLAYING" || s.TransportState == "TRANSITIONING") {
+		return c.Play(ctx)
+	}
+	return nil
+}
+
+func (s *Snapshot) restoreSource(ctx context.Context, c *Client) error {
+	switch s.Source {
+	case SourceNone:
+		return nil
+
+	case SourceQueue:
+		if err := s.restoreQueue(ctx, c); err != nil {
+			return err
+		}
+		if err := c.SetAVTransportURI(ctx, "x-rincon-queue:"+s.CoordinatorUUID+"#0", ""); err != nil {
+			return err
+		}
+		if s.Track > 0 && len(s.Queue) > 0 {
+			if err := ignoreTransitionErr(c.SeekTrackNumber(ctx, s.Track)); err != nil {
+				return err
+			}
+		}
+		if err := s.restorePosition(ctx, c); err != nil {
+			return err
+		}
+		if s.PlayMode != "" {
+			return c.SetPlayMode(ctx, s.PlayMode)
+		}
+		return nil
+
+	case SourceTrack:
+		if err := c.SetAVTransportURI(ctx, s.TransportURI, s.TransportMeta); err != nil {
+			return err
+		}
+		return s.restorePosition(ctx, c)
+
+	default:
+		// Streams resume live; line-in and TV only need the input selected again.
+		return c.SetAVTransportURI(ctx, s.TransportURI, s.TransportMeta)
+	}
+}
+
+// restoreQueue puts the recorded queue back if it changed since the snapshot.
+func (s *Snapshot) restoreQueue(ctx context.Context, c *Client) error {
+	br, err := c.Browse(ctx, "Q:0", 0, 1)
+	if err != nil {
+		return err
+	}
+	if br.UpdateID == s.QueueUpdateID && br.TotalMatches == len(s.Queue) {
+		return nil
+	}
+	if err := c.RemoveAllTracksFromQueue(ctx); err != nil {
+		return err
+	}
+	for _, it := range s.Queue {
+		if _, err := c.AddURIToQueue(ctx, it.URI, it.Meta, 0, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) restorePosition(ctx context.Context, c *Client) error {
+	if s.RelTime == "" || s.RelTime == "0:00:00" || s.RelTime == "NOT_IMPLEMENTED" {
+		return nil
+	}
+	return ignoreTransitionErr(c.SeekRelTime(ctx, s.RelTime))
+}
+
+func (s *Snapshot) client(ip string) *Client {
+	var c *Client
+	if s.httpClient != nil {
+		c = &Client{IP: ip, Port: s.port, HTTP: s.httpClient}
+	} else {
+		c = NewClient(ip, 10*time.Second)
+	}
+	c.VolumeLimit = s.volumeLimit
+	return c
+}
+
+// ignoreTransitionErr treats "transition not available" (701) and "illegal
+// seek target" (711) as success: the source cannot seek, which is fine.
+func ignoreTransitionErr(err error) error {
+	var upnpErr *UPnPError
+	if errors.As(err, &upnpErr) && (upnpErr.Code == "701" || upnpErr.Code == "711") {
+		return nil
+	}
+	return err
+}
+
+// splitDIDLItems returns each item of a DIDL-Lite result as a standalone
+// DIDL-Lite document, preserving the original metadata verbatim.
+func splitDIDLItems(didlXML string) ([]SnapshotQueueItem, error) {
+	didlXML = strings.TrimSpace(didlXML)
+	if didlXML == "" {
+		return nil, nil
+	}
+	dec := xml.NewDecoder(bytes.NewReader([]byte(didlXML)))
+	var (
+		header string
+		out    []SnapshotQueueItem
+		depth  int
+	)
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return out, nil
+			}
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			if _, ok := tok.(xml.EndElement); ok {
+				depth--
+			}
+			continue
+		}
+		depth++
+		if depth == 1 {
+			header = didlXML[offset:dec.InputOffset()]
+			continue
+		}
+		if depth != 2 || (se.Name.Local != "item" && se.Name.Local != "container") {
+			continue
+		}
+		it, err := parseDIDLItem(dec, se)
+		if err != nil {
+			return nil, err
+		}
+		depth--
+		raw := didlXML[offset:dec.InputOffset()]
+		out = append(out, SnapshotQueueItem{URI: it.URI, Meta: header + raw + "</DIDL-Lite>"})
+	}
+}
@@ -0,0 +1,223 @@
+package sonos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func newSnapshotHousehold(t *testing.T, rooms ...string) *sonostest.Household {
+	t.Helper()
+	h, err := sonostest.NewHousehold(rooms...)
+	if err != nil {
+		t.Skipf("fake household unavailable: %v", err)
+	}
+	t.Cleanup(h.Close)
+	return h
+}
+
+func TestSnapshotRestoresQueuePositionAndVolumes(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Office")
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	kitchen, office := h.Speaker("Kitchen"), h.Speaker("Office")
+	kitchen.SetQueue(
+		sonostest.Track{URI: "http://example.com/a.mp3", Title: "A", Duration: "0:03:00"},
+		sonostest.Track{URI: "http://example.com/b.mp3", Title: "B", Duration: "0:04:00"},
+		sonostest.Track{URI: "http://example.com/c.mp3", Title: "C", Duration: "0:05:00"},
+	)
+	kitchen.SetVolume(12)
+	office.SetVolume(34)
+
+	ctx := context.Background()
+	c := NewClient(kitchen.IP, 2*time.Second)
+	if err := c.PlayQueuePosition(ctx, 2); err != nil {
+		t.Fatalf("PlayQueuePosition: %v", err)
+	}
+	if err := c.SeekRelTime(ctx, "0:01:15"); err != nil {
+		t.Fatalf("SeekRelTime: %v", err)
+	}
+	if err := c.SetPlayMode(ctx, PlayModeRepeatAll); err != nil {
+		t.Fatalf("SetPlayMode: %v", err)
+	}
+
+	snap, err := TakeSnapshot(ctx, c)
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	if snap.Source != SourceQueue || snap.Track != 2 || snap.RelTime != "0:01:15" || len(snap.Queue) != 3 || len(snap.Members) != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+
+	// Interrupt: play a radio stream, clear the queue, split the group, change volumes.
+	if err := c.ClearQueue(ctx); err != nil {
+		t.Fatalf("ClearQueue: %v", err)
+	}
+	if err := c.PlayURI(ctx, "x-rincon-mp3radio://example.com/live", ""); err != nil {
+		t.Fatalf("PlayURI: %v", err)
+	}
+	if err := NewClient(office.IP, 2*time.Second).LeaveGroup(ctx); err != nil {
+		t.Fatalf("LeaveGroup: %v", err)
+	}
+	kitchen.SetVolume(60)
+	office.SetVolume(60)
+
+	if err := snap.Restore(ctx); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	st := kitchen.State()
+	if len(st.Queue) != 3 || st.Queue[1].Title != "B" {
+		t.Fatalf("queue not restored: %+v", st.Queue)
+	}
+	if st.Track != 2 || st.RelTime != "0:01:15" || st.PlayMode != "REPEAT_ALL" || st.TransportState != "PLAYING" {
+		t.Fatalf("unexpected transport state: %+v", st)
+	}
+	if st.Volume != 12 || office.State().Volume != 34 {
+		t.Fatalf("volumes not restored: %d %d", st.Volume, office.State().Volume)
+	}
+	if got := office.State().Coordinator; got != kitchen.UUID {
+		t.Fatalf("Office not re-joined: %q", got)
+	}
+}
+
+func TestSnapshotKeepsUnchangedQueue(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen")
+	sp := h.Speaker("Kitchen")
+	sp.SetQueue(sonostest.Track{URI: "http://example.com/a.mp3", Title: "A"})
+	ctx := context.Background()
+	c := NewClient(sp.IP, 2*time.Second)
+	if err := c.PlayQueuePosition(ctx, 1); err != nil {
+		t.Fatalf("PlayQueuePosition: %v", err)
+	}
+	if err := c.Pause(ctx); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+
+	snap, err := TakeSnapshot(ctx, c)
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	if err := snap.Restore(ctx); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for _, call := range sp.Calls() {
+		if call == "RemoveAllTracksFromQueue" || call == "AddURIToQueue" {
+			t.Fatalf("queue should not be rebuilt: %v", sp.Calls())
+		}
+	}
+	if st := sp.State().TransportState; st == "PLAYING" {
+		t.Fatalf("paused snapshot should not resume playback")
+	}
+}
+
+func TestSnapshotRestoresStreamAndLineIn(t *testing.T) {
+	h := newSnapshotHousehold(t, "Den")
+	sp := h.Speaker("Den")
+	ctx := context.Background()
+	c := NewClient(sp.IP, 2*time.Second)
+
+	for _, uri := range []string{"x-rincon-mp3radio://example.com/live", "x-rincon-stream:" + sp.UUID} {
+		if err := c.PlayURI(ctx, uri, ""); err != nil {
+			t.Fatalf("PlayURI(%s): %v", uri, err)
+		}
+		snap, err := TakeSnapshot(ctx, c)
+		if err != nil {
+			t.Fatalf("TakeSnapshot: %v", err)
+		}
+		if snap.Track != 0 || snap.RelTime != "" || len(snap.Queue) != 0 {
+			t.Fatalf("stream snapshot should not record a position: %+v", snap)
+		}
+		if err := c.PlayURI(ctx, "http://example.com/other.mp3", ""); err != nil {
+			t.Fatalf("PlayURI: %v", err)
+		}
+		if err := snap.Restore(ctx); err != nil {
+			t.Fatalf("Restore(%s): %v", snap.Source, err)
+		}
+		if st := sp.State(); st.AVTransportURI != uri || st.TransportState != "PLAYING" {
+			t.Fatalf("unexpected state after restoring %s: %+v", snap.Source, st)
+		}
+	}
+}
+
+func TestSnapshotRestoreDropsRoomsThatJoined(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Office")
+	kitchen, office := h.Speaker("Kitchen"), h.Speaker("Office")
+	ctx := context.Background()
+	c := NewClient(kitchen.IP, 2*time.Second)
+
+	snap, err := TakeSnapshot(ctx, c)
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := snap.Restore(ctx); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := office.State().Coordinator; got != office.UUID {
+		t.Fatalf("Office should be standalone again, coordinator=%q", got)
+	}
+}
+
+func TestSnapshotRequiresCoordinator(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Office")
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	_, err := TakeSnapshot(context.Background(), NewClient(h.Speaker("Office").IP, 2*time.Second))
+	if err == nil || !strings.Contains(err.Error(), "coordinator") {
+		t.Fatalf("expected coordinator error, got %v", err)
+	}
+}
+
+func TestSourceKind(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"":                                      SourceNone,
+		"x-rincon-queue:RINCON_1#0":             SourceQueue,
+		"x-rincon-stream:RINCON_1":              SourceLineIn,
+		"x-sonos-htastream:RINCON_1:spdif":      SourceTV,
+		"x-sonosapi-stream:s1234?sid=254":       SourceStream,
+		"x-rincon-mp3radio://example.com/live":  SourceStream,
+		"aac://example.com/live":                SourceStream,
+		"x-sonos-spotify:spotify%3atrack%3a123": SourceTrack,
+		"http://example.com/a.mp3":              SourceTrack,
+	}
+	for uri, want := range cases {
+		if got := SourceKind(uri); got != want {
+			t.Fatalf("SourceKind(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestSplitDIDLItemsKeepsMetadata(t *testing.T) {
+	t.Parallel()
+
+	didl := `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="Q:0/1"><dc:title>One &amp; Two</dc:title><res>http://example.com/1.mp3</res></item>` +
+		`<item id="Q:0/2"><dc:title>Three</dc:title><res>x-sonos-spotify:spotify%3atrack%3a3</res><desc id="cdudn">SA_RINCON2311_X</desc></item>` +
+		`</DIDL-Lite>`
+	items, err := splitDIDLItems(didl)
+	if err != nil {
+		t.Fatalf("splitDIDLItems: %v", err)
+	}
+	if len(items) != 2 || items[0].URI != "http://example.com/1.mp3" || items[1].URI != "x-sonos-spotify:spotify%3atrack%3a3" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if !strings.HasPrefix(items[1].Meta, `<DIDL-Lite xmlns:dc=`) || !strings.Contains(items[1].Meta, `<desc id="cdudn">SA_RINCON2311_X</desc>`) ||
+		!strings.HasSuffix(items[1].Meta, "</item></DIDL-Lite>") || strings.Contains(items[1].Meta, "Q:0/1") {
+		t.Fatalf("unexpected meta: %s", items[1].Meta)
+	}
+	if !strings.Contains(items[0].Meta, "One &amp; Two") {
+		t.Fatalf("escaping lost: %s", items[0].Meta)
+	}
+	if items, err := splitDIDLItems(""); err != nil || items != nil {
+		t.Fatalf("empty: %v %v", items, err)
+	}
+}
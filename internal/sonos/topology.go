@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +15,33 @@ type Member struct {
 	Location      string `json:"location"`
 	IsVisible     bool   `json:"isVisible"`
 	IsCoordinator bool   `json:"isCoordinator"`
+
+	// Set for devices in a stereo pair or home-theater set (ChannelMapSet /
+	// HTSatChanMapSet): the channels the device plays, its BondRole and, on
+	// the secondary devices, the UUID of the room's primary device.
+	Channels string `json:"channels,omitempty"`
+	Role     string `json:"role,omitempty"`
+	BondedTo string `json:"bondedTo,omitempty"`
+
+	// Raw details as reported in ZoneGroupState. Numeric attributes are
+	// passed through unchanged (zero when absent); MicEnabled is nil on
+	// devices without microphones.
+	SoftwareVersion    string `json:"softwareVersion,omitempty"`
+	BootSeq            int    `json:"bootSeq,omitempty"`
+	ChannelMapSet      string `json:"channelMapSet,omitempty"`
+	HTSatChanMapSet    string `json:"htSatChanMapSet,omitempty"`
+	MicEnabled         *bool  `json:"micEnabled,omitempty"`
+	WirelessMode       int    `json:"wirelessMode,omitempty"`
+	BehindWifiExtender int    `json:"behindWifiExtender,omitempty"`
+	Orientation        int    `json:"orientation,omitempty"`
+}
+
+// VanishedDevice is a player the household lost track of (powered off,
+// dropped off the network), from the VanishedDevices section.
+type VanishedDevice struct {
+	UUID   string `json:"uuid"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
 }
 
 type Group struct {
@@ -24,6 +52,7 @@ type Group struct {
 
 type Topology struct {
 	Groups      []Group           `json:"groups"`
+	Vanished    []VanishedDevice  `json:"vanishedDevices,omitempty"`
 	ByName      map[string]Member `json:"-"`
 	ByIP        map[string]Member `json:"-"`
 	byUUID      map[string]Member
@@ -42,11 +71,26 @@ func (c *Client) GetTopology(ctx context.Context) (Topology, error) {
 	return parseZoneGroupStateXML(zgs)
 }
 
+// ParseZoneGroupState parses a ZoneGroupState document, as carried by
+// ZoneGroupTopology events.
+func ParseZoneGroupState(zgs string) (Topology, error) {
+	return parseZoneGroupStateXML(zgs)
+}
+
 type zgsEnvelope struct {
 	ZoneGroups *struct {
 		Groups []zgsGroup `xml:"ZoneGroup"`
 	} `xml:"ZoneGroups"`
-	Groups []zgsGroup `xml:"ZoneGroup"`
+	Groups   []zgsGroup `xml:"ZoneGroup"`
+	Vanished *struct {
+		Devices []zgsVanished `xml:"Device"`
+	} `xml:"VanishedDevices"`
+}
+
+type zgsVanished struct {
+	UUID     string `xml:"UUID,attr"`
+	ZoneName string `xml:"ZoneName,attr"`
+	Reason   string `xml:"Reason,attr"`
 }
 
 type zgsGroup struct {
@@ -60,6 +104,18 @@ type zgsMember struct {
 	Location  string `xml:"Location,attr"`
 	UUID      string `xml:"UUID,attr"`
 	Invisible string `xml:"Invisible,attr"`
+	// ChannelMapSet is set on stereo pair members, HTSatChanMapSet on
+	// home-theater speakers and their satellites.
+	ChannelMapSet   string `xml:"ChannelMapSet,attr"`
+	HTSatChanMapSet string `xml:"HTSatChanMapSet,attr"`
+
+	SoftwareVersion    string `xml:"SoftwareVersion,attr"`
+	BootSeq            string `xml:"BootSeq,attr"`
+	MicEnabled         string `xml:"MicEnabled,attr"`
+	WirelessMode       string `xml:"WirelessMode,attr"`
+	BehindWifiExtender string `xml:"BehindWifiExtender,attr"`
+	Orientation        string `xml:"Orientation,attr"`
+
 	// Home-theater satellites appear nested under a ZoneGroupMember.
 	// Some firmwares also use nested members for bonded devices.
 	Satellites []zgsMember `xml:"Satellite"`
@@ -77,6 +133,7 @@ func parseZoneGroupStateXML(payload string) (Topology, error) {
 	}
 
 	t := Topology{
+		Vanished:    parseVanished(env),
 		ByName:      map[string]Member{},
 		ByIP:        map[string]Member{},
 		byUUID:      map[string]Member{},
@@ -106,12 +163,15 @@ func parseZoneGroupStateXML(payload string) (Topology, error) {
 		}
 	}
 
+	bonds := collectBonds(groups)
+
 	for _, g := range groups {
 		members := make([]Member, 0, len(g.Members))
 		var coordinator Member
 		for _, m := range g.Members {
 			mem, ok := toMember(g.Coordinator, m)
 			if ok {
+				applyBond(&mem, bonds)
 				if mem.IsCoordinator {
 					coordinator = mem
 				}
@@ -131,6 +191,7 @@ func parseZoneGroupStateXML(payload string) (Topology, error) {
 				}
 				// Satellites cannot be coordinators.
 				smem.IsCoordinator = false
+				applyBond(&smem, bonds)
 				members = append(members, smem)
 				setByName(smem)
 				t.ByIP[smem.IP] = smem
@@ -159,6 +220,49 @@ func parseZoneGroupStateXML(payload string) (Topology, error) {
 	return t, nil
 }
 
+type bondInfo struct {
+	channels string
+	primary  string
+}
+
+// collectBonds maps device UUIDs to their channels and bonded room. The
+// primary is the visible device announcing the map (the left speaker of a
+// stereo pair, the home-theater speaker).
+func collectBonds(groups []zgsGroup) map[string]bondInfo {
+	out := map[string]bondInfo{}
+	var visit func(m zgsMember)
+	visit = func(m zgsMember) {
+		if m.Invisible != "1" {
+			for _, set := range []string{m.ChannelMapSet, m.HTSatChanMapSet} {
+				for _, c := range ParseChannelMapSet(set) {
+					out[c.UUID] = bondInfo{channels: c.Channels, primary: m.UUID}
+				}
+			}
+		}
+		for _, sat := range m.Satellites {
+			visit(sat)
+		}
+	}
+	for _, g := range groups {
+		for _, m := range g.Members {
+			visit(m)
+		}
+	}
+	return out
+}
+
+func applyBond(m *Member, bonds map[string]bondInfo) {
+	b, ok := bonds[m.UUID]
+	if !ok {
+		return
+	}
+	m.Channels = b.channels
+	m.Role = BondRole(b.channels)
+	if b.primary != m.UUID {
+		m.BondedTo = b.primary
+	}
+}
+
 func toMember(groupCoordinatorUUID string, m zgsMember) (Member, bool) {
 	ip, err := hostToIP(m.Location)
 	if err != nil || ip == "" {
@@ -170,6 +274,18 @@ func toMember(groupCoordinatorUUID string, m zgsMember) (Member, bool) {
 		UUID:      m.UUID,
 		Location:  m.Location,
 		IsVisible: m.Invisible != "1",
+
+		SoftwareVersion:    m.SoftwareVersion,
+		BootSeq:            atoiOrZero(m.BootSeq),
+		ChannelMapSet:      m.ChannelMapSet,
+		HTSatChanMapSet:    m.HTSatChanMapSet,
+		WirelessMode:       atoiOrZero(m.WirelessMode),
+		BehindWifiExtender: atoiOrZero(m.BehindWifiExtender),
+		Orientation:        atoiOrZero(m.Orientation),
+	}
+	if m.MicEnabled != "" {
+		mic := m.MicEnabled == "1"
+		mem.MicEnabled = &mic
 	}
 	if groupCoordinatorUUID != "" {
 		mem.IsCoordinator = mem.UUID == groupCoordinatorUUID
@@ -177,6 +293,27 @@ func toMember(groupCoordinatorUUID string, m zgsMember) (Member, bool) {
 	return mem, true
 }
 
+// parseVanished reads the VanishedDevices section S2 firmware sends next to
+// ZoneGroups; older payloads do not have it.
+func parseVanished(env zgsEnvelope) []VanishedDevice {
+	if env.Vanished == nil {
+		return nil
+	}
+	out := make([]VanishedDevice, 0, len(env.Vanished.Devices))
+	for _, d := range env.Vanished.Devices {
+		if d.UUID == "" {
+			continue
+		}
+		out = append(out, VanishedDevice{UUID: d.UUID, Name: d.ZoneName, Reason: d.Reason})
+	}
+	return out
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
 func (t Topology) FindByName(name string) (Member, bool) {
 	mem, ok := t.ByName[name]
 	return mem, ok
@@ -187,6 +324,21 @@ func (t Topology) FindByIP(ip string) (Member, bool) {
 	return mem, ok
 }
 
+// FindByUUID returns the member with the given RINCON_ UUID.
+func (t Topology) FindByUUID(uuid string) (Member, bool) {
+	if mem, ok := t.byUUID[uuid]; ok {
+		return mem, true
+	}
+	for _, g := range t.Groups {
+		for _, m := range g.Members {
+			if m.UUID == uuid {
+				return m, true
+			}
+		}
+	}
+	return Member{}, false
+}
+
 func (t Topology) GroupForIP(ip string) (Group, bool) {
 	for _, g := range t.Groups {
 		for _, m := range g.Members {
@@ -93,3 +93,48 @@ func TestTopology_FindByIP_AndCoordinatorUUIDForIP(t *testing.T) {
 		t.Fatalf("expected CoordinatorUUIDForIP to fail for unknown ip")
 	}
 }
+
+func TestParseZoneGroupStateXML_DetailsAndVanished(t *testing.T) {
+	payload := `
+<ZoneGroupState>
+  <ZoneGroups>
+    <ZoneGroup Coordinator="RINCON_ABC1400" ID="RINCON_ABC1400:7">
+      <ZoneGroupMember ZoneName="Den" UUID="RINCON_ABC1400" Location="http://192.168.1.10:1400/xml/device_description.xml" Invisible="0"
+        SoftwareVersion="85.0-64110" BootSeq="123" MicEnabled="0" WirelessMode="1" BehindWifiExtender="2" Orientation="3"
+        HTSatChanMapSet="RINCON_ABC1400:LF,RF;RINCON_SUB1400:SW">
+        <Satellite ZoneName="Den" UUID="RINCON_SUB1400" Location="http://192.168.1.12:1400/xml/device_description.xml" Invisible="1"
+          SoftwareVersion="84.1-61240" BootSeq="9" HTSatChanMapSet="RINCON_ABC1400:LF,RF;RINCON_SUB1400:SW" />
+      </ZoneGroupMember>
+    </ZoneGroup>
+  </ZoneGroups>
+  <VanishedDevices>
+    <Device UUID="RINCON_OLD1400" ZoneName="Garage" Reason="powered off" />
+  </VanishedDevices>
+</ZoneGroupState>`
+
+	top, err := parseZoneGroupStateXML(payload)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	den, ok := top.FindByIP("192.168.1.10")
+	if !ok {
+		t.Fatalf("Den not found")
+	}
+	if den.SoftwareVersion != "85.0-64110" || den.BootSeq != 123 || den.WirelessMode != 1 || den.BehindWifiExtender != 2 || den.Orientation != 3 {
+		t.Fatalf("unexpected details: %+v", den)
+	}
+	if den.MicEnabled == nil || *den.MicEnabled {
+		t.Fatalf("MicEnabled = %v, want false", den.MicEnabled)
+	}
+	if den.HTSatChanMapSet != "RINCON_ABC1400:LF,RF;RINCON_SUB1400:SW" || den.ChannelMapSet != "" {
+		t.Fatalf("unexpected channel maps: %+v", den)
+	}
+	sub, ok := top.FindByIP("192.168.1.12")
+	if !ok || sub.SoftwareVersion != "84.1-61240" || sub.BootSeq != 9 || sub.MicEnabled != nil {
+		t.Fatalf("unexpected satellite: %v %+v", ok, sub)
+	}
+	want := []VanishedDevice{{UUID: "RINCON_OLD1400", Name: "Garage", Reason: "powered off"}}
+	if len(top.Vanished) != 1 || top.Vanished[0] != want[0] {
+		t.Fatalf("Vanished = %+v", top.Vanished)
+	}
+}
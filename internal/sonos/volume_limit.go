@@ -0,0 +1,201 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// VolumeLimit reports the highest volume currently allowed for a room; ok is
+// false when the room is unrestricted.
+type VolumeLimit func(room string) (max int, ok bool)
+
+// groupLimitTTL is how long the group layout behind limited group volume
+// writes is reused, so a fade does not re-read the topology on every step.
+const groupLimitTTL = 10 * time.Second
+
+// VolumeCap returns the limit in effect for this speaker's room, looked up by
+// name through the household topology (read once per client). Without
+// Client.VolumeLimit there is no cap. If the room cannot be resolved the
+// error is returned rather than writing uncapped.
+func (c *Client) VolumeCap(ctx context.Context) (int, bool, error) {
+	if c.VolumeLimit == nil {
+		return 0, false, nil
+	}
+	room, err := c.limitRoomName(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	limit, ok := c.VolumeLimit(room)
+	return limit, ok, nil
+}
+
+// limitRoomName returns this speaker's room name, looked up on first use and
+// kept for the life of the client.
+func (c *Client) limitRoomName(ctx context.Context) (string, error) {
+	c.limitMu.Lock()
+	defer c.limitMu.Unlock()
+	if c.limitRoom != "" {
+		return c.limitRoom, nil
+	}
+	top, err := c.GetTopology(ctx)
+	if err != nil {
+		return "", fmt.Errorf("volume limit: %w", err)
+	}
+	m, ok := top.FindByIP(c.IP)
+	if !ok || m.Name == "" {
+		return "", errors.New("volume limit: speaker not found in topology: " + c.IP)
+	}
+	c.limitRoom = m.Name
+	return m.Name, nil
+}
+
+// CapVolume lowers volume to the room's limit, i.e. the level a write of
+// volume through this client actually reaches.
+func (c *Client) CapVolume(ctx context.Context, volume int) (int, error) {
+	limit, ok, err := c.VolumeCap(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ok && volume > limit {
+		slog.Debug("volume limit: capping", "ip", c.IP, "requested", volume, "max", limit)
+		return limit, nil
+	}
+	return volume, nil
+}
+
+// limitedMember is one room of a group together with its limit, if any.
+type limitedMember struct {
+	client  *Client
+	name    string
+	limit   int
+	limited bool
+}
+
+// groupLimits returns the rooms of this coordinator's group with the limits
+// in effect now, or nil when none of them is limited (and the group volume
+// can be written as is).
+func (c *Client) groupLimits(ctx context.Context) ([]limitedMember, error) {
+	if c.VolumeLimit == nil {
+		return nil, nil
+	}
+	rooms, err := c.limitGroupRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]limitedMember, 0, len(rooms))
+	anyLimited := false
+	for _, m := range rooms {
+		limit, ok := c.VolumeLimit(m.Name)
+		anyLimited = anyLimited || ok
+		members = append(members, limitedMember{
+			client:  &Client{IP: m.IP, Port: c.Port, HTTP: c.HTTP},
+			name:    m.Name,
+			limit:   limit,
+			limited: ok,
+		})
+	}
+	if !anyLimited {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// limitGroupRooms returns the visible rooms grouped with this coordinator,
+// re-reading the topology at most every groupLimitTTL.
+func (c *Client) limitGroupRooms(ctx context.Context) ([]Member, error) {
+	c.limitMu.Lock()
+	defer c.limitMu.Unlock()
+	if c.limitGroup != nil && time.Since(c.limitGroupAt) < groupLimitTTL {
+		return c.limitGroup, nil
+	}
+	top, err := c.GetTopology(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("volume limit: %w", err)
+	}
+	g, ok := top.GroupForIP(c.IP)
+	if !ok {
+		return nil, errors.New("volume limit: speaker not found in topology: " + c.IP)
+	}
+	rooms := []Member{}
+	for _, m := range g.Members {
+		if m.IsVisible && m.IP != "" {
+			rooms = append(rooms, m)
+		}
+	}
+	c.limitGroup, c.limitGroupAt = rooms, time.Now()
+	return rooms, nil
+}
+
+// memberVolumes reads the current volume of every member and the group
+// volume they add up to (the average, as Sonos reports it).
+func memberVolumes(ctx context.Context, members []limitedMember) ([]int, int, error) {
+	vols := make([]int, len(members))
+	total := 0
+	for i, m := range members {
+		v, err := m.client.GetVolume(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		vols[i] = v
+		total += v
+	}
+	return vols, averageVolume(total, len(members)), nil
+}
+
+func averageVolume(total, n int) int {
+	if n == 0 {
+		return 0
+	}
+	return (total + n/2) / n
+}
+
+// setLimitedGroupVolume moves every member by the same step the group volume
+// moves (from the current group volume to target(current)), writing each
+// member itself so a limited room is capped before it is ever raised. It
+// returns the resulting group volume.
+func setLimitedGroupVolume(ctx context.Context, members []limitedMember, target func(current int) int) (int, error) {
+	vols, current, err := memberVolumes(ctx, members)
+	if err != nil {
+		return 0, err
+	}
+	delta := target(current) - current
+	total := 0
+	for i, m := range members {
+		v := min(max(vols[i]+delta, 0), 100)
+		if m.limited && v > m.limit {
+			slog.Debug("volume limit: capping group member", "room", m.name, "requested", v, "max", m.limit)
+			v = m.limit
+		}
+		if v != vols[i] {
+			if err := m.client.SetVolume(ctx, v); err != nil {
+				return 0, err
+			}
+		}
+		total += v
+	}
+	return averageVolume(total, len(members)), nil
+}
+
+// CapGroupVolume lowers a group volume to the highest level this
+// coordinator's group can reach without any limited room having to be held
+// back, i.e. with every member still moving by the same step.
+func (c *Client) CapGroupVolume(ctx context.Context, volume int) (int, error) {
+	members, err := c.groupLimits(ctx)
+	if err != nil || members == nil {
+		return volume, err
+	}
+	vols, current, err := memberVolumes(ctx, members)
+	if err != nil {
+		return 0, err
+	}
+	headroom := 100
+	for i, m := range members {
+		if m.limited {
+			headroom = min(headroom, m.limit-vols[i])
+		}
+	}
+	return min(volume, max(current+headroom, 0)), nil
+}
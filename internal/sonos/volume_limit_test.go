@@ -0,0 +1,167 @@
+package sonos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestVolumeLimitCapsEveryWritePath(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Kids")
+	kitchen, kids := h.Speaker("Kitchen"), h.Speaker("Kids")
+	kitchen.SetVolume(30)
+	kids.SetVolume(10)
+	limit := func(room string) (int, bool) {
+		if room == "Kids" {
+			return 20, true
+		}
+		return 0, false
+	}
+	ctx := context.Background()
+
+	c := NewClient(kids.IP, 2*time.Second)
+	c.VolumeLimit = limit
+	if err := c.SetVolume(ctx, 60); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("SetVolume not capped: %d", v)
+	}
+	kids.SetVolume(15)
+	if v, err := c.SetRelativeVolume(ctx, 10); err != nil || v != 20 {
+		t.Fatalf("SetRelativeVolume = %d, %v", v, err)
+	}
+	if _, err := c.RampToVolume(ctx, RampSleepTimer, 80); err != nil {
+		t.Fatalf("RampToVolume: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("RampToVolume not capped: %d", v)
+	}
+
+	// Unlimited rooms are untouched.
+	kc := NewClient(kitchen.IP, 2*time.Second)
+	kc.VolumeLimit = limit
+	if err := kc.SetVolume(ctx, 70); err != nil || kitchen.State().Volume != 70 {
+		t.Fatalf("unlimited room capped: %d, %v", kitchen.State().Volume, err)
+	}
+
+	kids.SetVolume(10)
+	kitchen.SetVolume(30)
+	if err := h.Join("Kids", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := kc.SetGroupVolume(ctx, 60); err != nil {
+		t.Fatalf("SetGroupVolume: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("group member above limit after SetGroupVolume: %d", v)
+	}
+	if kitchen.State().Volume <= 30 {
+		t.Fatalf("coordinator volume not raised: %d", kitchen.State().Volume)
+	}
+	// Members are written one by one, so Kids is never raised past 20 and
+	// pulled back afterwards.
+	for _, call := range kitchen.Calls() {
+		if call == "GroupRenderingControl#SetGroupVolume" {
+			t.Fatalf("limited group written through SetGroupVolume: %v", kitchen.Calls())
+		}
+	}
+	if _, err := kc.SetRelativeGroupVolume(ctx, 15); err != nil {
+		t.Fatalf("SetRelativeGroupVolume: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("group member above limit after SetRelativeGroupVolume: %d", v)
+	}
+}
+
+func TestVolumeLimitReadsTopologyOnce(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kids")
+	kids := h.Speaker("Kids")
+	c := NewClient(kids.IP, 2*time.Second)
+	c.VolumeLimit = func(room string) (int, bool) { return 20, room == "Kids" }
+	ctx := context.Background()
+
+	for _, v := range []int{5, 10, 15, 25} {
+		if err := c.SetVolume(ctx, v); err != nil {
+			t.Fatalf("SetVolume(%d): %v", v, err)
+		}
+	}
+	if got, err := c.CapVolume(ctx, 90); err != nil || got != 20 {
+		t.Fatalf("CapVolume(90) = %d, %v", got, err)
+	}
+	lookups := 0
+	for _, call := range kids.Calls() {
+		if call == "ZoneGroupTopology#GetZoneGroupState" {
+			lookups++
+		}
+	}
+	if lookups != 1 || kids.State().Volume != 20 {
+		t.Fatalf("topology read %d times, volume %d", lookups, kids.State().Volume)
+	}
+}
+
+func TestSnapshotRestoreRespectsVolumeLimit(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kids")
+	kids := h.Speaker("Kids")
+	kids.SetVolume(60)
+	ctx := context.Background()
+
+	snap, err := TakeSnapshot(ctx, NewClient(kids.IP, 2*time.Second))
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	c := NewClient(kids.IP, 2*time.Second)
+	c.VolumeLimit = func(room string) (int, bool) { return 20, room == "Kids" }
+	snap.UseClient(c)
+	if err := snap.Restore(ctx); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if v := kids.State().Volume; v != 20 {
+		t.Fatalf("restored volume above limit: %d", v)
+	}
+}
+
+func TestCapGroupVolumeKeepsLimitedRoomsMoving(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kitchen", "Kids")
+	kitchen, kids := h.Speaker("Kitchen"), h.Speaker("Kids")
+	kitchen.SetVolume(30)
+	kids.SetVolume(10)
+	if err := h.Join("Kids", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	c := NewClient(kitchen.IP, 2*time.Second)
+	c.VolumeLimit = func(room string) (int, bool) { return 20, room == "Kids" }
+	ctx := context.Background()
+
+	// Group volume 20; Kids has 10 to go before its limit.
+	if got, err := c.CapGroupVolume(ctx, 60); err != nil || got != 30 {
+		t.Fatalf("CapGroupVolume(60) = %d, %v", got, err)
+	}
+	if got, err := c.CapGroupVolume(ctx, 25); err != nil || got != 25 {
+		t.Fatalf("CapGroupVolume(25) = %d, %v", got, err)
+	}
+	c.VolumeLimit = func(string) (int, bool) { return 0, false }
+	if got, err := c.CapGroupVolume(ctx, 60); err != nil || got != 60 {
+		t.Fatalf("CapGroupVolume without limited rooms = %d, %v", got, err)
+	}
+}
+
+func TestVolumeLimitFailsClosedWithoutTopology(t *testing.T) {
+	h := newSnapshotHousehold(t, "Kids")
+	kids := h.Speaker("Kids")
+	kids.SetVolume(10)
+	kids.SetFault("GetZoneGroupState", "501")
+	c := NewClient(kids.IP, 2*time.Second)
+	c.VolumeLimit = func(room string) (int, bool) { return 20, room == "Kids" }
+	ctx := context.Background()
+
+	if err := c.SetVolume(ctx, 80); err == nil {
+		t.Fatalf("expected SetVolume to fail without a topology")
+	}
+	if err := c.SetGroupVolume(ctx, 80); err == nil {
+		t.Fatalf("expected SetGroupVolume to fail without a topology")
+	}
+	if v := kids.State().Volume; v != 10 {
+		t.Fatalf("volume written uncapped: %d", v)
+	}
+}
@@ -0,0 +1,183 @@
+package sonostest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Alarm is a household alarm held by the fake AlarmClock service.
+type Alarm struct {
+	ID                 int
+	StartTime          string // HH:MM:SS
+	Duration           string // HH:MM:SS
+	Recurrence         string
+	Enabled            bool
+	RoomUUID           string
+	ProgramURI         string
+	ProgramMetaData    string
+	PlayMode           string
+	Volume             int
+	IncludeLinkedZones bool
+}
+
+// Alarms returns a copy of the household alarm list.
+func (h *Household) Alarms() []Alarm {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Alarm(nil), h.alarms...)
+}
+
+// SetAlarms replaces the household alarm list. IDs of zero are assigned.
+func (h *Household) SetAlarms(alarms ...Alarm) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.alarms = nil
+	for _, a := range alarms {
+		if a.ID == 0 {
+			h.nextAlarmID++
+			a.ID = h.nextAlarmID
+		} else if a.ID > h.nextAlarmID {
+			h.nextAlarmID = a.ID
+		}
+		h.alarms = append(h.alarms, a)
+	}
+	h.alarmsChangedLocked()
+}
+
+func (h *Household) alarmsChangedLocked() {
+	h.alarmListVersion++
+	for _, s := range h.speakers {
+		s.notifyLocked(serviceAlarmClock)
+	}
+}
+
+var alarmClockService = &soapService{
+	name: "AlarmClock",
+	urn:  "urn:schemas-upnp-org:service:AlarmClock:1",
+	actions: map[string]actionHandler{
+		"ListAlarms":   acListAlarms,
+		"CreateAlarm":  acCreateAlarm,
+		"UpdateAlarm":  acUpdateAlarm,
+		"DestroyAlarm": acDestroyAlarm,
+	},
+}
+
+func acListAlarms(s *Speaker, _ map[string]string) (map[string]string, error) {
+	var b strings.Builder
+	b.WriteString(`<Alarms>`)
+	for _, a := range s.h.alarms {
+		b.WriteString(`<Alarm ID="` + strconv.Itoa(a.ID) + `"`)
+		attr := func(name, val string) { b.WriteString(` ` + name + `="` + xmlEscape(val) + `"`) }
+		attr("StartTime", a.StartTime)
+		attr("Duration", a.Duration)
+		attr("Recurrence", a.Recurrence)
+		attr("Enabled", boolString(a.Enabled))
+		attr("RoomUUID", a.RoomUUID)
+		attr("ProgramURI", a.ProgramURI)
+		attr("ProgramMetaData", a.ProgramMetaData)
+		attr("PlayMode", a.PlayMode)
+		attr("Volume", strconv.Itoa(a.Volume))
+		attr("IncludeLinkedZones", boolString(a.IncludeLinkedZones))
+		b.WriteString(`/>`)
+	}
+	b.WriteString(`</Alarms>`)
+	return map[string]string{
+		"CurrentAlarmList":        b.String(),
+		"CurrentAlarmListVersion": s.h.alarmListVersionLocked(),
+	}, nil
+}
+
+func acCreateAlarm(s *Speaker, args map[string]string) (map[string]string, error) {
+	a, err := alarmFromArgs(s.h, args)
+	if err != nil {
+		return nil, err
+	}
+	s.h.nextAlarmID++
+	a.ID = s.h.nextAlarmID
+	s.h.alarms = append(s.h.alarms, a)
+	s.h.alarmsChangedLocked()
+	return map[string]string{"AssignedID": strconv.Itoa(a.ID)}, nil
+}
+
+func acUpdateAlarm(s *Speaker, args map[string]string) (map[string]string, error) {
+	i, err := s.h.alarmIndexLocked(args["ID"])
+	if err != nil {
+		return nil, err
+	}
+	a, err := alarmFromArgs(s.h, args)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = s.h.alarms[i].ID
+	s.h.alarms[i] = a
+	s.h.alarmsChangedLocked()
+	return nil, nil
+}
+
+func acDestroyAlarm(s *Speaker, args map[string]string) (map[string]string, error) {
+	i, err := s.h.alarmIndexLocked(args["ID"])
+	if err != nil {
+		return nil, err
+	}
+	s.h.alarms = append(s.h.alarms[:i], s.h.alarms[i+1:]...)
+	s.h.alarmsChangedLocked()
+	return nil, nil
+}
+
+func (h *Household) alarmIndexLocked(raw string) (int, error) {
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errUPnP("402", "Invalid Args")
+	}
+	for i, a := range h.alarms {
+		if a.ID == id {
+			return i, nil
+		}
+	}
+	return 0, errUPnP("801", "No such alarm")
+}
+
+func (h *Household) alarmListVersionLocked() string {
+	uuid := ""
+	if len(h.speakers) > 0 {
+		uuid = h.speakers[0].UUID
+	}
+	return uuid + ":" + strconv.Itoa(h.alarmListVersion)
+}
+
+var (
+	alarmTimeRE       = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d:[0-5]\d$`)
+	alarmRecurrenceRE = regexp.MustCompile(`^(ONCE|DAILY|WEEKDAYS|WEEKENDS|ON_[0-6]+)$`)
+)
+
+func alarmFromArgs(h *Household, args map[string]string) (Alarm, error) {
+	vol, err := strconv.Atoi(args["Volume"])
+	if err != nil || vol < 0 || vol > 100 {
+		return Alarm{}, errUPnP("402", "Invalid Args")
+	}
+	if !alarmTimeRE.MatchString(args["StartLocalTime"]) || !alarmTimeRE.MatchString(args["Duration"]) ||
+		!alarmRecurrenceRE.MatchString(args["Recurrence"]) {
+		return Alarm{}, errUPnP("402", "Invalid Args")
+	}
+	switch args["PlayMode"] {
+	case "NORMAL", "SHUFFLE", "SHUFFLE_NOREPEAT", "REPEAT_ALL", "REPEAT_ONE":
+	default:
+		return Alarm{}, errUPnP("402", "Invalid Args")
+	}
+	if h.byUUIDLocked(args["RoomUUID"]) == nil {
+		return Alarm{}, errUPnP("402", "Invalid Args")
+	}
+	return Alarm{
+		StartTime:          args["StartLocalTime"],
+		Duration:           args["Duration"],
+		Recurrence:         args["Recurrence"],
+		Enabled:            args["Enabled"] == "1",
+		RoomUUID:           args["RoomUUID"],
+		ProgramURI:         args["ProgramURI"],
+		ProgramMetaData:    args["ProgramMetaData"],
+		PlayMode:           args["PlayMode"],
+		Volume:             vol,
+		IncludeLinkedZones: args["IncludeLinkedZones"] == "1",
+	}, nil
+}
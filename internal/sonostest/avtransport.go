@@ -0,0 +1,469 @@
+package sonostest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var avTransportService = &soapService{
+	name: "AVTransport",
+	urn:  "urn:schemas-upnp-org:service:AVTransport:1",
+	actions: map[string]actionHandler{
+		"SetAVTransportURI":                  avSetAVTransportURI,
+		"Play":                               avPlay,
+		"Pause":                              avPause,
+		"Stop":                               avStop,
+		"Next":                               avNext,
+		"Previous":                           avPrevious,
+		"Seek":                               avSeek,
+		"AddURIToQueue":                      avAddURIToQueue,
+		"AddMultipleURIsToQueue":             avAddMultipleURIsToQueue,
+		"ReorderTracksInQueue":               avReorderTracksInQueue,
+		"RemoveTrackRangeFromQueue":          avRemoveTrackRangeFromQueue,
+		"CreateSavedQueue":                   avCreateSavedQueue,
+		"AddURIToSavedQueue":                 avAddURIToSavedQueue,
+		"ReorderTracksInSavedQueue":          avReorderTracksInSavedQueue,
+		"SaveQueue":                          avSaveQueue,
+		"RemoveTrackFromQueue":               avRemoveTrackFromQueue,
+		"RemoveAllTracksFromQueue":           avRemoveAllTracksFromQueue,
+		"GetPositionInfo":                    avGetPositionInfo,
+		"GetTransportInfo":                   avGetTransportInfo,
+		"GetTransportSettings":               avGetTransportSettings,
+		"SetPlayMode":                        avSetPlayMode,
+		"GetCrossfadeMode":                   avGetCrossfadeMode,
+		"SetCrossfadeMode":                   avSetCrossfadeMode,
+		"GetCurrentTransportActions":         avGetCurrentTransportActions,
+		"GetMediaInfo":                       avGetMediaInfo,
+		"BecomeCoordinatorOfStandaloneGroup": avBecomeCoordinatorOfStandaloneGroup,
+		"ConfigureSleepTimer":                avConfigureSleepTimer,
+		"GetRemainingSleepTimerDuration":     avGetRemainingSleepTimerDuration,
+	},
+}
+
+// Stream-like sources that do not support seeking or track navigation.
+var streamPrefixes = []string{
+	"x-rincon-mp3radio:",
+	"x-sonosapi-stream:",
+	"x-sonosapi-radio:",
+	"x-rincon-stream:",
+	"x-sonos-htastream:",
+	"aac:",
+}
+
+func isStreamURI(uri string) bool {
+	for _, p := range streamPrefixes {
+		if strings.HasPrefix(uri, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Speaker) requireCoordinatorLocked() error {
+	if s.coordinator != s.UUID {
+		return errUPnP("800", "command not supported on a group member")
+	}
+	return nil
+}
+
+func (s *Speaker) coordinatorLocked() *Speaker {
+	if c := s.h.byUUIDLocked(s.coordinator); c != nil {
+		return c
+	}
+	return s
+}
+
+func avSetAVTransportURI(s *Speaker, args map[string]string) (map[string]string, error) {
+	uri := args["CurrentURI"]
+	if rest, ok := strings.CutPrefix(uri, "x-rincon:"); ok {
+		return nil, s.h.joinLocked(s, rest)
+	}
+	if s.coordinator != s.UUID {
+		s.h.leaveLocked(s)
+	}
+	if strings.HasPrefix(uri, "x-rincon-queue:") {
+		uri = s.queueURI()
+	}
+	s.avURI = uri
+	s.avMeta = args["CurrentURIMetaData"]
+	s.transportState = stateStopped
+	s.relTime = zeroTime
+	s.track = 0
+	if uri != "" && (!s.usesQueue() || len(s.queue) > 0) {
+		s.track = 1
+	}
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avPlay(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if s.avURI == "" || (s.usesQueue() && len(s.queue) == 0) {
+		return nil, errUPnP("701", "Transition not available")
+	}
+	if s.track == 0 {
+		s.track = 1
+	}
+	s.transportState = statePlaying
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avPause(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if s.transportState != statePlaying || strings.HasPrefix(s.avURI, "x-sonos-htastream:") {
+		return nil, errUPnP("701", "Transition not available")
+	}
+	s.transportState = statePaused
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avStop(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(s.avURI, "x-sonos-htastream:") {
+		return nil, errUPnP("701", "Transition not available")
+	}
+	s.transportState = stateStopped
+	s.relTime = zeroTime
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func (s *Speaker) repeats() bool {
+	switch s.playMode {
+	case "REPEAT_ALL", "SHUFFLE":
+		return true
+	}
+	return false
+}
+
+func avNext(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if !s.usesQueue() {
+		return nil, errUPnP("701", "Transition not available")
+	}
+	switch {
+	case s.track < len(s.queue):
+		s.track++
+	case s.repeats() && len(s.queue) > 0:
+		s.track = 1
+	default:
+		return nil, errUPnP("711", "Illegal seek target")
+	}
+	s.relTime = zeroTime
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avPrevious(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if !s.usesQueue() {
+		return nil, errUPnP("701", "Transition not available")
+	}
+	switch {
+	case s.track > 1:
+		s.track--
+	case s.repeats() && len(s.queue) > 0:
+		s.track = len(s.queue)
+	default:
+		return nil, errUPnP("711", "Illegal seek target")
+	}
+	s.relTime = zeroTime
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avSeek(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	target := args["Target"]
+	switch args["Unit"] {
+	case "REL_TIME":
+		if s.avURI == "" || isStreamURI(s.avURI) {
+			return nil, errUPnP("701", "Transition not available")
+		}
+		secs, ok := parseHMS(target)
+		if !ok {
+			return nil, errUPnP("711", "Illegal seek target")
+		}
+		if cur, ok := s.currentTrackLocked(); ok {
+			if dur, ok := parseHMS(cur.Duration); ok && dur > 0 && secs > dur {
+				return nil, errUPnP("711", "Illegal seek target")
+			}
+		}
+		s.relTime = formatHMS(secs)
+	case "TRACK_NR":
+		if !s.usesQueue() {
+			return nil, errUPnP("701", "Transition not available")
+		}
+		n, err := strconv.Atoi(target)
+		if err != nil || n < 1 || n > len(s.queue) {
+			return nil, errUPnP("711", "Illegal seek target")
+		}
+		s.track = n
+		s.relTime = zeroTime
+	default:
+		return nil, errUPnP("710", "Seek mode not supported")
+	}
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avAddURIToQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	tracks := []Track{trackFromMeta(args["EnqueuedURI"], args["EnqueuedURIMetaData"])}
+	if id, ok := strings.CutPrefix(args["EnqueuedURI"], playlistURIPrefix); ok {
+		p, err := s.h.playlistLocked("SQ:" + id)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append([]Track(nil), p.Tracks...)
+	}
+	if lib, ok, err := s.libraryTracksLocked(args["EnqueuedURI"]); ok {
+		if err != nil {
+			return nil, err
+		}
+		tracks = lib
+	}
+	pos := s.insertQueueTracksLocked(tracks, args)
+	return map[string]string{
+		"FirstTrackNumberEnqueued": strconv.Itoa(pos),
+		"NumTracksAdded":           strconv.Itoa(len(tracks)),
+		"NewQueueLength":           strconv.Itoa(len(s.queue)),
+	}, nil
+}
+
+// insertQueueTracksLocked inserts tracks where the Desired/EnqueueAsNext
+// arguments ask for them and returns the first track's position.
+func (s *Speaker) insertQueueTracksLocked(tracks []Track, args map[string]string) int {
+	desired, _ := strconv.Atoi(args["DesiredFirstTrackNumberEnqueued"])
+	if args["EnqueueAsNext"] == "1" && desired == 0 && s.track > 0 {
+		desired = s.track + 1
+	}
+	pos := len(s.queue) + 1
+	if desired > 0 && desired <= len(s.queue) {
+		pos = desired
+	}
+	s.queue = append(s.queue[:pos-1], append(tracks, s.queue[pos-1:]...)...)
+	s.queueUpdateID++
+	if s.usesQueue() && s.track >= pos && len(s.queue) > len(tracks) {
+		s.track += len(tracks)
+	}
+	s.notifyLocked(serviceQueue)
+	return pos
+}
+
+func avRemoveTrackFromQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.checkQueueUpdateIDLocked(args); err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(args["ObjectID"], "Q:0/"))
+	if err != nil || n < 1 || n > len(s.queue) {
+		return nil, errUPnP("701", "No such object")
+	}
+	s.queue = append(s.queue[:n-1], s.queue[n:]...)
+	s.queueUpdateID++
+	if s.usesQueue() && s.track > n {
+		s.track--
+	}
+	if s.track > len(s.queue) {
+		s.track = len(s.queue)
+	}
+	s.notifyLocked(serviceQueue)
+	return nil, nil
+}
+
+func avRemoveAllTracksFromQueue(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	s.queue = nil
+	s.queueUpdateID++
+	if s.usesQueue() {
+		s.track = 0
+		s.relTime = zeroTime
+		s.transportState = stateStopped
+		s.notifyLocked(serviceAVTransport)
+	}
+	s.notifyLocked(serviceQueue)
+	return nil, nil
+}
+
+func (s *Speaker) currentTrackLocked() (Track, bool) {
+	if s.usesQueue() {
+		if s.track < 1 || s.track > len(s.queue) {
+			return Track{}, false
+		}
+		return s.queue[s.track-1], true
+	}
+	if s.avURI == "" {
+		return Track{}, false
+	}
+	t := trackFromMeta(s.avURI, s.avMeta)
+	t.Meta = s.avMeta
+	return t, true
+}
+
+func avGetPositionInfo(s *Speaker, _ map[string]string) (map[string]string, error) {
+	c := s.coordinatorLocked()
+	out := map[string]string{
+		"Track":         "0",
+		"TrackDuration": zeroTime,
+		"TrackMetaData": "",
+		"TrackURI":      "",
+		"RelTime":       c.relTime,
+		"AbsTime":       "NOT_IMPLEMENTED",
+		"RelCount":      "2147483647",
+		"AbsCount":      "2147483647",
+	}
+	if t, ok := c.currentTrackLocked(); ok {
+		out["Track"] = strconv.Itoa(max(c.track, 1))
+		if t.Duration != "" {
+			out["TrackDuration"] = t.Duration
+		}
+		out["TrackURI"] = t.URI
+		if t.Meta != "" {
+			out["TrackMetaData"] = t.Meta
+		} else {
+			out["TrackMetaData"] = trackDIDL("-1", "-1", t)
+		}
+	}
+	return out, nil
+}
+
+func avGetTransportInfo(s *Speaker, _ map[string]string) (map[string]string, error) {
+	c := s.coordinatorLocked()
+	return map[string]string{
+		"CurrentTransportState":  c.transportState,
+		"CurrentTransportStatus": "OK",
+		"CurrentSpeed":           "1",
+	}, nil
+}
+
+func avGetTransportSettings(s *Speaker, _ map[string]string) (map[string]string, error) {
+	c := s.coordinatorLocked()
+	return map[string]string{
+		"PlayMode":       c.playMode,
+		"RecQualityMode": "NOT_IMPLEMENTED",
+	}, nil
+}
+
+func avSetPlayMode(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	switch mode := args["NewPlayMode"]; mode {
+	case "NORMAL", "SHUFFLE", "SHUFFLE_NOREPEAT", "REPEAT_ALL", "REPEAT_ONE", "SHUFFLE_REPEAT_ONE":
+		s.playMode = mode
+	default:
+		return nil, errUPnP("712", "Play mode not supported")
+	}
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+func avGetCrossfadeMode(s *Speaker, _ map[string]string) (map[string]string, error) {
+	mode := "0"
+	if s.coordinatorLocked().crossfade {
+		mode = "1"
+	}
+	return map[string]string{"CrossfadeMode": mode}, nil
+}
+
+func avSetCrossfadeMode(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	switch args["CrossfadeMode"] {
+	case "0", "1":
+		s.crossfade = args["CrossfadeMode"] == "1"
+	default:
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	s.notifyLocked(serviceAVTransport)
+	return nil, nil
+}
+
+// avGetCurrentTransportActions mirrors what real players report: streams can
+// only be started and stopped, queues can also be navigated and scrubbed.
+func avGetCurrentTransportActions(s *Speaker, _ map[string]string) (map[string]string, error) {
+	c := s.coordinatorLocked()
+	actions := "Set"
+	switch {
+	case c.usesQueue() && len(c.queue) > 0:
+		actions = "Set, Stop, Pause, Play, X_DLNA_SeekTime, Next, Previous, X_DLNA_SeekTrackNr"
+	case isStreamURI(c.avURI):
+		actions = "Set, Stop, Play"
+	case c.avURI != "":
+		actions = "Set, Stop, Pause, Play, X_DLNA_SeekTime"
+	}
+	return map[string]string{"Actions": actions}, nil
+}
+
+func avGetMediaInfo(s *Speaker, _ map[string]string) (map[string]string, error) {
+	c := s.coordinatorLocked()
+	n := 0
+	switch {
+	case c.usesQueue():
+		n = len(c.queue)
+	case c.avURI != "":
+		n = 1
+	}
+	return map[string]string{
+		"NrTracks":           strconv.Itoa(n),
+		"MediaDuration":      "NOT_IMPLEMENTED",
+		"CurrentURI":         s.avURI,
+		"CurrentURIMetaData": s.avMeta,
+		"NextURI":            "",
+		"NextURIMetaData":    "",
+		"PlayMedium":         "NETWORK",
+		"RecordMedium":       "NOT_IMPLEMENTED",
+		"WriteStatus":        "NOT_IMPLEMENTED",
+	}, nil
+}
+
+func avBecomeCoordinatorOfStandaloneGroup(s *Speaker, _ map[string]string) (map[string]string, error) {
+	s.h.leaveLocked(s)
+	return map[string]string{
+		"DelegatedGroupCoordinatorID": "",
+		"NewGroupID":                  s.UUID + ":1",
+	}, nil
+}
+
+func parseHMS(v string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(v), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	total := 0
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return total, true
+}
+
+func formatHMS(secs int) string {
+	return fmt.Sprintf("%d:%02d:%02d", secs/3600, (secs/60)%60, secs%60)
+}
@@ -0,0 +1,44 @@
+package sonostest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Battery is the state a portable speaker (Roam, Move) reports on
+// /status/batterystatus.
+type Battery struct {
+	Level       int
+	PowerSource string // BATTERY, SONOS_CHARGING_RING or USB_POWER
+	Temperature string // NORMAL, ...
+	Health      string // GREEN, ...
+}
+
+// SetBattery makes the speaker a portable with the given battery state.
+// Speakers without one answer the battery page with an empty document like
+// mains-powered players do, or with 403 once status pages are disabled.
+func (s *Speaker) SetBattery(b Battery) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.battery = &b
+}
+
+func (s *Speaker) serveBatteryStatus(w http.ResponseWriter) {
+	s.h.mu.Lock()
+	b, disabled := s.battery, s.noStatusPages
+	s.h.mu.Unlock()
+	if b == nil && disabled {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	if b == nil {
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" ?><ZPSupportInfo></ZPSupportInfo>`)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" ?><ZPSupportInfo><LocalBatteryStatus>`+
+		`<Data name="Health">%s</Data><Data name="Level">%d</Data>`+
+		`<Data name="Temperature">%s</Data><Data name="PowerSource">%s</Data>`+
+		`</LocalBatteryStatus></ZPSupportInfo>`,
+		xmlEscape(b.Health), b.Level, xmlEscape(b.Temperature), xmlEscape(b.PowerSource))
+}
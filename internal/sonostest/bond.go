@@ -0,0 +1,191 @@
+package sonostest
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	bondStereo      = "stereo"
+	bondHomeTheater = "ht"
+)
+
+type channelEntry struct {
+	uuid, channels string
+}
+
+func parseChannelMap(v string) ([]channelEntry, bool) {
+	var out []channelEntry
+	for _, part := range strings.Split(v, ";") {
+		uuid, channels, ok := strings.Cut(part, ":")
+		if !ok || uuid == "" || channels == "" {
+			return nil, false
+		}
+		out = append(out, channelEntry{uuid, channels})
+	}
+	return out, len(out) > 0
+}
+
+// writeZoneGroupMemberLocked renders m and, for bonded rooms, its secondary
+// devices the way real players do: a stereo pair's right speaker as an
+// invisible member carrying the same ChannelMapSet, home-theater satellites
+// nested as <Satellite> elements.
+func (h *Household) writeZoneGroupMemberLocked(b *strings.Builder, m *Speaker) {
+	var stereo, satellites []*Speaker
+	for _, s := range h.speakers {
+		if s.bondedTo != m.UUID {
+			continue
+		}
+		if s.bondKind == bondStereo {
+			stereo = append(stereo, s)
+		} else {
+			satellites = append(satellites, s)
+		}
+	}
+	attrs := func(s *Speaker, invisible bool) string {
+		inv := "0"
+		if invisible {
+			inv = "1"
+		}
+		out := fmt.Sprintf(`UUID="%s" Location="%s" ZoneName="%s" Invisible="%s"`, s.UUID, s.location(), xmlEscape(s.Name), inv)
+		out += memberDetailAttrs(s)
+		switch {
+		case len(stereo) > 0:
+			out += ` ChannelMapSet="` + xmlEscape(h.channelMapLocked(m, stereo)) + `"`
+		case len(satellites) > 0:
+			out += ` HTSatChanMapSet="` + xmlEscape(h.channelMapLocked(m, satellites)) + `"`
+		}
+		return out
+	}
+
+	if len(satellites) == 0 {
+		fmt.Fprintf(b, `<ZoneGroupMember %s/>`, attrs(m, false))
+	} else {
+		fmt.Fprintf(b, `<ZoneGroupMember %s>`, attrs(m, false))
+		for _, s := range satellites {
+			fmt.Fprintf(b, `<Satellite %s/>`, attrs(s, true))
+		}
+		b.WriteString(`</ZoneGroupMember>`)
+	}
+	for _, s := range stereo {
+		fmt.Fprintf(b, `<ZoneGroupMember %s/>`, attrs(s, true))
+	}
+}
+
+func (h *Household) channelMapLocked(primary *Speaker, secondaries []*Speaker) string {
+	parts := []string{primary.UUID + ":" + primary.channels}
+	for _, s := range secondaries {
+		parts = append(parts, s.UUID+":"+s.channels)
+	}
+	return strings.Join(parts, ";")
+}
+
+// bondLocked folds s into primary's room.
+func (h *Household) bondLocked(primary, s *Speaker, kind, channels string) {
+	h.leaveLocked(s)
+	s.bondKind = kind
+	s.bondedTo = primary.UUID
+	s.channels = channels
+	s.unbondedName = s.Name
+	s.Name = primary.Name
+}
+
+func (h *Household) unbondLocked(s *Speaker) {
+	s.bondKind = ""
+	s.bondedTo = ""
+	s.channels = ""
+	if s.unbondedName != "" {
+		s.Name = s.unbondedName
+	}
+	s.unbondedName = ""
+}
+
+// bondableLocked checks that uuid names a standalone, unbonded speaker other
+// than primary.
+func (h *Household) bondableLocked(primary *Speaker, uuid string) (*Speaker, error) {
+	s := h.byUUIDLocked(uuid)
+	if s == nil || s == primary || s.bondedTo != "" || s.bondKind != "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	return s, nil
+}
+
+func dpCreateStereoPair(s *Speaker, args map[string]string) (map[string]string, error) {
+	entries, ok := parseChannelMap(args["ChannelMapSet"])
+	if !ok || len(entries) != 2 || entries[0].uuid != s.UUID || s.bondKind != "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	right, err := s.h.bondableLocked(s, entries[1].uuid)
+	if err != nil {
+		return nil, err
+	}
+	s.bondKind = bondStereo
+	s.channels = entries[0].channels
+	s.h.bondLocked(s, right, bondStereo, entries[1].channels)
+	s.h.notifyTopologyLocked()
+	return nil, nil
+}
+
+func dpSeparateStereoPair(s *Speaker, args map[string]string) (map[string]string, error) {
+	if s.bondKind != bondStereo || s.bondedTo != "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	for _, o := range s.h.speakers {
+		if o.bondedTo == s.UUID {
+			s.h.unbondLocked(o)
+		}
+	}
+	s.bondKind = ""
+	s.channels = ""
+	s.h.notifyTopologyLocked()
+	return nil, nil
+}
+
+func dpAddHTSatellite(s *Speaker, args map[string]string) (map[string]string, error) {
+	entries, ok := parseChannelMap(args["HTSatChanMapSet"])
+	if !ok || entries[0].uuid != s.UUID || s.bondKind == bondStereo || s.bondedTo != "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	type attach struct {
+		sat      *Speaker
+		channels string
+	}
+	var attaches []attach
+	for _, e := range entries[1:] {
+		if o := s.h.byUUIDLocked(e.uuid); o != nil && o.bondedTo == s.UUID {
+			continue // already attached
+		}
+		sat, err := s.h.bondableLocked(s, e.uuid)
+		if err != nil {
+			return nil, err
+		}
+		attaches = append(attaches, attach{sat, e.channels})
+	}
+	s.bondKind = bondHomeTheater
+	s.channels = entries[0].channels
+	for _, a := range attaches {
+		s.h.bondLocked(s, a.sat, bondHomeTheater, a.channels)
+	}
+	s.h.notifyTopologyLocked()
+	return nil, nil
+}
+
+func dpRemoveHTSatellite(s *Speaker, args map[string]string) (map[string]string, error) {
+	sat := s.h.byUUIDLocked(args["SatRoomUUID"])
+	if sat == nil || sat.bondedTo != s.UUID || sat.bondKind != bondHomeTheater {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	s.h.unbondLocked(sat)
+	remaining := false
+	for _, o := range s.h.speakers {
+		if o.bondedTo == s.UUID {
+			remaining = true
+		}
+	}
+	if !remaining {
+		s.bondKind = ""
+		s.channels = ""
+	}
+	s.h.notifyTopologyLocked()
+	return nil, nil
+}
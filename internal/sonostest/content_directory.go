@@ -0,0 +1,206 @@
+package sonostest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var contentDirectoryService = &soapService{
+	name: "ContentDirectory",
+	urn:  "urn:schemas-upnp-org:service:ContentDirectory:1",
+	actions: map[string]actionHandler{
+		"Browse":        cdBrowse,
+		"DestroyObject": cdDestroyObject,
+	},
+}
+
+var zoneGroupTopologyService = &soapService{
+	name: "ZoneGroupTopology",
+	urn:  "urn:schemas-upnp-org:service:ZoneGroupTopology:1",
+	actions: map[string]actionHandler{
+		"GetZoneGroupState": func(s *Speaker, _ map[string]string) (map[string]string, error) {
+			return map[string]string{"ZoneGroupState": s.h.zoneGroupStateLocked()}, nil
+		},
+	},
+}
+
+var devicePropertiesService = &soapService{
+	name: "DeviceProperties",
+	urn:  "urn:schemas-upnp-org:service:DeviceProperties:1",
+	actions: map[string]actionHandler{
+		"GetHouseholdID": func(s *Speaker, _ map[string]string) (map[string]string, error) {
+			return map[string]string{"CurrentHouseholdID": s.h.ID}, nil
+		},
+		"GetZoneInfo":            dpGetZoneInfo,
+		"GetZoneAttributes":      dpGetZoneAttributes,
+		"SetZoneAttributes":      dpSetZoneAttributes,
+		"GetLEDState":            dpGetLEDState,
+		"SetLEDState":            dpSetLEDState,
+		"GetButtonLockState":     dpGetButtonLockState,
+		"SetButtonLockState":     dpSetButtonLockState,
+		"GetAutoplayRoomUUID":    dpGetAutoplayRoomUUID,
+		"SetAutoplayRoomUUID":    dpSetAutoplayRoomUUID,
+		"GetAutoplayLinkedZones": dpGetAutoplayLinkedZones,
+		"SetAutoplayLinkedZones": dpSetAutoplayLinkedZones,
+		"GetUseAutoplayVolume":   dpGetUseAutoplayVolume,
+		"SetUseAutoplayVolume":   dpSetUseAutoplayVolume,
+		"GetAutoplayVolume":      dpGetAutoplayVolume,
+		"SetAutoplayVolume":      dpSetAutoplayVolume,
+		"CreateStereoPair":       dpCreateStereoPair,
+		"SeparateStereoPair":     dpSeparateStereoPair,
+		"AddHTSatellite":         dpAddHTSatellite,
+		"RemoveHTSatellite":      dpRemoveHTSatellite,
+	},
+}
+
+const didlHeader = `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">`
+
+func cdBrowse(s *Speaker, args map[string]string) (map[string]string, error) {
+	start, _ := strconv.Atoi(args["StartingIndex"])
+	count, _ := strconv.Atoi(args["RequestedCount"])
+	if start < 0 {
+		start = 0
+	}
+
+	var entries []string
+	updateID := 0
+	switch id := args["ObjectID"]; id {
+	case "Q:0":
+		c := s.coordinatorLocked()
+		for i, t := range c.queue {
+			entries = append(entries, trackDIDLItem("Q:0/"+strconv.Itoa(i+1), "Q:0", t))
+		}
+		updateID = c.queueUpdateID
+	case "FV:2":
+		for i, f := range s.h.favorites {
+			entries = append(entries, favoriteDIDLItem("FV:2/"+strconv.Itoa(i+1), f))
+		}
+	case "SQ:":
+		for _, p := range s.h.playlists {
+			entries = append(entries, playlistDIDLItem(p))
+		}
+	default:
+		if strings.HasPrefix(id, "A:") {
+			var err error
+			if entries, err = s.libraryEntriesLocked(id); err != nil {
+				return nil, err
+			}
+			break
+		}
+		p, err := s.h.playlistLocked(id)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range p.Tracks {
+			entries = append(entries, trackDIDLItem(id+"/"+strconv.Itoa(i+1), id, t))
+		}
+		updateID = p.UpdateID
+	}
+
+	total := len(entries)
+	if start > total {
+		start = total
+	}
+	end := total
+	if count > 0 && start+count < end {
+		end = start + count
+	}
+	page := entries[start:end]
+	return map[string]string{
+		"Result":         didlHeader + strings.Join(page, "") + "</DIDL-Lite>",
+		"NumberReturned": strconv.Itoa(len(page)),
+		"TotalMatches":   strconv.Itoa(total),
+		"UpdateID":       strconv.Itoa(updateID),
+	}, nil
+}
+
+func trackDIDL(id, parentID string, t Track) string {
+	return didlHeader + trackDIDLItem(id, parentID, t) + "</DIDL-Lite>"
+}
+
+func trackDIDLItem(id, parentID string, t Track) string {
+	var b strings.Builder
+	b.WriteString(`<item id="` + xmlEscape(id) + `" parentID="` + xmlEscape(parentID) + `" restricted="true">`)
+	b.WriteString(`<res protocolInfo="http-get:*:audio/mpeg:*"`)
+	if t.Duration != "" {
+		b.WriteString(` duration="` + xmlEscape(t.Duration) + `"`)
+	}
+	b.WriteString(`>` + xmlEscape(t.URI) + `</res>`)
+	b.WriteString(`<dc:title>` + xmlEscape(t.Title) + `</dc:title>`)
+	b.WriteString(`<upnp:class>object.item.audioItem.musicTrack</upnp:class>`)
+	if t.Artist != "" {
+		b.WriteString(`<dc:creator>` + xmlEscape(t.Artist) + `</dc:creator>`)
+	}
+	if t.Album != "" {
+		b.WriteString(`<upnp:album>` + xmlEscape(t.Album) + `</upnp:album>`)
+	}
+	b.WriteString(`</item>`)
+	return b.String()
+}
+
+func favoriteDIDLItem(id string, f Favorite) string {
+	var b strings.Builder
+	b.WriteString(`<item id="` + xmlEscape(id) + `" parentID="FV:2" restricted="false">`)
+	b.WriteString(`<dc:title>` + xmlEscape(f.Title) + `</dc:title>`)
+	b.WriteString(`<upnp:class>object.itemobject.item.sonos-favorite</upnp:class>`)
+	if f.URI != "" {
+		b.WriteString(`<res protocolInfo="http-get:*:audio/mpeg:*">` + xmlEscape(f.URI) + `</res>`)
+	}
+	if f.Meta != "" {
+		b.WriteString(`<r:resMD>` + xmlEscape(f.Meta) + `</r:resMD>`)
+	}
+	b.WriteString(`</item>`)
+	return b.String()
+}
+
+// trackFromMeta builds a Track from a URI plus optional DIDL-Lite metadata.
+func trackFromMeta(uri, meta string) Track {
+	t := Track{URI: uri, Meta: meta}
+	dec := xml.NewDecoder(bytes.NewReader([]byte(meta)))
+	var current string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF || err != nil {
+			break
+		}
+		switch v := tok.(type) {
+		case xml.StartElement:
+			current = v.Name.Local
+			if current == "res" {
+				for _, a := range v.Attr {
+					if a.Name.Local == "duration" {
+						t.Duration = a.Value
+					}
+				}
+			}
+		case xml.EndElement:
+			current = ""
+		case xml.CharData:
+			val := strings.TrimSpace(string(v))
+			if val == "" {
+				continue
+			}
+			switch current {
+			case "title":
+				if t.Title == "" {
+					t.Title = val
+				}
+			case "creator", "artist":
+				if t.Artist == "" {
+					t.Artist = val
+				}
+			case "album":
+				if t.Album == "" {
+					t.Album = val
+				}
+			}
+		}
+	}
+	if t.Title == "" {
+		t.Title = uri
+	}
+	return t
+}
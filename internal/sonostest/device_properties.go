@@ -0,0 +1,228 @@
+package sonostest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fakeSoftwareVersion = "85.0-64110"
+	fakeHardwareVersion = "1.20.1.6-2.0"
+	// fakeUptime is added to the time since the speaker was created so that
+	// /status/proc/uptime looks like a speaker that has been on for a while.
+	fakeUptime = 3*24*time.Hour + 4*time.Hour
+)
+
+// macAddress derives a stable MAC from the UUID (RINCON_<MAC>01400).
+func (s *Speaker) macAddress() string {
+	hex := strings.TrimSuffix(strings.TrimPrefix(s.UUID, "RINCON_"), "01400")
+	if len(hex) != 12 {
+		return "00:0E:58:00:00:00"
+	}
+	parts := make([]string, 0, 6)
+	for i := 0; i < 12; i += 2 {
+		parts = append(parts, hex[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+func (s *Speaker) serialNumber() string {
+	return strings.ReplaceAll(s.macAddress(), ":", "-") + ":A"
+}
+
+// SetStatusPages controls whether the /status diagnostic pages are served;
+// current firmware answers most of them with 403.
+func (s *Speaker) SetStatusPages(enabled bool) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.noStatusPages = !enabled
+}
+
+func dpGetZoneInfo(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{
+		"SerialNumber":           s.serialNumber(),
+		"SoftwareVersion":        s.softwareVersion,
+		"DisplaySoftwareVersion": "16.3",
+		"HardwareVersion":        fakeHardwareVersion,
+		"IPAddress":              s.IP,
+		"MACAddress":             s.macAddress(),
+		"CopyrightInfo":          "© 2004-2024 Sonos, Inc. All Rights Reserved.",
+		"ExtraInfo":              "",
+		"HTAudioIn":              "0",
+		"Flags":                  "0",
+	}, nil
+}
+
+// autoplayState is one source's DeviceProperties autoplay configuration.
+type autoplayState struct {
+	roomUUID  string
+	linked    bool
+	useVolume bool
+	volume    int
+}
+
+func dpSetZoneAttributes(s *Speaker, args map[string]string) (map[string]string, error) {
+	name := strings.TrimSpace(args["DesiredZoneName"])
+	if name == "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	s.Name = name
+	s.notifyLocked(serviceDeviceProperties)
+	s.h.notifyTopologyLocked()
+	return nil, nil
+}
+
+func dpGetLEDState(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{"CurrentLEDState": onOff(!s.ledOff)}, nil
+}
+
+func dpSetLEDState(s *Speaker, args map[string]string) (map[string]string, error) {
+	on, err := parseOnOff(args["DesiredLEDState"])
+	if err != nil {
+		return nil, err
+	}
+	s.ledOff = !on
+	return nil, nil
+}
+
+func dpGetButtonLockState(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{"CurrentButtonLockState": onOff(s.buttonsLocked)}, nil
+}
+
+func dpSetButtonLockState(s *Speaker, args map[string]string) (map[string]string, error) {
+	locked, err := parseOnOff(args["DesiredButtonLockState"])
+	if err != nil {
+		return nil, err
+	}
+	s.buttonsLocked = locked
+	return nil, nil
+}
+
+func dpGetAutoplayRoomUUID(s *Speaker, args map[string]string) (map[string]string, error) {
+	return map[string]string{"RoomUUID": s.autoplay[args["Source"]].roomUUID}, nil
+}
+
+func dpSetAutoplayRoomUUID(s *Speaker, args map[string]string) (map[string]string, error) {
+	return s.updateAutoplay(args, func(a *autoplayState) error {
+		a.roomUUID = args["RoomUUID"]
+		return nil
+	})
+}
+
+func dpGetAutoplayLinkedZones(s *Speaker, args map[string]string) (map[string]string, error) {
+	return map[string]string{"IncludeLinkedZones": boolString(s.autoplay[args["Source"]].linked)}, nil
+}
+
+func dpSetAutoplayLinkedZones(s *Speaker, args map[string]string) (map[string]string, error) {
+	return s.updateAutoplay(args, func(a *autoplayState) error {
+		a.linked = args["IncludeLinkedZones"] == "1"
+		return nil
+	})
+}
+
+func dpGetUseAutoplayVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	return map[string]string{"UseVolume": boolString(s.autoplay[args["Source"]].useVolume)}, nil
+}
+
+func dpSetUseAutoplayVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	return s.updateAutoplay(args, func(a *autoplayState) error {
+		a.useVolume = args["UseVolume"] == "1"
+		return nil
+	})
+}
+
+func dpGetAutoplayVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	return map[string]string{"CurrentVolume": strconv.Itoa(s.autoplay[args["Source"]].volume)}, nil
+}
+
+func dpSetAutoplayVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	return s.updateAutoplay(args, func(a *autoplayState) error {
+		v, err := strconv.Atoi(args["Volume"])
+		if err != nil || v < 0 || v > 100 {
+			return errUPnP("402", "Invalid Args")
+		}
+		a.volume = v
+		return nil
+	})
+}
+
+func (s *Speaker) updateAutoplay(args map[string]string, update func(*autoplayState) error) (map[string]string, error) {
+	source := args["Source"]
+	if source == "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	a := s.autoplay[source]
+	if err := update(&a); err != nil {
+		return nil, err
+	}
+	s.autoplay[source] = a
+	return nil, nil
+}
+
+func onOff(v bool) string {
+	if v {
+		return "On"
+	}
+	return "Off"
+}
+
+func parseOnOff(v string) (bool, error) {
+	switch v {
+	case "On":
+		return true, nil
+	case "Off":
+		return false, nil
+	}
+	return false, errUPnP("402", "Invalid Args")
+}
+
+func dpGetZoneAttributes(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{
+		"CurrentZoneName":       s.Name,
+		"CurrentIcon":           "x-rincon-roomicon:living",
+		"CurrentConfiguration":  "1",
+		"CurrentTargetRoomName": s.Name,
+	}, nil
+}
+
+func (s *Speaker) serveStatusPage(w http.ResponseWriter, r *http.Request) {
+	// Unlike the other diagnostic pages, current firmware still serves the
+	// battery status on portables.
+	if r.URL.Path == "/status/batterystatus" {
+		s.serveBatteryStatus(w)
+		return
+	}
+	s.h.mu.Lock()
+	disabled := s.noStatusPages
+	uptime := fakeUptime + time.Since(s.started)
+	s.h.mu.Unlock()
+	if disabled {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var cmdline, text string
+	switch r.URL.Path {
+	case "/status/proc/uptime":
+		cmdline = "/bin/cat /proc/uptime"
+		text = fmt.Sprintf("%.2f %.2f", uptime.Seconds(), uptime.Seconds()*0.9)
+	case "/status/ifconfig":
+		cmdline = "/sbin/ifconfig"
+		text = fmt.Sprintf("br0       Link encap:Ethernet  HWaddr %s\n"+
+			"          inet addr:%s  Bcast:%s  Mask:255.255.255.0\n"+
+			"          UP BROADCAST RUNNING MULTICAST  MTU:1500  Metric:1\n\n"+
+			"lo        Link encap:Local Loopback\n"+
+			"          inet addr:127.0.0.1  Mask:255.0.0.0\n"+
+			"          UP LOOPBACK RUNNING  MTU:65536  Metric:1\n",
+			s.macAddress(), s.IP, s.IP)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" ?><ZPSupportInfo><Command cmdline="%s">%s</Command></ZPSupportInfo>`,
+		xmlEscape(cmdline), xmlEscape(text))
+}
@@ -0,0 +1,101 @@
+package sonostest
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// homeTheaterEQTypes are the GetEQ/SetEQ types soundbars accept; other
+// models answer 402 like real speakers do.
+var homeTheaterEQTypes = []string{"NightMode", "DialogLevel", "SubEnable", "SubGain", "SurroundEnable", "SurroundLevel"}
+
+func (s *Speaker) eqTypesLocked() []string {
+	model := strings.ToLower(s.Model)
+	switch {
+	case strings.Contains(model, "arc"):
+		return append(slices.Clone(homeTheaterEQTypes), "HeightChannelLevel")
+	case strings.Contains(model, "beam"), strings.Contains(model, "ray"), strings.Contains(model, "playbar"), strings.Contains(model, "playbase"), strings.Contains(model, "amp"):
+		return homeTheaterEQTypes
+	}
+	return nil
+}
+
+func eqRangeArg(args map[string]string, key string, min, max int) (int, error) {
+	v, err := strconv.Atoi(args[key])
+	if err != nil || v < min || v > max {
+		return 0, errUPnP("402", "Invalid Args")
+	}
+	return v, nil
+}
+
+func (s *Speaker) setEQLocked(key string, v int) {
+	s.eq[key] = v
+	s.notifyLocked(serviceRenderingControl)
+}
+
+func rcGetBass(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{"CurrentBass": strconv.Itoa(s.eq["Bass"])}, nil
+}
+
+func rcSetBass(s *Speaker, args map[string]string) (map[string]string, error) {
+	v, err := eqRangeArg(args, "DesiredBass", -10, 10)
+	if err != nil {
+		return nil, err
+	}
+	s.setEQLocked("Bass", v)
+	return nil, nil
+}
+
+func rcGetTreble(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return map[string]string{"CurrentTreble": strconv.Itoa(s.eq["Treble"])}, nil
+}
+
+func rcSetTreble(s *Speaker, args map[string]string) (map[string]string, error) {
+	v, err := eqRangeArg(args, "DesiredTreble", -10, 10)
+	if err != nil {
+		return nil, err
+	}
+	s.setEQLocked("Treble", v)
+	return nil, nil
+}
+
+func rcGetLoudness(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	return map[string]string{"CurrentLoudness": strconv.Itoa(s.eq["Loudness"])}, nil
+}
+
+func rcSetLoudness(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	v, err := eqRangeArg(args, "DesiredLoudness", 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	s.setEQLocked("Loudness", v)
+	return nil, nil
+}
+
+func rcGetEQ(s *Speaker, args map[string]string) (map[string]string, error) {
+	eqType := args["EQType"]
+	if !slices.Contains(s.eqTypesLocked(), eqType) {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	return map[string]string{"CurrentValue": strconv.Itoa(s.eq[eqType])}, nil
+}
+
+func rcSetEQ(s *Speaker, args map[string]string) (map[string]string, error) {
+	eqType := args["EQType"]
+	if !slices.Contains(s.eqTypesLocked(), eqType) {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	v, err := eqRangeArg(args, "DesiredValue", -15, 15)
+	if err != nil {
+		return nil, err
+	}
+	s.setEQLocked(eqType, v)
+	return nil, nil
+}
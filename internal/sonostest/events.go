@@ -0,0 +1,299 @@
+package sonostest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAVTransport           = "AVTransport"
+	serviceRenderingControl      = "RenderingControl"
+	serviceGroupRenderingControl = "GroupRenderingControl"
+	serviceZoneGroupTopology     = "ZoneGroupTopology"
+	serviceContentDirectory      = "ContentDirectory"
+	serviceQueue                 = "Queue"
+	serviceAlarmClock            = "AlarmClock"
+	serviceDeviceProperties      = "DeviceProperties"
+)
+
+var eventPaths = map[string]string{
+	"/MediaRenderer/AVTransport/Event":           serviceAVTransport,
+	"/MediaRenderer/RenderingControl/Event":      serviceRenderingControl,
+	"/MediaRenderer/GroupRenderingControl/Event": serviceGroupRenderingControl,
+	"/MediaRenderer/Queue/Event":                 serviceQueue,
+	"/ZoneGroupTopology/Event":                   serviceZoneGroupTopology,
+	"/MediaServer/ContentDirectory/Event":        serviceContentDirectory,
+	"/AlarmClock/Event":                          serviceAlarmClock,
+	"/DeviceProperties/Event":                    serviceDeviceProperties,
+}
+
+const defaultSubscriptionTimeout = 1800 * time.Second
+
+type subscription struct {
+	sid      string
+	service  string
+	callback string
+	timeout  time.Duration
+	expires  time.Time
+	seq      uint32
+}
+
+type notification struct {
+	url  string
+	sid  string
+	seq  uint32
+	body string
+}
+
+// Subscriptions returns the services with live GENA subscriptions, sorted.
+func (s *Speaker) Subscriptions() []string {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	now := time.Now()
+	var out []string
+	for _, sub := range s.subs {
+		if now.Before(sub.expires) {
+			out = append(out, sub.service)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DropSubscriptions forgets every subscription, as a speaker reboot would.
+// Later renewals are answered with 412 Precondition Failed.
+func (s *Speaker) DropSubscriptions() {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.subs = map[string]*subscription{}
+}
+
+// FailRenewals answers the next n renewals with 500 while the subscriptions
+// themselves stay alive, as a briefly overloaded speaker would.
+func (s *Speaker) FailRenewals(n int) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.failRenewals = n
+}
+
+func (s *Speaker) serveSubscribe(w http.ResponseWriter, r *http.Request) {
+	service, ok := eventPaths[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	timeout := defaultSubscriptionTimeout
+	if v := strings.TrimSpace(r.Header.Get("TIMEOUT")); v != "" {
+		if secs, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(v), "second-")); err == nil && secs > 0 {
+			timeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+
+	if sid := strings.TrimSpace(r.Header.Get("SID")); sid != "" {
+		if s.failRenewals > 0 {
+			s.failRenewals--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sub, ok := s.subs[sid]
+		if !ok || sub.service != service || time.Now().After(sub.expires) {
+			delete(s.subs, sid)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		sub.timeout = timeout
+		sub.expires = time.Now().Add(timeout)
+		w.Header().Set("SID", sid)
+		w.Header().Set("TIMEOUT", fmt.Sprintf("Second-%d", int(timeout.Seconds())))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Header.Get("NT") != "upnp:event" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	callback := strings.Trim(strings.TrimSpace(r.Header.Get("CALLBACK")), "<>")
+	if callback == "" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	sub := &subscription{
+		sid:      fmt.Sprintf("uuid:%s_sub%010d", s.UUID, time.Now().UnixNano()%1e10),
+		service:  service,
+		callback: callback,
+		timeout:  timeout,
+		expires:  time.Now().Add(timeout),
+	}
+	s.subs[sub.sid] = sub
+	w.Header().Set("SID", sub.sid)
+	w.Header().Set("TIMEOUT", fmt.Sprintf("Second-%d", int(timeout.Seconds())))
+	w.WriteHeader(http.StatusOK)
+
+	// GENA sends the initial state right after subscribing (SEQ 0).
+	s.enqueueLocked(sub, s.eventBodyLocked(service))
+}
+
+func (s *Speaker) serveUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	sid := strings.TrimSpace(r.Header.Get("SID"))
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	if _, ok := s.subs[sid]; !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	delete(s.subs, sid)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Speaker) notifyLocked(service string) {
+	now := time.Now()
+	var body string
+	for sid, sub := range s.subs {
+		if sub.service != service {
+			continue
+		}
+		if now.After(sub.expires) {
+			delete(s.subs, sid)
+			continue
+		}
+		if body == "" {
+			body = s.eventBodyLocked(service)
+		}
+		s.enqueueLocked(sub, body)
+	}
+}
+
+func (s *Speaker) enqueueLocked(sub *subscription, body string) {
+	n := notification{url: sub.callback, sid: sub.sid, seq: sub.seq, body: body}
+	sub.seq++
+	select {
+	case s.notifyCh <- n:
+	default:
+		// Drop like a congested speaker would; SEQ gaps reveal it.
+	}
+}
+
+func (s *Speaker) notifyLoop() {
+	// Callbacks are always local; a bare transport also keeps the fake from
+	// reading (and caching) the proxy environment.
+	client := &http.Client{Timeout: 2 * time.Second, Transport: &http.Transport{}}
+	for {
+		select {
+		case <-s.done:
+			return
+		case n := <-s.notifyCh:
+			req, err := http.NewRequest("NOTIFY", n.url, strings.NewReader(n.body))
+			if err != nil {
+				continue
+			}
+			req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+			req.Header.Set("NT", "upnp:event")
+			req.Header.Set("NTS", "upnp:propchange")
+			req.Header.Set("SID", n.sid)
+			req.Header.Set("SEQ", strconv.FormatUint(uint64(n.seq), 10))
+			resp, err := client.Do(req)
+			if err != nil {
+				continue
+			}
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+func (s *Speaker) eventBodyLocked(service string) string {
+	switch service {
+	case serviceAVTransport:
+		return lastChangeBody(s.avTransportLastChangeLocked())
+	case serviceRenderingControl:
+		return lastChangeBody(`<Event xmlns="urn:schemas-upnp-org:metadata-1-0/RCS/"><InstanceID val="0">` +
+			`<Volume channel="Master" val="` + strconv.Itoa(s.volume) + `"/>` +
+			`<Mute channel="Master" val="` + boolString(s.mute) + `"/>` +
+			`</InstanceID></Event>`)
+	case serviceGroupRenderingControl:
+		c := s.coordinatorLocked()
+		return propertySet(map[string]string{
+			"GroupVolume":           strconv.Itoa(c.groupVolumeLocked()),
+			"GroupMute":             boolString(c.groupMuteLocked()),
+			"GroupVolumeChangeable": "1",
+		})
+	case serviceZoneGroupTopology:
+		return propertySet(map[string]string{"ZoneGroupState": s.h.zoneGroupStateLocked()})
+	case serviceQueue:
+		return lastChangeBody(`<Event xmlns="urn:schemas-sonos-com:metadata-1-0/Queue/"><QueueID val="0">` +
+			`<UpdateID val="` + strconv.Itoa(s.queueUpdateID) + `"/>` +
+			`</QueueID></Event>`)
+	case serviceContentDirectory:
+		return propertySet(map[string]string{"ContainerUpdateIDs": "Q:0," + strconv.Itoa(s.queueUpdateID)})
+	case serviceAlarmClock:
+		return propertySet(map[string]string{"AlarmListVersion": s.h.alarmListVersionLocked()})
+	case serviceDeviceProperties:
+		return propertySet(map[string]string{"ZoneName": s.Name})
+	default:
+		return propertySet(nil)
+	}
+}
+
+func (s *Speaker) avTransportLastChangeLocked() string {
+	c := s.coordinatorLocked()
+	var b strings.Builder
+	b.WriteString(`<Event xmlns="urn:schemas-upnp-org:metadata-1-0/AVT/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/"><InstanceID val="0">`)
+	attr := func(name, val string) {
+		b.WriteString(`<` + name + ` val="` + xmlEscape(val) + `"/>`)
+	}
+	attr("TransportState", c.transportState)
+	attr("CurrentPlayMode", c.playMode)
+	crossfade := "0"
+	if c.crossfade {
+		crossfade = "1"
+	}
+	attr("CurrentCrossfadeMode", crossfade)
+	n := 0
+	if c.usesQueue() {
+		n = len(c.queue)
+	} else if c.avURI != "" {
+		n = 1
+	}
+	attr("NumberOfTracks", strconv.Itoa(n))
+	attr("CurrentTrack", strconv.Itoa(c.track))
+	attr("AVTransportURI", s.avURI)
+	t, ok := c.currentTrackLocked()
+	if ok {
+		attr("CurrentTrackURI", t.URI)
+		attr("CurrentTrackDuration", t.Duration)
+		meta := t.Meta
+		if meta == "" {
+			meta = trackDIDL("-1", "-1", t)
+		}
+		// Metadata goes last: it is the only value that nests escaped XML.
+		attr("CurrentTrackMetaData", meta)
+	}
+	b.WriteString(`</InstanceID></Event>`)
+	return b.String()
+}
+
+func lastChangeBody(inner string) string {
+	return propertySet(map[string]string{"LastChange": inner})
+}
+
+func propertySet(props map[string]string) string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0">`)
+	for _, k := range keys {
+		b.WriteString(`<e:property><` + k + `>` + xmlEscape(props[k]) + `</` + k + `></e:property>`)
+	}
+	b.WriteString(`</e:propertyset>`)
+	return b.String()
+}
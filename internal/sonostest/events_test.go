@@ -0,0 +1,88 @@
+package sonostest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+type notifyEvent struct {
+	sid  string
+	seq  string
+	vars map[string]string
+}
+
+func TestSubscribeReceivesInitialAndChangeEvents(t *testing.T) {
+	h := newHousehold(t, "Kitchen")
+	sp := h.Speaker("Kitchen")
+	c := sonos.NewClient(sp.IP, 2*time.Second)
+	ctx := context.Background()
+
+	events := make(chan notifyEvent, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		vars, err := sonos.ParseEvent(body)
+		if err != nil {
+			t.Errorf("ParseEvent: %v", err)
+		}
+		events <- notifyEvent{sid: r.Header.Get("SID"), seq: r.Header.Get("SEQ"), vars: vars}
+	}))
+	t.Cleanup(srv.Close)
+
+	sub, err := c.SubscribeAVTransport(ctx, srv.URL+"/notify", 60*time.Second)
+	if err != nil {
+		t.Fatalf("SubscribeAVTransport: %v", err)
+	}
+	if sub.Timeout != 60*time.Second {
+		t.Fatalf("timeout: %s", sub.Timeout)
+	}
+
+	next := func() notifyEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for NOTIFY")
+			return notifyEvent{}
+		}
+	}
+
+	initial := next()
+	if initial.sid != sub.SID || initial.seq != "0" || initial.vars["transport_state"] != "STOPPED" {
+		t.Fatalf("unexpected initial event: %+v", initial)
+	}
+
+	sp.SetQueue(sonostest.Track{URI: "http://example.com/a.mp3", Title: "A"})
+	if ev := next(); ev.seq != "1" || ev.vars["number_of_tracks"] != "1" {
+		t.Fatalf("unexpected queue event: %+v", ev)
+	}
+	if err := c.Play(ctx); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if ev := next(); ev.seq != "2" || ev.vars["transport_state"] != "PLAYING" {
+		t.Fatalf("unexpected play event: %+v", ev)
+	}
+
+	if _, err := c.Renew(ctx, sub, 60*time.Second); err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if got := sp.Subscriptions(); len(got) != 1 || got[0] != "AVTransport" {
+		t.Fatalf("subscriptions: %v", got)
+	}
+
+	// After a "reboot" the speaker no longer knows the SID.
+	sp.DropSubscriptions()
+	if _, err := c.Renew(ctx, sub, 60*time.Second); err == nil {
+		t.Fatalf("expected renew to fail after reboot")
+	}
+	if err := c.Unsubscribe(ctx, sub); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+}
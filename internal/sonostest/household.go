@@ -0,0 +1,300 @@
+// Package sonostest provides an in-process fake Sonos household for tests.
+//
+// Each fake speaker is a stateful ZonePlayer HTTP server bound to a random
+// loopback alias (127.x.y.z) on port 1400, so code that builds clients from a
+// bare IP (sonos.NewClient, the cli package, ...) talks to it unchanged.
+// Loopback aliases beyond 127.0.0.1 are available by default on Linux; on
+// other platforms NewHousehold returns an error and callers should skip.
+package sonostest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SeedEnv is the environment variable sonos.Discover reads for seed speaker
+// IPs (sonos.SeedIPsEnv). Tests can set it to Household.SeedIPs() so
+// name-based commands resolve against the fake household without SSDP.
+//
+// It cannot refer to sonos.SeedIPsEnv: the sonos package tests import this
+// package. TestSeedIPsEnvMatchesFakeHousehold in sonos keeps the two equal.
+const SeedEnv = "SONOSCLI_SEED_IPS"
+
+// Household is a set of fake speakers that share grouping state.
+type Household struct {
+	ID string
+
+	mu               sync.Mutex
+	speakers         []*Speaker
+	nextID           int
+	favorites        []Favorite
+	playlists        []Playlist
+	nextPlaylistID   int
+	library          []LibraryTrack
+	alarms           []Alarm
+	nextAlarmID      int
+	alarmListVersion int
+	vanished         []vanishedDevice
+}
+
+// NewHousehold starts one standalone speaker per room name.
+func NewHousehold(rooms ...string) (*Household, error) {
+	h := &Household{ID: fmt.Sprintf("Sonos_test%08x", rand.Uint32())}
+	for _, room := range rooms {
+		if _, err := h.Add(room); err != nil {
+			h.Close()
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Add starts another standalone speaker in the household.
+func (h *Household) Add(room string) (*Speaker, error) {
+	room = strings.TrimSpace(room)
+	if room == "" {
+		return nil, errors.New("sonostest: room name is required")
+	}
+	ln, ip, err := listenLoopbackAlias()
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	h.nextID++
+	id := h.nextID
+	uuid := fmt.Sprintf("RINCON_5CAAFD%06X01400", id)
+	h.mu.Unlock()
+
+	s := newSpeaker(h, room, uuid, ip)
+	s.bootSeq = 40 + id
+	s.srv = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() { _ = s.srv.Serve(ln) }()
+	go s.notifyLoop()
+
+	h.mu.Lock()
+	h.speakers = append(h.speakers, s)
+	h.notifyTopologyLocked()
+	h.mu.Unlock()
+	return s, nil
+}
+
+// Close stops every speaker server.
+func (h *Household) Close() {
+	h.mu.Lock()
+	speakers := append([]*Speaker(nil), h.speakers...)
+	h.mu.Unlock()
+	for _, s := range speakers {
+		s.close()
+	}
+}
+
+// Speaker returns the speaker with the given room name (case-insensitive).
+// For a bonded room that is its primary device.
+func (h *Household) Speaker(room string) *Speaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range h.speakers {
+		if strings.EqualFold(s.Name, room) && s.bondedTo == "" {
+			return s
+		}
+	}
+	return nil
+}
+
+// Speakers returns all speakers in creation order.
+func (h *Household) Speakers() []*Speaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*Speaker(nil), h.speakers...)
+}
+
+// SeedIPs returns a comma-separated list of speaker IPs suitable for SeedEnv.
+func (h *Household) SeedIPs() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ips := make([]string, 0, len(h.speakers))
+	for _, s := range h.speakers {
+		ips = append(ips, s.IP)
+	}
+	return strings.Join(ips, ",")
+}
+
+// Join groups member under coordinator, as the Sonos app would.
+func (h *Household) Join(member, coordinator string) error {
+	m := h.Speaker(member)
+	c := h.Speaker(coordinator)
+	if m == nil || c == nil {
+		return fmt.Errorf("sonostest: unknown room %q or %q", member, coordinator)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.joinLocked(m, c.UUID)
+}
+
+// ZoneGroupState renders the household topology as returned by
+// ZoneGroupTopology.GetZoneGroupState.
+func (h *Household) ZoneGroupState() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.zoneGroupStateLocked()
+}
+
+func (h *Household) byUUIDLocked(uuid string) *Speaker {
+	for _, s := range h.speakers {
+		if s.UUID == uuid {
+			return s
+		}
+	}
+	return nil
+}
+
+func (h *Household) membersLocked(coordinatorUUID string) []*Speaker {
+	var out []*Speaker
+	for _, s := range h.speakers {
+		if s.coordinator == coordinatorUUID && s.bondedTo == "" {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		// Coordinator first, then creation order.
+		return out[i].UUID == coordinatorUUID && out[j].UUID != coordinatorUUID
+	})
+	return out
+}
+
+func (h *Household) joinLocked(s *Speaker, coordinatorUUID string) error {
+	coord := h.byUUIDLocked(coordinatorUUID)
+	if coord == nil {
+		return errUPnP("800", "unknown coordinator")
+	}
+	if coord.coordinator != coord.UUID {
+		// Joining a member joins its group.
+		coord = h.byUUIDLocked(coord.coordinator)
+	}
+	if s.UUID == coord.UUID {
+		return nil
+	}
+	h.detachLocked(s)
+	s.coordinator = coord.UUID
+	s.transportState = stateStopped
+	s.avURI = "x-rincon:" + coord.UUID
+	s.avMeta = ""
+	h.notifyTopologyLocked()
+	s.notifyLocked(serviceAVTransport)
+	coord.notifyLocked(serviceGroupRenderingControl)
+	return nil
+}
+
+// leaveLocked makes s a standalone coordinator.
+func (h *Household) leaveLocked(s *Speaker) {
+	wasGrouped := h.detachLocked(s)
+	s.coordinator = s.UUID
+	s.transportState = stateStopped
+	s.avURI = ""
+	s.avMeta = ""
+	s.track = 0
+	s.relTime = zeroTime
+	if wasGrouped {
+		h.notifyTopologyLocked()
+	}
+	s.notifyLocked(serviceAVTransport)
+}
+
+// detachLocked removes s from its group. When s coordinated other members,
+// the first remaining member takes over playback like a real household.
+func (h *Household) detachLocked(s *Speaker) bool {
+	if s.coordinator != s.UUID {
+		prev := h.byUUIDLocked(s.coordinator)
+		s.coordinator = s.UUID
+		if prev != nil {
+			prev.notifyLocked(serviceGroupRenderingControl)
+		}
+		return true
+	}
+	members := h.membersLocked(s.UUID)
+	if len(members) <= 1 {
+		return false
+	}
+	var heir *Speaker
+	for _, m := range members {
+		if m == s {
+			continue
+		}
+		if heir == nil {
+			heir = m
+			heir.coordinator = heir.UUID
+			heir.transportState = s.transportState
+			heir.avURI = s.avURI
+			heir.avMeta = s.avMeta
+			heir.queue = append([]Track(nil), s.queue...)
+			heir.queueUpdateID++
+			heir.track = s.track
+			heir.relTime = s.relTime
+			heir.playMode = s.playMode
+			if strings.HasPrefix(heir.avURI, "x-rincon-queue:") {
+				heir.avURI = "x-rincon-queue:" + heir.UUID + "#0"
+			}
+			heir.notifyLocked(serviceAVTransport)
+			continue
+		}
+		m.coordinator = heir.UUID
+		m.avURI = "x-rincon:" + heir.UUID
+	}
+	return true
+}
+
+func (h *Household) notifyTopologyLocked() {
+	for _, s := range h.speakers {
+		s.notifyLocked(serviceZoneGroupTopology)
+	}
+}
+
+func (h *Household) zoneGroupStateLocked() string {
+	var b strings.Builder
+	b.WriteString("<ZoneGroupState><ZoneGroups>")
+	for _, coord := range h.speakers {
+		if coord.coordinator != coord.UUID || coord.bondedTo != "" {
+			continue
+		}
+		fmt.Fprintf(&b, `<ZoneGroup Coordinator="%s" ID="%s:1">`, coord.UUID, coord.UUID)
+		for _, m := range h.membersLocked(coord.UUID) {
+			h.writeZoneGroupMemberLocked(&b, m)
+		}
+		b.WriteString("</ZoneGroup>")
+	}
+	b.WriteString("</ZoneGroups>")
+	h.writeVanishedLocked(&b)
+	b.WriteString("</ZoneGroupState>")
+	return b.String()
+}
+
+func listenLoopbackAlias() (net.Listener, string, error) {
+	var lastErr error
+	for i := 0; i < 32; i++ {
+		ip := fmt.Sprintf("127.%d.%d.%d", 100+rand.IntN(150), rand.IntN(256), 1+rand.IntN(254))
+		ln, err := net.Listen("tcp", net.JoinHostPort(ip, "1400"))
+		if err == nil {
+			return ln, ip, nil
+		}
+		lastErr = err
+	}
+	return nil, "", fmt.Errorf("sonostest: listen on loopback alias port 1400: %w", lastErr)
+}
+
+func shutdown(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = srv.Shutdown(ctx)
+}
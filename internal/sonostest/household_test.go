@@ -0,0 +1,209 @@
+package sonostest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/STop211650/sonoscli/internal/sonos"
+	"github.com/STop211650/sonoscli/internal/sonostest"
+)
+
+func newHousehold(t *testing.T, rooms ...string) *sonostest.Household {
+	t.Helper()
+	h, err := sonostest.NewHousehold(rooms...)
+	if err != nil {
+		t.Skipf("fake household unavailable: %v", err)
+	}
+	t.Cleanup(h.Close)
+	return h
+}
+
+func TestHouseholdDiscoverAndGrouping(t *testing.T) {
+	h := newHousehold(t, "Kitchen", "Office")
+	ctx := context.Background()
+
+	devs, err := sonos.Discover(ctx, sonos.DiscoverOptions{
+		Timeout: 2 * time.Second,
+		SeedIPs: []string{h.Speaker("Kitchen").IP},
+	})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(devs) != 2 || devs[0].Name != "Kitchen" || devs[1].Name != "Office" {
+		t.Fatalf("unexpected devices: %+v", devs)
+	}
+
+	office := sonos.NewClient(h.Speaker("Office").IP, 2*time.Second)
+	if err := office.JoinGroup(ctx, h.Speaker("Kitchen").UUID); err != nil {
+		t.Fatalf("JoinGroup: %v", err)
+	}
+	top, err := office.GetTopology(ctx)
+	if err != nil {
+		t.Fatalf("GetTopology: %v", err)
+	}
+	if len(top.Groups) != 1 || len(top.Groups[0].Members) != 2 {
+		t.Fatalf("unexpected topology: %+v", top.Groups)
+	}
+	if ip, ok := top.CoordinatorIPForName("Office"); !ok || ip != h.Speaker("Kitchen").IP {
+		t.Fatalf("coordinator for Office: %v %q", ok, ip)
+	}
+
+	// Transport commands on a member are rejected like on real hardware.
+	var upnpErr *sonos.UPnPError
+	if err := office.Play(ctx); !errors.As(err, &upnpErr) || upnpErr.Code != "800" {
+		t.Fatalf("Play on member: %v", err)
+	}
+
+	if err := office.LeaveGroup(ctx); err != nil {
+		t.Fatalf("LeaveGroup: %v", err)
+	}
+	if got := h.Speaker("Office").State().Coordinator; got != h.Speaker("Office").UUID {
+		t.Fatalf("expected standalone, coordinator=%q", got)
+	}
+}
+
+func TestQueuePlaybackRoundTrip(t *testing.T) {
+	h := newHousehold(t, "Kitchen")
+	sp := h.Speaker("Kitchen")
+	c := sonos.NewClient(sp.IP, 2*time.Second)
+	ctx := context.Background()
+
+	for _, title := range []string{"One", "Two", "Three"} {
+		meta := `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/"><item id="x"><dc:title>` + title + `</dc:title><res duration="0:03:00">http://example.com/` + title + `.mp3</res></item></DIDL-Lite>`
+		if _, err := c.AddURIToQueue(ctx, "http://example.com/"+title+".mp3", meta, 0, false); err != nil {
+			t.Fatalf("AddURIToQueue: %v", err)
+		}
+	}
+	page, err := c.ListQueue(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListQueue: %v", err)
+	}
+	if page.TotalMatches != 3 || page.Items[1].Item.Title != "Two" || page.UpdateID == 0 {
+		t.Fatalf("unexpected queue: %+v", page)
+	}
+
+	if err := c.PlayQueuePosition(ctx, 2); err != nil {
+		t.Fatalf("PlayQueuePosition: %v", err)
+	}
+	if err := c.SeekRelTime(ctx, "0:01:30"); err != nil {
+		t.Fatalf("SeekRelTime: %v", err)
+	}
+	pos, err := c.GetPositionInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetPositionInfo: %v", err)
+	}
+	if pos.Track != "2" || pos.RelTime != "0:01:30" || pos.TrackDuration != "0:03:00" {
+		t.Fatalf("unexpected position: %+v", pos)
+	}
+	if np, ok := sonos.ParseNowPlaying(pos.TrackMeta); !ok || np.Title != "Two" {
+		t.Fatalf("unexpected now playing: %+v", np)
+	}
+
+	if err := c.Next(ctx); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if err := c.SetPlayMode(ctx, sonos.PlayModeRepeatAll); err != nil {
+		t.Fatalf("SetPlayMode: %v", err)
+	}
+	if err := c.Next(ctx); err != nil {
+		t.Fatalf("Next (wrap): %v", err)
+	}
+	if err := c.Pause(ctx); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	st := sp.State()
+	if st.Track != 1 || st.TransportState != "PAUSED_PLAYBACK" || st.PlayMode != "REPEAT_ALL" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	if err := c.RemoveQueuePosition(ctx, 3); err != nil {
+		t.Fatalf("RemoveQueuePosition: %v", err)
+	}
+	if err := c.ClearQueue(ctx); err != nil {
+		t.Fatalf("ClearQueue: %v", err)
+	}
+	if n := len(sp.State().Queue); n != 0 {
+		t.Fatalf("queue not cleared: %d", n)
+	}
+}
+
+func TestRenderingFavoritesAndFaults(t *testing.T) {
+	h := newHousehold(t, "Kitchen", "Office")
+	if err := h.Join("Office", "Kitchen"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	h.Speaker("Kitchen").SetVolume(10)
+	h.Speaker("Office").SetVolume(30)
+	c := sonos.NewClient(h.Speaker("Kitchen").IP, 2*time.Second)
+	ctx := context.Background()
+
+	if v, err := c.GetGroupVolume(ctx); err != nil || v != 20 {
+		t.Fatalf("GetGroupVolume: %d %v", v, err)
+	}
+	if err := c.SetGroupVolume(ctx, 25); err != nil {
+		t.Fatalf("SetGroupVolume: %v", err)
+	}
+	if k, o := h.Speaker("Kitchen").State().Volume, h.Speaker("Office").State().Volume; k != 15 || o != 35 {
+		t.Fatalf("unexpected member volumes: %d %d", k, o)
+	}
+	if err := c.SetMute(ctx, true); err != nil {
+		t.Fatalf("SetMute: %v", err)
+	}
+	if m, err := c.GetMute(ctx); err != nil || !m {
+		t.Fatalf("GetMute: %v %v", m, err)
+	}
+
+	h.Speaker("Kitchen").SetFavorites(sonostest.Favorite{Title: "Radio", URI: "x-rincon-mp3radio://example.com/live"})
+	favs, err := c.ListFavorites(ctx, 0, 10)
+	if err != nil || len(favs.Items) != 1 {
+		t.Fatalf("ListFavorites: %+v %v", favs, err)
+	}
+	if err := c.PlayFavorite(ctx, favs.Items[0].Item); err != nil {
+		t.Fatalf("PlayFavorite: %v", err)
+	}
+	if st := h.Speaker("Kitchen").State(); st.TransportState != "PLAYING" || st.AVTransportURI != "x-rincon-mp3radio://example.com/live" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	// Radio streams cannot seek.
+	var upnpErr *sonos.UPnPError
+	if err := c.SeekRelTime(ctx, "0:00:10"); !errors.As(err, &upnpErr) || upnpErr.Code != "701" {
+		t.Fatalf("SeekRelTime on radio: %v", err)
+	}
+
+	h.Speaker("Kitchen").SetFault("Stop", "701")
+	if err := c.StopOrNoop(ctx); err != nil {
+		t.Fatalf("StopOrNoop: %v", err)
+	}
+}
+
+func TestSleepTimerStopsPlayback(t *testing.T) {
+	h := newHousehold(t, "Bedroom")
+	sp := h.Speaker("Bedroom")
+	sp.SetQueue(sonostest.Track{URI: "http://example.com/a.mp3", Title: "A"})
+	c := sonos.NewClient(sp.IP, 2*time.Second)
+	ctx := context.Background()
+
+	if err := c.Play(ctx); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if err := c.ConfigureSleepTimer(ctx, time.Second); err != nil {
+		t.Fatalf("ConfigureSleepTimer: %v", err)
+	}
+	if d, active, err := c.GetRemainingSleepTimerDuration(ctx); err != nil || !active || d != time.Second {
+		t.Fatalf("remaining: %s %v %v", d, active, err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for sp.State().TransportState == "PLAYING" {
+		if time.Now().After(deadline) {
+			t.Fatalf("sleep timer did not stop playback")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, active, err := c.GetRemainingSleepTimerDuration(ctx); err != nil || active {
+		t.Fatalf("timer still active: %v %v", active, err)
+	}
+}
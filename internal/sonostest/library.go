@@ -0,0 +1,191 @@
+package sonostest
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LibraryTrack is a track in the fake local music library (ContentDirectory A:).
+type LibraryTrack struct {
+	URI      string
+	Title    string
+	Artist   string
+	Album    string
+	Genre    string
+	Composer string
+}
+
+// SetLibrary replaces the household's indexed music library.
+func (h *Household) SetLibrary(tracks ...LibraryTrack) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.library = append([]LibraryTrack(nil), tracks...)
+}
+
+type libraryLevel struct {
+	class string
+	key   func(LibraryTrack) string
+}
+
+var (
+	levelArtist   = libraryLevel{"object.container.person.musicArtist", func(t LibraryTrack) string { return t.Artist }}
+	levelAlbum    = libraryLevel{"object.container.album.musicAlbum", func(t LibraryTrack) string { return t.Album }}
+	levelGenre    = libraryLevel{"object.container.genre.musicGenre", func(t LibraryTrack) string { return t.Genre }}
+	levelComposer = libraryLevel{"object.container.person.composer", func(t LibraryTrack) string { return t.Composer }}
+)
+
+// libraryRoots lists the A: containers in the order Sonos returns them, with
+// the container levels below each one (tracks come after the last level).
+var libraryRoots = []struct {
+	id     string
+	title  string
+	levels []libraryLevel
+}{
+	{"ARTIST", "Artists", []libraryLevel{levelArtist, levelAlbum}},
+	{"ALBUMARTIST", "Album Artists", []libraryLevel{levelArtist, levelAlbum}},
+	{"ALBUM", "Albums", []libraryLevel{levelAlbum}},
+	{"GENRE", "Genres", []libraryLevel{levelGenre, levelArtist}},
+	{"COMPOSER", "Composers", []libraryLevel{levelComposer, levelAlbum}},
+	{"TRACKS", "Tracks", nil},
+	{"PLAYLISTS", "Imported Playlists", nil},
+}
+
+// libraryEntriesLocked returns the DIDL entries below a library object ID,
+// including "<container>:<term>" searches.
+func (s *Speaker) libraryEntriesLocked(objectID string) ([]string, error) {
+	rest := strings.TrimPrefix(objectID, "A:")
+	if rest == "" {
+		var out []string
+		for _, r := range libraryRoots {
+			out = append(out, libraryContainerDIDL("A:"+r.id, "A:", r.title, "object.container", ""))
+		}
+		return out, nil
+	}
+	rootID, path, _ := strings.Cut(rest, "/")
+	rootID, term, searching := strings.Cut(rootID, ":")
+	if searching {
+		var err error
+		if term, err = url.PathUnescape(term); err != nil {
+			return nil, errUPnP("402", "Invalid Args")
+		}
+	}
+	for _, root := range libraryRoots {
+		if root.id != rootID {
+			continue
+		}
+		if root.id == "PLAYLISTS" {
+			return nil, nil
+		}
+		parentID := "A:" + root.id
+		tracks := s.h.library
+		var segments []string
+		if path != "" {
+			segments = strings.Split(path, "/")
+		}
+		if len(segments) > len(root.levels) {
+			return nil, errUPnP("701", "No such object")
+		}
+		for i, seg := range segments {
+			name, err := url.PathUnescape(seg)
+			if err != nil {
+				return nil, errUPnP("701", "No such object")
+			}
+			var kept []LibraryTrack
+			for _, t := range tracks {
+				if root.levels[i].key(t) == name {
+					kept = append(kept, t)
+				}
+			}
+			tracks = kept
+			parentID += "/" + seg
+		}
+		if len(segments) > 0 && len(tracks) == 0 {
+			return nil, errUPnP("701", "No such object")
+		}
+		matches := func(name string) bool {
+			return !searching || strings.Contains(strings.ToLower(name), strings.ToLower(term))
+		}
+
+		var out []string
+		if len(segments) < len(root.levels) {
+			level := root.levels[len(segments)]
+			seen := map[string]bool{}
+			var names []string
+			for _, t := range tracks {
+				if name := level.key(t); name != "" && !seen[name] && matches(name) {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				id := parentID + "/" + url.PathEscape(name)
+				out = append(out, libraryContainerDIDL(id, parentID, name, level.class, "x-rincon-playlist:"+s.UUID+"#"+id))
+			}
+			return out, nil
+		}
+		for i, t := range tracks {
+			if !matches(t.Title) {
+				continue
+			}
+			out = append(out, trackDIDLItem(parentID+"/"+strconv.Itoa(i+1), parentID, Track{URI: t.URI, Title: t.Title, Artist: t.Artist, Album: t.Album}))
+		}
+		return out, nil
+	}
+	return nil, errUPnP("701", "No such object")
+}
+
+// libraryTracksLocked expands an x-rincon-playlist URI for a library container
+// into its tracks.
+func (s *Speaker) libraryTracksLocked(uri string) ([]Track, bool, error) {
+	_, id, ok := strings.Cut(uri, "#")
+	if !strings.HasPrefix(uri, "x-rincon-playlist:") || !ok || !strings.HasPrefix(id, "A:") {
+		return nil, false, nil
+	}
+	rest := strings.TrimPrefix(id, "A:")
+	rootID, path, _ := strings.Cut(rest, "/")
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		name, err := url.PathUnescape(seg)
+		if err != nil {
+			return nil, true, errUPnP("701", "No such object")
+		}
+		names = append(names, name)
+	}
+	for _, root := range libraryRoots {
+		if root.id != rootID || len(names) > len(root.levels) {
+			continue
+		}
+		var out []Track
+		for _, t := range s.h.library {
+			match := true
+			for i, name := range names {
+				if root.levels[i].key(t) != name {
+					match = false
+					break
+				}
+			}
+			if match {
+				out = append(out, Track{URI: t.URI, Title: t.Title, Artist: t.Artist, Album: t.Album})
+			}
+		}
+		if len(out) > 0 {
+			return out, true, nil
+		}
+	}
+	return nil, true, errUPnP("701", "No such object")
+}
+
+func libraryContainerDIDL(id, parentID, title, class, res string) string {
+	var b strings.Builder
+	b.WriteString(`<container id="` + xmlEscape(id) + `" parentID="` + xmlEscape(parentID) + `" restricted="true">`)
+	b.WriteString(`<dc:title>` + xmlEscape(title) + `</dc:title>`)
+	b.WriteString(`<upnp:class>` + xmlEscape(class) + `</upnp:class>`)
+	if res != "" {
+		b.WriteString(`<res protocolInfo="x-rincon-playlist:*:*:*">` + xmlEscape(res) + `</res>`)
+	}
+	b.WriteString(`</container>`)
+	return b.String()
+}
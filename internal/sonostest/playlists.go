@@ -0,0 +1,226 @@
+package sonostest
+
+import (
+	"strconv"
+	"strings"
+)
+
+const playlistURIPrefix = "file:///jffs/settings/savedqueues.rsq#"
+
+// Playlist is a Sonos playlist (saved queue) served as SQ:<ID>.
+type Playlist struct {
+	ID       int
+	Title    string
+	Tracks   []Track
+	UpdateID int
+}
+
+// Playlists returns a copy of the household's playlists.
+func (h *Household) Playlists() []Playlist {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Playlist, 0, len(h.playlists))
+	for _, p := range h.playlists {
+		p.Tracks = append([]Track(nil), p.Tracks...)
+		out = append(out, p)
+	}
+	return out
+}
+
+// SetPlaylists replaces the household's playlists. IDs of zero are assigned.
+func (h *Household) SetPlaylists(playlists ...Playlist) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.playlists = nil
+	for _, p := range playlists {
+		if p.ID == 0 {
+			h.nextPlaylistID++
+			p.ID = h.nextPlaylistID
+		} else if p.ID > h.nextPlaylistID {
+			h.nextPlaylistID = p.ID
+		}
+		p.Tracks = append([]Track(nil), p.Tracks...)
+		h.playlists = append(h.playlists, p)
+	}
+}
+
+func (h *Household) playlistLocked(objectID string) (*Playlist, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(objectID, "SQ:"))
+	if err != nil || !strings.HasPrefix(objectID, "SQ:") {
+		return nil, errUPnP("701", "No such object")
+	}
+	for i := range h.playlists {
+		if h.playlists[i].ID == id {
+			return &h.playlists[i], nil
+		}
+	}
+	return nil, errUPnP("701", "No such object")
+}
+
+func (h *Household) createPlaylistLocked(title string, tracks []Track) *Playlist {
+	h.nextPlaylistID++
+	h.playlists = append(h.playlists, Playlist{
+		ID:       h.nextPlaylistID,
+		Title:    title,
+		Tracks:   tracks,
+		UpdateID: 1,
+	})
+	return &h.playlists[len(h.playlists)-1]
+}
+
+// editablePlaylistLocked resolves a playlist and checks the caller's UpdateID.
+func (h *Household) editablePlaylistLocked(args map[string]string) (*Playlist, error) {
+	p, err := h.playlistLocked(args["ObjectID"])
+	if err != nil {
+		return nil, err
+	}
+	if args["UpdateID"] != strconv.Itoa(p.UpdateID) {
+		return nil, errUPnP("402", "Invalid Args (stale UpdateID)")
+	}
+	return p, nil
+}
+
+func playlistDIDLItem(p Playlist) string {
+	id := "SQ:" + strconv.Itoa(p.ID)
+	var b strings.Builder
+	b.WriteString(`<container id="` + id + `" parentID="SQ:" restricted="true">`)
+	b.WriteString(`<dc:title>` + xmlEscape(p.Title) + `</dc:title>`)
+	b.WriteString(`<upnp:class>object.container.playlistContainer</upnp:class>`)
+	b.WriteString(`<res protocolInfo="file:*:audio/mpegurl:*">` + playlistURIPrefix + strconv.Itoa(p.ID) + `</res>`)
+	b.WriteString(`</container>`)
+	return b.String()
+}
+
+func avCreateSavedQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	title := strings.TrimSpace(args["Title"])
+	if title == "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	var tracks []Track
+	if uri := args["EnqueuedURI"]; uri != "" {
+		tracks = append(tracks, trackFromMeta(uri, args["EnqueuedURIMetaData"]))
+	}
+	p := s.h.createPlaylistLocked(title, tracks)
+	return map[string]string{
+		"NumTracksAdded":   strconv.Itoa(len(tracks)),
+		"NewQueueLength":   strconv.Itoa(len(p.Tracks)),
+		"AssignedObjectID": "SQ:" + strconv.Itoa(p.ID),
+		"NewUpdateID":      strconv.Itoa(p.UpdateID),
+	}, nil
+}
+
+func avAddURIToSavedQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	p, err := s.h.editablePlaylistLocked(args)
+	if err != nil {
+		return nil, err
+	}
+	t := trackFromMeta(args["EnqueuedURI"], args["EnqueuedURIMetaData"])
+	at, err := strconv.Atoi(args["AddAtIndex"])
+	if err != nil || at < 0 || at > len(p.Tracks) {
+		at = len(p.Tracks)
+	}
+	p.Tracks = append(p.Tracks, Track{})
+	copy(p.Tracks[at+1:], p.Tracks[at:])
+	p.Tracks[at] = t
+	p.UpdateID++
+	return map[string]string{
+		"NumTracksAdded": "1",
+		"NewQueueLength": strconv.Itoa(len(p.Tracks)),
+		"NewUpdateID":    strconv.Itoa(p.UpdateID),
+	}, nil
+}
+
+// avReorderTracksInSavedQueue applies each (track, new position) pair in
+// order; an empty NewPositionList removes the listed tracks.
+func avReorderTracksInSavedQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	p, err := s.h.editablePlaylistLocked(args)
+	if err != nil {
+		return nil, err
+	}
+	parse := func(list string) ([]int, error) {
+		var out []int
+		for _, f := range strings.Split(list, ",") {
+			if f = strings.TrimSpace(f); f == "" {
+				continue
+			}
+			n, err := strconv.Atoi(f)
+			if err != nil || n < 0 || n >= len(p.Tracks) {
+				return nil, errUPnP("402", "Invalid Args")
+			}
+			out = append(out, n)
+		}
+		return out, nil
+	}
+	from, err := parse(args["TrackList"])
+	if err != nil {
+		return nil, err
+	}
+	to, err := parse(args["NewPositionList"])
+	if err != nil {
+		return nil, err
+	}
+	before := len(p.Tracks)
+	switch {
+	case len(to) == 0:
+		for _, i := range from {
+			if i >= len(p.Tracks) {
+				return nil, errUPnP("402", "Invalid Args")
+			}
+			p.Tracks = append(p.Tracks[:i], p.Tracks[i+1:]...)
+		}
+	case len(to) == len(from):
+		for k, i := range from {
+			t := p.Tracks[i]
+			p.Tracks = append(p.Tracks[:i], p.Tracks[i+1:]...)
+			j := to[k]
+			p.Tracks = append(p.Tracks, Track{})
+			copy(p.Tracks[j+1:], p.Tracks[j:])
+			p.Tracks[j] = t
+		}
+	default:
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	p.UpdateID++
+	return map[string]string{
+		"QueueLengthChange": strconv.Itoa(len(p.Tracks) - before),
+		"NewQueueLength":    strconv.Itoa(len(p.Tracks)),
+		"NewUpdateID":       strconv.Itoa(p.UpdateID),
+	}, nil
+}
+
+func avSaveQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(args["Title"])
+	if title == "" {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	tracks := append([]Track(nil), s.queue...)
+	if id := args["ObjectID"]; id != "" {
+		p, err := s.h.playlistLocked(id)
+		if err != nil {
+			return nil, err
+		}
+		p.Title = title
+		p.Tracks = tracks
+		p.UpdateID++
+		return map[string]string{"AssignedObjectID": id}, nil
+	}
+	p := s.h.createPlaylistLocked(title, tracks)
+	return map[string]string{"AssignedObjectID": "SQ:" + strconv.Itoa(p.ID)}, nil
+}
+
+func cdDestroyObject(s *Speaker, args map[string]string) (map[string]string, error) {
+	p, err := s.h.playlistLocked(args["ObjectID"])
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.h.playlists {
+		if s.h.playlists[i].ID == p.ID {
+			s.h.playlists = append(s.h.playlists[:i], s.h.playlists[i+1:]...)
+			break
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package sonostest
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// checkQueueUpdateIDLocked rejects edits planned against an older queue. An
+// empty or "0" UpdateID skips the check, as on real speakers.
+func (s *Speaker) checkQueueUpdateIDLocked(args map[string]string) error {
+	if id := args["UpdateID"]; id != "" && id != "0" && id != strconv.Itoa(s.queueUpdateID) {
+		return errUPnP("402", "Invalid Args (stale UpdateID)")
+	}
+	return nil
+}
+
+// queueRangeLocked parses 1-based StartingIndex/NumberOfTracks arguments.
+func (s *Speaker) queueRangeLocked(args map[string]string) (int, int, error) {
+	start, err := strconv.Atoi(args["StartingIndex"])
+	if err != nil || start < 1 || start > len(s.queue) {
+		return 0, 0, errUPnP("402", "Invalid Args")
+	}
+	count, err := strconv.Atoi(args["NumberOfTracks"])
+	if err != nil || count < 1 || start+count-1 > len(s.queue) {
+		return 0, 0, errUPnP("402", "Invalid Args")
+	}
+	return start, count, nil
+}
+
+func avReorderTracksInQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.checkQueueUpdateIDLocked(args); err != nil {
+		return nil, err
+	}
+	start, count, err := s.queueRangeLocked(args)
+	if err != nil {
+		return nil, err
+	}
+	before, err := strconv.Atoi(args["InsertBefore"])
+	if err != nil || before < 1 || before > len(s.queue)+1 {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	if before >= start && before <= start+count {
+		return nil, nil
+	}
+
+	// Track which entry is playing so it keeps playing after the move.
+	playing := -1
+	if s.usesQueue() && s.track >= 1 {
+		playing = s.track - 1
+	}
+	index := make([]int, len(s.queue))
+	for i := range index {
+		index[i] = i
+	}
+	moved := append([]int(nil), index[start-1:start-1+count]...)
+	rest := append(append([]int(nil), index[:start-1]...), index[start-1+count:]...)
+	at := before - 1
+	if before > start {
+		at -= count
+	}
+	order := append(append(append([]int(nil), rest[:at]...), moved...), rest[at:]...)
+	queue := make([]Track, len(order))
+	for i, from := range order {
+		queue[i] = s.queue[from]
+		if from == playing {
+			s.track = i + 1
+		}
+	}
+	s.queue = queue
+	s.queueUpdateID++
+	s.notifyLocked(serviceQueue)
+	return nil, nil
+}
+
+func avRemoveTrackRangeFromQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.checkQueueUpdateIDLocked(args); err != nil {
+		return nil, err
+	}
+	start, count, err := s.queueRangeLocked(args)
+	if err != nil {
+		return nil, err
+	}
+	s.queue = append(s.queue[:start-1], s.queue[start-1+count:]...)
+	s.queueUpdateID++
+	if s.usesQueue() {
+		switch {
+		case s.track >= start+count:
+			s.track -= count
+		case s.track >= start:
+			s.track = start
+		}
+		if s.track > len(s.queue) {
+			s.track = len(s.queue)
+		}
+	}
+	s.notifyLocked(serviceQueue)
+	return map[string]string{"NewUpdateID": strconv.Itoa(s.queueUpdateID)}, nil
+}
+
+func avAddMultipleURIsToQueue(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.checkQueueUpdateIDLocked(args); err != nil {
+		return nil, err
+	}
+	uris := strings.Fields(args["EnqueuedURIs"])
+	n, err := strconv.Atoi(args["NumberOfURIs"])
+	if err != nil || n != len(uris) || n == 0 {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	// Metadata entries are separated by single spaces; empty entries collapse.
+	metas := strings.Split(args["EnqueuedURIsMetaData"], " ")
+	tracks := make([]Track, n)
+	for i, u := range uris {
+		if decoded, err := url.PathUnescape(u); err == nil && strings.Contains(decoded, " ") {
+			u = decoded
+		}
+		meta := ""
+		if len(metas) == n {
+			meta = metas[i]
+		}
+		tracks[i] = trackFromMeta(u, meta)
+	}
+	pos := s.insertQueueTracksLocked(tracks, args)
+	return map[string]string{
+		"FirstTrackNumberEnqueued": strconv.Itoa(pos),
+		"NumTracksAdded":           strconv.Itoa(len(tracks)),
+		"NewQueueLength":           strconv.Itoa(len(s.queue)),
+		"NewUpdateID":              strconv.Itoa(s.queueUpdateID),
+	}, nil
+}
@@ -0,0 +1,226 @@
+package sonostest
+
+import (
+	"strconv"
+)
+
+var renderingControlService = &soapService{
+	name: "RenderingControl",
+	urn:  "urn:schemas-upnp-org:service:RenderingControl:1",
+	actions: map[string]actionHandler{
+		"GetVolume":         rcGetVolume,
+		"SetVolume":         rcSetVolume,
+		"SetRelativeVolume": rcSetRelativeVolume,
+		"RampToVolume":      rcRampToVolume,
+		"GetMute":           rcGetMute,
+		"SetMute":           rcSetMute,
+		"GetBass":           rcGetBass,
+		"SetBass":           rcSetBass,
+		"GetTreble":         rcGetTreble,
+		"SetTreble":         rcSetTreble,
+		"GetLoudness":       rcGetLoudness,
+		"SetLoudness":       rcSetLoudness,
+		"GetEQ":             rcGetEQ,
+		"SetEQ":             rcSetEQ,
+	},
+}
+
+var groupRenderingControlService = &soapService{
+	name: "GroupRenderingControl",
+	urn:  "urn:schemas-upnp-org:service:GroupRenderingControl:1",
+	actions: map[string]actionHandler{
+		"SnapshotGroupVolume":    grcSnapshotGroupVolume,
+		"GetGroupVolume":         grcGetGroupVolume,
+		"SetGroupVolume":         grcSetGroupVolume,
+		"SetRelativeGroupVolume": grcSetRelativeGroupVolume,
+		"GetGroupMute":           grcGetGroupMute,
+		"SetGroupMute":           grcSetGroupMute,
+	},
+}
+
+func requireMasterChannel(args map[string]string) error {
+	if ch := args["Channel"]; ch != "" && ch != "Master" {
+		return errUPnP("402", "Invalid Args")
+	}
+	return nil
+}
+
+func rcGetVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	if ch := args["Channel"]; ch == "LF" || ch == "RF" {
+		return map[string]string{"CurrentVolume": strconv.Itoa(s.eq[ch])}, nil
+	}
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	return map[string]string{"CurrentVolume": strconv.Itoa(s.volume)}, nil
+}
+
+func rcSetVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	v, err := strconv.Atoi(args["DesiredVolume"])
+	if err != nil || v < 0 || v > 100 {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	if ch := args["Channel"]; ch == "LF" || ch == "RF" {
+		s.setEQLocked(ch, v)
+		return nil, nil
+	}
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	s.volume = v
+	s.notifyLocked(serviceRenderingControl)
+	s.coordinatorLocked().notifyLocked(serviceGroupRenderingControl)
+	return nil, nil
+}
+
+func rcSetRelativeVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	adj, err := strconv.Atoi(args["Adjustment"])
+	if err != nil {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	s.volume = clampVolume(s.volume + adj)
+	s.notifyLocked(serviceRenderingControl)
+	s.coordinatorLocked().notifyLocked(serviceGroupRenderingControl)
+	return map[string]string{"NewVolume": strconv.Itoa(s.volume)}, nil
+}
+
+// rcRampToVolume jumps straight to the target; RampTime reports the one
+// second per step a real speaker would take.
+func rcRampToVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	switch args["RampType"] {
+	case "SLEEP_TIMER_RAMP_TYPE", "ALARM_RAMP_TYPE", "AUTOPLAY_RAMP_TYPE":
+	default:
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	v, err := strconv.Atoi(args["DesiredVolume"])
+	if err != nil || v < 0 || v > 100 {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	steps := v - s.volume
+	if steps < 0 {
+		steps = -steps
+	}
+	s.volume = v
+	s.lastRampType = args["RampType"]
+	s.notifyLocked(serviceRenderingControl)
+	s.coordinatorLocked().notifyLocked(serviceGroupRenderingControl)
+	return map[string]string{"RampTime": strconv.Itoa(steps)}, nil
+}
+
+func rcGetMute(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	return map[string]string{"CurrentMute": boolString(s.mute)}, nil
+}
+
+func rcSetMute(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := requireMasterChannel(args); err != nil {
+		return nil, err
+	}
+	s.mute = args["DesiredMute"] == "1"
+	s.notifyLocked(serviceRenderingControl)
+	s.coordinatorLocked().notifyLocked(serviceGroupRenderingControl)
+	return nil, nil
+}
+
+func grcSnapshotGroupVolume(s *Speaker, _ map[string]string) (map[string]string, error) {
+	return nil, s.requireCoordinatorLocked()
+}
+
+// groupVolumeLocked mirrors Sonos: the group volume is the average of the
+// member volumes.
+func (s *Speaker) groupVolumeLocked() int {
+	members := s.h.membersLocked(s.UUID)
+	if len(members) == 0 {
+		return s.volume
+	}
+	total := 0
+	for _, m := range members {
+		total += m.volume
+	}
+	return (total + len(members)/2) / len(members)
+}
+
+func (s *Speaker) groupMuteLocked() bool {
+	for _, m := range s.h.membersLocked(s.UUID) {
+		if !m.mute {
+			return false
+		}
+	}
+	return true
+}
+
+func grcGetGroupVolume(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	return map[string]string{"CurrentVolume": strconv.Itoa(s.groupVolumeLocked())}, nil
+}
+
+func grcSetGroupVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	v, err := strconv.Atoi(args["DesiredVolume"])
+	if err != nil || v < 0 || v > 100 {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	// Shift every member by the same delta, like the Sonos app's group slider.
+	delta := v - s.groupVolumeLocked()
+	for _, m := range s.h.membersLocked(s.UUID) {
+		m.volume = clampVolume(m.volume + delta)
+		m.notifyLocked(serviceRenderingControl)
+	}
+	s.notifyLocked(serviceGroupRenderingControl)
+	return nil, nil
+}
+
+func grcSetRelativeGroupVolume(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	adj, err := strconv.Atoi(args["Adjustment"])
+	if err != nil {
+		return nil, errUPnP("402", "Invalid Args")
+	}
+	for _, m := range s.h.membersLocked(s.UUID) {
+		m.volume = clampVolume(m.volume + adj)
+		m.notifyLocked(serviceRenderingControl)
+	}
+	s.notifyLocked(serviceGroupRenderingControl)
+	return map[string]string{"NewVolume": strconv.Itoa(s.groupVolumeLocked())}, nil
+}
+
+func grcGetGroupMute(s *Speaker, _ map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	return map[string]string{"CurrentMute": boolString(s.groupMuteLocked())}, nil
+}
+
+func grcSetGroupMute(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	mute := args["DesiredMute"] == "1"
+	for _, m := range s.h.membersLocked(s.UUID) {
+		m.mute = mute
+		m.notifyLocked(serviceRenderingControl)
+	}
+	s.notifyLocked(serviceGroupRenderingControl)
+	return nil, nil
+}
+
+func boolString(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
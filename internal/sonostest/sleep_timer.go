@@ -0,0 +1,70 @@
+package sonostest
+
+import (
+	"strconv"
+	"time"
+)
+
+func avConfigureSleepTimer(s *Speaker, args map[string]string) (map[string]string, error) {
+	if err := s.requireCoordinatorLocked(); err != nil {
+		return nil, err
+	}
+	raw := args["NewSleepTimerDuration"]
+	secs := 0
+	if raw != "" {
+		n, ok := parseHMS(raw)
+		if !ok {
+			return nil, errUPnP("402", "Invalid Args")
+		}
+		secs = n
+	}
+
+	s.sleepGeneration++
+	if s.sleepTimer != nil {
+		s.sleepTimer.Stop()
+		s.sleepTimer = nil
+	}
+	s.sleepEnd = time.Time{}
+	if secs > 0 {
+		d := time.Duration(secs) * time.Second
+		gen := s.sleepGeneration
+		s.sleepEnd = time.Now().Add(d)
+		s.sleepTimer = time.AfterFunc(d, func() { s.fireSleepTimer(gen) })
+	}
+	return nil, nil
+}
+
+func (s *Speaker) fireSleepTimer(gen int) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	if gen != s.sleepGeneration {
+		return
+	}
+	s.sleepTimer = nil
+	s.sleepEnd = time.Time{}
+	if s.transportState == statePlaying {
+		s.transportState = stateStopped
+		s.notifyLocked(serviceAVTransport)
+	}
+}
+
+func (s *Speaker) sleepRemainingLocked() time.Duration {
+	if s.sleepEnd.IsZero() {
+		return 0
+	}
+	if d := time.Until(s.sleepEnd); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func avGetRemainingSleepTimerDuration(s *Speaker, _ map[string]string) (map[string]string, error) {
+	remaining := ""
+	if d := s.sleepRemainingLocked(); d > 0 {
+		remaining = formatHMS(int(d.Round(time.Second).Seconds()))
+	}
+	return map[string]string{
+		"RemainingSleepTimerDuration": remaining,
+		"CurrentSleepTimerGeneration": strconv.Itoa(s.sleepGeneration),
+	}, nil
+}
@@ -0,0 +1,173 @@
+package sonostest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type upnpFault struct {
+	Code        string
+	Description string
+}
+
+func (e *upnpFault) Error() string { return "upnp error " + e.Code + ": " + e.Description }
+
+func errUPnP(code, desc string) error { return &upnpFault{Code: code, Description: desc} }
+
+type actionHandler func(s *Speaker, args map[string]string) (map[string]string, error)
+
+type soapService struct {
+	name    string
+	urn     string
+	actions map[string]actionHandler
+}
+
+var soapServices = map[string]*soapService{
+	"/MediaRenderer/AVTransport/Control":           avTransportService,
+	"/MediaRenderer/RenderingControl/Control":      renderingControlService,
+	"/MediaRenderer/GroupRenderingControl/Control": groupRenderingControlService,
+	"/MediaServer/ContentDirectory/Control":        contentDirectoryService,
+	"/ZoneGroupTopology/Control":                   zoneGroupTopologyService,
+	"/DeviceProperties/Control":                    devicePropertiesService,
+	"/AlarmClock/Control":                          alarmClockService,
+}
+
+func (s *Speaker) serveSOAP(w http.ResponseWriter, r *http.Request) {
+	svc, ok := soapServices[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 4<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	action, args, err := parseSOAPRequest(raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if header := strings.Trim(r.Header.Get("SOAPACTION"), `"`); header != "" {
+		if _, name, ok := strings.Cut(header, "#"); ok && name != action {
+			writeFault(w, errUPnP("401", "SOAPACTION does not match body"))
+			return
+		}
+	}
+
+	s.h.mu.Lock()
+	s.calls = append(s.calls, svc.name+"#"+action)
+	var resp map[string]string
+	if code, ok := s.faults[action]; ok {
+		err = errUPnP(code, "injected fault")
+	} else if fn, ok := svc.actions[action]; ok {
+		resp, err = fn(s, args)
+	} else {
+		err = errUPnP("401", "Invalid Action")
+	}
+	s.h.mu.Unlock()
+
+	if err != nil {
+		writeFault(w, err)
+		return
+	}
+	writeSOAPResponse(w, svc.urn, action, resp)
+}
+
+func parseSOAPRequest(raw []byte) (string, map[string]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	args := map[string]string{}
+	var action, key string
+	depth := 0
+	inBody := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !inBody {
+				if t.Name.Local == "Body" {
+					inBody = true
+				}
+				continue
+			}
+			depth++
+			switch depth {
+			case 1:
+				action = t.Name.Local
+			case 2:
+				key = t.Name.Local
+				args[key] = ""
+			}
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			if depth == 0 {
+				inBody = false
+				continue
+			}
+			if depth == 2 {
+				key = ""
+			}
+			depth--
+		case xml.CharData:
+			if depth == 2 && key != "" {
+				args[key] += string(t)
+			}
+		}
+	}
+	if action == "" {
+		return "", nil, errors.New("soap body has no action")
+	}
+	return action, args, nil
+}
+
+func writeSOAPResponse(w http.ResponseWriter, urn, action string, resp map[string]string) {
+	keys := make([]string, 0, len(resp))
+	for k := range resp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&b, `<u:%sResponse xmlns:u="%s">`, action, urn)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "<%s>%s</%s>", k, xmlEscape(resp[k]), k)
+	}
+	fmt.Fprintf(&b, `</u:%sResponse></s:Body></s:Envelope>`, action)
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	_, _ = io.WriteString(w, b.String())
+}
+
+func writeFault(w http.ResponseWriter, err error) {
+	var fault *upnpFault
+	if !errors.As(err, &fault) {
+		fault = &upnpFault{Code: "501", Description: err.Error()}
+	}
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>`+
+		`<faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring>`+
+		`<detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%s</errorCode><errorDescription>%s</errorDescription></UPnPError></detail>`+
+		`</s:Fault></s:Body></s:Envelope>`, xmlEscape(fault.Code), xmlEscape(fault.Description))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
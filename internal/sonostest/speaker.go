@@ -0,0 +1,305 @@
+package sonostest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	stateStopped = "STOPPED"
+	statePlaying = "PLAYING"
+	statePaused  = "PAUSED_PLAYBACK"
+	zeroTime     = "0:00:00"
+)
+
+// Track is an item in a fake queue or container.
+type Track struct {
+	URI      string
+	Title    string
+	Artist   string
+	Album    string
+	Duration string // H:MM:SS
+	// Meta is returned as-is when set; otherwise DIDL-Lite is generated.
+	Meta string
+}
+
+// Favorite is an entry in the fake Sonos Favorites container (FV:2).
+type Favorite struct {
+	Title string
+	URI   string
+	Meta  string
+}
+
+// State is a point-in-time copy of a speaker's observable state.
+type State struct {
+	TransportState  string
+	AVTransportURI  string
+	AVTransportMeta string
+	Queue           []Track
+	Track           int // 1-based; 0 when nothing is loaded
+	RelTime         string
+	PlayMode        string
+	Crossfade       bool
+	LED             bool
+	ButtonsLocked   bool
+	Volume          int
+	Mute            bool
+	Coordinator     string        // UUID
+	SleepTimer      time.Duration // remaining; 0 when off
+	LastRampType    string        // RampType of the most recent RampToVolume
+	// EQ holds Bass, Treble, Loudness, the LF/RF channel volumes and any
+	// home-theater EQTypes that have been set.
+	EQ map[string]int
+}
+
+// Speaker is one fake ZonePlayer.
+type Speaker struct {
+	Name  string
+	UUID  string
+	IP    string
+	Model string
+
+	h   *Household
+	srv *http.Server
+
+	// Guarded by h.mu.
+	transportState string
+	avURI          string
+	avMeta         string
+	queue          []Track
+	queueUpdateID  int
+	track          int
+	relTime        string
+	playMode       string
+	crossfade      bool
+	volume         int
+	mute           bool
+	lastRampType   string
+	eq             map[string]int
+	coordinator    string
+	subs           map[string]*subscription
+	failRenewals   int
+	faults         map[string]string
+	calls          []string
+	started        time.Time
+	noStatusPages  bool
+	ledOff         bool
+	buttonsLocked  bool
+	autoplay       map[string]autoplayState
+
+	// Bonding: a stereo pair's right speaker or a home-theater satellite
+	// records the primary's UUID and disappears as a room of its own.
+	bondKind     string // bondStereo or bondHomeTheater, on every bonded device
+	bondedTo     string
+	channels     string
+	unbondedName string
+
+	softwareVersion string
+	bootSeq         int
+	battery         *Battery
+
+	sleepTimer      *time.Timer
+	sleepEnd        time.Time
+	sleepGeneration int
+
+	notifyCh  chan notification
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newSpeaker(h *Household, name, uuid, ip string) *Speaker {
+	return &Speaker{
+		Name:            name,
+		UUID:            uuid,
+		IP:              ip,
+		Model:           "Sonos One",
+		softwareVersion: fakeSoftwareVersion,
+		h:               h,
+		transportState:  stateStopped,
+		relTime:         zeroTime,
+		playMode:        "NORMAL",
+		volume:          20,
+		eq:              map[string]int{"Loudness": 1, "LF": 100, "RF": 100},
+		coordinator:     uuid,
+		subs:            map[string]*subscription{},
+		faults:          map[string]string{},
+		autoplay:        map[string]autoplayState{},
+		started:         time.Now(),
+		notifyCh:        make(chan notification, 256),
+		done:            make(chan struct{}),
+	}
+}
+
+func (s *Speaker) close() {
+	s.closeOnce.Do(func() {
+		s.h.mu.Lock()
+		if s.sleepTimer != nil {
+			s.sleepTimer.Stop()
+		}
+		s.h.mu.Unlock()
+		shutdown(s.srv)
+		close(s.done)
+	})
+}
+
+func (s *Speaker) location() string {
+	return fmt.Sprintf("http://%s:1400/xml/device_description.xml", s.IP)
+}
+
+// State returns a copy of the speaker's current state.
+func (s *Speaker) State() State {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	return State{
+		TransportState:  s.transportState,
+		AVTransportURI:  s.avURI,
+		AVTransportMeta: s.avMeta,
+		Queue:           append([]Track(nil), s.queue...),
+		Track:           s.track,
+		RelTime:         s.relTime,
+		PlayMode:        s.playMode,
+		Crossfade:       s.crossfade,
+		LED:             !s.ledOff,
+		ButtonsLocked:   s.buttonsLocked,
+		Volume:          s.volume,
+		Mute:            s.mute,
+		Coordinator:     s.coordinator,
+		SleepTimer:      s.sleepRemainingLocked(),
+		LastRampType:    s.lastRampType,
+		EQ:              maps.Clone(s.eq),
+	}
+}
+
+// SetModel changes the model name reported in the device description, which
+// also decides which home-theater EQ settings the speaker accepts.
+func (s *Speaker) SetModel(model string) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.Model = model
+}
+
+// SetQueue replaces the queue and points the transport at it.
+func (s *Speaker) SetQueue(tracks ...Track) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.queue = append([]Track(nil), tracks...)
+	s.queueUpdateID++
+	s.avURI = s.queueURI()
+	s.avMeta = ""
+	s.track = 0
+	if len(s.queue) > 0 {
+		s.track = 1
+	}
+	s.relTime = zeroTime
+	s.notifyLocked(serviceAVTransport)
+	s.notifyLocked(serviceQueue)
+}
+
+// SetFavorites replaces the Sonos Favorites list. Like on real systems the
+// list is shared by the household, so every speaker serves it.
+func (s *Speaker) SetFavorites(favs ...Favorite) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.h.favorites = append([]Favorite(nil), favs...)
+}
+
+// SetVolume changes the volume as if the physical buttons were pressed.
+func (s *Speaker) SetVolume(v int) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.volume = clampVolume(v)
+	s.notifyLocked(serviceRenderingControl)
+}
+
+// SetTransportState changes the transport state (e.g. a track ending) and
+// emits the corresponding AVTransport event.
+func (s *Speaker) SetTransportState(state string) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.transportState = state
+	s.notifyLocked(serviceAVTransport)
+}
+
+// SetFault makes every call to action (e.g. "Seek") fail with the given UPnP
+// error code. An empty code clears the fault.
+func (s *Speaker) SetFault(action, code string) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	if code == "" {
+		delete(s.faults, action)
+		return
+	}
+	s.faults[action] = code
+}
+
+// Calls returns the SOAP actions received so far as "Service#Action".
+func (s *Speaker) Calls() []string {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Speaker) queueURI() string {
+	return "x-rincon-queue:" + s.UUID + "#0"
+}
+
+func (s *Speaker) usesQueue() bool {
+	return strings.HasPrefix(s.avURI, "x-rincon-queue:")
+}
+
+// ServeHTTP implements the ZonePlayer HTTP surface on port 1400.
+func (s *Speaker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/xml/device_description.xml":
+		s.serveDeviceDescription(w)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/status/"):
+		s.serveStatusPage(w, r)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/Control"):
+		s.serveSOAP(w, r)
+	case r.Method == "SUBSCRIBE":
+		s.serveSubscribe(w, r)
+	case r.Method == "UNSUBSCRIBE":
+		s.serveUnsubscribe(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Speaker) serveDeviceDescription(w http.ResponseWriter) {
+	s.h.mu.Lock()
+	model, name, version := s.Model, s.Name, s.softwareVersion
+	s.h.mu.Unlock()
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>`+
+		`<root xmlns="urn:schemas-upnp-org:device-1-0">`+
+		`<specVersion><major>1</major><minor>0</minor></specVersion>`+
+		`<device>`+
+		`<deviceType>urn:schemas-upnp-org:device:ZonePlayer:1</deviceType>`+
+		`<friendlyName>%s - %s</friendlyName>`+
+		`<manufacturer>Sonos, Inc.</manufacturer>`+
+		`<modelName>%s</modelName>`+
+		`<modelNumber>S18</modelNumber>`+
+		`<displayName>One</displayName>`+
+		`<serialNum>%s</serialNum>`+
+		`<softwareVersion>%s</softwareVersion>`+
+		`<hardwareVersion>%s</hardwareVersion>`+
+		`<seriesid>P100</seriesid>`+
+		`<UDN>uuid:%s</UDN>`+
+		`<roomName>%s</roomName>`+
+		`</device></root>`,
+		xmlEscape(s.IP), xmlEscape(model), xmlEscape(model), s.serialNumber(), xmlEscape(version), fakeHardwareVersion, s.UUID, xmlEscape(name))
+}
+
+func clampVolume(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
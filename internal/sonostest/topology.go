@@ -0,0 +1,68 @@
+package sonostest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vanishedDevice is a speaker removed with Vanish, still listed in
+// ZoneGroupState's VanishedDevices section.
+type vanishedDevice struct {
+	uuid, name, reason string
+}
+
+// SetSoftwareVersion changes the firmware version the speaker reports in its
+// device description, GetZoneInfo and ZoneGroupState.
+func (s *Speaker) SetSoftwareVersion(version string) {
+	s.h.mu.Lock()
+	defer s.h.mu.Unlock()
+	s.softwareVersion = version
+	s.h.notifyTopologyLocked()
+}
+
+// Vanish takes the room's speaker off the network the way a powered-off
+// player disappears: its server stops, it leaves its group and the rest of
+// the household lists it under VanishedDevices with the given reason.
+func (h *Household) Vanish(room, reason string) error {
+	s := h.Speaker(room)
+	if s == nil {
+		return fmt.Errorf("sonostest: unknown room %q", room)
+	}
+	h.mu.Lock()
+	for _, o := range h.speakers {
+		if o.bondedTo == s.UUID {
+			h.mu.Unlock()
+			return fmt.Errorf("sonostest: %q is a bonded room", room)
+		}
+	}
+	h.detachLocked(s)
+	for i, o := range h.speakers {
+		if o == s {
+			h.speakers = append(h.speakers[:i], h.speakers[i+1:]...)
+			break
+		}
+	}
+	h.vanished = append(h.vanished, vanishedDevice{uuid: s.UUID, name: s.Name, reason: reason})
+	h.notifyTopologyLocked()
+	h.mu.Unlock()
+	s.close()
+	return nil
+}
+
+// memberDetailAttrs renders the per-device ZoneGroupMember attributes that
+// real players report besides name, location and bonding.
+func memberDetailAttrs(s *Speaker) string {
+	return fmt.Sprintf(` SoftwareVersion="%s" BootSeq="%d" MicEnabled="1" WirelessMode="0" BehindWifiExtender="0" Orientation="0"`,
+		xmlEscape(s.softwareVersion), s.bootSeq)
+}
+
+func (h *Household) writeVanishedLocked(b *strings.Builder) {
+	if len(h.vanished) == 0 {
+		return
+	}
+	b.WriteString("<VanishedDevices>")
+	for _, d := range h.vanished {
+		fmt.Fprintf(b, `<Device UUID="%s" ZoneName="%s" Reason="%s"/>`, d.uuid, xmlEscape(d.name), xmlEscape(d.reason))
+	}
+	b.WriteString("</VanishedDevices>")
+}